@@ -1,17 +1,26 @@
 package main
 
 import (
+	"astra-api/internal/auth"
 	"astra-api/internal/cache"
+	"astra-api/internal/compress"
 	"astra-api/internal/config"
 	"astra-api/internal/handler"
 	. "astra-api/internal/handler"
 	"astra-api/internal/middleware"
+	"astra-api/internal/model"
 	"astra-api/internal/repository"
+	"astra-api/internal/scan"
 	"astra-api/internal/service"
+	"astra-api/internal/sniff"
+	"astra-api/internal/storage"
+	"context"
+	"crypto/rand"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -36,25 +45,123 @@ func main() {
 	} else {
 		log.Println("Auto-migration disabled. Use AUTO_MIGRATE=true to enable automatic migrations.")
 	}
+	if len(os.Args) > 1 && os.Args[1] == "rotate-keys" {
+		rotateKeys(cfg, db)
+		return
+	}
+
+	auth.Default.AllowQuery = cfg.AllowQueryToken
+	auth.Cookies.Secure = cfg.CookieSecure
 
 	// Initialize repositories (implementing interfaces)
 	var userRepo repository.UserRepositoryInterface = repository.NewUserRepository(db)
 	var docRepo repository.DocumentRepositoryInterface = repository.NewDocumentRepository(db)
+	var auditRepo repository.AuditRepositoryInterface = repository.NewAuditRepository(db)
+	var groupRepo repository.GroupRepositoryInterface = repository.NewGroupRepository(db)
+	var grantRepo repository.GrantRepositoryInterface = repository.NewGrantRepository(db)
+	var linkRepo repository.ShareLinkRepositoryInterface = repository.NewShareLinkRepository(db)
+	var uploadRepo repository.UploadReservationRepositoryInterface = repository.NewUploadReservationRepository(db)
+	var schemaRepo repository.SchemaRepositoryInterface = repository.NewSchemaRepository(db)
+	var folderRepo repository.FolderRepositoryInterface = repository.NewFolderRepository(db)
+	var quotaRepo repository.QuotaRepositoryInterface = repository.NewQuotaRepository(db)
+	var txManager repository.TxManagerInterface = repository.NewTxManager(db)
+
+	files, blobs := initBlobs(cfg, db)
 
 	// Initialize services (implementing interfaces)
 	var authService service.AuthServiceInterface = service.NewAuthService(userRepo, cfg.AdminToken)
-	var sessionService service.SessionServiceInterface = service.NewSessionService()
+	sessionService := initSessions(cfg, db, userRepo)
 	var docsService service.DocsServiceInterface = service.NewDocsService(docRepo)
+	var mimeService service.MimeServiceInterface = service.NewMimeService(sniff.ParseList(cfg.MimeAllow), sniff.ParseList(cfg.MimeDeny), cfg.MimeMismatch)
+	var compressionService service.CompressionServiceInterface = service.NewCompressionService(compress.Policy{Types: sniff.ParseList(cfg.CompressTypes), MaxRatio: cfg.CompressMaxRatio})
+	var scanService service.ScanServiceInterface = service.NewScanService(docRepo, blobs, contentScanner(cfg))
+	var quotaService service.QuotaServiceInterface = service.NewQuotaService(quotaRepo, docRepo, blobs, model.QuotaLimits{MaxBytes: cfg.QuotaMaxBytes, MaxDocuments: cfg.QuotaMaxDocuments, MaxFileSize: cfg.QuotaMaxFileSize})
+	var groupService service.GroupServiceInterface = service.NewGroupService(groupRepo, userRepo)
+	var accessService service.AccessServiceInterface = service.NewAccessService(userRepo, groupRepo, grantRepo)
+	var accountService service.AccountServiceInterface = service.NewAccountService(userRepo, docRepo, grantRepo, blobs, sessionService, txManager, cfg.AccountDeletionGrace)
+	var linkService service.LinkServiceInterface = service.NewLinkService(linkRepo, docRepo, accessService, signingSecret("SHARE_LINK_SECRET", cfg.ShareLinkSecret), cfg.ShareLinkTTL, cfg.ShareLinkMaxTTL)
+	var uploadService service.UploadServiceInterface = service.NewUploadService(uploadRepo, docRepo, blobs, quotaService, mimeService, scanService, compressionService, txManager, signingSecret("UPLOAD_URL_SECRET", cfg.UploadURLSecret), cfg.UploadURLTTL)
+	var schemaService service.SchemaServiceInterface = service.NewSchemaService(schemaRepo)
+	var folderService service.FolderServiceInterface = service.NewFolderService(folderRepo, docRepo, accessService, schemaService, txManager)
+	var fsService service.FSServiceInterface = service.NewFSService(userRepo, folderRepo, docRepo, accessService, folderService, schemaService, blobs, quotaService, mimeService, scanService, compressionService, txManager)
+	var reaperService service.ReaperServiceInterface = service.NewReaperService(docRepo, blobs, cfg.ReaperBatch)
+	var trashService service.TrashServiceInterface = service.NewTrashService(docRepo, accessService, schemaService, blobs, cfg.TrashRetention)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService, sessionService)
 	cache := cache.NewCache(5 * time.Minute)
-	docsHandler := handler.NewDocsHandler(docsService, cache, sessionService, userRepo)
+	docsHandler := handler.NewDocsHandler(docsService, cache, sessionService, userRepo, blobs, accessService, schemaService, folderService, quotaService, mimeService, scanService, compressionService)
+	adminHandler := handler.NewAdminHandler(sessionService, userRepo, auditRepo, reaperService, quotaService, cfg.ImpersonationTTL)
+	meHandler := handler.NewMeHandler(sessionService, accountService, quotaService)
+	groupsHandler := handler.NewGroupsHandler(sessionService, groupService)
+	shareHandler := handler.NewShareHandler(sessionService, docsService, accessService, linkService, blobs)
+	uploadsHandler := handler.NewUploadsHandler(sessionService, accessService, folderService, schemaService, uploadService, cache)
+	schemasHandler := handler.NewSchemasHandler(sessionService, schemaService)
+	foldersHandler := handler.NewFoldersHandler(sessionService, folderService, accessService, cache)
+	fsHandler := handler.NewFSHandler(sessionService, fsService, blobs, cache)
+	trashHandler := handler.NewTrashHandler(sessionService, trashService, cache)
 
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(sessionService, userRepo)
+	auditMiddleware := middleware.NewAuditMiddleware(auditRepo)
+
+	// The database enforces quotas on every document write and needs the configured defaults
+	if err := quotaService.SaveDefaults(); err != nil {
+		log.Fatalf("Failed to save default quotas: %v", err)
+	}
+
+	// Files uploaded before they were stored by document ID must be moved before they are
+	// served or counted against quotas
+	if n, err := service.MigrateLegacyBlobs(docRepo, files, cfg.ReaperBatch); err != nil {
+		log.Printf("Legacy file migration failed: %v", err)
+	} else if n > 0 {
+		log.Printf("Moved %d files stored under document names", n)
+	}
 
-	routes(authHandler, docsHandler, authMiddleware)
+	// Background jobs
+	go func() {
+		n, err := quotaService.BackfillSizes(cfg.ReaperBatch)
+		if err != nil {
+			log.Printf("File size backfill failed: %v", err)
+		} else if n > 0 {
+			log.Printf("Counted sizes of %d files uploaded before quotas", n)
+		}
+	}()
+	go service.RunEvery(context.Background(), cfg.PurgeInterval, "account purge", func() error {
+		n, err := accountService.PurgeDue(time.Now())
+		if n > 0 {
+			log.Printf("Purged %d deleted accounts", n)
+		}
+		return err
+	})
+	go service.RunEvery(context.Background(), cfg.PurgeInterval, "upload reservations purge", func() error {
+		_, err := uploadService.PurgeExpired(time.Now())
+		return err
+	})
+	go service.RunEvery(context.Background(), cfg.ReaperInterval, "expired documents reaper", func() error {
+		res, err := reaperService.Reap(time.Now())
+		if res.Documents > 0 {
+			log.Printf("Reaped %d expired documents (%d files) in %d batches", res.Documents, res.Files, res.Batches)
+		}
+		return err
+	})
+	go service.RunEvery(context.Background(), cfg.ScanInterval, "pending scans", func() error {
+		// Свежие файлы проверяются при загрузке, фоновая задача их не трогает
+		n, err := scanService.ScanPending(time.Now().Add(-cfg.ScanInterval), cfg.ScanBatch)
+		if n > 0 {
+			log.Printf("Scanned %d pending files", n)
+		}
+		return err
+	})
+	go service.RunEvery(context.Background(), cfg.PurgeInterval, "trash purge", func() error {
+		n, err := trashService.PurgeExpired(time.Now())
+		if n > 0 {
+			log.Printf("Purged %d documents from trash", n)
+		}
+		return err
+	})
+
+	routes(authHandler, docsHandler, adminHandler, meHandler, groupsHandler, shareHandler, uploadsHandler, schemasHandler, foldersHandler, fsHandler, trashHandler, authMiddleware, auditMiddleware)
 	log.Println("Server started on :8080")
 	log.Fatal(http.ListenAndServe(":8080", nil))
 }
@@ -67,6 +174,129 @@ func initConfig() *config.Config {
 	return cfg
 }
 
+func initSessions(cfg *config.Config, db *sqlx.DB, userRepo repository.UserRepositoryInterface) service.SessionServiceInterface {
+	switch cfg.SessionMode {
+	case config.SessionModeMemory:
+		return service.NewSessionService()
+	case config.SessionModeToken:
+		keys, err := service.ParseSigningKeys(cfg.TokenSigningKeys)
+		if err != nil {
+			log.Fatalf("Invalid TOKEN_SIGNING_KEYS: %v", err)
+		}
+		if len(keys) == 0 {
+			// Без заданных ключей токены перестанут проверяться после перезапуска
+			log.Println("TOKEN_SIGNING_KEYS is empty, generating an ephemeral signing key")
+			key, err := service.GenerateSigningKey("ephemeral")
+			if err != nil {
+				log.Fatalf("Cannot generate signing key: %v", err)
+			}
+			keys = append(keys, key)
+		}
+		ring, err := service.NewKeyRing(keys...)
+		if err != nil {
+			log.Fatalf("Invalid signing keys: %v", err)
+		}
+		var refreshRepo repository.RefreshTokenRepositoryInterface = repository.NewRefreshTokenRepository(db)
+		tokens := service.NewTokenService(ring, userRepo, refreshRepo, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+		// Revocation is checked against an in-memory copy; revocations made by other
+		// instances show up after the next refresh
+		if err := tokens.LoadRevocations(); err != nil {
+			log.Printf("Cannot load access token revocations: %v", err)
+		}
+		go service.RunEvery(context.Background(), cfg.TokenRevocationRefresh, "access token revocations", tokens.LoadRevocations)
+		return tokens
+	default:
+		log.Fatalf("Unknown SESSION_MODE %q", cfg.SessionMode)
+		return nil
+	}
+}
+
+func signingSecret(name, value string) []byte {
+	if value != "" {
+		return []byte(value)
+	}
+	// Без заданного секрета выданные ссылки перестанут работать после перезапуска
+	log.Printf("%s is empty, generating an ephemeral secret", name)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Cannot generate %s: %v", name, err)
+	}
+	return secret
+}
+
+// contentScanner выбирает антивирус: clamd, если задан CLAMD_ADDR, иначе без проверки
+func contentScanner(cfg *config.Config) scan.ContentScanner {
+	if cfg.ClamdAddr == "" {
+		log.Printf("CLAMD_ADDR is empty, uploaded files are not scanned")
+		return scan.Nop{}
+	}
+	clamd, err := scan.NewClamd(cfg.ClamdAddr, cfg.ClamdTimeout)
+	if err != nil {
+		log.Fatalf("Cannot configure clamd: %v", err)
+	}
+	return clamd
+}
+
+// initBlobs открывает хранилище файлов; с заданными мастер-ключами файлы шифруются.
+// Возвращает и сам каталог, и хранилище поверх него.
+func initBlobs(cfg *config.Config, db *sqlx.DB) (*storage.FileStore, storage.BlobStore) {
+	files, err := storage.NewFileStore(cfg.UploadsDir)
+	if err != nil {
+		log.Fatalf("Cannot open uploads dir: %v", err)
+	}
+	encrypted := encryptedBlobs(cfg, db, files)
+	if encrypted == nil {
+		log.Println("ENCRYPTION_KEYS is empty, files are stored unencrypted")
+		return files, files
+	}
+	return files, encrypted
+}
+
+// encryptedBlobs оборачивает хранилище шифрованием или возвращает nil, если ключи не заданы
+func encryptedBlobs(cfg *config.Config, db *sqlx.DB, files storage.BlobStore) *storage.EncryptedStore {
+	spec := cfg.EncryptionKeys
+	if cfg.EncryptionKeysFile != "" {
+		if spec != "" {
+			log.Fatal("Only one of ENCRYPTION_KEYS and ENCRYPTION_KEYS_FILE may be set")
+		}
+		data, err := os.ReadFile(cfg.EncryptionKeysFile)
+		if err != nil {
+			log.Fatalf("Cannot read ENCRYPTION_KEYS_FILE: %v", err)
+		}
+		spec = string(data)
+	}
+	masters, err := storage.ParseMasterKeys(spec)
+	if err != nil {
+		log.Fatalf("Invalid encryption keys: %v", err)
+	}
+	if len(masters) == 0 {
+		return nil
+	}
+	var keyRepo repository.BlobKeyRepositoryInterface = repository.NewBlobKeyRepository(db)
+	store, err := storage.NewEncryptedStore(files, keyRepo, masters)
+	if err != nil {
+		log.Fatalf("Cannot configure encryption: %v", err)
+	}
+	return store
+}
+
+// rotateKeys перешифровывает ключи данных текущим мастер-ключом; сами файлы не переписываются
+func rotateKeys(cfg *config.Config, db *sqlx.DB) {
+	files, err := storage.NewFileStore(cfg.UploadsDir)
+	if err != nil {
+		log.Fatalf("Cannot open uploads dir: %v", err)
+	}
+	store := encryptedBlobs(cfg, db, files)
+	if store == nil {
+		log.Fatal("ENCRYPTION_KEYS is empty, nothing to rotate")
+	}
+	n, err := store.Rotate(cfg.ReaperBatch)
+	if err != nil {
+		log.Fatalf("Key rotation failed after %d keys: %v", n, err)
+	}
+	log.Printf("Re-wrapped %d data keys", n)
+}
+
 func initDB(cfg *config.Config, attempts int, delay time.Duration) *sqlx.DB {
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
 	var db *sqlx.DB
@@ -98,10 +328,18 @@ func migrate(db *sqlx.DB) {
 	log.Println("Migrations applied successfully")
 }
 
-func routes(authHandler *handler.AuthHandler, docsHandler *handler.DocsHandler, authMiddleware *middleware.AuthMiddleware) {
+func routes(authHandler *handler.AuthHandler, docsHandler *handler.DocsHandler, adminHandler *handler.AdminHandler, meHandler *handler.MeHandler, groupsHandler *handler.GroupsHandler, shareHandler *handler.ShareHandler, uploadsHandler *handler.UploadsHandler, schemasHandler *handler.SchemasHandler, foldersHandler *handler.FoldersHandler, fsHandler *handler.FSHandler, trashHandler *handler.TrashHandler, authMiddleware *middleware.AuthMiddleware, auditMiddleware *middleware.AuditMiddleware) {
 	// Base middleware for all routes
 	baseMiddleware := middleware.ChainMiddleware(
 		middleware.LoggingMiddleware,
+		middleware.Compress,
+	)
+
+	// Logout may be authenticated by the session cookie alone
+	csrfMiddleware := middleware.ChainMiddleware(
+		middleware.LoggingMiddleware,
+		middleware.Compress,
+		middleware.CSRF,
 	)
 
 	// Public routes (no auth required)
@@ -111,16 +349,18 @@ func routes(authHandler *handler.AuthHandler, docsHandler *handler.DocsHandler,
 		case http.MethodPost:
 			baseMiddleware(authHandler.Auth)(w, r)
 		case http.MethodDelete:
-			baseMiddleware(authHandler.Logout)(w, r)
+			csrfMiddleware(authHandler.Logout)(w, r)
 		default:
 			WriteError(w, 405, "method not allowed")
 		}
 	})
 
+	http.HandleFunc("/api/auth/refresh", baseMiddleware(authHandler.Refresh))
+
 	http.HandleFunc("/api/auth/", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodDelete:
-			baseMiddleware(authHandler.Logout)(w, r)
+			csrfMiddleware(authHandler.Logout)(w, r)
 		default:
 			WriteError(w, 405, "method not allowed")
 		}
@@ -129,7 +369,10 @@ func routes(authHandler *handler.AuthHandler, docsHandler *handler.DocsHandler,
 	// Protected routes (auth required)
 	protectedMiddleware := middleware.ChainMiddleware(
 		middleware.LoggingMiddleware,
+		middleware.Compress,
+		middleware.CSRF,
 		authMiddleware.RequireAuth,
+		auditMiddleware.RecordImpersonation,
 	)
 
 	http.HandleFunc("/api/docs", func(w http.ResponseWriter, r *http.Request) {
@@ -143,10 +386,59 @@ func routes(authHandler *handler.AuthHandler, docsHandler *handler.DocsHandler,
 		}
 	})
 
+	http.HandleFunc("/api/docs/upload-url", protectedMiddleware(uploadsHandler.CreateURL))
+	http.HandleFunc("/api/docs/search", protectedMiddleware(docsHandler.Search))
+	http.HandleFunc("/api/docs/move", protectedMiddleware(foldersHandler.MoveDocuments))
+	http.HandleFunc("/api/tags", protectedMiddleware(docsHandler.Tags))
+	http.HandleFunc("/api/tags/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			protectedMiddleware(schemasHandler.AttachTag)(w, r)
+		case http.MethodDelete:
+			protectedMiddleware(schemasHandler.DetachTag)(w, r)
+		default:
+			WriteError(w, 405, "method not allowed")
+		}
+	})
+
 	http.HandleFunc("/api/docs/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/access") {
+			protectedMiddleware(docsHandler.Access)(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/links") {
+			switch r.Method {
+			case http.MethodPost:
+				protectedMiddleware(shareHandler.Create)(w, r)
+			case http.MethodGet, http.MethodHead:
+				protectedMiddleware(shareHandler.List)(w, r)
+			default:
+				WriteError(w, 405, "method not allowed")
+			}
+			return
+		}
+		if strings.Contains(r.URL.Path, "/links/") {
+			protectedMiddleware(shareHandler.Revoke)(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/grants") {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead:
+				protectedMiddleware(docsHandler.ListGrants)(w, r)
+			case http.MethodPut:
+				protectedMiddleware(docsHandler.SetGrant)(w, r)
+			case http.MethodDelete:
+				protectedMiddleware(docsHandler.RevokeGrant)(w, r)
+			default:
+				WriteError(w, 405, "method not allowed")
+			}
+			return
+		}
 		switch r.Method {
 		case http.MethodGet, http.MethodHead:
 			protectedMiddleware(docsHandler.GetByID)(w, r)
+		case http.MethodPatch:
+			protectedMiddleware(docsHandler.Update)(w, r)
 		case http.MethodDelete:
 			protectedMiddleware(docsHandler.DeleteByID)(w, r)
 		default:
@@ -154,6 +446,130 @@ func routes(authHandler *handler.AuthHandler, docsHandler *handler.DocsHandler,
 		}
 	})
 
+	http.HandleFunc("/api/groups", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			protectedMiddleware(groupsHandler.Create)(w, r)
+		case http.MethodGet, http.MethodHead:
+			protectedMiddleware(groupsHandler.List)(w, r)
+		default:
+			WriteError(w, 405, "method not allowed")
+		}
+	})
+
+	http.HandleFunc("/api/groups/", func(w http.ResponseWriter, r *http.Request) {
+		members := strings.Contains(strings.TrimPrefix(r.URL.Path, "/api/groups/"), "/")
+		switch {
+		case members && r.Method == http.MethodPut:
+			protectedMiddleware(groupsHandler.SetMember)(w, r)
+		case members && r.Method == http.MethodDelete:
+			protectedMiddleware(groupsHandler.RemoveMember)(w, r)
+		case !members && (r.Method == http.MethodGet || r.Method == http.MethodHead):
+			protectedMiddleware(groupsHandler.Get)(w, r)
+		case !members && r.Method == http.MethodDelete:
+			protectedMiddleware(groupsHandler.Delete)(w, r)
+		default:
+			WriteError(w, 405, "method not allowed")
+		}
+	})
+
+	http.HandleFunc("/api/schemas", protectedMiddleware(schemasHandler.List))
+	http.HandleFunc("/api/schemas/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			protectedMiddleware(schemasHandler.Get)(w, r)
+		case http.MethodPut:
+			protectedMiddleware(schemasHandler.Put)(w, r)
+		case http.MethodDelete:
+			protectedMiddleware(schemasHandler.Delete)(w, r)
+		default:
+			WriteError(w, 405, "method not allowed")
+		}
+	})
+
+	http.HandleFunc("/api/folders", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			protectedMiddleware(foldersHandler.Create)(w, r)
+		case http.MethodGet, http.MethodHead:
+			protectedMiddleware(foldersHandler.List)(w, r)
+		default:
+			WriteError(w, 405, "method not allowed")
+		}
+	})
+
+	http.HandleFunc("/api/folders/", func(w http.ResponseWriter, r *http.Request) {
+		grants := strings.HasSuffix(r.URL.Path, "/grants")
+		switch {
+		case grants && (r.Method == http.MethodGet || r.Method == http.MethodHead):
+			protectedMiddleware(foldersHandler.ListGrants)(w, r)
+		case grants && r.Method == http.MethodPut:
+			protectedMiddleware(foldersHandler.SetGrant)(w, r)
+		case grants && r.Method == http.MethodDelete:
+			protectedMiddleware(foldersHandler.RevokeGrant)(w, r)
+		case !grants && (r.Method == http.MethodGet || r.Method == http.MethodHead):
+			protectedMiddleware(foldersHandler.Get)(w, r)
+		case !grants && r.Method == http.MethodPatch:
+			protectedMiddleware(foldersHandler.Update)(w, r)
+		case !grants && r.Method == http.MethodDelete:
+			protectedMiddleware(foldersHandler.Delete)(w, r)
+		default:
+			WriteError(w, 405, "method not allowed")
+		}
+	})
+
+	http.HandleFunc("/api/fs/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			protectedMiddleware(fsHandler.Get)(w, r)
+		case http.MethodPut:
+			protectedMiddleware(fsHandler.Put)(w, r)
+		case http.MethodPatch:
+			protectedMiddleware(fsHandler.Move)(w, r)
+		case http.MethodDelete:
+			protectedMiddleware(fsHandler.Delete)(w, r)
+		default:
+			WriteError(w, 405, "method not allowed")
+		}
+	})
+
+	http.HandleFunc("/api/trash", protectedMiddleware(trashHandler.List))
+	http.HandleFunc("/api/trash/", func(w http.ResponseWriter, r *http.Request) {
+		restore := strings.HasSuffix(r.URL.Path, "/restore")
+		switch {
+		case restore && r.Method == http.MethodPost:
+			protectedMiddleware(trashHandler.Restore)(w, r)
+		case !restore && r.Method == http.MethodDelete:
+			protectedMiddleware(trashHandler.Delete)(w, r)
+		default:
+			WriteError(w, 405, "method not allowed")
+		}
+	})
+
+	// Загрузка по подписанной ссылке: авторизация уже проверена при выдаче ссылки
+	http.HandleFunc("/api/uploads/", baseMiddleware(uploadsHandler.Put))
+
+	// Ссылки на документы работают без сессии, токен ссылки сам по себе даёт доступ
+	http.HandleFunc("/s/", baseMiddleware(shareHandler.Open))
+
+	http.HandleFunc("/api/me", protectedMiddleware(meHandler.Delete))
+	http.HandleFunc("/api/me/export", protectedMiddleware(meHandler.Export))
+	http.HandleFunc("/api/me/usage", protectedMiddleware(meHandler.Usage))
+
+	http.HandleFunc("/api/admin/impersonate/", protectedMiddleware(adminHandler.Impersonate))
+	http.HandleFunc("/api/admin/audit", protectedMiddleware(adminHandler.Audit))
+	http.HandleFunc("/api/admin/reaper", protectedMiddleware(adminHandler.Reaper))
+	http.HandleFunc("/api/admin/quotas/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			protectedMiddleware(adminHandler.Quota)(w, r)
+		case http.MethodPut:
+			protectedMiddleware(adminHandler.SetQuota)(w, r)
+		default:
+			WriteError(w, 405, "method not allowed")
+		}
+	})
+
 	http.Handle("/docs/", http.StripPrefix("/docs/", http.FileServer(http.Dir("./docs"))))
 	httpSwagger.URL("http://localhost:8080/docs/swagger.json")
 	http.HandleFunc("/swagger/", httpSwagger.WrapHandler)
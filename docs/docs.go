@@ -18,6 +18,204 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/admin/audit": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Журнал аудита",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен администратора",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID администратора",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/impersonate/{login}": {
+            "post": {
+                "description": "Выдаёт администратору токен, действующий от имени пользователя ограниченное время.\nОтветы на запросы с этим токеном помечаются заголовком X-Impersonated-By, каждый запрос пишется в журнал аудита.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Имперсонация пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен администратора",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Логин пользователя",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.ImpersonateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/quotas/{login}": {
+            "get": {
+                "description": "Использование места пользователем, действующие лимиты и личные лимиты, если они заданы",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Квота пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен администратора",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Логин пользователя",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Личные лимиты задаются целиком: null — лимит по умолчанию, 0 — без ограничения;\nвсе null возвращают лимиты по умолчанию. Уменьшение лимита не удаляет документы:\nновые загрузки отклоняются, пока место не освободится.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Задать квоту пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен администратора",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Логин пользователя",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Личные лимиты",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.QuotaOverride"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/reaper": {
+            "get": {
+                "description": "Накоплена с запуска сервера: число проходов и ошибок, удалённые документы и файлы, итог последнего прохода",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Статистика очистки истёкших документов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен администратора",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/api/auth": {
             "post": {
                 "consumes": [
@@ -32,12 +230,1827 @@ const docTemplate = `{
                 "summary": "Логин",
                 "parameters": [
                     {
-                        "description": "Данные",
+                        "description": "Данные",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AuthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/refresh": {
+            "post": {
+                "description": "Обменивает refresh-токен на новую пару токенов (только в режиме SESSION_MODE=token)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Обновление токена",
+                "parameters": [
+                    {
+                        "description": "Refresh-токен",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/{token}": {
+            "delete": {
+                "description": "Токен берётся из пути, а если его там нет — из заголовка Authorization или cookie сессии",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Логаут",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/docs": {
+            "get": {
+                "description": "Со своим логином (или без него) — все свои документы; с чужим — только публичные\nи выданные вызывающему напрямую или через группу.\nscope=shared — чужие документы, доступные вызывающему; scope=all — свои и доступные.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Список документов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Логин",
+                        "name": "login",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "owned (по умолчанию), shared или all",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поля через запятую: id,name,mime,file,public,owner,created,json; без json содержимое не читается",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Условия на JSON: path==value, !=, \u003e, \u003e=, \u003c, \u003c=, @\u003e, path (есть), !path (нет)",
+                        "name": "where",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Теги, которые должны быть у документа все сразу",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Значение метаданных по ключу key, например meta.customer=acme",
+                        "name": "meta.key",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "meta.schema — имя своей JSON Schema: json проверяется ею, нарушения — в error.details ответа 400.\nmeta.folder — id папки с уровнем write: документ достаётся владельцу папки и проверяется её схемой.\nmeta.tags — теги (приводятся к нижнему регистру), meta.metadata — объект строковых пар ключ/значение.\nmeta.expires_at — срок жизни: время RFC 3339 или длительность (\"90m\", \"24h\", \"7d\"); истёкший документ не виден и удаляется.\nДокумент учитывается в квоте владельца: 413 — файл больше допустимого, 507 — нет места или превышено число документов.\nТип файла определяется по содержимому: без meta.mime берётся найденный, несовместимый с содержимым или запрещённый тип — 415.\nФайл проверяется антивирусом сразу после загрузки, результат — в scan_status ответа.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Загрузка документа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Метаданные: name, file, public, mime, grants, format (json, yaml, csv, ndjson, msgpack — формат поля json), schema, folder, tags, metadata, expires_at",
+                        "name": "meta",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/docs/move": {
+            "post": {
+                "description": "Все документы переносятся одной транзакцией или не переносится ни один.\nНужен manage на каждый документ и на папку; folder \"\" — перенос в корень.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Перенести документы в папку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Документы и папка",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MoveDocumentsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/docs/search": {
+            "get": {
+                "description": "Ищет по имени и строковым значениям JSON среди документов, которые вызывающий может читать.\nСинтаксис запроса как в поисковиках: слова, \"фраза\", OR, -исключение. Результаты отсортированы\nпо релевантности, совпадения во фрагменте snippet обрамлены \u003cmark\u003e...\u003c/mark\u003e, остальной текст экранирован как HTML.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Полнотекстовый поиск",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поисковый запрос",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Лимит",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/docs/upload-url": {
+            "post": {
+                "description": "Создаёт документ и возвращает короткоживущую ссылку, по которой файл загружается\nзапросом PUT без токена сессии. Метаданные те же, что meta у POST /api/docs (без file и format);\nexpires_at-длительность отсчитывается от выдачи ссылки. До загрузки документ не виден,\nно уже занимает имя в папке (409 — имя занято) и заявленный size в квоте (507 — нет места).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Ссылка для прямой загрузки файла",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Метаданные документа",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UploadURLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/docs/{id}": {
+            "get": {
+                "description": "select — вернуть только части JSON: JSON Pointer (/meta/tags/0) или JSONPath ($.items[*].name).\nОтвет — объект {выражение: значение}; JSONPath всегда даёт массив совпадений.\nformat или Accept (application/yaml, text/csv, application/x-ndjson, application/msgpack) —\nвернуть JSON-документ без обёртки в этом формате; format=json — JSON без обёртки.\nCSV — только для массивов плоских объектов, NDJSON — для массивов, иначе 406.\nФайл отдаётся после проверки антивирусом: пока она идёт — 409, заражённый или непроверяемый файл — 403.\nСжатый при хранении файл клиенту с Accept-Encoding: gzip отдаётся как хранится, с Content-Encoding: gzip; Range-запросы получают исходные байты.",
+                "produces": [
+                    "application/json",
+                    "application/yaml",
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Документ по id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "gzip — получить сжатый файл без распаковки на сервере",
+                        "name": "Accept-Encoding",
+                        "in": "header"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "JSON Pointer или JSONPath",
+                        "name": "select",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json, yaml, csv, ndjson или msgpack",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Нужен уровень manage. Документ переносится в корзину: его можно восстановить\nчерез POST /api/trash/{id}/restore, пока он не удалён окончательно.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Удалить документ",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Нужен уровень write; смена public и схемы требует manage.\njson документа проверяется его схемой и схемой папки, нарушения — в error.details ответа 400.\ntags заменяет список тегов целиком; metadata — merge patch, null удаляет ключ.\nexpires_at (нужен manage) — время RFC 3339 или длительность от текущего момента, \"\" — бессрочно.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Изменить документ",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateDocumentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/docs/{id}/access": {
+            "get": {
+                "description": "Владелец, прямые гранты, участники групп с грантом и публичный доступ.\nПолный список видят обладатели уровня share, остальные — только записи о себе.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Кто имеет доступ к документу",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/docs/{id}/grants": {
+            "get": {
+                "description": "Нужен уровень share",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Гранты документа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Нужен уровень share; выдать можно уровень не выше собственного. Повторная выдача меняет уровень и срок.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Выдать доступ",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Грант",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.GrantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Нужен уровень manage; от собственного гранта можно отказаться без него",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Отозвать доступ",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Логин, user:\u003clogin\u003e или group:\u003cname\u003e",
+                        "name": "principal",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/docs/{id}/links": {
+            "get": {
+                "description": "Нужен уровень share. Токены не возвращаются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Ссылки на документ",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Нужен уровень share. Токен ссылки возвращается только в этом ответе.\nБез expires_at ссылка живёт SHARE_LINK_TTL. Срок урезается до SHARE_LINK_MAX_TTL и до срока гранта создателя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Создать ссылку на документ",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Параметры ссылки",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/docs/{id}/links/{linkID}": {
+            "delete": {
+                "description": "Нужен уровень share",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Отозвать ссылку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID ссылки",
+                        "name": "linkID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/folders": {
+            "get": {
+                "description": "Корневые папки пользователя и чужие папки, выданные ему грантом",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Мои и доступные мне папки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "В корне или внутри папки с уровнем manage; вложенная папка принадлежит владельцу родителя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Создать папку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Имя и родитель",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateFolderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/folders/{id}": {
+            "get": {
+                "description": "Папка, уровень доступа к ней, подпапки и документы (без json); нужен уровень read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Содержимое папки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID папки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Только пустую; нужен уровень manage",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Удалить папку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID папки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Переименование — уровень write; перенос вместе с содержимым и смена схемы — manage.\nПеренос возможен только в папку того же владельца и не внутрь самой себя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Изменить папку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID папки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateFolderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/folders/{id}/grants": {
+            "get": {
+                "description": "Нужен уровень share. Гранты действуют на всё содержимое папки.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Гранты папки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID папки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Нужен уровень share; выдать можно уровень не выше собственного",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Выдать доступ к папке",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID папки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Грант",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.GrantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Нужен уровень manage; от собственного гранта можно отказаться без него",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Отозвать доступ к папке",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID папки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Логин, user:\u003clogin\u003e или group:\u003cname\u003e",
+                        "name": "principal",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/fs/{login}/{path}": {
+            "get": {
+                "description": "Для документа — его содержимое, как GET /api/docs/{id} (включая format и Accept).\nДля папки или корня — листинг. Корень виден только владельцу, остальное — с уровнем read.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fs"
+                ],
+                "summary": "Документ или каталог по пути",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Владелец пространства",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Путь: имена папок и документа через /",
+                        "name": "path",
+                        "in": "path"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Тело — содержимое. Content-Type application/json, application/yaml, text/csv,\napplication/x-ndjson или application/msgpack создаёт JSON-документ, остальное — файл\n(file=true — всегда файл). Недостающие папки создаются. Замена не меняет вид документа (иначе 409).\nТип файла проверяется по содержимому: несовместимый с ним или запрещённый Content-Type — 415.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fs"
+                ],
+                "summary": "Создать или заменить документ по пути",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Владелец пространства",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Путь документа",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Сохранить тело как файл",
+                        "name": "file",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Документ — уровень manage; папка — только пустая",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fs"
+                ],
+                "summary": "Удалить по пути",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Владелец пространства",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Путь",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "В пределах пространства владельца; каталог назначения должен существовать.\nПереименование — уровень write, перенос в другой каталог — manage.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fs"
+                ],
+                "summary": "Переименовать или перенести по пути",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Владелец пространства",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Текущий путь",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый путь",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.FSMoveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/groups": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Мои группы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создатель становится владельцем группы",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Создать группу",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Название",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateGroupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/groups/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Группа и её участники",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Удалить группу",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/groups/{id}/members/{login}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Добавить участника или сменить роль",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Логин",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Роль: member (по умолчанию) или owner",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.GroupMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Владелец может исключить любого, участник — только выйти сам",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Исключить участника",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Логин",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me": {
+            "delete": {
+                "description": "Требует пароль. Аккаунт, документы и файлы удаляются после льготного периода; вход до его окончания отменяет удаление.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Удаление аккаунта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Пароль",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.DeleteAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/export": {
+            "get": {
+                "description": "ZIP-архив: profile.json, documents.json, json/\u003cid\u003e.json и files/\u003cid\u003e/\u003cname\u003e",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Выгрузка данных пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/usage": {
+            "get": {
+                "description": "Занятые байты и число документов (включая корзину) и действующие лимиты; 0 — без ограничения.\noverride — личные лимиты, заданные администратором.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Использование места",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/register": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Регистрация",
+                "parameters": [
+                    {
+                        "description": "Данные",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/schemas": {
+            "get": {
+                "description": "Без текста схем, с числом привязанных документов",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schemas"
+                ],
+                "summary": "Мои JSON Schema",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/schemas/{name}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schemas"
+                ],
+                "summary": "JSON Schema по имени",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя схемы",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Тело — сама схема (draft 2020-12). Замена отклоняется с 409, если привязанные\nдокументы ей не соответствуют; error.details — документы и нарушения.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schemas"
+                ],
+                "summary": "Создать или заменить JSON Schema",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя схемы",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "JSON Schema",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Схему с привязанными документами удалить нельзя (409)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schemas"
+                ],
+                "summary": "Удалить JSON Schema",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя схемы",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tags": {
+            "get": {
+                "description": "По умолчанию — по своим документам. scope=shared — по чужим доступным, scope=all — по всем доступным.\nСортировка — по убыванию числа документов.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Теги и число документов с ними",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "owned (по умолчанию), shared или all",
+                        "name": "scope",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tags/{tag}/schema": {
+            "put": {
+                "description": "Схема проверяет все свои JSON-документы с тегом: при привязке (409 с error.details, если\nкакие-то не подходят), при создании и изменении документа, при замене схемы.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schemas"
+                ],
+                "summary": "Привязать JSON Schema к тегу",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тег",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Имя схемы",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.AuthRequest"
+                            "$ref": "#/definitions/model.TagSchemaRequest"
                         }
                     }
                 ],
@@ -49,22 +2062,27 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/auth/{token}": {
+            },
             "delete": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "schemas"
                 ],
-                "summary": "Логаут",
+                "summary": "Отвязать JSON Schema от тега",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "Токен",
                         "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тег",
+                        "name": "tag",
                         "in": "path",
                         "required": true
                     }
@@ -79,15 +2097,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/docs": {
+        "/api/trash": {
             "get": {
+                "description": "Свои удалённые документы, недавно удалённые первыми. purge_at — когда документ удалится окончательно.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "docs"
+                    "trash"
                 ],
-                "summary": "Список документов",
+                "summary": "Корзина",
                 "parameters": [
                     {
                         "type": "string",
@@ -96,15 +2115,9 @@ const docTemplate = `{
                         "in": "query",
                         "required": true
                     },
-                    {
-                        "type": "string",
-                        "description": "Логин",
-                        "name": "login",
-                        "in": "query"
-                    },
                     {
                         "type": "integer",
-                        "description": "Лимит",
+                        "description": "Лимит (по умолчанию 20)",
                         "name": "limit",
                         "in": "query"
                     }
@@ -117,18 +2130,18 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "consumes": [
-                    "multipart/form-data"
-                ],
+            }
+        },
+        "/api/trash/{id}": {
+            "delete": {
+                "description": "Нужен уровень manage; файл документа удаляется вместе с ним",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "docs"
+                    "trash"
                 ],
-                "summary": "Загрузка документа",
+                "summary": "Удалить документ окончательно",
                 "parameters": [
                     {
                         "type": "string",
@@ -139,16 +2152,10 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Метаданные",
-                        "name": "meta",
-                        "in": "formData",
+                        "description": "ID документа",
+                        "name": "id",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Файл",
-                        "name": "file",
-                        "in": "formData"
                     }
                 ],
                 "responses": {
@@ -161,15 +2168,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/docs/{id}": {
-            "get": {
+        "/api/trash/{id}/restore": {
+            "post": {
+                "description": "Нужен уровень manage. Документ возвращается на прежнее место (в корень, если папку удалили)\nи снова проверяется схемами; если путь занят — 409.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "docs"
+                    "trash"
                 ],
-                "summary": "Документ по id",
+                "summary": "Восстановить документ из корзины",
                 "parameters": [
                     {
                         "type": "string",
@@ -180,7 +2188,7 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "ID",
+                        "description": "ID документа",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -194,27 +2202,26 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/uploads/{token}": {
+            "put": {
+                "description": "Тело запроса — содержимое файла. Сессия не нужна, ссылка одноразовая.",
+                "consumes": [
+                    "application/octet-stream"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "docs"
                 ],
-                "summary": "Удалить документ",
+                "summary": "Загрузка файла по подписанной ссылке",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Токен",
+                        "description": "Токен загрузки",
                         "name": "token",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "ID",
-                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -225,36 +2232,74 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/model.APIResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/register": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/s/{token}": {
+            "get": {
+                "description": "Не требует сессии. Пароль передаётся заголовком X-Share-Password или полем password формы (POST).\nКаждый GET/POST учитывается в счётчике скачиваний, HEAD — нет.",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "auth"
+                    "links"
                 ],
-                "summary": "Регистрация",
+                "summary": "Скачать документ по ссылке",
                 "parameters": [
                     {
-                        "description": "Данные",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.RegisterRequest"
-                        }
+                        "type": "string",
+                        "description": "Токен ссылки",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Пароль ссылки",
+                        "name": "X-Share-Password",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
                         "schema": {
                             "$ref": "#/definitions/model.APIResponse"
                         }
@@ -270,6 +2315,9 @@ const docTemplate = `{
                 "code": {
                     "type": "integer"
                 },
+                "details": {
+                    "description": "Details — подробности ошибки, например нарушения JSON Schema"
+                },
                 "text": {
                     "type": "string"
                 }
@@ -298,6 +2346,172 @@ const docTemplate = `{
                 }
             }
         },
+        "model.CreateFolderRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "reports"
+                },
+                "parent": {
+                    "description": "Parent — id родительской папки; без него папка создаётся в корне",
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateGroupRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "accounting"
+                }
+            }
+        },
+        "model.CreateLinkRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "max_downloads": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "password": {
+                    "type": "string"
+                },
+                "permission": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Permission"
+                        }
+                    ],
+                    "example": "read"
+                }
+            }
+        },
+        "model.DeleteAccountRequest": {
+            "type": "object",
+            "properties": {
+                "pswd": {
+                    "type": "string",
+                    "example": "Qwerty123!"
+                }
+            }
+        },
+        "model.FSMoveRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string",
+                    "example": "/reports/2026/q1-final.json"
+                }
+            }
+        },
+        "model.GrantRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "permission": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Permission"
+                        }
+                    ],
+                    "example": "read"
+                },
+                "principal": {
+                    "description": "Логин, \"user:\u003clogin\u003e\" или \"group:\u003cname\u003e\"",
+                    "type": "string",
+                    "example": "group:accounting"
+                }
+            }
+        },
+        "model.GroupMemberRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "example": "member"
+                }
+            }
+        },
+        "model.ImpersonateRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "ticket #123: document is missing"
+                }
+            }
+        },
+        "model.Metadata": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "model.MoveDocumentsRequest": {
+            "type": "object",
+            "properties": {
+                "documents": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "folder": {
+                    "type": "string",
+                    "example": "5f0c7a1e-2d3b-4c5d-8e9f-0a1b2c3d4e5f"
+                }
+            }
+        },
+        "model.Permission": {
+            "type": "string",
+            "enum": [
+                "read",
+                "comment",
+                "write",
+                "share",
+                "manage"
+            ],
+            "x-enum-varnames": [
+                "PermissionRead",
+                "PermissionComment",
+                "PermissionWrite",
+                "PermissionShare",
+                "PermissionManage"
+            ]
+        },
+        "model.QuotaOverride": {
+            "type": "object",
+            "properties": {
+                "max_bytes": {
+                    "type": "integer"
+                },
+                "max_documents": {
+                    "type": "integer"
+                },
+                "max_file_size": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "3q2-7wAAAAA"
+                }
+            }
+        },
         "model.RegisterRequest": {
             "type": "object",
             "properties": {
@@ -314,6 +2528,123 @@ const docTemplate = `{
                     "example": "supersecrettoken"
                 }
             }
+        },
+        "model.TagSchemaRequest": {
+            "type": "object",
+            "properties": {
+                "schema": {
+                    "type": "string",
+                    "example": "invoice"
+                }
+            }
+        },
+        "model.UpdateDocumentRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "ExpiresAt — срок жизни: время RFC 3339 или длительность от текущего момента (\"24h\", \"7d\"), \"\" — бессрочно",
+                    "type": "string",
+                    "example": "7d"
+                },
+                "json": {
+                    "type": "object"
+                },
+                "metadata": {
+                    "description": "Metadata — merge patch метаданных: значение задаёт ключ, null удаляет",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "report.json"
+                },
+                "public": {
+                    "type": "boolean"
+                },
+                "schema": {
+                    "description": "Schema — имя схемы владельца документа для привязки, \"\" — отвязать",
+                    "type": "string",
+                    "example": "service-config"
+                },
+                "tags": {
+                    "description": "Tags — новый список тегов целиком, [] — снять все",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "invoice",
+                        "2026"
+                    ]
+                }
+            }
+        },
+        "model.UpdateFolderRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "reports-2026"
+                },
+                "parent": {
+                    "description": "Parent — id новой родительской папки, \"\" — перенести в корень",
+                    "type": "string"
+                },
+                "schema": {
+                    "description": "Schema — имя JSON Schema владельца для документов в папке, \"\" — отвязать",
+                    "type": "string"
+                }
+            }
+        },
+        "model.UploadURLRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "ExpiresAt — время RFC 3339 или длительность от момента загрузки",
+                    "type": "string",
+                    "example": "24h"
+                },
+                "folder": {
+                    "description": "Folder — id папки, в которую кладётся документ",
+                    "type": "string"
+                },
+                "grants": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "metadata": {
+                    "$ref": "#/definitions/model.Metadata"
+                },
+                "mime": {
+                    "type": "string",
+                    "example": "application/pdf"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "report.pdf"
+                },
+                "public": {
+                    "type": "boolean"
+                },
+                "schema": {
+                    "description": "Schema — имя JSON Schema загружающего, которой должен соответствовать json",
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 1048576
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
         }
     }
 }`
@@ -3,6 +3,7 @@ module astra-api
 go 1.24.3
 
 require (
+	github.com/andybalholm/brotli v1.2.6
 	github.com/google/uuid v1.6.0
 	github.com/jmoiron/sqlx v1.4.0
 	github.com/joho/godotenv v1.5.1
@@ -12,6 +13,7 @@ require (
 	github.com/swaggo/swag v1.16.6
 	go.uber.org/mock v0.5.0
 	golang.org/x/crypto v0.41.0
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
@@ -30,5 +32,4 @@ require (
 	golang.org/x/net v0.42.0 // indirect
 	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/tools v0.35.0 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExtractor_BearerHeader(t *testing.T) {
+	e := &Extractor{AllowQuery: true}
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer xyz")
+
+	token, source := e.Extract(req)
+	if token != "xyz" || source != SourceHeader {
+		t.Fatalf("expected xyz from header, got %q (%v)", token, source)
+	}
+}
+
+func TestExtractor_BearerCaseInsensitive(t *testing.T) {
+	e := &Extractor{}
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "bearer   xyz ")
+
+	if token := e.Token(req); token != "xyz" {
+		t.Fatalf("expected xyz, got %q", token)
+	}
+}
+
+func TestExtractor_BareHeader(t *testing.T) {
+	e := &Extractor{}
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "xyz")
+
+	if token := e.Token(req); token != "xyz" {
+		t.Fatalf("expected xyz, got %q", token)
+	}
+}
+
+func TestExtractor_OtherScheme(t *testing.T) {
+	e := &Extractor{}
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+
+	if token := e.Token(req); token != "" {
+		t.Fatalf("expected no token for Basic scheme, got %q", token)
+	}
+}
+
+func TestExtractor_Cookie(t *testing.T) {
+	e := &Extractor{}
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: "fromcookie"})
+
+	token, source := e.Extract(req)
+	if token != "fromcookie" || source != SourceCookie {
+		t.Fatalf("expected fromcookie from cookie, got %q (%v)", token, source)
+	}
+}
+
+func TestExtractor_HeaderWinsOverCookie(t *testing.T) {
+	e := &Extractor{}
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer fromheader")
+	req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: "fromcookie"})
+
+	if _, source := e.Extract(req); source != SourceHeader {
+		t.Fatalf("expected header to take precedence, got %v", source)
+	}
+}
+
+func TestExtractor_QueryDisabled(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/test?token=q", nil)
+
+	if token := (&Extractor{AllowQuery: true}).Token(req); token != "q" {
+		t.Fatalf("expected q, got %q", token)
+	}
+	if token := (&Extractor{AllowQuery: false}).Token(req); token != "" {
+		t.Fatalf("expected query token to be ignored, got %q", token)
+	}
+}
+
+func TestExtractor_Form(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("token", "formtoken")
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/test", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	token, source := (&Extractor{}).Extract(req)
+	if token != "formtoken" || source != SourceForm {
+		t.Fatalf("expected formtoken from form, got %q (%v)", token, source)
+	}
+}
+
+func TestSetSessionCookies(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	csrf, err := SetSessionCookies(rr, "tok", time.Time{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	cookies := map[string]*http.Cookie{}
+	for _, c := range rr.Result().Cookies() {
+		cookies[c.Name] = c
+	}
+	session, ok := cookies[SessionCookieName]
+	if !ok || session.Value != "tok" {
+		t.Fatal("expected session cookie with token")
+	}
+	if !session.HttpOnly || session.SameSite != http.SameSiteStrictMode {
+		t.Fatal("expected session cookie to be HttpOnly and SameSite=Strict")
+	}
+	csrfCookie, ok := cookies[CSRFCookieName]
+	if !ok || csrfCookie.Value != csrf || csrf == "" {
+		t.Fatal("expected csrf cookie matching returned token")
+	}
+	if csrfCookie.HttpOnly {
+		t.Fatal("expected csrf cookie to be readable by scripts")
+	}
+}
+
+func TestValidCSRF(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/test", nil)
+	req.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: "abc"})
+
+	if ValidCSRF(req) {
+		t.Fatal("expected missing header to fail")
+	}
+	req.Header.Set(CSRFHeaderName, "abd")
+	if ValidCSRF(req) {
+		t.Fatal("expected mismatched header to fail")
+	}
+	req.Header.Set(CSRFHeaderName, "abc")
+	if !ValidCSRF(req) {
+		t.Fatal("expected matching header to pass")
+	}
+}
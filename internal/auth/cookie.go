@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"time"
+)
+
+const (
+	// SessionCookieName holds the session token; HttpOnly so scripts cannot read it
+	SessionCookieName = "astra_session"
+	// CSRFCookieName holds the double-submit token that scripts echo back in CSRFHeaderName
+	CSRFCookieName = "astra_csrf"
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// CookieOptions configures the cookies set on login
+type CookieOptions struct {
+	// Secure should be enabled whenever the API is served over HTTPS
+	Secure bool
+}
+
+// Cookies are the options used by SetSessionCookies
+var Cookies = CookieOptions{}
+
+// SetSessionCookies issues the session cookie and a fresh CSRF cookie.
+// A zero expires produces browser-session cookies.
+func SetSessionCookies(w http.ResponseWriter, token string, expires time.Time) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	csrf := base64.RawURLEncoding.EncodeToString(raw)
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   Cookies.Secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookieName,
+		Value:    csrf,
+		Path:     "/",
+		Expires:  expires,
+		Secure:   Cookies.Secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return csrf, nil
+}
+
+// ClearSessionCookies expires both cookies
+func ClearSessionCookies(w http.ResponseWriter) {
+	for _, name := range []string{SessionCookieName, CSRFCookieName} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    "",
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: name == SessionCookieName,
+			Secure:   Cookies.Secure,
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
+}
+
+// ValidCSRF checks the double-submit token: the header must repeat the CSRF cookie
+func ValidCSRF(r *http.Request) bool {
+	c, err := r.Cookie(CSRFCookieName)
+	if err != nil || c.Value == "" {
+		return false
+	}
+	header := r.Header.Get(CSRFHeaderName)
+	return header != "" && subtle.ConstantTimeCompare([]byte(header), []byte(c.Value)) == 1
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Source tells where the request token was found
+type Source int
+
+const (
+	SourceNone Source = iota
+	SourceHeader
+	SourceCookie
+	SourceQuery
+	SourceForm
+)
+
+// Extractor finds the session token in a request. It is shared by the
+// handlers and the auth middleware so both accept exactly the same inputs.
+type Extractor struct {
+	// AllowQuery enables the legacy ?token= parameter. Query strings end up
+	// in access logs and proxies, so deployments should turn it off.
+	AllowQuery bool
+}
+
+// Default is the extractor used by handler.GetToken and the middleware
+var Default = &Extractor{AllowQuery: true}
+
+// Token returns the token without its source
+func (e *Extractor) Token(r *http.Request) string {
+	token, _ := e.Extract(r)
+	return token
+}
+
+// Extract looks for a token in the Authorization header, the session cookie,
+// the query string (if allowed) and a multipart form field, in that order.
+func (e *Extractor) Extract(r *http.Request) (string, Source) {
+	if token := parseAuthorization(r.Header.Get("Authorization")); token != "" {
+		return token, SourceHeader
+	}
+	if c, err := r.Cookie(SessionCookieName); err == nil && c.Value != "" {
+		return c.Value, SourceCookie
+	}
+	if e.AllowQuery {
+		if token := r.URL.Query().Get("token"); token != "" {
+			return token, SourceQuery
+		}
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err == nil {
+			if token := r.FormValue("token"); token != "" {
+				return token, SourceForm
+			}
+		}
+	}
+	return "", SourceNone
+}
+
+// parseAuthorization accepts "Bearer <token>" and, for older clients,
+// a bare token without a scheme
+func parseAuthorization(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	scheme, token, ok := strings.Cut(value, " ")
+	if !ok {
+		return value
+	}
+	if !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
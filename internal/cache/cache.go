@@ -29,6 +29,22 @@ func (c *Cache) Set(key string, value interface{}) {
 	c.store.Store(key, CacheItem{Value: value, Expiration: expiration})
 }
 
+// SetUntil сохраняет значение не дольше deadline: запись истекает по TTL кэша
+// или в deadline, что раньше. Нулевой deadline — как Set.
+func (c *Cache) SetUntil(key string, value interface{}, deadline time.Time) {
+	if deadline.IsZero() {
+		c.Set(key, value)
+		return
+	}
+	expiration := deadline.UnixNano()
+	if c.ttl > 0 {
+		if ttl := time.Now().Add(c.ttl).UnixNano(); ttl < expiration {
+			expiration = ttl
+		}
+	}
+	c.store.Store(key, CacheItem{Value: value, Expiration: expiration})
+}
+
 func (c *Cache) Get(key string) (interface{}, bool) {
 	item, ok := c.store.Load(key)
 	if !ok {
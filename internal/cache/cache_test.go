@@ -223,3 +223,26 @@ func TestCache_DifferentTypes(t *testing.T) {
 		t.Fatal("map value incorrect")
 	}
 }
+
+func TestCache_SetUntil(t *testing.T) {
+	cache := NewCache(time.Minute)
+
+	cache.SetUntil("past", "value", time.Now().Add(-time.Second))
+	if _, ok := cache.Get("past"); ok {
+		t.Fatal("expected entry with passed deadline to be expired")
+	}
+
+	cache.SetUntil("soon", "value", time.Now().Add(20*time.Millisecond))
+	if _, ok := cache.Get("soon"); !ok {
+		t.Fatal("expected entry to exist before deadline")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := cache.Get("soon"); ok {
+		t.Fatal("expected entry to expire at deadline")
+	}
+
+	cache.SetUntil("none", "value", time.Time{})
+	if _, ok := cache.Get("none"); !ok {
+		t.Fatal("expected entry without deadline to exist")
+	}
+}
@@ -0,0 +1,192 @@
+// Package codec переводит JSON-документы в другие форматы и обратно: YAML, CSV,
+// NDJSON и MessagePack. Значения — то, что даёт json.Unmarshal в interface{}.
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown format")
+	// ErrNotTabular — CSV и NDJSON умеют только массивы (CSV — массивы плоских объектов)
+	ErrNotTabular = errors.New("document is not an array of flat objects")
+)
+
+type Format string
+
+const (
+	FormatJSON    Format = "json"
+	FormatYAML    Format = "yaml"
+	FormatCSV     Format = "csv"
+	FormatNDJSON  Format = "ndjson"
+	FormatMsgpack Format = "msgpack"
+)
+
+var contentTypes = map[Format]string{
+	FormatJSON:    "application/json",
+	FormatYAML:    "application/yaml",
+	FormatCSV:     "text/csv; charset=utf-8",
+	FormatNDJSON:  "application/x-ndjson",
+	FormatMsgpack: "application/msgpack",
+}
+
+var mimeFormats = map[string]Format{
+	"application/json":        FormatJSON,
+	"application/yaml":        FormatYAML,
+	"application/x-yaml":      FormatYAML,
+	"text/yaml":               FormatYAML,
+	"text/x-yaml":             FormatYAML,
+	"text/csv":                FormatCSV,
+	"application/x-ndjson":    FormatNDJSON,
+	"application/ndjson":      FormatNDJSON,
+	"application/jsonl":       FormatNDJSON,
+	"application/msgpack":     FormatMsgpack,
+	"application/x-msgpack":   FormatMsgpack,
+	"application/vnd.msgpack": FormatMsgpack,
+}
+
+// ParseFormat принимает имя формата (json, yaml, yml, csv, ndjson, jsonl, msgpack) или MIME-тип
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "csv":
+		return FormatCSV, nil
+	case "ndjson", "jsonl":
+		return FormatNDJSON, nil
+	case "msgpack", "messagepack":
+		return FormatMsgpack, nil
+	}
+	if f, ok := FromMime(s); ok {
+		return f, nil
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownFormat, s)
+}
+
+// FromMime определяет формат по Content-Type, параметры вроде charset игнорируются
+func FromMime(contentType string) (Format, bool) {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	f, ok := mimeFormats[mt]
+	return f, ok
+}
+
+func (f Format) ContentType() string {
+	return contentTypes[f]
+}
+
+// Negotiate выбирает формат по заголовку Accept с учётом q. Подстановки (*/*, application/*)
+// не выбирают формат: решение остаётся за вызывающим. ok=false — подходящего формата нет.
+func Negotiate(accept string) (Format, bool) {
+	best, bestQ := Format(""), 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		f, ok := mimeFormats[mt]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > bestQ {
+			best, bestQ = f, q
+		}
+	}
+	return best, best != ""
+}
+
+// Encode пишет значение в формате f
+func Encode(w io.Writer, f Format, v interface{}) error {
+	switch f {
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(v)
+	case FormatYAML:
+		return encodeYAML(w, v)
+	case FormatCSV:
+		return encodeCSV(w, v)
+	case FormatNDJSON:
+		rows, ok := v.([]interface{})
+		if !ok {
+			return ErrNotTabular
+		}
+		enc := json.NewEncoder(w)
+		for _, row := range rows {
+			if err := enc.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatMsgpack:
+		var buf bytes.Buffer
+		if err := encodeMsgpack(&buf, v); err != nil {
+			return err
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownFormat, f)
+	}
+}
+
+// Decode читает документ в формате f и возвращает значение, пригодное для json.Marshal
+func Decode(r io.Reader, f Format) (interface{}, error) {
+	var v interface{}
+	switch f {
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	case FormatYAML:
+		return decodeYAML(r)
+	case FormatCSV:
+		return decodeCSV(r)
+	case FormatNDJSON:
+		rows := []interface{}{}
+		dec := json.NewDecoder(r)
+		for {
+			var row interface{}
+			if err := dec.Decode(&row); err == io.EOF {
+				return rows, nil
+			} else if err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+	case FormatMsgpack:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMsgpack(data)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownFormat, f)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,105 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func decodeJSON(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("bad test json: %v", err)
+	}
+	return v
+}
+
+func TestRoundTrip(t *testing.T) {
+	doc := decodeJSON(t, `{"name": "конфиг", "n": 3, "ratio": 0.25, "neg": -40000, "on": true, "none": null,
+		"list": [1, "two", {"deep": [false]}], "long": "`+strings.Repeat("x", 300)+`"}`)
+	for _, f := range []Format{FormatJSON, FormatYAML, FormatMsgpack} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, f, doc); err != nil {
+			t.Fatalf("%s: encode: %v", f, err)
+		}
+		got, err := Decode(&buf, f)
+		if err != nil {
+			t.Fatalf("%s: decode: %v", f, err)
+		}
+		if !reflect.DeepEqual(got, doc) {
+			t.Fatalf("%s: round trip mismatch: %v", f, got)
+		}
+	}
+}
+
+func TestTabular(t *testing.T) {
+	rows := decodeJSON(t, `[{"id": 1, "name": "a,b", "zip": "007"}, {"id": 2, "active": true}]`)
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, FormatCSV, rows); err != nil {
+		t.Fatalf("csv encode: %v", err)
+	}
+	want := "active,id,name,zip\n,1,\"a,b\",007\ntrue,2,,\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected csv:\n%s", buf.String())
+	}
+	got, err := Decode(&buf, FormatCSV)
+	if err != nil {
+		t.Fatalf("csv decode: %v", err)
+	}
+	wantRows := decodeJSON(t, `[{"active": null, "id": 1, "name": "a,b", "zip": "007"}, {"active": true, "id": 2, "name": null, "zip": null}]`)
+	if !reflect.DeepEqual(got, wantRows) {
+		t.Fatalf("unexpected csv rows: %v", got)
+	}
+
+	buf.Reset()
+	if err := Encode(&buf, FormatNDJSON, rows); err != nil {
+		t.Fatalf("ndjson encode: %v", err)
+	}
+	if strings.Count(buf.String(), "\n") != 2 {
+		t.Fatalf("expected one line per row, got %q", buf.String())
+	}
+	if got, err := Decode(&buf, FormatNDJSON); err != nil || !reflect.DeepEqual(got, rows) {
+		t.Fatalf("ndjson round trip: %v, %v", got, err)
+	}
+
+	for _, v := range []interface{}{decodeJSON(t, `{"a": 1}`), decodeJSON(t, `[{"a": {"b": 1}}]`), decodeJSON(t, `[1]`)} {
+		if err := Encode(&bytes.Buffer{}, FormatCSV, v); !errors.Is(err, ErrNotTabular) {
+			t.Fatalf("%v: expected ErrNotTabular, got %v", v, err)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]Format{
+		"application/yaml":                          FormatYAML,
+		"text/csv;q=0.5, application/x-ndjson":      FormatNDJSON,
+		"text/html, application/msgpack;q=0.9, */*": FormatMsgpack,
+		"*/*":       "",
+		"text/html": "",
+	}
+	for accept, want := range cases {
+		got, ok := Negotiate(accept)
+		if got != want || ok != (want != "") {
+			t.Fatalf("%q: expected %q, got %q", accept, want, got)
+		}
+	}
+	if f, err := ParseFormat("text/yaml; charset=utf-8"); err != nil || f != FormatYAML {
+		t.Fatalf("expected yaml from mime type, got %q, %v", f, err)
+	}
+	if _, err := ParseFormat("xml"); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestDecodeMsgpack_Malformed(t *testing.T) {
+	for _, data := range [][]byte{{0xdc, 0xff, 0xff}, {0xa5, 'a'}, {0xc1}, {0x01, 0x02}} {
+		if _, err := Decode(bytes.NewReader(data), FormatMsgpack); err == nil {
+			t.Fatalf("% x: expected error", data)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package codec
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// encodeCSV пишет массив плоских объектов: заголовок — объединение ключей по алфавиту,
+// отсутствующие и null значения — пустые ячейки
+func encodeCSV(w io.Writer, v interface{}) error {
+	rows, ok := v.([]interface{})
+	if !ok {
+		return ErrNotTabular
+	}
+	seen := map[string]interface{}{}
+	for _, row := range rows {
+		obj, ok := row.(map[string]interface{})
+		if !ok {
+			return ErrNotTabular
+		}
+		for key, val := range obj {
+			switch val.(type) {
+			case map[string]interface{}, []interface{}:
+				return ErrNotTabular
+			}
+			seen[key] = nil
+		}
+	}
+	header := sortedKeys(seen)
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(header))
+	for _, row := range rows {
+		obj := row.(map[string]interface{})
+		for i, key := range header {
+			record[i] = csvCell(obj[key])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvCell(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case bool:
+		return strconv.FormatBool(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// decodeCSV читает таблицу с заголовком в массив объектов. Числа и true/false
+// становятся числами и булевыми значениями, пустые ячейки — null.
+func decodeCSV(r io.Reader) (interface{}, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return []interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows := []interface{}{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		obj := make(map[string]interface{}, len(header))
+		for i, key := range header {
+			obj[key] = csvValue(record[i])
+		}
+		rows = append(rows, obj)
+	}
+}
+
+// csvValue распознаёт только числа в синтаксисе JSON: "007" и "1e" остаются строками
+func csvValue(s string) interface{} {
+	switch s {
+	case "":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if (s[0] == '-' || (s[0] >= '0' && s[0] <= '9')) && json.Valid([]byte(s)) {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
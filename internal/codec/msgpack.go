@@ -0,0 +1,265 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var errMsgpackTruncated = errors.New("msgpack: unexpected end of data")
+
+// encodeMsgpack кодирует значения из encoding/json. Целые числа пишутся как int,
+// чтобы не раздувать данные, остальные — как float64.
+func encodeMsgpack(buf *bytes.Buffer, v interface{}) error {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if t {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case float64:
+		if t == math.Trunc(t) && t >= math.MinInt64 && t < math.MaxInt64 {
+			writeMsgpackInt(buf, int64(t))
+		} else {
+			buf.WriteByte(0xcb)
+			binary.Write(buf, binary.BigEndian, math.Float64bits(t))
+		}
+	case string:
+		n := len(t)
+		switch {
+		case n < 32:
+			buf.WriteByte(0xa0 | byte(n))
+		case n <= math.MaxUint8:
+			buf.WriteByte(0xd9)
+			buf.WriteByte(byte(n))
+		case n <= math.MaxUint16:
+			buf.WriteByte(0xda)
+			binary.Write(buf, binary.BigEndian, uint16(n))
+		default:
+			buf.WriteByte(0xdb)
+			binary.Write(buf, binary.BigEndian, uint32(n))
+		}
+		buf.WriteString(t)
+	case []interface{}:
+		writeMsgpackHeader(buf, len(t), 0x90, 0xdc, 0xdd)
+		for _, item := range t {
+			if err := encodeMsgpack(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		writeMsgpackHeader(buf, len(t), 0x80, 0xde, 0xdf)
+		for _, key := range sortedKeys(t) {
+			if err := encodeMsgpack(buf, key); err != nil {
+				return err
+			}
+			if err := encodeMsgpack(buf, t[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+	return nil
+}
+
+func writeMsgpackHeader(buf *bytes.Buffer, n int, fix, code16, code32 byte) {
+	switch {
+	case n < 16:
+		buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(code16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(code32)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+func writeMsgpackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i < 128:
+		buf.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+// decodeMsgpack читает одно значение. Двоичные данные становятся строками,
+// расширения (ext) не поддерживаются.
+func decodeMsgpack(data []byte) (interface{}, error) {
+	d := &msgpackDecoder{data: data}
+	v, err := d.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, errors.New("msgpack: trailing data")
+	}
+	return v, nil
+}
+
+// maxMsgpackDepth защищает от переполнения стека на вложенных массивах
+const maxMsgpackDepth = 256
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) take(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errMsgpackTruncated
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *msgpackDecoder) uint(n int) (uint64, error) {
+	b, err := d.take(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+func (d *msgpackDecoder) value(depth int) (interface{}, error) {
+	if depth > maxMsgpackDepth {
+		return nil, errors.New("msgpack: nesting too deep")
+	}
+	b, err := d.take(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return float64(c), nil
+	case c >= 0xe0:
+		return float64(int8(c)), nil
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	case c&0xf0 == 0x90:
+		return d.array(int(c&0x0f), depth)
+	case c&0xf0 == 0x80:
+		return d.object(int(c&0x0f), depth)
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(1 << (c - 0xcc))
+		return float64(u), err
+	case 0xd0:
+		u, err := d.uint(1)
+		return float64(int8(u)), err
+	case 0xd1:
+		u, err := d.uint(2)
+		return float64(int16(u)), err
+	case 0xd2:
+		u, err := d.uint(4)
+		return float64(int32(u)), err
+	case 0xd3:
+		u, err := d.uint(8)
+		return float64(int64(u)), err
+	case 0xca:
+		u, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := d.uint(8)
+		return math.Float64frombits(u), err
+	case 0xd9, 0xda, 0xdb, 0xc4, 0xc5, 0xc6:
+		size := 1
+		switch c {
+		case 0xda, 0xc5:
+			size = 2
+		case 0xdb, 0xc6:
+			size = 4
+		}
+		n, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		return d.str(int(n))
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(int(n), depth)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.object(int(n), depth)
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type 0x%02x", c)
+}
+
+func (d *msgpackDecoder) str(n int) (interface{}, error) {
+	b, err := d.take(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) array(n, depth int) (interface{}, error) {
+	// каждый элемент занимает хотя бы байт: не выделяем память под заведомо ложную длину
+	if n > len(d.data)-d.pos {
+		return nil, errMsgpackTruncated
+	}
+	out := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func (d *msgpackDecoder) object(n, depth int) (interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgpackTruncated
+	}
+	out := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		out[key] = v
+	}
+	return out, nil
+}
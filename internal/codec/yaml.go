@@ -0,0 +1,51 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+func encodeYAML(w io.Writer, v interface{}) error {
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+func decodeYAML(r io.Reader) (interface{}, error) {
+	var v interface{}
+	if err := yaml.NewDecoder(r).Decode(&v); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return normalizeYAML(v), nil
+}
+
+// normalizeYAML приводит значения yaml.v2 к виду encoding/json: ключи объектов — строки,
+// целые — float64, как после json.Unmarshal
+func normalizeYAML(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			m[fmt.Sprint(k)] = normalizeYAML(val)
+		}
+		return m
+	case []interface{}:
+		for i := range t {
+			t[i] = normalizeYAML(t[i])
+		}
+		return t
+	case int:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case uint64:
+		return float64(t)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,287 @@
+// Package compress сжимает содержимое файлов перед записью в хранилище и читает
+// сжатое. Сжимать ли файл, решают его тип и то, насколько сжимается его начало.
+package compress
+
+import (
+	"astra-api/internal/sniff"
+	"astra-api/internal/storage"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Gzip — файл хранится сжатым gzip; пустая кодировка — файл хранится как есть
+const Gzip = "gzip"
+
+// SampleSize — по скольким первым байтам оценивается сжимаемость
+const SampleSize = 64 << 10
+
+// minSample — файлы меньше этого не сжимаются: выигрыш не окупает заголовок gzip
+const minSample = 512
+
+// ErrUnknownEncoding — файл записан кодировкой, которую этот пакет не читает
+var ErrUnknownEncoding = errors.New("unknown blob encoding")
+
+// compressible — двоичные форматы, которые хорошо сжимаются; текстовые типы
+// (sniff.Textual) сжимаются и без этого списка
+var compressible = map[string]bool{
+	"image/bmp": true, "image/tiff": true, "image/svg+xml": true, "application/x-tar": true,
+	"application/vnd.sqlite3": true, "application/x-elf": true, "application/pdf": true,
+	"application/vnd.microsoft.portable-executable": true, "application/x-ole-storage": true,
+	"application/msword": true, "application/vnd.ms-excel": true, "application/wasm": true,
+}
+
+// Policy решает, какие файлы сжимать
+type Policy struct {
+	// Types — типы, которые стоит пробовать сжимать; пустой — текстовые типы
+	// и двоичные форматы, которые заведомо сжимаются
+	Types sniff.List
+	// MaxRatio — файл сжимается, если сжатое начало не больше этой доли исходного;
+	// 0 отключает сжатие
+	MaxRatio float64
+}
+
+// Candidate сообщает, что файл такого типа стоит пробовать сжимать
+func (p Policy) Candidate(mime string) bool {
+	if p.MaxRatio <= 0 {
+		return false
+	}
+	if len(p.Types) > 0 {
+		return p.Types.Match(mime)
+	}
+	return Compressible(mime)
+}
+
+// Compressible сообщает, что содержимое такого типа обычно хорошо сжимается: текст
+// и несжатые двоичные форматы. Картинки, видео, аудио и архивы уже сжаты.
+func Compressible(mime string) bool {
+	return sniff.Textual(mime) || compressible[sniff.Normalize(mime)]
+}
+
+// Worth сжимает образец и сообщает, что сжатие окупается
+func (p Policy) Worth(sample []byte) bool {
+	if len(sample) < minSample {
+		return false
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write(sample)
+	_ = zw.Close()
+	return float64(buf.Len()) <= p.MaxRatio*float64(len(sample))
+}
+
+// Packed — поток для записи в хранилище: сжатый или исходный
+type Packed struct {
+	io.Reader
+	// Encoding — Gzip или пусто, если файл записывается как есть
+	Encoding string
+	src      *countingReader
+}
+
+// Size возвращает число прочитанных исходных байт — после записи это размер файла
+func (p *Packed) Size() int64 {
+	return p.src.n
+}
+
+// Pack читает начало r и решает, сжимать ли файл с типом mime. Ошибки чтения r
+// возвращаются из Read как есть.
+func (p Policy) Pack(mime string, r io.Reader) (*Packed, error) {
+	src := &countingReader{r: r}
+	if !p.Candidate(mime) {
+		return &Packed{Reader: src, src: src}, nil
+	}
+	sample := make([]byte, SampleSize)
+	n, err := io.ReadFull(r, sample)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	sample = sample[:n]
+	src.r = io.MultiReader(bytes.NewReader(sample), r)
+	if !p.Worth(sample) {
+		return &Packed{Reader: src, src: src}, nil
+	}
+	return &Packed{Reader: newGzipReader(src), Encoding: Gzip, src: src}, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// gzipReader сжимает src по мере чтения, без горутины: если хранилище перестало
+// читать на ошибке, сжимать дальше некому
+type gzipReader struct {
+	src   io.Reader
+	zw    *gzip.Writer
+	out   bytes.Buffer
+	chunk []byte
+	done  bool
+}
+
+func newGzipReader(src io.Reader) *gzipReader {
+	g := &gzipReader{src: src, chunk: make([]byte, 32<<10)}
+	g.zw = gzip.NewWriter(&g.out)
+	return g
+}
+
+func (g *gzipReader) Read(p []byte) (int, error) {
+	for g.out.Len() == 0 && !g.done {
+		n, err := g.src.Read(g.chunk)
+		if n > 0 {
+			_, _ = g.zw.Write(g.chunk[:n])
+		}
+		if err == io.EOF {
+			_ = g.zw.Close()
+			g.done = true
+		} else if err != nil {
+			return 0, err
+		}
+	}
+	if g.out.Len() == 0 {
+		return 0, io.EOF
+	}
+	return g.out.Read(p)
+}
+
+// Open открывает файл key для чтения исходного содержимого: сжатый распаковывается
+// на лету. size — исходный размер файла, по нему Seek находит конец без распаковки.
+func Open(blobs storage.BlobStore, key, encoding string, size int64) (io.ReadSeekCloser, error) {
+	switch encoding {
+	case "":
+		return blobs.Open(key)
+	case Gzip:
+		raw, err := blobs.Open(key)
+		if err != nil {
+			return nil, err
+		}
+		return &gunzipReader{raw: raw, size: size}, nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownEncoding, encoding)
+	}
+}
+
+// gunzipReader распаковывает файл последовательно. Seek только запоминает позицию:
+// чтение вперёд пропускает распакованные байты, назад — начинает распаковку заново.
+// Так Range-запросы к сжатому файлу работают без распаковки его целиком в память.
+type gunzipReader struct {
+	raw  io.ReadSeekCloser
+	zr   *gzip.Reader
+	size int64
+	// pos — позиция следующего Read, dec — сколько байт уже распаковано
+	pos, dec int64
+}
+
+func (g *gunzipReader) Read(p []byte) (int, error) {
+	if g.pos >= g.size {
+		return 0, io.EOF
+	}
+	if g.zr == nil || g.dec > g.pos {
+		if err := g.rewind(); err != nil {
+			return 0, err
+		}
+	}
+	if g.dec < g.pos {
+		n, err := io.CopyN(io.Discard, g.zr, g.pos-g.dec)
+		g.dec += n
+		if err != nil {
+			return 0, unexpected(err)
+		}
+	}
+	if rest := g.size - g.pos; int64(len(p)) > rest {
+		p = p[:rest]
+	}
+	n, err := g.zr.Read(p)
+	g.pos += int64(n)
+	g.dec += int64(n)
+	if err == io.EOF && g.pos < g.size {
+		return n, io.ErrUnexpectedEOF
+	}
+	if err == io.EOF || err == nil && g.pos == g.size {
+		// Дочитываем поток, чтобы gzip сверил контрольную сумму
+		if _, err := io.Copy(io.Discard, g.zr); err != nil {
+			return n, err
+		}
+		return n, io.EOF
+	}
+	return n, err
+}
+
+func (g *gunzipReader) rewind() error {
+	if _, err := g.raw.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	var err error
+	if g.zr == nil {
+		g.zr, err = gzip.NewReader(g.raw)
+	} else {
+		err = g.zr.Reset(g.raw)
+	}
+	g.dec = 0
+	return err
+}
+
+func (g *gunzipReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += g.pos
+	case io.SeekEnd:
+		offset += g.size
+	default:
+		return 0, errors.New("compress: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("compress: negative position")
+	}
+	g.pos = offset
+	return offset, nil
+}
+
+func (g *gunzipReader) Close() error {
+	return g.raw.Close()
+}
+
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Accepts сообщает, что заголовок Accept-Encoding разрешает кодировку coding:
+// она (или *) указана без q=0. Явно указанная кодировка важнее *.
+func Accepts(header, coding string) bool {
+	explicit, wildcard := -1.0, -1.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		switch name {
+		case coding, "x-" + coding:
+			explicit = q
+		case "*":
+			wildcard = q
+		}
+	}
+	if explicit >= 0 {
+		return explicit > 0
+	}
+	return wildcard > 0
+}
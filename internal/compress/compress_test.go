@@ -0,0 +1,178 @@
+package compress
+
+import (
+	"astra-api/internal/sniff"
+	"astra-api/internal/storage"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newStore(t *testing.T) *storage.FileStore {
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	return store
+}
+
+func TestPolicy_Candidate(t *testing.T) {
+	p := Policy{MaxRatio: 0.9}
+	for _, m := range []string{"text/plain", "application/json; charset=utf-8", "text/csv", "application/vnd.sqlite3"} {
+		if !p.Candidate(m) {
+			t.Errorf("expected %q to be compressed", m)
+		}
+	}
+	for _, m := range []string{"image/png", "application/zip", "video/mp4", sniff.Unknown} {
+		if p.Candidate(m) {
+			t.Errorf("expected %q to be stored as is", m)
+		}
+	}
+	if !(Policy{Types: sniff.ParseList("image/png"), MaxRatio: 0.9}).Candidate("image/png") {
+		t.Error("expected configured types to replace the defaults")
+	}
+	if (Policy{}).Candidate("text/plain") {
+		t.Error("expected zero MaxRatio to disable compression")
+	}
+}
+
+func TestPolicy_Pack(t *testing.T) {
+	p := Policy{MaxRatio: 0.9}
+	text := strings.Repeat(`{"name":"alpha","count":1}`+"\n", 5000)
+
+	packed, err := p.Pack("application/json", strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored, _ := io.ReadAll(packed)
+	if packed.Encoding != Gzip || packed.Size() != int64(len(text)) || len(stored) >= len(text)/5 {
+		t.Fatalf("unexpected result: encoding %q, size %d, stored %d", packed.Encoding, packed.Size(), len(stored))
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(stored))
+	if err != nil {
+		t.Fatalf("stored data is not gzip: %v", err)
+	}
+	if plain, _ := io.ReadAll(zr); string(plain) != text {
+		t.Fatal("stored data does not decompress to the original")
+	}
+
+	// Случайные байты не сжимаются: файл пишется как есть
+	random := make([]byte, 100000)
+	_, _ = rand.Read(random)
+	packed, _ = p.Pack("text/plain", bytes.NewReader(random))
+	if stored, _ := io.ReadAll(packed); packed.Encoding != "" || !bytes.Equal(stored, random) || packed.Size() != 100000 {
+		t.Fatalf("expected incompressible data to be stored as is, encoding %q", packed.Encoding)
+	}
+
+	packed, _ = p.Pack("text/plain", strings.NewReader("short"))
+	if stored, _ := io.ReadAll(packed); packed.Encoding != "" || string(stored) != "short" {
+		t.Fatalf("expected small file to be stored as is, encoding %q", packed.Encoding)
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("read failed") }
+
+func TestPolicy_Pack_ReadError(t *testing.T) {
+	p := Policy{MaxRatio: 0.9}
+	r := io.MultiReader(strings.NewReader(strings.Repeat("a", SampleSize+10)), errReader{})
+	packed, err := p.Pack("text/plain", r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := io.ReadAll(packed); err == nil || err.Error() != "read failed" {
+		t.Fatalf("expected the read error to pass through, got %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	store := newStore(t)
+	plain := make([]byte, 200000)
+	for i := range plain {
+		plain[i] = byte(i % 251)
+	}
+	packed, _ := Policy{MaxRatio: 0.9}.Pack("application/octet-stream", bytes.NewReader(plain))
+	if packed.Encoding != "" {
+		t.Fatal("expected unknown type to be stored as is")
+	}
+	packed, _ = Policy{Types: sniff.ParseList("*/*"), MaxRatio: 0.9}.Pack("application/octet-stream", bytes.NewReader(plain))
+	if _, err := store.Put("f", packed); err != nil || packed.Encoding != Gzip {
+		t.Fatalf("unexpected result %q, %v", packed.Encoding, err)
+	}
+
+	f, err := Open(store, "f", Gzip, int64(len(plain)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	if got, err := io.ReadAll(f); err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("unexpected content, %v", err)
+	}
+	if end, err := f.Seek(0, io.SeekEnd); err != nil || end != int64(len(plain)) {
+		t.Fatalf("expected size %d, got %d, %v", len(plain), end, err)
+	}
+	// Range вперёд и назад, как их читает http.ServeContent
+	for _, r := range [][2]int{{100, 200}, {150000, 150010}, {5, 6}, {199990, 200000}} {
+		if _, err := f.Seek(int64(r[0]), io.SeekStart); err != nil {
+			t.Fatalf("seek: %v", err)
+		}
+		got := make([]byte, r[1]-r[0])
+		if _, err := io.ReadFull(f, got); err != nil || !bytes.Equal(got, plain[r[0]:r[1]]) {
+			t.Fatalf("range %v: unexpected content, %v", r, err)
+		}
+	}
+
+	if _, err := Open(store, "f", "br", 1); !errors.Is(err, ErrUnknownEncoding) {
+		t.Fatalf("expected ErrUnknownEncoding, got %v", err)
+	}
+	_, _ = store.Put("plain", strings.NewReader("as is"))
+	f2, _ := Open(store, "plain", "", 5)
+	defer f2.Close()
+	if got, _ := io.ReadAll(f2); string(got) != "as is" {
+		t.Fatalf("unexpected content %q", got)
+	}
+}
+
+func TestOpen_Corrupted(t *testing.T) {
+	store := newStore(t)
+	text := strings.Repeat("corrupt me ", 1000)
+	packed, _ := Policy{MaxRatio: 0.9}.Pack("text/plain", strings.NewReader(text))
+	stored, _ := io.ReadAll(packed)
+	// Последние 8 байт — CRC32 и размер
+	stored[len(stored)-8] ^= 0xFF
+	_, _ = store.Put("f", bytes.NewReader(stored))
+
+	f, _ := Open(store, "f", Gzip, int64(len(text)))
+	defer f.Close()
+	if _, err := io.ReadAll(f); err == nil {
+		t.Fatal("expected checksum error")
+	}
+}
+
+func TestAccepts(t *testing.T) {
+	cases := []struct {
+		header string
+		want   bool
+	}{
+		{"gzip", true},
+		{"deflate, GZIP;q=0.5", true},
+		{"x-gzip", true},
+		{"*", true},
+		{"", false},
+		{"br", false},
+		{"gzip;q=0", false},
+		{"gzip;q=0.000, *", false},
+		{"*;q=0", false},
+		{"br, *;q=0.1", true},
+	}
+	for _, c := range cases {
+		if got := Accepts(c.header, Gzip); got != c.want {
+			t.Errorf("Accepts(%q) = %v, want %v", c.header, got, c.want)
+		}
+	}
+}
@@ -3,10 +3,19 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	// SessionModeMemory — непрозрачные токены, сессии в памяти процесса
+	SessionModeMemory = "memory"
+	// SessionModeToken — подписанные access-токены и refresh-токены в БД
+	SessionModeToken = "token"
+)
+
 type Config struct {
 	DBHost      string
 	DBPort      string
@@ -15,6 +24,70 @@ type Config struct {
 	DBName      string
 	AdminToken  string
 	AutoMigrate bool
+
+	SessionMode      string
+	TokenSigningKeys string
+	AccessTokenTTL   time.Duration
+	RefreshTokenTTL  time.Duration
+	// TokenRevocationRefresh — как часто перечитывать отзыв access-токенов, сделанный другими экземплярами
+	TokenRevocationRefresh time.Duration
+
+	AllowQueryToken bool
+	CookieSecure    bool
+
+	ImpersonationTTL time.Duration
+
+	UploadsDir           string
+	AccountDeletionGrace time.Duration
+	PurgeInterval        time.Duration
+	// TrashRetention — сколько удалённые документы хранятся в корзине
+	TrashRetention time.Duration
+	// ReaperInterval и ReaperBatch — период очистки документов с истёкшим expires_at и размер пачки
+	ReaperInterval time.Duration
+	ReaperBatch    int
+
+	// QuotaMaxBytes, QuotaMaxDocuments и QuotaMaxFileSize — лимиты по умолчанию
+	// на место, число документов и размер файла; 0 — без ограничения
+	QuotaMaxBytes     int64
+	QuotaMaxDocuments int64
+	QuotaMaxFileSize  int64
+
+	// MimeAllow и MimeDeny — разрешённые и запрещённые типы файлов через запятую,
+	// можно image/*; пустой MimeAllow разрешает всё, что не запрещено
+	MimeAllow string
+	MimeDeny  string
+	// MimeMismatch — что делать, если заявленный тип не совпал с содержимым:
+	// reject — отклонить загрузку, flag — принять с найденным типом
+	MimeMismatch string
+
+	// ClamdAddr — адрес clamd для проверки файлов (tcp://host:port или unix:///path);
+	// пусто — файлы не проверяются и сразу считаются чистыми
+	ClamdAddr    string
+	ClamdTimeout time.Duration
+	// ScanInterval и ScanBatch — период и размер пачки фоновой проверки файлов,
+	// которые не удалось проверить при загрузке
+	ScanInterval time.Duration
+	ScanBatch    int
+
+	// CompressTypes — типы файлов, которые стоит сжимать при записи; пусто — текстовые
+	// и заведомо сжимаемые двоичные. CompressMaxRatio — файл сжимается, если его начало
+	// сжимается хотя бы до этой доли; 0 отключает сжатие
+	CompressTypes    string
+	CompressMaxRatio float64
+
+	// EncryptionKeys — мастер-ключи шифрования файлов "id:base64,...", первый — текущий;
+	// EncryptionKeysFile — файл с ключами в том же формате, по одному на строке.
+	// Без ключей файлы хранятся как есть
+	EncryptionKeys     string
+	EncryptionKeysFile string
+
+	ShareLinkSecret string
+	ShareLinkTTL    time.Duration
+	// ShareLinkMaxTTL — наибольший срок жизни ссылки; 0 — без ограничения
+	ShareLinkMaxTTL time.Duration
+
+	UploadURLSecret string
+	UploadURLTTL    time.Duration
 }
 
 func LoadConfig(envFile string) *Config {
@@ -28,6 +101,16 @@ func LoadConfig(envFile string) *Config {
 		autoMigrate = true
 	}
 
+	sessionMode := os.Getenv("SESSION_MODE")
+	if sessionMode == "" {
+		sessionMode = SessionModeMemory
+	}
+
+	uploadsDir := os.Getenv("UPLOADS_DIR")
+	if uploadsDir == "" {
+		uploadsDir = "uploads"
+	}
+
 	return &Config{
 		DBHost:      os.Getenv("DB_HOST"),
 		DBPort:      os.Getenv("DB_PORT"),
@@ -36,5 +119,144 @@ func LoadConfig(envFile string) *Config {
 		DBName:      os.Getenv("DB_NAME"),
 		AdminToken:  os.Getenv("ADMIN_TOKEN"),
 		AutoMigrate: autoMigrate,
+
+		SessionMode:      sessionMode,
+		TokenSigningKeys: os.Getenv("TOKEN_SIGNING_KEYS"),
+		AccessTokenTTL:   getPositiveDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:  getPositiveDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		TokenRevocationRefresh: getPositiveDuration("TOKEN_REVOCATION_REFRESH", 30*time.Second),
+
+		AllowQueryToken: getBool("ALLOW_QUERY_TOKEN", true),
+		CookieSecure:    getBool("COOKIE_SECURE", false),
+
+		ImpersonationTTL: getPositiveDuration("IMPERSONATION_TTL", 30*time.Minute),
+
+		UploadsDir:           uploadsDir,
+		AccountDeletionGrace: getDuration("ACCOUNT_DELETION_GRACE", 7*24*time.Hour),
+		PurgeInterval:        getPositiveDuration("PURGE_INTERVAL", time.Hour),
+		TrashRetention:       getPositiveDuration("TRASH_RETENTION", 30*24*time.Hour),
+		ReaperInterval:       getPositiveDuration("REAPER_INTERVAL", time.Minute),
+		ReaperBatch:          getInt("REAPER_BATCH", 500),
+
+		QuotaMaxBytes:     getSize("QUOTA_MAX_BYTES", 10<<30),
+		QuotaMaxDocuments: getSize("QUOTA_MAX_DOCUMENTS", 100000),
+		QuotaMaxFileSize:  getSize("QUOTA_MAX_FILE_SIZE", 1<<30),
+
+		MimeAllow:    os.Getenv("MIME_ALLOW"),
+		MimeDeny:     os.Getenv("MIME_DENY"),
+		MimeMismatch: getChoice("MIME_MISMATCH", "reject", "reject", "flag"),
+
+		ClamdAddr:    os.Getenv("CLAMD_ADDR"),
+		ClamdTimeout: getPositiveDuration("CLAMD_TIMEOUT", 30*time.Second),
+		ScanInterval: getPositiveDuration("SCAN_INTERVAL", time.Minute),
+		ScanBatch:    getInt("SCAN_BATCH", 50),
+
+		CompressTypes:    os.Getenv("COMPRESS_TYPES"),
+		CompressMaxRatio: getRatio("COMPRESS_MAX_RATIO", 0.9),
+
+		EncryptionKeys:     os.Getenv("ENCRYPTION_KEYS"),
+		EncryptionKeysFile: os.Getenv("ENCRYPTION_KEYS_FILE"),
+
+		ShareLinkSecret: os.Getenv("SHARE_LINK_SECRET"),
+		ShareLinkTTL:    getPositiveDuration("SHARE_LINK_TTL", 24*time.Hour),
+		ShareLinkMaxTTL: getDuration("SHARE_LINK_MAX_TTL", 30*24*time.Hour),
+
+		UploadURLSecret: os.Getenv("UPLOAD_URL_SECRET"),
+		UploadURLTTL:    getPositiveDuration("UPLOAD_URL_TTL", 15*time.Minute),
+	}
+}
+
+// getDuration читает длительность в формате time.ParseDuration, при ошибке берёт значение по умолчанию
+func getDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("Некорректное значение %s=%q, используется %v", key, v, def)
+		return def
+	}
+	return d
+}
+
+// getPositiveDuration читает длительность, как getDuration, но 0 и отрицательные значения
+// считает ошибкой: периоды фоновых задач и сроки жизни токенов и ссылок должны быть больше нуля
+func getPositiveDuration(key string, def time.Duration) time.Duration {
+	d := getDuration(key, def)
+	if d <= 0 {
+		log.Printf("Некорректное значение %s=%q, используется %v", key, os.Getenv(key), def)
+		return def
+	}
+	return d
+}
+
+// getBool читает флаг "true"/"false", пустое значение означает значение по умолчанию
+func getBool(key string, def bool) bool {
+	switch os.Getenv(key) {
+	case "true":
+		return true
+	case "false":
+		return false
+	default:
+		return def
+	}
+}
+
+// getChoice читает одно из допустимых значений, при ошибке берёт значение по умолчанию
+func getChoice(key, def string, allowed ...string) string {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	for _, a := range allowed {
+		if v == a {
+			return v
+		}
+	}
+	log.Printf("Некорректное значение %s=%q, используется %v", key, v, def)
+	return def
+}
+
+// getInt читает положительное целое, при ошибке берёт значение по умолчанию
+func getInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Printf("Некорректное значение %s=%q, используется %v", key, v, def)
+		return def
+	}
+	return n
+}
+
+// getSize читает неотрицательное целое (0 — без ограничения), при ошибке берёт значение по умолчанию
+func getSize(key string, def int64) int64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		log.Printf("Некорректное значение %s=%q, используется %v", key, v, def)
+		return def
+	}
+	return n
+}
+
+// getRatio читает долю от 0 до 1, при ошибке берёт значение по умолчанию
+func getRatio(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 || f > 1 {
+		log.Printf("Некорректное значение %s=%q, используется %v", key, v, def)
+		return def
 	}
+	return f
 }
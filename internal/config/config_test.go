@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig_WithEnvFile(t *testing.T) {
@@ -203,3 +204,143 @@ func TestLoadConfig_EmptyValues(t *testing.T) {
 		t.Fatal("expected AutoMigrate to be false")
 	}
 }
+
+func TestLoadConfig_SessionDefaults(t *testing.T) {
+	os.Unsetenv("SESSION_MODE")
+	os.Unsetenv("ACCESS_TOKEN_TTL")
+	os.Unsetenv("REFRESH_TOKEN_TTL")
+	os.Unsetenv("IMPERSONATION_TTL")
+
+	config := LoadConfig("nonexistent.env")
+
+	if config.SessionMode != SessionModeMemory {
+		t.Fatalf("expected SessionMode %q, got %q", SessionModeMemory, config.SessionMode)
+	}
+	if config.AccessTokenTTL != 15*time.Minute {
+		t.Fatalf("expected AccessTokenTTL 15m, got %v", config.AccessTokenTTL)
+	}
+	if config.RefreshTokenTTL != 30*24*time.Hour {
+		t.Fatalf("expected RefreshTokenTTL 720h, got %v", config.RefreshTokenTTL)
+	}
+	if config.ImpersonationTTL != 30*time.Minute {
+		t.Fatalf("expected ImpersonationTTL 30m, got %v", config.ImpersonationTTL)
+	}
+}
+
+func TestLoadConfig_TokenMode(t *testing.T) {
+	os.Setenv("SESSION_MODE", "token")
+	os.Setenv("TOKEN_SIGNING_KEYS", "k1:c2VlZA==")
+	os.Setenv("ACCESS_TOKEN_TTL", "5m")
+	os.Setenv("REFRESH_TOKEN_TTL", "not-a-duration")
+	defer func() {
+		os.Unsetenv("SESSION_MODE")
+		os.Unsetenv("TOKEN_SIGNING_KEYS")
+		os.Unsetenv("ACCESS_TOKEN_TTL")
+		os.Unsetenv("REFRESH_TOKEN_TTL")
+	}()
+
+	config := LoadConfig("nonexistent.env")
+
+	if config.SessionMode != SessionModeToken {
+		t.Fatalf("expected SessionMode %q, got %q", SessionModeToken, config.SessionMode)
+	}
+	if config.TokenSigningKeys != "k1:c2VlZA==" {
+		t.Fatalf("expected TokenSigningKeys to be read, got %q", config.TokenSigningKeys)
+	}
+	if config.AccessTokenTTL != 5*time.Minute {
+		t.Fatalf("expected AccessTokenTTL 5m, got %v", config.AccessTokenTTL)
+	}
+	if config.RefreshTokenTTL != 30*24*time.Hour {
+		t.Fatalf("expected invalid RefreshTokenTTL to fall back to default, got %v", config.RefreshTokenTTL)
+	}
+}
+
+func TestLoadConfig_TokenTransport(t *testing.T) {
+	os.Unsetenv("ALLOW_QUERY_TOKEN")
+	os.Unsetenv("COOKIE_SECURE")
+
+	config := LoadConfig("nonexistent.env")
+	if !config.AllowQueryToken {
+		t.Fatal("expected AllowQueryToken to default to true")
+	}
+	if config.CookieSecure {
+		t.Fatal("expected CookieSecure to default to false")
+	}
+
+	os.Setenv("ALLOW_QUERY_TOKEN", "false")
+	os.Setenv("COOKIE_SECURE", "true")
+	defer func() {
+		os.Unsetenv("ALLOW_QUERY_TOKEN")
+		os.Unsetenv("COOKIE_SECURE")
+	}()
+
+	config = LoadConfig("nonexistent.env")
+	if config.AllowQueryToken {
+		t.Fatal("expected AllowQueryToken to be false")
+	}
+	if !config.CookieSecure {
+		t.Fatal("expected CookieSecure to be true")
+	}
+}
+
+func TestLoadConfig_AccountDeletion(t *testing.T) {
+	os.Unsetenv("UPLOADS_DIR")
+	os.Unsetenv("ACCOUNT_DELETION_GRACE")
+	os.Unsetenv("PURGE_INTERVAL")
+
+	config := LoadConfig("nonexistent.env")
+	if config.UploadsDir != "uploads" {
+		t.Fatalf("expected UploadsDir 'uploads', got %s", config.UploadsDir)
+	}
+	if config.AccountDeletionGrace != 7*24*time.Hour {
+		t.Fatalf("expected AccountDeletionGrace 168h, got %v", config.AccountDeletionGrace)
+	}
+	if config.PurgeInterval != time.Hour {
+		t.Fatalf("expected PurgeInterval 1h, got %v", config.PurgeInterval)
+	}
+}
+
+func TestLoadConfig_ShareLinks(t *testing.T) {
+	os.Unsetenv("SHARE_LINK_SECRET")
+	os.Setenv("SHARE_LINK_TTL", "2h")
+	defer os.Unsetenv("SHARE_LINK_TTL")
+
+	config := LoadConfig("nonexistent.env")
+	if config.ShareLinkSecret != "" {
+		t.Fatalf("expected empty ShareLinkSecret, got %q", config.ShareLinkSecret)
+	}
+	if config.ShareLinkTTL != 2*time.Hour {
+		t.Fatalf("expected ShareLinkTTL 2h, got %v", config.ShareLinkTTL)
+	}
+}
+
+func TestLoadConfig_UploadURLDefaults(t *testing.T) {
+	os.Unsetenv("UPLOAD_URL_SECRET")
+	os.Unsetenv("UPLOAD_URL_TTL")
+
+	config := LoadConfig("nonexistent.env")
+	if config.UploadURLTTL != 15*time.Minute {
+		t.Fatalf("expected UploadURLTTL 15m, got %v", config.UploadURLTTL)
+	}
+}
+
+func TestLoadConfig_NonPositiveDurations(t *testing.T) {
+	os.Setenv("PURGE_INTERVAL", "0s")
+	os.Setenv("ACCESS_TOKEN_TTL", "-5m")
+	os.Setenv("SHARE_LINK_MAX_TTL", "0")
+	defer os.Unsetenv("PURGE_INTERVAL")
+	defer os.Unsetenv("ACCESS_TOKEN_TTL")
+	defer os.Unsetenv("SHARE_LINK_MAX_TTL")
+
+	config := LoadConfig("nonexistent.env")
+	if config.PurgeInterval != time.Hour {
+		t.Fatalf("expected default PurgeInterval 1h, got %v", config.PurgeInterval)
+	}
+	if config.AccessTokenTTL != 15*time.Minute {
+		t.Fatalf("expected default AccessTokenTTL 15m, got %v", config.AccessTokenTTL)
+	}
+	// 0 means no limit for ShareLinkMaxTTL
+	if config.ShareLinkMaxTTL != 0 {
+		t.Fatalf("expected ShareLinkMaxTTL 0, got %v", config.ShareLinkMaxTTL)
+	}
+}
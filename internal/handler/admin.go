@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"astra-api/internal/service"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AdminHandler struct {
+	sessionService   service.SessionServiceInterface
+	userRepo         repository.UserRepositoryInterface
+	auditRepo        repository.AuditRepositoryInterface
+	reaper           service.ReaperServiceInterface
+	quota            service.QuotaServiceInterface
+	impersonationTTL time.Duration
+}
+
+func NewAdminHandler(sessionService service.SessionServiceInterface, userRepo repository.UserRepositoryInterface, auditRepo repository.AuditRepositoryInterface, reaper service.ReaperServiceInterface, quota service.QuotaServiceInterface, impersonationTTL time.Duration) *AdminHandler {
+	return &AdminHandler{sessionService: sessionService, userRepo: userRepo, auditRepo: auditRepo, reaper: reaper, quota: quota, impersonationTTL: impersonationTTL}
+}
+
+// @Summary Имперсонация пользователя
+// @Description Выдаёт администратору токен, действующий от имени пользователя ограниченное время.
+// @Description Ответы на запросы с этим токеном помечаются заголовком X-Impersonated-By, каждый запрос пишется в журнал аудита.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен администратора"
+// @Param login path string true "Логин пользователя"
+// @Param input body model.ImpersonateRequest false "Причина"
+// @Success 200 {object} model.APIResponse
+// @Router /api/admin/impersonate/{login} [post]
+func (h *AdminHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	if r.Method != http.MethodPost {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	login := strings.TrimPrefix(r.URL.Path, "/api/admin/impersonate/")
+	if login == "" || strings.Contains(login, "/") {
+		WriteError(w, 400, "missing login")
+		return
+	}
+	var req model.ImpersonateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	target, err := h.userRepo.GetByLogin(login)
+	if err != nil {
+		WriteError(w, 404, "user not found")
+		return
+	}
+	if target.ID == sess.UserID {
+		WriteError(w, 400, "cannot impersonate yourself")
+		return
+	}
+
+	// Без записи в журнале сессию не выдаём
+	entry := &model.AuditEntry{
+		ID:        uuid.New().String(),
+		ActorID:   sess.UserID,
+		UserID:    target.ID,
+		Action:    model.AuditImpersonationStart,
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Status:    200,
+		Details:   req.Reason,
+		CreatedAt: time.Now(),
+	}
+	if err := h.auditRepo.Create(entry); err != nil {
+		WriteError(w, 500, "cannot write audit log")
+		return
+	}
+	token := h.sessionService.CreateImpersonation(sess, target, h.impersonationTTL)
+	if token == "" {
+		WriteError(w, 500, "cannot issue token")
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Response: map[string]interface{}{
+		"token":      token,
+		"login":      target.Login,
+		"expires_at": entry.CreatedAt.Add(h.impersonationTTL),
+	}})
+}
+
+// @Summary Журнал аудита
+// @Tags admin
+// @Produce json
+// @Param token query string true "Токен администратора"
+// @Param actor query string false "ID администратора"
+// @Param limit query int false "Лимит"
+// @Success 200 {object} model.APIResponse
+// @Router /api/admin/audit [get]
+func (h *AdminHandler) Audit(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	limit := 100
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	entries, err := h.auditRepo.List(r.URL.Query().Get("actor"), limit)
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"entries": entries}})
+}
+
+// @Summary Статистика очистки истёкших документов
+// @Description Накоплена с запуска сервера: число проходов и ошибок, удалённые документы и файлы, итог последнего прохода
+// @Tags admin
+// @Produce json
+// @Param token query string true "Токен администратора"
+// @Success 200 {object} model.APIResponse
+// @Router /api/admin/reaper [get]
+func (h *AdminHandler) Reaper(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: h.reaper.Stats()})
+}
+
+// @Summary Квота пользователя
+// @Description Использование места пользователем, действующие лимиты и личные лимиты, если они заданы
+// @Tags admin
+// @Produce json
+// @Param token query string true "Токен администратора"
+// @Param login path string true "Логин пользователя"
+// @Success 200 {object} model.APIResponse
+// @Router /api/admin/quotas/{login} [get]
+func (h *AdminHandler) Quota(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	user, ok := h.quotaUser(w, r)
+	if !ok {
+		return
+	}
+	report, err := h.quota.Usage(user.ID)
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: report})
+}
+
+// @Summary Задать квоту пользователя
+// @Description Личные лимиты задаются целиком: null — лимит по умолчанию, 0 — без ограничения;
+// @Description все null возвращают лимиты по умолчанию. Уменьшение лимита не удаляет документы:
+// @Description новые загрузки отклоняются, пока место не освободится.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен администратора"
+// @Param login path string true "Логин пользователя"
+// @Param input body model.QuotaOverride true "Личные лимиты"
+// @Success 200 {object} model.APIResponse
+// @Router /api/admin/quotas/{login} [put]
+func (h *AdminHandler) SetQuota(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	if r.Method != http.MethodPut {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	user, ok := h.quotaUser(w, r)
+	if !ok {
+		return
+	}
+	var req model.QuotaOverride
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	report, err := h.quota.SetOverride(user.ID, &req)
+	if errors.Is(err, model.ErrInvalidQuota) {
+		WriteError(w, 400, err.Error())
+		return
+	}
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: report})
+}
+
+// quotaUser находит пользователя по логину из /api/admin/quotas/{login}
+func (h *AdminHandler) quotaUser(w http.ResponseWriter, r *http.Request) (*model.User, bool) {
+	login := strings.TrimPrefix(r.URL.Path, "/api/admin/quotas/")
+	if login == "" || strings.Contains(login, "/") {
+		WriteError(w, 400, "missing login")
+		return nil, false
+	}
+	user, err := h.userRepo.GetByLogin(login)
+	if err != nil {
+		WriteError(w, 404, "user not found")
+		return nil, false
+	}
+	return user, true
+}
+
+// requireAdmin проверяет токен и роль администратора. Роль читается из БД,
+// а не из сессии: так её отзыв действует сразу. Из сессии имперсонации
+// административные действия запрещены.
+func (h *AdminHandler) requireAdmin(w http.ResponseWriter, r *http.Request) (model.Session, bool) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return sess, false
+	}
+	if sess.Impersonated() {
+		WriteError(w, 403, "not allowed while impersonating")
+		return sess, false
+	}
+	user, err := h.userRepo.GetByID(sess.UserID)
+	if err != nil || !user.HasRole(model.RoleAdmin) {
+		WriteError(w, 403, "admin role required")
+		return sess, false
+	}
+	return sess, true
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestAdminHandler_Impersonate_OK(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	auditRepo := mocksgen.NewMockAuditRepositoryInterface(ctrl)
+
+	admin := model.Session{UserID: "a1", Login: "adminuser"}
+	target := &model.User{ID: "u1", Login: "testuser"}
+	sess.EXPECT().Validate("t").Return(admin, true)
+	userRepo.EXPECT().GetByID("a1").Return(&model.User{ID: "a1", Roles: []string{model.RoleAdmin}}, nil)
+	userRepo.EXPECT().GetByLogin("testuser").Return(target, nil)
+	auditRepo.EXPECT().Create(gomock.Any()).Do(func(entry *model.AuditEntry) {
+		if entry.Action != model.AuditImpersonationStart || entry.Details != "ticket 42" {
+			t.Fatalf("unexpected audit entry: %+v", entry)
+		}
+	}).Return(nil)
+	sess.EXPECT().CreateImpersonation(admin, target, 10*time.Minute).Return("imp")
+
+	h := NewAdminHandler(sess, userRepo, auditRepo, nil, nil, 10*time.Minute)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/impersonate/testuser?token=t", strings.NewReader(`{"reason":"ticket 42"}`))
+
+	h.Impersonate(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"token":"imp"`) {
+		t.Fatalf("expected impersonation token in response, got %s", rr.Body.String())
+	}
+}
+
+func TestAdminHandler_Impersonate_NotAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	auditRepo := mocksgen.NewMockAuditRepositoryInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u2"}, true)
+	userRepo.EXPECT().GetByID("u2").Return(&model.User{ID: "u2"}, nil)
+
+	h := NewAdminHandler(sess, userRepo, auditRepo, nil, nil, time.Minute)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/impersonate/testuser?token=t", nil)
+
+	h.Impersonate(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected code 403, got %d", rr.Code)
+	}
+}
+
+func TestAdminHandler_Impersonate_FromImpersonatedSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	auditRepo := mocksgen.NewMockAuditRepositoryInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1", ImpersonatorID: "a1"}, true)
+
+	h := NewAdminHandler(sess, userRepo, auditRepo, nil, nil, time.Minute)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/impersonate/otheruser?token=t", nil)
+
+	h.Impersonate(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected code 403, got %d", rr.Code)
+	}
+}
+
+func TestAdminHandler_Impersonate_UnknownLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	auditRepo := mocksgen.NewMockAuditRepositoryInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "a1"}, true)
+	userRepo.EXPECT().GetByID("a1").Return(&model.User{ID: "a1", Roles: []string{model.RoleAdmin}}, nil)
+	userRepo.EXPECT().GetByLogin("ghost").Return(nil, errors.New("not found"))
+
+	h := NewAdminHandler(sess, userRepo, auditRepo, nil, nil, time.Minute)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/impersonate/ghost?token=t", nil)
+
+	h.Impersonate(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected code 404, got %d", rr.Code)
+	}
+}
+
+func TestAdminHandler_Impersonate_AuditFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	auditRepo := mocksgen.NewMockAuditRepositoryInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "a1"}, true)
+	userRepo.EXPECT().GetByID("a1").Return(&model.User{ID: "a1", Roles: []string{model.RoleAdmin}}, nil)
+	userRepo.EXPECT().GetByLogin("testuser").Return(&model.User{ID: "u1", Login: "testuser"}, nil)
+	auditRepo.EXPECT().Create(gomock.Any()).Return(errors.New("db down"))
+
+	h := NewAdminHandler(sess, userRepo, auditRepo, nil, nil, time.Minute)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/impersonate/testuser?token=t", nil)
+
+	h.Impersonate(rr, req)
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected code 500, got %d", rr.Code)
+	}
+}
+
+func TestAdminHandler_Audit_OK(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	auditRepo := mocksgen.NewMockAuditRepositoryInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "a1"}, true)
+	userRepo.EXPECT().GetByID("a1").Return(&model.User{ID: "a1", Roles: []string{model.RoleAdmin}}, nil)
+	auditRepo.EXPECT().List("a1", 5).Return([]model.AuditEntry{{ID: "e1"}}, nil)
+
+	h := NewAdminHandler(sess, userRepo, auditRepo, nil, nil, time.Minute)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/audit?token=t&actor=a1&limit=5", nil)
+
+	h.Audit(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+}
+
+func TestAdminHandler_Reaper(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	auditRepo := mocksgen.NewMockAuditRepositoryInterface(ctrl)
+	reaper := mocksgen.NewMockReaperServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "a1"}, true)
+	userRepo.EXPECT().GetByID("a1").Return(&model.User{ID: "a1", Roles: []string{model.RoleAdmin}}, nil)
+	reaper.EXPECT().Stats().Return(model.ReaperStats{Runs: 3, Documents: 42, Files: 7})
+
+	h := NewAdminHandler(sess, userRepo, auditRepo, reaper, nil, time.Minute)
+
+	rr := httptest.NewRecorder()
+	h.Reaper(rr, httptest.NewRequest(http.MethodGet, "/api/admin/reaper?token=t", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"documents":42`) {
+		t.Fatalf("unexpected response %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAdminHandler_Quota(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	quota := mocksgen.NewMockQuotaServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "a1"}, true).Times(3)
+	userRepo.EXPECT().GetByID("a1").Return(&model.User{ID: "a1", Roles: []string{model.RoleAdmin}}, nil).Times(3)
+	userRepo.EXPECT().GetByLogin("bob").Return(&model.User{ID: "u2", Login: "bob"}, nil).Times(3)
+
+	h := NewAdminHandler(sess, userRepo, nil, nil, quota, time.Minute)
+
+	quota.EXPECT().Usage("u2").Return(&model.UsageReport{Usage: model.Usage{Bytes: 10}}, nil)
+	rr := httptest.NewRecorder()
+	h.Quota(rr, httptest.NewRequest(http.MethodGet, "/api/admin/quotas/bob?token=t", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"bytes":10`) {
+		t.Fatalf("unexpected response %d: %s", rr.Code, rr.Body.String())
+	}
+
+	quota.EXPECT().SetOverride("u2", gomock.Any()).DoAndReturn(func(userID string, o *model.QuotaOverride) (*model.UsageReport, error) {
+		if o.MaxBytes == nil || *o.MaxBytes != 5000 || o.MaxDocuments != nil {
+			t.Fatalf("unexpected override %+v", o)
+		}
+		return &model.UsageReport{Limits: model.QuotaLimits{MaxBytes: 5000}, Override: o}, nil
+	})
+	rr = httptest.NewRecorder()
+	h.SetQuota(rr, httptest.NewRequest(http.MethodPut, "/api/admin/quotas/bob?token=t", strings.NewReader(`{"max_bytes":5000,"max_documents":null}`)))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"max_bytes":5000`) {
+		t.Fatalf("unexpected response %d: %s", rr.Code, rr.Body.String())
+	}
+
+	quota.EXPECT().SetOverride("u2", gomock.Any()).Return(nil, model.ErrInvalidQuota)
+	rr = httptest.NewRecorder()
+	h.SetQuota(rr, httptest.NewRequest(http.MethodPut, "/api/admin/quotas/bob?token=t", strings.NewReader(`{"max_bytes":-1}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
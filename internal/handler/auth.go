@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"astra-api/internal/auth"
 	"astra-api/internal/model"
 	"astra-api/internal/service"
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 )
 
 type AuthHandler struct {
@@ -64,11 +66,64 @@ func (h *AuthHandler) Auth(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, 401, err.Error())
 		return
 	}
+	if tokens, ok := h.sessionService.(service.TokenServiceInterface); ok {
+		pair, err := tokens.CreatePair(user.ID, user.Login)
+		if err != nil {
+			WriteError(w, 500, "cannot issue token")
+			return
+		}
+		if _, err := auth.SetSessionCookies(w, pair.AccessToken, pair.ExpiresAt); err != nil {
+			WriteError(w, 500, "cannot issue token")
+			return
+		}
+		WriteResponse(w, &model.APIResponse{Response: pair})
+		return
+	}
 	token := h.sessionService.Create(user.ID, user.Login)
+	if token == "" {
+		WriteError(w, 500, "cannot issue token")
+		return
+	}
+	if _, err := auth.SetSessionCookies(w, token, time.Time{}); err != nil {
+		WriteError(w, 500, "cannot issue token")
+		return
+	}
 	WriteResponse(w, &model.APIResponse{Response: map[string]string{"token": token}})
 }
 
+// @Summary Обновление токена
+// @Description Обменивает refresh-токен на новую пару токенов (только в режиме SESSION_MODE=token)
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body model.RefreshRequest true "Refresh-токен"
+// @Success 200 {object} model.APIResponse
+// @Router /api/auth/refresh [post]
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	tokens, ok := h.sessionService.(service.TokenServiceInterface)
+	if !ok {
+		WriteError(w, 404, "refresh tokens are not enabled")
+		return
+	}
+	var req model.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	pair, err := tokens.Refresh(req.RefreshToken)
+	if err != nil {
+		WriteError(w, 401, err.Error())
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Response: pair})
+}
+
 // @Summary Логаут
+// @Description Токен берётся из пути, а если его там нет — из заголовка Authorization или cookie сессии
 // @Tags auth
 // @Produce json
 // @Param token path string true "Токен"
@@ -79,12 +134,13 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, 405, "method not allowed")
 		return
 	}
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) < 4 {
-		WriteError(w, 400, "missing token")
-		return
+	var token string
+	if parts := strings.Split(r.URL.Path, "/"); len(parts) >= 4 {
+		token = parts[3]
+	}
+	if token == "" {
+		token = GetToken(r)
 	}
-	token := parts[3]
 	if token == "" {
 		WriteError(w, 400, "missing token")
 		return
@@ -94,6 +150,7 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, 400, "invalid token")
 		return
 	}
+	auth.ClearSessionCookies(w)
 	WriteResponse(w, &model.APIResponse{Response: map[string]bool{token: true}})
 }
 
@@ -104,6 +161,14 @@ func WriteError(w http.ResponseWriter, code int, text string) {
 	}
 }
 
+// WriteErrorDetails — WriteError с подробностями в error.details
+func WriteErrorDetails(w http.ResponseWriter, code int, text string, details interface{}) {
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(&model.APIResponse{Error: &model.APIError{Code: code, Text: text, Details: details}}); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
 func WriteResponse(w http.ResponseWriter, resp *model.APIResponse) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"astra-api/internal/auth"
 	mocksgen "astra-api/internal/mocks/gomock"
 	"astra-api/internal/model"
 	"errors"
@@ -260,3 +261,142 @@ func TestAuthHandler_Logout_WrongMethod(t *testing.T) {
 		t.Fatalf("expected code 405, got %d", rr.Code)
 	}
 }
+
+func TestAuthHandler_Auth_TokenPair(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auth := mocksgen.NewMockAuthServiceInterface(ctrl)
+	tokens := mocksgen.NewMockTokenServiceInterface(ctrl)
+
+	auth.EXPECT().Authenticate("a", "b").Return(&model.User{Login: "a", ID: "u1"}, nil)
+	tokens.EXPECT().CreatePair("u1", "a").Return(&model.TokenPair{AccessToken: "acc", RefreshToken: "ref"}, nil)
+
+	h := NewAuthHandler(auth, tokens)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/auth", strings.NewReader(`{"login":"a","pswd":"b"}`))
+
+	h.Auth(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"refresh_token":"ref"`) {
+		t.Fatalf("expected refresh token in response, got %s", rr.Body.String())
+	}
+}
+
+func TestAuthHandler_Refresh_OK(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auth := mocksgen.NewMockAuthServiceInterface(ctrl)
+	tokens := mocksgen.NewMockTokenServiceInterface(ctrl)
+
+	tokens.EXPECT().Refresh("ref").Return(&model.TokenPair{AccessToken: "acc2", RefreshToken: "ref2"}, nil)
+
+	h := NewAuthHandler(auth, tokens)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(`{"refresh_token":"ref"}`))
+
+	h.Refresh(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+}
+
+func TestAuthHandler_Refresh_Reused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auth := mocksgen.NewMockAuthServiceInterface(ctrl)
+	tokens := mocksgen.NewMockTokenServiceInterface(ctrl)
+
+	tokens.EXPECT().Refresh("ref").Return(nil, errors.New("refresh token reuse detected"))
+
+	h := NewAuthHandler(auth, tokens)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(`{"refresh_token":"ref"}`))
+
+	h.Refresh(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected code 401, got %d", rr.Code)
+	}
+}
+
+func TestAuthHandler_Refresh_NotEnabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auth := mocksgen.NewMockAuthServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+
+	h := NewAuthHandler(auth, sess)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(`{"refresh_token":"ref"}`))
+
+	h.Refresh(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected code 404, got %d", rr.Code)
+	}
+}
+
+func TestAuthHandler_Auth_SetsSessionCookies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authSvc := mocksgen.NewMockAuthServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+
+	authSvc.EXPECT().Authenticate("a", "b").Return(&model.User{Login: "a", ID: "u1"}, nil)
+	sess.EXPECT().Create("u1", "a").Return("tok")
+
+	h := NewAuthHandler(authSvc, sess)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/auth", strings.NewReader(`{"login":"a","pswd":"b"}`))
+
+	h.Auth(rr, req)
+
+	var session, csrf bool
+	for _, c := range rr.Result().Cookies() {
+		switch c.Name {
+		case auth.SessionCookieName:
+			session = c.Value == "tok" && c.HttpOnly
+		case auth.CSRFCookieName:
+			csrf = c.Value != ""
+		}
+	}
+	if !session || !csrf {
+		t.Fatal("expected session and csrf cookies to be set")
+	}
+}
+
+func TestAuthHandler_Logout_BearerHeader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authSvc := mocksgen.NewMockAuthServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+
+	sess.EXPECT().Delete("tok").Return(true)
+
+	h := NewAuthHandler(authSvc, sess)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/auth", nil)
+	req.Header.Set("Authorization", "Bearer tok")
+
+	h.Logout(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+}
@@ -2,35 +2,65 @@ package handler
 
 import (
 	"astra-api/internal/cache"
+	"astra-api/internal/codec"
+	"astra-api/internal/compress"
+	"astra-api/internal/jsonsel"
 	"astra-api/internal/model"
 	"astra-api/internal/repository"
 	"astra-api/internal/service"
+	"astra-api/internal/sniff"
+	"astra-api/internal/storage"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
-	"os"
-	"path/filepath"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+// maxWhereConditions ограничивает число условий where в одном запросе списка
+const maxWhereConditions = 10
+
 type DocsHandler struct {
 	docsService    service.DocsServiceInterface
 	cache          *cache.Cache
 	sessionService service.SessionServiceInterface
 	userRepo       repository.UserRepositoryInterface
+	blobs          storage.BlobStore
+	access         service.AccessServiceInterface
+	schemas        service.SchemaServiceInterface
+	folders        service.FolderServiceInterface
+	quota          service.QuotaServiceInterface
+	mime           service.MimeServiceInterface
+	scans          service.ScanServiceInterface
+	compression    service.CompressionServiceInterface
 }
 
-func NewDocsHandler(docsService service.DocsServiceInterface, cache *cache.Cache, sessionService service.SessionServiceInterface, userRepo repository.UserRepositoryInterface) *DocsHandler {
-	return &DocsHandler{docsService: docsService, cache: cache, sessionService: sessionService, userRepo: userRepo}
+func NewDocsHandler(docsService service.DocsServiceInterface, cache *cache.Cache, sessionService service.SessionServiceInterface, userRepo repository.UserRepositoryInterface, blobs storage.BlobStore, access service.AccessServiceInterface, schemas service.SchemaServiceInterface, folders service.FolderServiceInterface, quota service.QuotaServiceInterface, mime service.MimeServiceInterface, scans service.ScanServiceInterface, compression service.CompressionServiceInterface) *DocsHandler {
+	return &DocsHandler{docsService: docsService, cache: cache, sessionService: sessionService, userRepo: userRepo, blobs: blobs, access: access, schemas: schemas, folders: folders, quota: quota, mime: mime, scans: scans, compression: compression}
 }
 
 // @Summary Загрузка документа
+// @Description meta.schema — имя своей JSON Schema: json проверяется ею, нарушения — в error.details ответа 400.
+// @Description meta.folder — id папки с уровнем write: документ достаётся владельцу папки и проверяется её схемой.
+// @Description meta.tags — теги (приводятся к нижнему регистру), meta.metadata — объект строковых пар ключ/значение.
+// @Description meta.expires_at — срок жизни: время RFC 3339 или длительность ("90m", "24h", "7d"); истёкший документ не виден и удаляется.
+// @Description Документ учитывается в квоте владельца: 413 — файл больше допустимого, 507 — нет места или превышено число документов.
+// @Description Тип файла определяется по содержимому: без meta.mime берётся найденный, несовместимый с содержимым или запрещённый тип — 415.
+// @Description Файл проверяется антивирусом сразу после загрузки, результат — в scan_status ответа.
 // @Tags docs
 // @Accept multipart/form-data
 // @Produce json
 // @Param token query string true "Токен"
-// @Param meta formData string true "Метаданные"
+// @Param meta formData string true "Метаданные: name, file, public, mime, grants, format (json, yaml, csv, ndjson, msgpack — формат поля json), schema, folder, tags, metadata, expires_at"
 // @Param file formData file false "Файл"
 // @Success 200 {object} model.APIResponse
 // @Router /api/docs [post]
@@ -51,62 +81,87 @@ func (h *DocsHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	}
 	metaStr := r.FormValue("meta")
 	var meta struct {
-		Name   string   `json:"name"`
-		File   bool     `json:"file"`
-		Public bool     `json:"public"`
-		Mime   string   `json:"mime"`
-		Grants []string `json:"grants"`
+		model.DocumentMeta
+		File bool `json:"file"`
+		// Format — формат поля json (или части file при file=false): json, yaml, csv, ndjson, msgpack
+		Format string `json:"format"`
 	}
 	if err := json.Unmarshal([]byte(metaStr), &meta); err != nil {
 		WriteError(w, 400, "invalid meta json")
 		return
 	}
-	var jsonData []byte
-	var jsonObj interface{}
-	if jsonStr := r.FormValue("json"); jsonStr != "" {
-		if err := json.Unmarshal([]byte(jsonStr), &jsonObj); err != nil {
-			WriteError(w, 400, "invalid json field")
-			return
-		}
-		jsonData = []byte(jsonStr)
+	doc, ok := newDocument(w, h.access, sess, meta.DocumentMeta)
+	if !ok {
+		return
+	}
+	jsonData, jsonObj, err := readJSONData(r, meta.File, meta.Format)
+	if err != nil {
+		WriteError(w, 400, err.Error())
+		return
+	}
+	doc.File = meta.File
+	doc.JsonData = jsonData
+	if !placeDocument(w, h.folders, h.schemas, sess, doc, meta.DocumentMeta) {
+		return
 	}
 	if meta.File {
-		file, header, err := r.FormFile("file")
+		file, _, err := r.FormFile("file")
 		if err != nil {
 			WriteError(w, 400, "file not found in form")
 			return
 		}
 		defer file.Close()
-		fileName := filepath.Join("uploads", header.Filename)
-		out, err := os.Create(fileName)
+		// Тип определяется по содержимому: заявленному в meta.mime без проверки верить нельзя
+		body, err := h.mime.Inspect(doc, file)
 		if err != nil {
-			WriteError(w, 500, "cannot save file")
+			writeDocumentError(w, err)
 			return
 		}
-		defer out.Close()
-		if _, err := io.Copy(out, file); err != nil {
+		body, err = h.quota.Reader(doc.Owner, 1, 0, body)
+		if err != nil {
+			writeDocumentError(w, err)
+			return
+		}
+		packed, err := h.compression.Pack(doc, body)
+		if err != nil {
+			writeDocumentError(w, err)
+			return
+		}
+		// Файл хранится под ID документа: имена разных пользователей могут совпадать
+		n, err := h.blobs.Put(doc.ID, packed)
+		if isQuotaError(err) {
+			writeDocumentError(w, err)
+			return
+		}
+		if err != nil {
 			WriteError(w, 500, "cannot write file")
 			return
 		}
-	}
-	doc := &model.Document{
-		Name:     meta.Name,
-		Mime:     meta.Mime,
-		File:     meta.File,
-		Public:   meta.Public,
-		Owner:    sess.UserID,
-		Grants:   meta.Grants,
-		JsonData: jsonData,
+		size := packed.Size()
+		doc.Size, doc.StoredSize = &size, &n
+		h.scans.Queue(doc)
+	} else if err := h.quota.Check(doc.Owner, 1, int64(len(doc.JsonData)), 0); err != nil {
+		writeDocumentError(w, err)
+		return
 	}
 	if err := h.docsService.Create(doc); err != nil {
-		WriteError(w, 500, err.Error())
+		if doc.File {
+			_ = h.blobs.Remove(doc.ID)
+		}
+		writeDocumentError(w, err)
 		return
 	}
 	h.cache.InvalidateAll()
+	// Недоступный сканер не мешает загрузке: файл останется в pending_scan,
+	// его проверит фоновая задача
+	_ = h.scans.Scan(doc)
 	resp := map[string]interface{}{
 		"id":   doc.ID,
 		"file": meta.Name,
 	}
+	if doc.File {
+		resp["scan_status"] = doc.ScanStatus
+	}
 	if jsonObj != nil {
 		resp["json"] = jsonObj
 	} else {
@@ -115,12 +170,107 @@ func (h *DocsHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	WriteResponse(w, &model.APIResponse{Data: resp})
 }
 
+// newDocument проверяет метаданные нового документа и собирает по ним документ
+// вызывающего; false — ответ с ошибкой уже записан
+func newDocument(w http.ResponseWriter, access service.AccessServiceInterface, sess model.Session, meta model.DocumentMeta) (*model.Document, bool) {
+	if !model.ValidName(meta.Name) {
+		WriteError(w, 400, service.ErrInvalidDocumentName.Error())
+		return nil, false
+	}
+	tags, err := model.NormalizeTags(meta.Tags)
+	if err != nil {
+		WriteError(w, 400, err.Error())
+		return nil, false
+	}
+	if err := meta.Metadata.Validate(); err != nil {
+		WriteError(w, 400, err.Error())
+		return nil, false
+	}
+	expiresAt, err := model.ParseExpiry(meta.ExpiresAt, time.Now())
+	if err != nil {
+		WriteError(w, 400, err.Error())
+		return nil, false
+	}
+	grants, ok := resolveGrants(w, access, sess, meta.Grants)
+	if !ok {
+		return nil, false
+	}
+	doc := &model.Document{
+		ID:        uuid.New().String(),
+		Name:      meta.Name,
+		Mime:      meta.Mime,
+		Public:    meta.Public,
+		Owner:     sess.UserID,
+		Tags:      tags,
+		Metadata:  meta.Metadata,
+		ExpiresAt: expiresAt,
+		Grants:    grants,
+	}
+	if len(doc.Metadata) == 0 {
+		doc.Metadata = nil
+	}
+	return doc, true
+}
+
+// placeDocument кладёт новый документ в папку из метаданных, привязывает схему и
+// проверяет документ схемами его тегов; false — ответ с ошибкой уже записан
+func placeDocument(w http.ResponseWriter, folders service.FolderServiceInterface, schemas service.SchemaServiceInterface, sess model.Session, doc *model.Document, meta model.DocumentMeta) bool {
+	if meta.Folder != "" {
+		if err := folders.Place(sess.UserID, doc, meta.Folder); err != nil {
+			writePlaceError(w, err)
+			return false
+		}
+	}
+	if meta.Schema != "" {
+		if err := schemas.Attach(doc, meta.Schema); err != nil {
+			writeAttachError(w, err)
+			return false
+		}
+	} else if meta.Folder == "" && !doc.File && len(doc.Tags) > 0 {
+		// Схемы тегов: с папкой или своей схемой документ уже проверен целиком
+		if err := schemas.Validate(doc); err != nil {
+			writeSchemaError(w, err)
+			return false
+		}
+	}
+	return true
+}
+
+// resolveGrants превращает grants из метаданных загрузки в гранты на чтение:
+// логин, "user:<login>" или "group:<name>"
+func resolveGrants(w http.ResponseWriter, access service.AccessServiceInterface, sess model.Session, names []string) ([]model.Grant, bool) {
+	if len(names) == 0 {
+		return nil, true
+	}
+	grants, err := access.ResolveGrants(names)
+	if errors.Is(err, service.ErrUnknownPrincipal) {
+		WriteError(w, 400, err.Error())
+		return nil, false
+	}
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return nil, false
+	}
+	for i := range grants {
+		grants[i].GrantedBy = &sess.UserID
+	}
+	return grants, true
+}
+
 // @Summary Список документов
+// @Description Со своим логином (или без него) — все свои документы; с чужим — только публичные
+// @Description и выданные вызывающему напрямую или через группу.
+// @Description scope=shared — чужие документы, доступные вызывающему; scope=all — свои и доступные.
 // @Tags docs
 // @Produce json
 // @Param token query string true "Токен"
 // @Param login query string false "Логин"
+// @Param scope query string false "owned (по умолчанию), shared или all"
 // @Param limit query int false "Лимит"
+// @Param fields query string false "Поля через запятую: id,name,mime,file,public,owner,created,json; без json содержимое не читается"
+// @Param where query []string false "Условия на JSON: path==value, !=, >, >=, <, <=, @>, path (есть), !path (нет)" collectionFormat(multi)
+// @Param tag query []string false "Теги, которые должны быть у документа все сразу" collectionFormat(multi)
+// @Param meta.key query string false "Значение метаданных по ключу key, например meta.customer=acme"
 // @Success 200 {object} model.APIResponse
 // @Router /api/docs [get]
 func (h *DocsHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -134,8 +284,20 @@ func (h *DocsHandler) List(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, 405, "method not allowed")
 		return
 	}
+	scope := r.URL.Query().Get("scope")
+	switch scope {
+	case "", "owned", "shared", "all":
+	default:
+		WriteError(w, 400, "scope must be owned, shared or all")
+		return
+	}
+	login := r.URL.Query().Get("login")
+	if login != "" && (scope == "shared" || scope == "all") {
+		WriteError(w, 400, "scope cannot be combined with login")
+		return
+	}
 	ownerID := sess.UserID
-	if login := r.URL.Query().Get("login"); login != "" {
+	if login != "" {
 		user, err := h.userRepo.GetByLogin(login)
 		if err != nil {
 			WriteError(w, 400, "unknown login")
@@ -150,32 +312,210 @@ func (h *DocsHandler) List(w http.ResponseWriter, r *http.Request) {
 			limit = l
 		}
 	}
-	cacheKey := "list:" + ownerID + ":" + strconv.Itoa(limit)
+	where := r.URL.Query()["where"]
+	if len(where) > maxWhereConditions {
+		WriteError(w, 400, "too many where conditions")
+		return
+	}
+	fields, err := model.ParseDocumentFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		WriteError(w, 400, err.Error())
+		return
+	}
+	tags, err := model.NormalizeTags(r.URL.Query()["tag"])
+	if err != nil {
+		WriteError(w, 400, err.Error())
+		return
+	}
+	metadata, err := model.ParseMetadataFilter(r.URL.Query())
+	if err != nil {
+		WriteError(w, 400, err.Error())
+		return
+	}
+	q := model.ListQuery{Limit: limit, Fields: fields, Tags: tags, Metadata: metadata}
+	for _, raw := range where {
+		cond, err := model.ParseJSONCondition(raw)
+		if err != nil {
+			WriteError(w, 400, err.Error())
+			return
+		}
+		q.Where = append(q.Where, cond)
+	}
+	if ownerID != sess.UserID || scope == "shared" || scope == "all" {
+		// Доступные чужие документы зависят от грантов и членства в группах, поэтому не кэшируются
+		principals, err := h.access.Principals(sess.UserID)
+		if err != nil {
+			WriteError(w, 500, err.Error())
+			return
+		}
+		var docs []model.Document
+		if ownerID != sess.UserID {
+			docs, err = h.docsService.ListVisible(ownerID, principals, q)
+		} else {
+			docs, err = h.docsService.ListAccessible(sess.UserID, principals, scope == "all", q)
+		}
+		if err != nil {
+			WriteError(w, 500, err.Error())
+			return
+		}
+		WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"docs": projectDocuments(docs, fields)}})
+		return
+	}
+	cacheKey := "list:" + ownerID + ":" + strconv.Itoa(limit) + ":" + strings.Join(where, "&") + ":" + strings.Join(fields, ",") + ":" + strings.Join(tags, ",") + ":" + metadataKey(metadata)
 	if r.Method == http.MethodGet || r.Method == http.MethodHead {
 		if cached, ok := h.cache.Get(cacheKey); ok {
-			WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"docs": cached}})
+			WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"docs": projectDocuments(cached.([]model.Document), fields)}})
 			return
 		}
 	}
-	docs, err := h.docsService.List(ownerID, limit)
+	docs, err := h.docsService.List(ownerID, q)
 	if err != nil {
 		WriteError(w, 500, err.Error())
 		return
 	}
-	h.cache.Set(cacheKey, docs)
-	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"docs": docs}})
+	h.cache.SetUntil(cacheKey, docs, expiryDeadline(docs...))
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"docs": projectDocuments(docs, fields)}})
 }
 
-// @Summary Документ по id
+// expiryDeadline — ближайший срок жизни среди документов; нулевое время — все бессрочные
+func expiryDeadline(docs ...model.Document) time.Time {
+	var deadline time.Time
+	for i := range docs {
+		if at := docs[i].ExpiresAt; at != nil && (deadline.IsZero() || at.Before(deadline)) {
+			deadline = *at
+		}
+	}
+	return deadline
+}
+
+// metadataKey — фильтр по метаданным в ключе кэша, пары отсортированы
+func metadataKey(m model.Metadata) string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, url.QueryEscape(k)+"="+url.QueryEscape(v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// @Summary Теги и число документов с ними
+// @Description По умолчанию — по своим документам. scope=shared — по чужим доступным, scope=all — по всем доступным.
+// @Description Сортировка — по убыванию числа документов.
 // @Tags docs
 // @Produce json
 // @Param token query string true "Токен"
+// @Param scope query string false "owned (по умолчанию), shared или all"
+// @Success 200 {object} model.APIResponse
+// @Router /api/tags [get]
+func (h *DocsHandler) Tags(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	scope := r.URL.Query().Get("scope")
+	var principals []string
+	switch scope {
+	case "", "owned":
+	case "shared", "all":
+		var err error
+		principals, err = h.access.Principals(sess.UserID)
+		if err != nil {
+			WriteError(w, 500, err.Error())
+			return
+		}
+	default:
+		WriteError(w, 400, "scope must be owned, shared or all")
+		return
+	}
+	counts, err := h.docsService.CountTags(sess.UserID, principals, scope != "shared", scope == "shared" || scope == "all")
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"tags": counts}})
+}
+
+// projectDocuments оставляет в документах списка только запрошенные поля
+func projectDocuments(docs []model.Document, fields []string) interface{} {
+	if len(fields) == 0 {
+		return docs
+	}
+	out := make([]map[string]interface{}, len(docs))
+	for i := range docs {
+		out[i] = docs[i].Project(fields)
+	}
+	return out
+}
+
+// @Summary Полнотекстовый поиск
+// @Description Ищет по имени и строковым значениям JSON среди документов, которые вызывающий может читать.
+// @Description Синтаксис запроса как в поисковиках: слова, "фраза", OR, -исключение. Результаты отсортированы
+// @Description по релевантности, совпадения во фрагменте snippet обрамлены <mark>...</mark>, остальной текст экранирован как HTML.
+// @Tags docs
+// @Produce json
+// @Param token query string true "Токен"
+// @Param q query string true "Поисковый запрос"
+// @Param limit query int false "Лимит"
+// @Success 200 {object} model.APIResponse
+// @Router /api/docs/search [get]
+func (h *DocsHandler) Search(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		WriteError(w, 400, "missing query")
+		return
+	}
+	limit := 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	principals, err := h.access.Principals(sess.UserID)
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return
+	}
+	results, err := h.docsService.Search(sess.UserID, principals, q, limit)
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"results": results}})
+}
+
+// @Summary Документ по id
+// @Description select — вернуть только части JSON: JSON Pointer (/meta/tags/0) или JSONPath ($.items[*].name).
+// @Description Ответ — объект {выражение: значение}; JSONPath всегда даёт массив совпадений.
+// @Description format или Accept (application/yaml, text/csv, application/x-ndjson, application/msgpack) —
+// @Description вернуть JSON-документ без обёртки в этом формате; format=json — JSON без обёртки.
+// @Description CSV — только для массивов плоских объектов, NDJSON — для массивов, иначе 406.
+// @Description Файл отдаётся после проверки антивирусом: пока она идёт — 409, заражённый или непроверяемый файл — 403.
+// @Description Сжатый при хранении файл клиенту с Accept-Encoding: gzip отдаётся как хранится, с Content-Encoding: gzip; Range-запросы получают исходные байты.
+// @Tags docs
+// @Produce json,application/yaml,text/csv,application/x-ndjson,application/msgpack
+// @Param token query string true "Токен"
 // @Param id path string true "ID"
+// @Param Accept-Encoding header string false "gzip — получить сжатый файл без распаковки на сервере"
+// @Param select query []string false "JSON Pointer или JSONPath" collectionFormat(multi)
+// @Param format query string false "json, yaml, csv, ndjson или msgpack"
 // @Success 200 {object} model.APIResponse
 // @Router /api/docs/{id} [get]
 func (h *DocsHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	token := GetToken(r)
-	if _, ok := h.sessionService.Validate(token); !ok {
+	sess, ok := h.sessionService.Validate(token)
+	if !ok {
 		WriteError(w, 401, "invalid token")
 		return
 	}
@@ -188,35 +528,24 @@ func (h *DocsHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, 400, "missing document id")
 		return
 	}
+	var selectors []*jsonsel.Selector
+	for _, expr := range r.URL.Query()["select"] {
+		sel, err := jsonsel.Parse(expr)
+		if err != nil {
+			WriteError(w, 400, err.Error())
+			return
+		}
+		selectors = append(selectors, sel)
+	}
 
 	// Сначала пробуем из кэша
 	cacheKey := "doc:" + id
 	if r.Method == http.MethodGet || r.Method == http.MethodHead {
 		if cached, ok := h.cache.Get(cacheKey); ok {
 			doc := cached.(*model.Document)
-			if doc.File {
-				filePath := "uploads/" + doc.Name
-				f, err := os.Open(filePath)
-				if err != nil {
-					WriteError(w, 404, "file not found")
-					return
-				}
-				defer f.Close()
-				w.Header().Set("Content-Type", doc.Mime)
-				w.Header().Set("Content-Disposition", "attachment; filename="+doc.Name)
-				if r.Method == http.MethodGet {
-					if _, err := io.Copy(w, f); err != nil {
-						WriteError(w, 500, "failed to stream file")
-						return
-					}
-				}
-				return
-			}
-			var obj interface{}
-			if len(doc.JsonData) > 0 {
-				_ = json.Unmarshal(doc.JsonData, &obj)
+			if h.authorize(w, sess, doc, model.PermissionRead) {
+				writeSelection(w, r, h.blobs, doc, selectors)
 			}
-			WriteResponse(w, &model.APIResponse{Data: obj})
 			return
 		}
 	}
@@ -228,25 +557,405 @@ func (h *DocsHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Сохраняем в кэш
-	h.cache.Set(cacheKey, doc)
+	// Сохраняем в кэш, но не дольше срока жизни документа. Непроверенный файл не
+	// кэшируем: проверка закончится без участия обработчика
+	if doc.ScanStatus != model.ScanPending {
+		h.cache.SetUntil(cacheKey, doc, expiryDeadline(*doc))
+	}
+
+	if h.authorize(w, sess, doc, model.PermissionRead) {
+		writeSelection(w, r, h.blobs, doc, selectors)
+	}
+}
+
+// writeSelection отдаёт документ целиком или, если заданы select, только выбранные
+// части JSON: {"<выражение>": значение}. Для JSON Pointer без совпадения значение — null,
+// для JSONPath — массив совпадений.
+func writeSelection(w http.ResponseWriter, r *http.Request, blobs storage.BlobStore, doc *model.Document, selectors []*jsonsel.Selector) {
+	if len(selectors) == 0 {
+		writeDocument(w, r, blobs, doc)
+		return
+	}
+	if doc.File {
+		WriteError(w, 400, "file document has no json")
+		return
+	}
+	var obj interface{}
+	if len(doc.JsonData) > 0 {
+		_ = json.Unmarshal(doc.JsonData, &obj)
+	}
+	out := make(map[string]interface{}, len(selectors))
+	for _, sel := range selectors {
+		value, _ := sel.Select(obj)
+		out[sel.String()] = value
+	}
+	writeValue(w, r, out)
+}
+
+// authorize проверяет уровень доступа к документу. Тем, кто не может его даже
+// читать, отвечаем 404, чтобы не раскрывать существование чужого документа.
+func (h *DocsHandler) authorize(w http.ResponseWriter, sess model.Session, doc *model.Document, need model.Permission) bool {
+	return authorizeDocument(w, h.access, sess, doc, need)
+}
+
+func authorizeDocument(w http.ResponseWriter, access service.AccessServiceInterface, sess model.Session, doc *model.Document, need model.Permission) bool {
+	level, err := access.Permission(sess.UserID, doc)
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return false
+	}
+	if level == "" {
+		WriteError(w, 404, "document not found")
+		return false
+	}
+	if !level.Includes(need) {
+		WriteError(w, 403, "permission denied")
+		return false
+	}
+	return true
+}
+
+// loadDocument читает документ из БД в обход кэша: изменяющим запросам нужна свежая версия
+func (h *DocsHandler) loadDocument(w http.ResponseWriter, r *http.Request) (*model.Document, bool) {
+	id := getIDFromURL(r.URL.Path)
+	if id == "" {
+		WriteError(w, 400, "missing document id")
+		return nil, false
+	}
+	doc, err := h.docsService.GetByID(id)
+	if err != nil {
+		WriteError(w, 404, "document not found")
+		return nil, false
+	}
+	return doc, true
+}
+
+// @Summary Изменить документ
+// @Description Нужен уровень write; смена public и схемы требует manage.
+// @Description json документа проверяется его схемой и схемой папки, нарушения — в error.details ответа 400.
+// @Description tags заменяет список тегов целиком; metadata — merge patch, null удаляет ключ.
+// @Description expires_at (нужен manage) — время RFC 3339 или длительность от текущего момента, "" — бессрочно.
+// @Tags docs
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID"
+// @Param input body model.UpdateDocumentRequest true "Изменяемые поля"
+// @Success 200 {object} model.APIResponse
+// @Router /api/docs/{id} [patch]
+func (h *DocsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPatch {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	var req model.UpdateDocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	doc, ok := h.loadDocument(w, r)
+	if !ok {
+		return
+	}
+	need := model.PermissionWrite
+	if req.Public != nil && *req.Public != doc.Public || req.Schema != nil || req.ExpiresAt != nil {
+		need = model.PermissionManage
+	}
+	if !h.authorize(w, sess, doc, need) {
+		return
+	}
+	if req.Name != nil {
+		if !model.ValidName(*req.Name) {
+			WriteError(w, 400, service.ErrInvalidDocumentName.Error())
+			return
+		}
+		doc.Name = *req.Name
+	}
+	if req.Public != nil {
+		doc.Public = *req.Public
+	}
+	if req.ExpiresAt != nil {
+		expiresAt, err := model.ParseExpiry(*req.ExpiresAt, time.Now())
+		if err != nil {
+			WriteError(w, 400, err.Error())
+			return
+		}
+		doc.ExpiresAt = expiresAt
+	}
+	if req.Tags != nil {
+		tags, err := model.NormalizeTags(*req.Tags)
+		if err != nil {
+			WriteError(w, 400, err.Error())
+			return
+		}
+		doc.Tags = tags
+	}
+	if len(req.Metadata) > 0 {
+		metadata := doc.Metadata.Patch(req.Metadata)
+		if err := metadata.Validate(); err != nil {
+			WriteError(w, 400, err.Error())
+			return
+		}
+		doc.Metadata = metadata
+	}
+	if len(req.Json) > 0 {
+		if doc.File {
+			WriteError(w, 400, "file document has no json")
+			return
+		}
+		replaced := int64(len(doc.JsonData))
+		if doc.Size != nil {
+			replaced = *doc.Size
+		}
+		if err := h.quota.Check(doc.Owner, 0, int64(len(req.Json)), replaced); err != nil {
+			writeDocumentError(w, err)
+			return
+		}
+		doc.JsonData = []byte(req.Json)
+	}
+	if req.Schema != nil {
+		if err := h.schemas.Attach(doc, *req.Schema); err != nil {
+			writeAttachError(w, err)
+			return
+		}
+	} else if len(req.Json) > 0 && (doc.SchemaID != nil || doc.FolderID != nil || len(doc.Tags) > 0) || req.Tags != nil && len(doc.Tags) > 0 {
+		if err := h.schemas.Validate(doc); err != nil {
+			writeSchemaError(w, err)
+			return
+		}
+	}
+	if err := h.docsService.Update(doc); err != nil {
+		writeDocumentError(w, err)
+		return
+	}
+	h.cache.Invalidate("doc:" + doc.ID)
+	h.cache.InvalidateAll()
+	WriteResponse(w, &model.APIResponse{Data: doc})
+}
+
+// @Summary Кто имеет доступ к документу
+// @Description Владелец, прямые гранты, участники групп с грантом и публичный доступ.
+// @Description Полный список видят обладатели уровня share, остальные — только записи о себе.
+// @Tags docs
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID"
+// @Success 200 {object} model.APIResponse
+// @Router /api/docs/{id}/access [get]
+func (h *DocsHandler) Access(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	doc, ok := h.loadDocument(w, r)
+	if !ok || !h.authorize(w, sess, doc, model.PermissionRead) {
+		return
+	}
+	entries, err := h.access.Explain(sess.UserID, doc)
+	if err != nil {
+		writeAccessError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"access": entries}})
+}
+
+// @Summary Гранты документа
+// @Description Нужен уровень share
+// @Tags docs
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID"
+// @Success 200 {object} model.APIResponse
+// @Router /api/docs/{id}/grants [get]
+func (h *DocsHandler) ListGrants(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	doc, ok := h.loadDocument(w, r)
+	if !ok || !h.authorize(w, sess, doc, model.PermissionRead) {
+		return
+	}
+	grants, err := h.access.ListGrants(sess.UserID, doc)
+	if err != nil {
+		writeAccessError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"grants": grants}})
+}
 
+// @Summary Выдать доступ
+// @Description Нужен уровень share; выдать можно уровень не выше собственного. Повторная выдача меняет уровень и срок.
+// @Tags docs
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID"
+// @Param input body model.GrantRequest true "Грант"
+// @Success 200 {object} model.APIResponse
+// @Router /api/docs/{id}/grants [put]
+func (h *DocsHandler) SetGrant(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPut {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	var req model.GrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Principal == "" {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	doc, ok := h.loadDocument(w, r)
+	if !ok || !h.authorize(w, sess, doc, model.PermissionRead) {
+		return
+	}
+	grant, err := h.access.SetGrant(sess.UserID, doc, req)
+	if err != nil {
+		writeAccessError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: grant})
+}
+
+// @Summary Отозвать доступ
+// @Description Нужен уровень manage; от собственного гранта можно отказаться без него
+// @Tags docs
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID"
+// @Param principal query string true "Логин, user:<login> или group:<name>"
+// @Success 200 {object} model.APIResponse
+// @Router /api/docs/{id}/grants [delete]
+func (h *DocsHandler) RevokeGrant(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	principal := r.URL.Query().Get("principal")
+	if principal == "" {
+		WriteError(w, 400, "missing principal")
+		return
+	}
+	doc, ok := h.loadDocument(w, r)
+	if !ok || !h.authorize(w, sess, doc, model.PermissionRead) {
+		return
+	}
+	if err := h.access.RevokeGrant(sess.UserID, doc, principal); err != nil {
+		writeAccessError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Response: map[string]bool{principal: true}})
+}
+
+// writeDocumentError отвечает на ошибки сохранения документа
+func writeDocumentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrDocumentExists):
+		WriteError(w, 409, err.Error())
+	case errors.Is(err, service.ErrFileTooLarge):
+		WriteError(w, 413, err.Error())
+	case errors.Is(err, service.ErrStorageQuota), errors.Is(err, service.ErrDocumentQuota):
+		WriteError(w, 507, err.Error())
+	case errors.Is(err, service.ErrMimeMismatch), errors.Is(err, service.ErrMimeNotAllowed):
+		WriteError(w, 415, err.Error())
+	default:
+		WriteError(w, 500, err.Error())
+	}
+}
+
+// isQuotaError сообщает, что запись прервана лимитом квоты
+func isQuotaError(err error) bool {
+	return errors.Is(err, service.ErrFileTooLarge) || errors.Is(err, service.ErrStorageQuota) || errors.Is(err, service.ErrDocumentQuota)
+}
+
+func writeAccessError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		WriteError(w, 403, err.Error())
+	case errors.Is(err, service.ErrUnknownPrincipal), errors.Is(err, service.ErrInvalidGrant):
+		WriteError(w, 400, err.Error())
+	default:
+		WriteError(w, 500, err.Error())
+	}
+}
+
+// writeDocument отдаёт файл документа либо его JSON
+func writeDocument(w http.ResponseWriter, r *http.Request, blobs storage.BlobStore, doc *model.Document) {
 	if doc.File {
-		filePath := "uploads/" + doc.Name
-		f, err := os.Open(filePath)
+		switch {
+		case doc.ScanStatus == model.ScanPending:
+			WriteError(w, 409, "file is pending virus scan")
+			return
+		case !doc.Downloadable():
+			WriteError(w, 403, "file is quarantined")
+			return
+		}
+		// Сжатый файл отдаётся как хранится, если клиент принимает gzip. Range относится
+		// к исходным байтам, поэтому на Range-запрос файл распаковывается.
+		passThrough := doc.Encoding == compress.Gzip && r.Header.Get("Range") == "" &&
+			compress.Accepts(r.Header.Get("Accept-Encoding"), compress.Gzip)
+		var f io.ReadSeekCloser
+		var err error
+		if passThrough {
+			f, err = blobs.Open(doc.StorageKey())
+		} else {
+			f, err = service.OpenContent(blobs, doc)
+		}
 		if err != nil {
 			WriteError(w, 404, "file not found")
 			return
 		}
 		defer f.Close()
-		w.Header().Set("Content-Type", doc.Mime)
-		w.Header().Set("Content-Disposition", "attachment; filename="+doc.Name)
-		if r.Method == http.MethodGet {
-			if _, err := io.Copy(w, f); err != nil {
-				WriteError(w, 500, "failed to stream file")
-				return
-			}
+		if doc.Encoding != "" {
+			w.Header().Add("Vary", "Accept-Encoding")
 		}
+		if passThrough {
+			w.Header().Set("Content-Encoding", compress.Gzip)
+		}
+		contentType := doc.Mime
+		if contentType == "" {
+			contentType = sniff.Unknown
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", contentDisposition(doc))
+		// Браузер не должен угадывать тип сам: иначе HTML под видом картинки исполнится
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if sniff.Risky(contentType) {
+			w.Header().Set("Content-Security-Policy", "sandbox")
+		}
+		// Перезапись кладёт файл под новый ключ, поэтому ключ служит сильным ETag.
+		// Сжатое представление отличается от исходного побайтно и получает свой тег
+		etag := doc.StorageKey()
+		if passThrough {
+			etag += "-" + compress.Gzip
+		}
+		w.Header().Set("ETag", `"`+etag+`"`)
+		// ServeContent обрабатывает HEAD, Range и условные запросы
+		http.ServeContent(w, r, doc.Name, doc.LastModified(), f)
 		return
 	}
 
@@ -254,10 +963,118 @@ func (h *DocsHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	if len(doc.JsonData) > 0 {
 		_ = json.Unmarshal(doc.JsonData, &obj)
 	}
-	WriteResponse(w, &model.APIResponse{Data: obj})
+	writeValue(w, r, obj)
+}
+
+// contentDisposition показывает в браузере только безопасные типы (картинки, аудио,
+// видео, простой текст); HTML, SVG и остальное, что браузер может исполнить, скачивается
+func contentDisposition(doc *model.Document) string {
+	disposition := "inline"
+	if sniff.Risky(doc.Mime) {
+		disposition = "attachment"
+	}
+	if v := mime.FormatMediaType(disposition, map[string]string{"filename": doc.Name}); v != "" {
+		return v
+	}
+	return disposition
+}
+
+// responseFormat определяет формат JSON-документа в ответе: ?format= важнее Accept.
+// explicit=false — формат не запрошен (или запрошен application/json), и ответ
+// остаётся в привычной обёртке APIResponse.
+func responseFormat(r *http.Request) (format codec.Format, explicit bool, err error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		format, err = codec.ParseFormat(name)
+		return format, true, err
+	}
+	if format, ok := codec.Negotiate(r.Header.Get("Accept")); ok && format != codec.FormatJSON {
+		return format, true, nil
+	}
+	return "", false, nil
+}
+
+// writeValue отдаёт содержимое документа в обёртке APIResponse или, если формат
+// запрошен явно, как есть в этом формате
+func writeValue(w http.ResponseWriter, r *http.Request, v interface{}) {
+	w.Header().Add("Vary", "Accept")
+	format, explicit, err := responseFormat(r)
+	if err != nil {
+		WriteError(w, 400, err.Error())
+		return
+	}
+	if !explicit {
+		WriteResponse(w, &model.APIResponse{Data: v})
+		return
+	}
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, format, v); err != nil {
+		if errors.Is(err, codec.ErrNotTabular) {
+			WriteError(w, 406, err.Error())
+			return
+		}
+		WriteError(w, 500, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.WriteHeader(200)
+	w.Write(buf.Bytes())
+}
+
+// readJSONData читает содержимое JSON-документа при загрузке из поля json, а при file=false
+// и отсутствии поля — из части file (так удобнее передавать CSV и двоичный MessagePack).
+// Формат — meta.format, для части file — ещё и её Content-Type; по умолчанию JSON.
+// Всё, кроме JSON, переводится в JSON; JSON сохраняется как прислан.
+func readJSONData(r *http.Request, file bool, formatName string) ([]byte, interface{}, error) {
+	format := codec.FormatJSON
+	if formatName != "" {
+		f, err := codec.ParseFormat(formatName)
+		if err != nil {
+			return nil, nil, err
+		}
+		format = f
+	}
+	var src io.Reader
+	if jsonStr := r.FormValue("json"); jsonStr != "" {
+		if format == codec.FormatJSON {
+			var obj interface{}
+			if err := json.Unmarshal([]byte(jsonStr), &obj); err != nil {
+				return nil, nil, errors.New("invalid json field")
+			}
+			return []byte(jsonStr), obj, nil
+		}
+		src = strings.NewReader(jsonStr)
+	} else if !file {
+		part, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, nil, nil
+		}
+		defer part.Close()
+		if formatName == "" {
+			f, ok := codec.FromMime(header.Header.Get("Content-Type"))
+			if !ok {
+				// Как и раньше, файл без формата при file=false не читается
+				return nil, nil, nil
+			}
+			format = f
+		}
+		src = part
+	} else {
+		return nil, nil, nil
+	}
+	obj, err := codec.Decode(src, format)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s data: %v", format, err)
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s data: %v", format, err)
+	}
+	return data, obj, nil
 }
 
 // @Summary Удалить документ
+// @Description Нужен уровень manage. Документ переносится в корзину: его можно восстановить
+// @Description через POST /api/trash/{id}/restore, пока он не удалён окончательно.
 // @Tags docs
 // @Produce json
 // @Param token query string true "Токен"
@@ -266,7 +1083,8 @@ func (h *DocsHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 // @Router /api/docs/{id} [delete]
 func (h *DocsHandler) DeleteByID(w http.ResponseWriter, r *http.Request) {
 	token := GetToken(r)
-	if _, ok := h.sessionService.Validate(token); !ok {
+	sess, ok := h.sessionService.Validate(token)
+	if !ok {
 		WriteError(w, 401, "invalid token")
 		return
 	}
@@ -274,11 +1092,11 @@ func (h *DocsHandler) DeleteByID(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, 405, "method not allowed")
 		return
 	}
-	id := getIDFromURL(r.URL.Path)
-	if id == "" {
-		WriteError(w, 400, "missing document id")
+	doc, ok := h.loadDocument(w, r)
+	if !ok || !h.authorize(w, sess, doc, model.PermissionManage) {
 		return
 	}
+	id := doc.ID
 	if err := h.docsService.Delete(id); err != nil {
 		WriteError(w, 404, "document not found")
 		return
@@ -2,14 +2,26 @@ package handler
 
 import (
 	"astra-api/internal/cache"
+	"astra-api/internal/compress"
 	mocksgen "astra-api/internal/mocks/gomock"
 	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"astra-api/internal/service"
+	"astra-api/internal/storage"
 	"bytes"
+	"compress/gzip"
+	"database/sql"
 	"errors"
+	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"go.uber.org/mock/gomock"
 )
@@ -21,12 +33,13 @@ func TestDocsHandler_List_OK(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 	docs.EXPECT().List("u1", gomock.Any()).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u1"}}, nil)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs?token=t", nil)
@@ -37,6 +50,68 @@ func TestDocsHandler_List_OK(t *testing.T) {
 	}
 }
 
+func TestDocsHandler_List_Where(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	docs.EXPECT().List("u1", gomock.Any()).DoAndReturn(func(owner string, q model.ListQuery) ([]model.Document, error) {
+		if len(q.Where) != 2 || q.Where[0].Op != model.JSONEq || q.Where[1].Op != model.JSONGt {
+			t.Fatalf("unexpected conditions %+v", q.Where)
+		}
+		return []model.Document{}, nil
+	})
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	h.List(rr, httptest.NewRequest(http.MethodGet, `/api/docs?token=t&where=status%3D%3D%22active%22&where=meta.priority%3E3`, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.List(rr, httptest.NewRequest(http.MethodGet, `/api/docs?token=t&where=status%3Dactive`, nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400 for invalid condition, got %d", rr.Code)
+	}
+}
+
+func TestDocsHandler_List_Fields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	docs.EXPECT().List("u1", model.ListQuery{Limit: 20, Fields: []string{"id", "name"}}).Return([]model.Document{{ID: "d1", Name: "f"}}, nil)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	h.List(rr, httptest.NewRequest(http.MethodGet, "/api/docs?token=t&fields=name", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `{"id":"d1","name":"f"}`) {
+		t.Fatalf("expected only id and name, got %s", body)
+	}
+
+	rr = httptest.NewRecorder()
+	h.List(rr, httptest.NewRequest(http.MethodGet, "/api/docs?token=t&fields=name,secret", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400 for unknown field, got %d", rr.Code)
+	}
+}
+
 func TestDocsHandler_List_InvalidToken(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -44,11 +119,12 @@ func TestDocsHandler_List_InvalidToken(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("invalid").Return(model.Session{}, false)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs?token=invalid", nil)
@@ -66,13 +142,15 @@ func TestDocsHandler_List_WithLogin(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 	userRepo.EXPECT().GetByLogin("otheruser").Return(&model.User{ID: "u2", Login: "otheruser"}, nil)
-	docs.EXPECT().List("u2", gomock.Any()).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u2"}}, nil)
+	access.EXPECT().Principals("u1").Return([]string{"user:u1"}, nil)
+	docs.EXPECT().ListVisible("u2", []string{"user:u1"}, gomock.Any()).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u2", Public: true}}, nil)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs?token=t&login=otheruser", nil)
@@ -83,6 +161,124 @@ func TestDocsHandler_List_WithLogin(t *testing.T) {
 	}
 }
 
+func TestDocsHandler_List_ScopeShared(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	principals := []string{"user:u1", "group:g1"}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	access.EXPECT().Principals("u1").Return(principals, nil).Times(2)
+	docs.EXPECT().ListAccessible("u1", principals, false, model.ListQuery{Limit: 20}).Return([]model.Document{{ID: "d1", Owner: "u2"}}, nil)
+	docs.EXPECT().ListAccessible("u1", principals, true, model.ListQuery{Limit: 20}).Return([]model.Document{{ID: "d1", Owner: "u2"}, {ID: "d2", Owner: "u1"}}, nil)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	for _, scope := range []string{"shared", "all"} {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/docs?token=t&scope="+scope, nil)
+
+		h.List(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("scope=%s: expected code 200, got %d", scope, rr.Code)
+		}
+	}
+}
+
+func TestDocsHandler_List_InvalidScope(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	for _, query := range []string{"scope=everything", "scope=shared&login=otheruser"} {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/docs?token=t&"+query, nil)
+
+		h.List(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected code 400, got %d", query, rr.Code)
+		}
+	}
+}
+
+func TestDocsHandler_Search(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	principals := []string{"user:u1", "group:g1"}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	access.EXPECT().Principals("u1").Return(principals, nil)
+	docs.EXPECT().Search("u1", principals, "квартальный отчёт", 5).Return([]model.SearchResult{
+		{Document: model.Document{ID: "d1"}, Rank: 0.5, Snippet: "<mark>Квартальный</mark> <mark>отчёт</mark>"},
+	}, nil)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	h.Search(rr, httptest.NewRequest(http.MethodGet, "/api/docs/search?token=t&limit=5&q=%D0%BA%D0%B2%D0%B0%D1%80%D1%82%D0%B0%D0%BB%D1%8C%D0%BD%D1%8B%D0%B9+%D0%BE%D1%82%D1%87%D1%91%D1%82", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"snippet"`) {
+		t.Fatalf("expected snippet in response, got %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.Search(rr, httptest.NewRequest(http.MethodGet, "/api/docs/search?token=t&q=+", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400 for empty query, got %d", rr.Code)
+	}
+}
+
+func TestDocsHandler_Access(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	doc := &model.Document{ID: "doc123", Owner: "u1"}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	docs.EXPECT().GetByID("doc123").Return(doc, nil)
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionManage, nil)
+	access.EXPECT().Explain("u1", doc).Return([]model.AccessEntry{
+		{UserID: "u1", Login: "owner", Permission: model.PermissionManage, Via: model.AccessViaOwner},
+		{UserID: "u2", Login: "member", Permission: model.PermissionRead, Via: model.AccessViaGroup, Group: "accounting"},
+	}, nil)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/docs/doc123/access?token=t", nil)
+
+	h.Access(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+	if !bytes.Contains(rr.Body.Bytes(), []byte(`"group":"accounting"`)) {
+		t.Fatalf("expected group entry in response, got %s", rr.Body.String())
+	}
+}
+
 func TestDocsHandler_List_WithLimit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -90,12 +286,13 @@ func TestDocsHandler_List_WithLimit(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
-	docs.EXPECT().List("u1", 5).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u1"}}, nil)
+	docs.EXPECT().List("u1", model.ListQuery{Limit: 5}).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u1"}}, nil)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs?token=t&limit=5", nil)
@@ -113,12 +310,13 @@ func TestDocsHandler_Upload_JSON_OK(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 	docs.EXPECT().Create(gomock.Any()).Return(nil)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	var buf bytes.Buffer
 	mw := multipart.NewWriter(&buf)
@@ -136,6 +334,86 @@ func TestDocsHandler_Upload_JSON_OK(t *testing.T) {
 	}
 }
 
+func TestDocsHandler_Upload_YAML(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	docs.EXPECT().Create(gomock.Any()).DoAndReturn(func(doc *model.Document) error {
+		if string(doc.JsonData) != `{"items":[1,2],"name":"конфиг"}` {
+			t.Fatalf("unexpected json data %s", doc.JsonData)
+		}
+		return nil
+	})
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("meta", `{"name":"doc","file":false,"format":"yaml","grants":[]}`)
+	_ = mw.WriteField("json", "name: конфиг\nitems:\n  - 1\n  - 2\n")
+	_ = mw.WriteField("token", "t")
+	_ = mw.Close()
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/docs", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	h.Upload(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestDocsHandler_Upload_File_RoundTrip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	var stored *model.Document
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	docs.EXPECT().Create(gomock.Any()).DoAndReturn(func(doc *model.Document) error {
+		stored = doc
+		return nil
+	})
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("meta", `{"name":"report.txt","file":true,"public":false,"mime":"text/plain","grants":[]}`)
+	fw, _ := mw.CreateFormFile("file", "report.txt")
+	_, _ = fw.Write([]byte("hello"))
+	_ = mw.WriteField("token", "t")
+	_ = mw.Close()
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/docs", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	h.Upload(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	docs.EXPECT().GetByID(stored.ID).Return(stored, nil)
+	access.EXPECT().Permission("u1", stored).Return(model.PermissionManage, nil)
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/docs/"+stored.ID+"?token=t", nil)
+
+	h.GetByID(rr, req)
+	if rr.Code != http.StatusOK || rr.Body.String() != "hello" {
+		t.Fatalf("expected stored file, got %d %q", rr.Code, rr.Body.String())
+	}
+}
+
 func TestDocsHandler_Upload_InvalidToken(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -143,11 +421,12 @@ func TestDocsHandler_Upload_InvalidToken(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("invalid").Return(model.Session{}, false)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	var buf bytes.Buffer
 	mw := multipart.NewWriter(&buf)
@@ -171,11 +450,12 @@ func TestDocsHandler_Upload_WrongMethod(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs?token=t", nil)
@@ -193,11 +473,12 @@ func TestDocsHandler_Upload_InvalidMeta(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	var buf bytes.Buffer
 	mw := multipart.NewWriter(&buf)
@@ -221,11 +502,12 @@ func TestDocsHandler_Upload_InvalidJson(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	var buf bytes.Buffer
 	mw := multipart.NewWriter(&buf)
@@ -250,12 +532,13 @@ func TestDocsHandler_Upload_ServiceError(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 	docs.EXPECT().Create(gomock.Any()).Return(errors.New("service error"))
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	var buf bytes.Buffer
 	mw := multipart.NewWriter(&buf)
@@ -280,6 +563,7 @@ func TestDocsHandler_GetByID_OK(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 	docs.EXPECT().GetByID("doc123").Return(&model.Document{
@@ -287,8 +571,10 @@ func TestDocsHandler_GetByID_OK(t *testing.T) {
 		File: false, Public: false, Owner: "u1", JsonData: []byte(`{"test": "data"}`),
 	}, nil)
 
+	access.EXPECT().Permission("u1", gomock.Any()).Return(model.PermissionManage, nil)
+
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t", nil)
@@ -299,6 +585,145 @@ func TestDocsHandler_GetByID_OK(t *testing.T) {
 	}
 }
 
+func TestDocsHandler_GetByID_Select(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	docs.EXPECT().GetByID("doc123").Return(&model.Document{
+		ID: "doc123", Owner: "u1", JsonData: []byte(`{"meta": {"tags": ["a", "b"]}, "items": [{"n": 1}, {"n": 2}]}`),
+	}, nil)
+	access.EXPECT().Permission("u1", gomock.Any()).Return(model.PermissionManage, nil)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	h.GetByID(rr, httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t&select=/meta/tags/1&select=$.items[*].n", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `"/meta/tags/1":"b"`) || !strings.Contains(body, `"$.items[*].n":[1,2]`) {
+		t.Fatalf("unexpected selection %s", body)
+	}
+
+	rr = httptest.NewRecorder()
+	h.GetByID(rr, httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t&select=meta", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400 for invalid selector, got %d", rr.Code)
+	}
+}
+
+func TestDocsHandler_GetByID_Format(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(4)
+	docs.EXPECT().GetByID("doc123").Return(&model.Document{
+		ID: "doc123", Owner: "u1", JsonData: []byte(`{"name": "a", "count": 1}`),
+	}, nil)
+	access.EXPECT().Permission("u1", gomock.Any()).Return(model.PermissionRead, nil).Times(4)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	h.GetByID(rr, httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t&format=yaml", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/yaml") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	if body := rr.Body.String(); body != "count: 1\nname: a\n" {
+		t.Fatalf("unexpected yaml body %q", body)
+	}
+
+	rr = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t", nil)
+	req.Header.Set("Accept", "text/csv")
+	h.GetByID(rr, req)
+	if rr.Code != http.StatusNotAcceptable {
+		t.Fatalf("expected code 406 for non-tabular csv, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t", nil)
+	req.Header.Set("Accept", "application/json")
+	h.GetByID(rr, req)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"data"`) {
+		t.Fatalf("expected wrapped json response, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.GetByID(rr, httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t&format=xml", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400 for unknown format, got %d", rr.Code)
+	}
+}
+
+func TestDocsHandler_GetByID_NoAccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	doc := &model.Document{ID: "doc123", Name: "secret.json", Owner: "u2"}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	docs.EXPECT().GetByID("doc123").Return(doc, nil)
+	access.EXPECT().Permission("u1", doc).Return(model.Permission(""), nil)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t", nil)
+
+	h.GetByID(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected code 404, got %d", rr.Code)
+	}
+}
+
+func TestDocsHandler_Upload_UnknownGrant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	access.EXPECT().ResolveGrants([]string{"group:nobody"}).Return(nil, fmt.Errorf("%w: group:nobody", service.ErrUnknownPrincipal))
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("meta", `{"name":"doc","file":false,"public":false,"mime":"application/json","grants":["group:nobody"]}`)
+	_ = mw.WriteField("token", "t")
+	_ = mw.Close()
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/docs", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	h.Upload(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400, got %d", rr.Code)
+	}
+}
+
 func TestDocsHandler_GetByID_InvalidToken(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -306,11 +731,12 @@ func TestDocsHandler_GetByID_InvalidToken(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("invalid").Return(model.Session{}, false)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=invalid", nil)
@@ -328,12 +754,13 @@ func TestDocsHandler_GetByID_NotFound(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 	docs.EXPECT().GetByID("nonexistent").Return(nil, errors.New("not found"))
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs/nonexistent?token=t", nil)
@@ -351,12 +778,16 @@ func TestDocsHandler_DeleteByID_OK(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
+	doc := &model.Document{ID: "doc123", Owner: "u1"}
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	docs.EXPECT().GetByID("doc123").Return(doc, nil)
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionManage, nil)
 	docs.EXPECT().Delete("doc123").Return(nil)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodDelete, "/api/docs/doc123?token=t", nil)
@@ -374,11 +805,12 @@ func TestDocsHandler_DeleteByID_InvalidToken(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("invalid").Return(model.Session{}, false)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodDelete, "/api/docs/doc123?token=invalid", nil)
@@ -396,11 +828,12 @@ func TestDocsHandler_DeleteByID_WrongMethod(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t", nil)
@@ -411,6 +844,102 @@ func TestDocsHandler_DeleteByID_WrongMethod(t *testing.T) {
 	}
 }
 
+func TestDocsHandler_DeleteByID_ReadOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	doc := &model.Document{ID: "doc123", Owner: "u2"}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	docs.EXPECT().GetByID("doc123").Return(doc, nil)
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionWrite, nil)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/docs/doc123?token=t", nil)
+
+	h.DeleteByID(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected code 403, got %d", rr.Code)
+	}
+}
+
+func TestDocsHandler_Update_Write(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	doc := &model.Document{ID: "doc123", Name: "old.json", Owner: "u2"}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	docs.EXPECT().GetByID("doc123").Return(doc, nil).Times(2)
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionWrite, nil).Times(2)
+	docs.EXPECT().Update(gomock.Any()).DoAndReturn(func(d *model.Document) error {
+		if d.Name != "new.json" || string(d.JsonData) != `{"a":2}` {
+			t.Fatalf("unexpected update: %+v", d)
+		}
+		return nil
+	})
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPatch, "/api/docs/doc123?token=t", bytes.NewBufferString(`{"name":"new.json","json":{"a":2}}`))
+	h.Update(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+
+	// Публичность меняет только manage
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPatch, "/api/docs/doc123?token=t", bytes.NewBufferString(`{"public":true}`))
+	h.Update(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected code 403, got %d", rr.Code)
+	}
+}
+
+func TestDocsHandler_SetGrant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	doc := &model.Document{ID: "doc123", Owner: "u1"}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	docs.EXPECT().GetByID("doc123").Return(doc, nil).Times(2)
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionManage, nil).Times(2)
+	access.EXPECT().SetGrant("u1", doc, gomock.Any()).Return(&model.Grant{Principal: "group:g1", Permission: model.PermissionRead}, nil)
+	access.EXPECT().SetGrant("u1", doc, gomock.Any()).Return(nil, service.ErrForbidden)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/docs/doc123/grants?token=t", bytes.NewBufferString(`{"principal":"group:accounting","permission":"read"}`))
+	h.SetGrant(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPut, "/api/docs/doc123/grants?token=t", bytes.NewBufferString(`{"principal":"group:accounting","permission":"manage"}`))
+	h.SetGrant(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected code 403, got %d", rr.Code)
+	}
+}
+
 func TestDocsHandler_DeleteByID_NotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -418,12 +947,13 @@ func TestDocsHandler_DeleteByID_NotFound(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
-	docs.EXPECT().Delete("nonexistent").Return(errors.New("not found"))
+	docs.EXPECT().GetByID("nonexistent").Return(nil, errors.New("not found"))
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodDelete, "/api/docs/nonexistent?token=t", nil)
@@ -441,12 +971,13 @@ func TestDocsHandler_List_WithInvalidLimit(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
-	docs.EXPECT().List("u1", 20).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u1"}}, nil)
+	docs.EXPECT().List("u1", model.ListQuery{Limit: 20}).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u1"}}, nil)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs?token=t&limit=invalid", nil)
@@ -464,12 +995,13 @@ func TestDocsHandler_List_WithNegativeLimit(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
-	docs.EXPECT().List("u1", -5).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u1"}}, nil)
+	docs.EXPECT().List("u1", model.ListQuery{Limit: -5}).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u1"}}, nil)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs?token=t&limit=-5", nil)
@@ -487,12 +1019,13 @@ func TestDocsHandler_List_WithZeroLimit(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
-	docs.EXPECT().List("u1", 0).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u1"}}, nil)
+	docs.EXPECT().List("u1", model.ListQuery{Limit: 0}).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u1"}}, nil)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs?token=t&limit=0", nil)
@@ -510,12 +1043,13 @@ func TestDocsHandler_List_WithLargeLimit(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
-	docs.EXPECT().List("u1", 100).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u1"}}, nil)
+	docs.EXPECT().List("u1", model.ListQuery{Limit: 100}).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u1"}}, nil)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs?token=t&limit=100", nil)
@@ -533,12 +1067,13 @@ func TestDocsHandler_List_WithUnknownLogin(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 	userRepo.EXPECT().GetByLogin("unknownuser").Return(nil, errors.New("not found"))
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs?token=t&login=unknownuser", nil)
@@ -556,12 +1091,13 @@ func TestDocsHandler_List_WithHeadMethod(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 	docs.EXPECT().List("u1", gomock.Any()).Return([]model.Document{{ID: "d1", Name: "f", Owner: "u1"}}, nil)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodHead, "/api/docs?token=t", nil)
@@ -579,6 +1115,7 @@ func TestDocsHandler_GetByID_WithHeadMethod(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 	docs.EXPECT().GetByID("doc123").Return(&model.Document{
@@ -586,8 +1123,10 @@ func TestDocsHandler_GetByID_WithHeadMethod(t *testing.T) {
 		File: false, Public: false, Owner: "u1", JsonData: []byte(`{"test": "data"}`),
 	}, nil)
 
+	access.EXPECT().Permission("u1", gomock.Any()).Return(model.PermissionManage, nil)
+
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodHead, "/api/docs/doc123?token=t", nil)
@@ -605,11 +1144,12 @@ func TestDocsHandler_GetByID_MissingID(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/api/docs/?token=t", nil)
@@ -627,11 +1167,12 @@ func TestDocsHandler_GetByID_WrongMethod(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/api/docs/doc123?token=t", nil)
@@ -649,11 +1190,12 @@ func TestDocsHandler_DeleteByID_MissingID(t *testing.T) {
 	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
 	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
 	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
 
 	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
 
 	c := cache.NewCache(0)
-	h := NewDocsHandler(docs, c, sess, userRepo)
+	h := NewDocsHandler(docs, c, sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
 
 	rr := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodDelete, "/api/docs/?token=t", nil)
@@ -663,3 +1205,565 @@ func TestDocsHandler_DeleteByID_MissingID(t *testing.T) {
 		t.Fatalf("expected code 400, got %d", rr.Code)
 	}
 }
+
+func newTestBlobStore(t *testing.T) storage.BlobStore {
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create blob store: %v", err)
+	}
+	return store
+}
+
+// newTestQuota пропускает любые загрузки: лимиты проверяются в тестах сервиса квот
+func newTestQuota(ctrl *gomock.Controller) *mocksgen.MockQuotaServiceInterface {
+	quota := mocksgen.NewMockQuotaServiceInterface(ctrl)
+	quota.EXPECT().Check(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	quota.EXPECT().Reader(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ string, _ int, _ int64, r io.Reader) (io.Reader, error) {
+		return r, nil
+	}).AnyTimes()
+	return quota
+}
+
+// newTestScans ставит файлы на проверку и сразу считает их чистыми
+func newTestScans(ctrl *gomock.Controller) *mocksgen.MockScanServiceInterface {
+	scans := mocksgen.NewMockScanServiceInterface(ctrl)
+	scans.EXPECT().Queue(gomock.Any()).Do(func(doc *model.Document) { doc.ScanStatus = model.ScanPending }).AnyTimes()
+	scans.EXPECT().Scan(gomock.Any()).Do(func(doc *model.Document) { doc.ScanStatus = model.ScanClean }).Return(nil).AnyTimes()
+	return scans
+}
+
+// newTestMime определяет тип по содержимому без списков типов
+func newTestMime() *service.MimeService {
+	return service.NewMimeService(nil, nil, service.MimeMismatchReject)
+}
+
+// newTestCompression сжимает текстовые файлы, как по умолчанию в конфигурации
+func newTestCompression() *service.CompressionService {
+	return service.NewCompressionService(compress.Policy{MaxRatio: 0.9})
+}
+
+func TestDocsHandler_List_TagsAndMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	docs.EXPECT().List("u1", model.ListQuery{
+		Limit:    20,
+		Tags:     []string{"invoice", "2026"},
+		Metadata: model.Metadata{"customer": "acme"},
+	}).Return([]model.Document{}, nil)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	h.List(rr, httptest.NewRequest(http.MethodGet, "/api/docs?token=t&tag=Invoice&tag=2026&meta.customer=acme", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.List(rr, httptest.NewRequest(http.MethodGet, "/api/docs?token=t&meta.bad%20key=x", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400 for invalid metadata key, got %d", rr.Code)
+	}
+}
+
+func TestDocsHandler_Update_TagsAndMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	schemas := mocksgen.NewMockSchemaServiceInterface(ctrl)
+
+	doc := &model.Document{ID: "doc123", Name: "a.json", Owner: "u1", Tags: []string{"old"}, Metadata: model.Metadata{"customer": "acme", "stage": "draft"}}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	docs.EXPECT().GetByID("doc123").Return(doc, nil)
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionWrite, nil)
+	// Новые теги могут принести с собой схемы
+	schemas.EXPECT().Validate(doc).Return(nil)
+	docs.EXPECT().Update(gomock.Any()).DoAndReturn(func(d *model.Document) error {
+		if !reflect.DeepEqual([]string(d.Tags), []string{"invoice"}) {
+			t.Fatalf("unexpected tags %v", d.Tags)
+		}
+		if !reflect.DeepEqual(d.Metadata, model.Metadata{"customer": "acme", "year": "2026"}) {
+			t.Fatalf("unexpected metadata %v", d.Metadata)
+		}
+		return nil
+	})
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, schemas, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPatch, "/api/docs/doc123?token=t", bytes.NewBufferString(`{"tags":["Invoice","invoice"],"metadata":{"stage":null,"year":"2026"}}`))
+	h.Update(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestDocsHandler_Tags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(3)
+	docs.EXPECT().CountTags("u1", nil, true, false).Return([]model.TagCount{{Tag: "invoice", Count: 3}}, nil)
+	access.EXPECT().Principals("u1").Return([]string{"user:u1"}, nil)
+	docs.EXPECT().CountTags("u1", []string{"user:u1"}, false, true).Return([]model.TagCount{}, nil)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	h.Tags(rr, httptest.NewRequest(http.MethodGet, "/api/tags?token=t", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"count":3`) {
+		t.Fatalf("expected tag counts, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.Tags(rr, httptest.NewRequest(http.MethodGet, "/api/tags?token=t&scope=shared", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.Tags(rr, httptest.NewRequest(http.MethodGet, "/api/tags?token=t&scope=mine", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400, got %d", rr.Code)
+	}
+}
+
+func TestDocsHandler_GetByID_ExpiredNotServedFromCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	expiresAt := time.Now().Add(30 * time.Millisecond)
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	docs.EXPECT().GetByID("doc123").Return(&model.Document{ID: "doc123", Owner: "u1", JsonData: []byte(`{}`), ExpiresAt: &expiresAt}, nil)
+	// После истечения срока документа запись кэша не используется, а БД его уже не отдаёт
+	docs.EXPECT().GetByID("doc123").Return(nil, sql.ErrNoRows)
+	access.EXPECT().Permission("u1", gomock.Any()).Return(model.PermissionManage, nil)
+
+	h := NewDocsHandler(docs, cache.NewCache(time.Hour), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	h.GetByID(rr, httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	rr = httptest.NewRecorder()
+	h.GetByID(rr, httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected code 404 after expiry, got %d", rr.Code)
+	}
+}
+
+func TestDocsHandler_Upload_ExpiresAt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	docs.EXPECT().Create(gomock.Any()).DoAndReturn(func(doc *model.Document) error {
+		if doc.ExpiresAt == nil || time.Until(*doc.ExpiresAt) < 23*time.Hour || time.Until(*doc.ExpiresAt) > 24*time.Hour {
+			t.Fatalf("unexpected expires_at %v", doc.ExpiresAt)
+		}
+		return nil
+	})
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, userRepo, newTestBlobStore(t), access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	for _, tc := range []struct {
+		expires string
+		code    int
+	}{
+		{"24h", http.StatusOK},
+		{"yesterday", http.StatusBadRequest},
+	} {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		_ = mw.WriteField("meta", `{"name":"build.json","expires_at":"`+tc.expires+`"}`)
+		_ = mw.WriteField("json", `{"a":1}`)
+		_ = mw.WriteField("token", "t")
+		_ = mw.Close()
+		req := httptest.NewRequest(http.MethodPost, "/api/docs", &buf)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		rr := httptest.NewRecorder()
+		h.Upload(rr, req)
+		if rr.Code != tc.code {
+			t.Fatalf("expires_at %q: expected code %d, got %d: %s", tc.expires, tc.code, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestDocsHandler_Upload_Quota(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{service.ErrFileTooLarge, http.StatusRequestEntityTooLarge},
+		{service.ErrStorageQuota, http.StatusInsufficientStorage},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+
+		docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+		sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+		quota := mocksgen.NewMockQuotaServiceInterface(ctrl)
+		sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+		// Квота обрывает чтение файла: документ не создаётся
+		quota.EXPECT().Reader("u1", 1, int64(0), gomock.Any()).Return(iotest.ErrReader(c.err), nil)
+
+		blobs := newTestBlobStore(t)
+		h := NewDocsHandler(docs, cache.NewCache(0), sess, nil, blobs, nil, nil, nil, quota, newTestMime(), newTestScans(ctrl), newTestCompression())
+
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		_ = mw.WriteField("meta", `{"name":"big.bin","file":true}`)
+		fw, _ := mw.CreateFormFile("file", "big.bin")
+		_, _ = fw.Write([]byte("payload"))
+		_ = mw.WriteField("token", "t")
+		_ = mw.Close()
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/docs", &buf)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+
+		h.Upload(rr, req)
+		if rr.Code != c.code {
+			t.Fatalf("%v: expected code %d, got %d: %s", c.err, c.code, rr.Code, rr.Body.String())
+		}
+		ctrl.Finish()
+	}
+}
+
+func TestDocsHandler_Upload_DocumentQuota(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	quota := mocksgen.NewMockQuotaServiceInterface(ctrl)
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	quota.EXPECT().Check("u1", 1, int64(len(`{"a":1}`)), int64(0)).Return(service.ErrDocumentQuota)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, nil, newTestBlobStore(t), nil, nil, nil, quota, newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("meta", `{"name":"a.json","file":false}`)
+	_ = mw.WriteField("json", `{"a":1}`)
+	_ = mw.WriteField("token", "t")
+	_ = mw.Close()
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/docs", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	h.Upload(rr, req)
+	if rr.Code != http.StatusInsufficientStorage {
+		t.Fatalf("expected code 507, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+// Лимит, превышенный одновременной записью, отклоняет триггер БД уже после проверки квоты
+func TestDocsHandler_Upload_QuotaRejectedByDatabase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	quota := mocksgen.NewMockQuotaServiceInterface(ctrl)
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	quota.EXPECT().Check("u1", 1, int64(len(`{"a":1}`)), int64(0)).Return(nil)
+	docs.EXPECT().Create(gomock.Any()).Return(repository.ErrDocumentQuota)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, nil, newTestBlobStore(t), nil, nil, nil, quota, newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("meta", `{"name":"a.json","file":false}`)
+	_ = mw.WriteField("json", `{"a":1}`)
+	_ = mw.WriteField("token", "t")
+	_ = mw.Close()
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/docs", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	h.Upload(rr, req)
+	if rr.Code != http.StatusInsufficientStorage {
+		t.Fatalf("expected code 507, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestDocsHandler_Upload_MimeMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, nil, newTestBlobStore(t), nil, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("meta", `{"name":"cat.png","file":true,"mime":"image/png"}`)
+	fw, _ := mw.CreateFormFile("file", "cat.png")
+	_, _ = fw.Write([]byte("<html><script>alert(1)</script></html>"))
+	_ = mw.WriteField("token", "t")
+	_ = mw.Close()
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/docs", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	h.Upload(rr, req)
+	if rr.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected code 415, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestDocsHandler_GetByID_FileHeaders(t *testing.T) {
+	cases := []struct {
+		mime        string
+		disposition string
+		csp         string
+	}{
+		{"image/png", `inline; filename="cat 1.png"`, ""},
+		{"text/html", `attachment; filename="cat 1.png"`, "sandbox"},
+		{"", `attachment; filename="cat 1.png"`, "sandbox"},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+
+		docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+		sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+		access := mocksgen.NewMockAccessServiceInterface(ctrl)
+		sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+		docs.EXPECT().GetByID("doc123").Return(&model.Document{ID: "doc123", Name: "cat 1.png", Mime: c.mime, File: true, Owner: "u1"}, nil)
+		access.EXPECT().Permission("u1", gomock.Any()).Return(model.PermissionManage, nil)
+
+		blobs := newTestBlobStore(t)
+		if _, err := blobs.Put("doc123", strings.NewReader("content")); err != nil {
+			t.Fatalf("cannot write blob: %v", err)
+		}
+		h := NewDocsHandler(docs, cache.NewCache(0), sess, nil, blobs, access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t", nil)
+		h.GetByID(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%q: expected code 200, got %d: %s", c.mime, rr.Code, rr.Body.String())
+		}
+		if got := rr.Header().Get("Content-Disposition"); got != c.disposition {
+			t.Errorf("%q: unexpected Content-Disposition %q", c.mime, got)
+		}
+		if got := rr.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+			t.Errorf("%q: expected nosniff, got %q", c.mime, got)
+		}
+		if got := rr.Header().Get("Content-Security-Policy"); got != c.csp {
+			t.Errorf("%q: unexpected Content-Security-Policy %q", c.mime, got)
+		}
+		ctrl.Finish()
+	}
+}
+
+func TestDocsHandler_Upload_Compressed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	var stored *model.Document
+	docs.EXPECT().Create(gomock.Any()).DoAndReturn(func(doc *model.Document) error {
+		stored = doc
+		return nil
+	})
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, nil, newTestBlobStore(t), nil, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	text := strings.Repeat("name,count\nalpha,1\n", 1000)
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("meta", `{"name":"report.csv","file":true,"mime":"text/csv"}`)
+	fw, _ := mw.CreateFormFile("file", "report.csv")
+	_, _ = fw.Write([]byte(text))
+	_ = mw.WriteField("token", "t")
+	_ = mw.Close()
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/docs", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	h.Upload(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	// В квоте учитывается исходный размер, сжатый — отдельно
+	if stored.Encoding != compress.Gzip || *stored.Size != int64(len(text)) || *stored.StoredSize >= int64(len(text))/5 {
+		t.Fatalf("unexpected document: encoding %q, size %d, stored %d", stored.Encoding, *stored.Size, *stored.StoredSize)
+	}
+}
+
+func TestDocsHandler_GetByID_Compressed(t *testing.T) {
+	text := strings.Repeat("0123456789", 1000)
+	blobs := newTestBlobStore(t)
+	packed, _ := compress.Policy{MaxRatio: 0.9}.Pack("text/plain", strings.NewReader(text))
+	if _, err := blobs.Put("doc123", packed); err != nil || packed.Encoding != compress.Gzip {
+		t.Fatalf("cannot write blob: %q, %v", packed.Encoding, err)
+	}
+	size := int64(len(text))
+	doc := &model.Document{ID: "doc123", Name: "digits.txt", Mime: "text/plain", File: true, Owner: "u1", Size: &size, Encoding: compress.Gzip}
+
+	cases := []struct {
+		name, acceptEncoding, rangeHeader string
+		code                              int
+		encoding, etag                    string
+		want                              string
+	}{
+		{"identity", "", "", http.StatusOK, "", `"doc123"`, text},
+		{"gzip refused", "gzip;q=0, br", "", http.StatusOK, "", `"doc123"`, text},
+		{"gzip", "br, gzip", "", http.StatusOK, "gzip", `"doc123-gzip"`, text},
+		{"range", "gzip", "bytes=5005-5014", http.StatusPartialContent, "", `"doc123"`, text[5005:5015]},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+		docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+		sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+		access := mocksgen.NewMockAccessServiceInterface(ctrl)
+		sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+		docs.EXPECT().GetByID("doc123").Return(doc, nil)
+		access.EXPECT().Permission("u1", gomock.Any()).Return(model.PermissionManage, nil)
+		h := NewDocsHandler(docs, cache.NewCache(0), sess, nil, blobs, access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t", nil)
+		if c.acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", c.acceptEncoding)
+		}
+		if c.rangeHeader != "" {
+			req.Header.Set("Range", c.rangeHeader)
+		}
+		h.GetByID(rr, req)
+
+		if rr.Code != c.code || rr.Header().Get("Content-Encoding") != c.encoding {
+			t.Fatalf("%s: unexpected code %d, Content-Encoding %q", c.name, rr.Code, rr.Header().Get("Content-Encoding"))
+		}
+		if rr.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("%s: expected Vary: Accept-Encoding", c.name)
+		}
+		if got := rr.Header().Get("ETag"); got != c.etag {
+			t.Errorf("%s: expected ETag %s, got %q", c.name, c.etag, got)
+		}
+		body := rr.Body.Bytes()
+		if c.encoding == "gzip" {
+			zr, err := gzip.NewReader(bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("%s: body is not gzip: %v", c.name, err)
+			}
+			body, _ = io.ReadAll(zr)
+		}
+		if string(body) != c.want {
+			t.Errorf("%s: unexpected body of %d bytes", c.name, len(body))
+		}
+		ctrl.Finish()
+	}
+}
+
+func TestDocsHandler_GetByID_ScanStatus(t *testing.T) {
+	cases := []struct {
+		status string
+		code   int
+	}{
+		{model.ScanPending, http.StatusConflict},
+		{model.ScanInfected, http.StatusForbidden},
+		{model.ScanFailed, http.StatusForbidden},
+		{model.ScanClean, http.StatusOK},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+
+		docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+		sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+		access := mocksgen.NewMockAccessServiceInterface(ctrl)
+		sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+		docs.EXPECT().GetByID("doc123").Return(&model.Document{ID: "doc123", Name: "a.txt", Mime: "text/plain", File: true, Owner: "u1", ScanStatus: c.status}, nil)
+		access.EXPECT().Permission("u1", gomock.Any()).Return(model.PermissionManage, nil)
+
+		blobs := newTestBlobStore(t)
+		if _, err := blobs.Put("doc123", strings.NewReader("content")); err != nil {
+			t.Fatalf("cannot write blob: %v", err)
+		}
+		h := NewDocsHandler(docs, cache.NewCache(time.Hour), sess, nil, blobs, access, nil, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+		rr := httptest.NewRecorder()
+		h.GetByID(rr, httptest.NewRequest(http.MethodGet, "/api/docs/doc123?token=t", nil))
+		if rr.Code != c.code {
+			t.Fatalf("%s: expected code %d, got %d: %s", c.status, c.code, rr.Code, rr.Body.String())
+		}
+		// Непроверенный файл не кэшируется: после проверки он должен отдаваться
+		if _, cached := h.cache.Get("doc:doc123"); cached == (c.status == model.ScanPending) {
+			t.Fatalf("%s: unexpected cache state %v", c.status, cached)
+		}
+		ctrl.Finish()
+	}
+}
+
+func TestDocsHandler_Upload_Scan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	scans := mocksgen.NewMockScanServiceInterface(ctrl)
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	gomock.InOrder(
+		scans.EXPECT().Queue(gomock.Any()).Do(func(doc *model.Document) { doc.ScanStatus = model.ScanPending }),
+		docs.EXPECT().Create(gomock.Any()).DoAndReturn(func(doc *model.Document) error {
+			if doc.ScanStatus != model.ScanPending {
+				t.Fatalf("expected the file to be created pending scan, got %q", doc.ScanStatus)
+			}
+			return nil
+		}),
+		// Сканер недоступен: загрузка всё равно успешна, файл ждёт проверки
+		scans.EXPECT().Scan(gomock.Any()).Return(errors.New("connection refused")),
+	)
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, nil, newTestBlobStore(t), nil, nil, nil, newTestQuota(ctrl), newTestMime(), scans, newTestCompression())
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("meta", `{"name":"a.txt","file":true}`)
+	fw, _ := mw.CreateFormFile("file", "a.txt")
+	_, _ = fw.Write([]byte("hello"))
+	_ = mw.WriteField("token", "t")
+	_ = mw.Close()
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/docs", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	h.Upload(rr, req)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"scan_status":"pending_scan"`) {
+		t.Fatalf("expected pending upload, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
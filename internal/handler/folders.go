@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"astra-api/internal/cache"
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// maxMoveDocuments ограничивает число документов в одном запросе переноса
+const maxMoveDocuments = 1000
+
+type FoldersHandler struct {
+	sessionService service.SessionServiceInterface
+	folders        service.FolderServiceInterface
+	access         service.AccessServiceInterface
+	cache          *cache.Cache
+}
+
+func NewFoldersHandler(sessionService service.SessionServiceInterface, folders service.FolderServiceInterface, access service.AccessServiceInterface, cache *cache.Cache) *FoldersHandler {
+	return &FoldersHandler{sessionService: sessionService, folders: folders, access: access, cache: cache}
+}
+
+// @Summary Создать папку
+// @Description В корне или внутри папки с уровнем manage; вложенная папка принадлежит владельцу родителя
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param input body model.CreateFolderRequest true "Имя и родитель"
+// @Success 200 {object} model.APIResponse
+// @Router /api/folders [post]
+func (h *FoldersHandler) Create(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPost {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	var req model.CreateFolderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	folder, err := h.folders.Create(sess.UserID, req)
+	if err != nil {
+		writeFolderError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: folder})
+}
+
+// @Summary Мои и доступные мне папки
+// @Description Корневые папки пользователя и чужие папки, выданные ему грантом
+// @Tags folders
+// @Produce json
+// @Param token query string true "Токен"
+// @Success 200 {object} model.APIResponse
+// @Router /api/folders [get]
+func (h *FoldersHandler) List(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	folders, err := h.folders.List(sess.UserID)
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"folders": folders}})
+}
+
+// @Summary Содержимое папки
+// @Description Папка, уровень доступа к ней, подпапки и документы (без json); нужен уровень read
+// @Tags folders
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID папки"
+// @Success 200 {object} model.APIResponse
+// @Router /api/folders/{id} [get]
+func (h *FoldersHandler) Get(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	id, _ := parseFolderPath(r.URL.Path)
+	if id == "" {
+		WriteError(w, 400, "missing folder id")
+		return
+	}
+	contents, err := h.folders.Get(sess.UserID, id)
+	if err != nil {
+		writeFolderError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: contents})
+}
+
+// @Summary Изменить папку
+// @Description Переименование — уровень write; перенос вместе с содержимым и смена схемы — manage.
+// @Description Перенос возможен только в папку того же владельца и не внутрь самой себя.
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID папки"
+// @Param input body model.UpdateFolderRequest true "Изменяемые поля"
+// @Success 200 {object} model.APIResponse
+// @Router /api/folders/{id} [patch]
+func (h *FoldersHandler) Update(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPatch {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	id, _ := parseFolderPath(r.URL.Path)
+	if id == "" {
+		WriteError(w, 400, "missing folder id")
+		return
+	}
+	var req model.UpdateFolderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	folder, err := h.folders.Update(sess.UserID, id, req)
+	if err != nil {
+		writeFolderError(w, err)
+		return
+	}
+	h.cache.InvalidateAll()
+	WriteResponse(w, &model.APIResponse{Data: folder})
+}
+
+// @Summary Удалить папку
+// @Description Только пустую; нужен уровень manage
+// @Tags folders
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID папки"
+// @Success 200 {object} model.APIResponse
+// @Router /api/folders/{id} [delete]
+func (h *FoldersHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	id, _ := parseFolderPath(r.URL.Path)
+	if id == "" {
+		WriteError(w, 400, "missing folder id")
+		return
+	}
+	if err := h.folders.Delete(sess.UserID, id); err != nil {
+		writeFolderError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Response: map[string]bool{id: true}})
+}
+
+// @Summary Гранты папки
+// @Description Нужен уровень share. Гранты действуют на всё содержимое папки.
+// @Tags folders
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID папки"
+// @Success 200 {object} model.APIResponse
+// @Router /api/folders/{id}/grants [get]
+func (h *FoldersHandler) ListGrants(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	folder, ok := h.loadFolder(w, r, sess)
+	if !ok {
+		return
+	}
+	grants, err := h.access.ListFolderGrants(sess.UserID, folder)
+	if err != nil {
+		writeAccessError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"grants": grants}})
+}
+
+// @Summary Выдать доступ к папке
+// @Description Нужен уровень share; выдать можно уровень не выше собственного
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID папки"
+// @Param input body model.GrantRequest true "Грант"
+// @Success 200 {object} model.APIResponse
+// @Router /api/folders/{id}/grants [put]
+func (h *FoldersHandler) SetGrant(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPut {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	var req model.GrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Principal == "" {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	folder, ok := h.loadFolder(w, r, sess)
+	if !ok {
+		return
+	}
+	grant, err := h.access.SetFolderGrant(sess.UserID, folder, req)
+	if err != nil {
+		writeAccessError(w, err)
+		return
+	}
+	h.cache.InvalidateAll()
+	WriteResponse(w, &model.APIResponse{Data: grant})
+}
+
+// @Summary Отозвать доступ к папке
+// @Description Нужен уровень manage; от собственного гранта можно отказаться без него
+// @Tags folders
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID папки"
+// @Param principal query string true "Логин, user:<login> или group:<name>"
+// @Success 200 {object} model.APIResponse
+// @Router /api/folders/{id}/grants [delete]
+func (h *FoldersHandler) RevokeGrant(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	principal := r.URL.Query().Get("principal")
+	if principal == "" {
+		WriteError(w, 400, "missing principal")
+		return
+	}
+	folder, ok := h.loadFolder(w, r, sess)
+	if !ok {
+		return
+	}
+	if err := h.access.RevokeFolderGrant(sess.UserID, folder, principal); err != nil {
+		writeAccessError(w, err)
+		return
+	}
+	h.cache.InvalidateAll()
+	WriteResponse(w, &model.APIResponse{Response: map[string]bool{principal: true}})
+}
+
+// @Summary Перенести документы в папку
+// @Description Все документы переносятся одной транзакцией или не переносится ни один.
+// @Description Нужен manage на каждый документ и на папку; folder "" — перенос в корень.
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param input body model.MoveDocumentsRequest true "Документы и папка"
+// @Success 200 {object} model.APIResponse
+// @Router /api/docs/move [post]
+func (h *FoldersHandler) MoveDocuments(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPost {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	var req model.MoveDocumentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	if len(req.Documents) == 0 {
+		WriteError(w, 400, "missing documents")
+		return
+	}
+	if len(req.Documents) > maxMoveDocuments {
+		WriteError(w, 400, "too many documents")
+		return
+	}
+	if err := h.folders.MoveDocuments(sess.UserID, req.Documents, req.Folder); err != nil {
+		writeFolderError(w, err)
+		return
+	}
+	h.cache.InvalidateAll()
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"moved": len(req.Documents), "folder": req.Folder}})
+}
+
+// loadFolder находит папку из URL, которую пользователь может читать
+func (h *FoldersHandler) loadFolder(w http.ResponseWriter, r *http.Request, sess model.Session) (*model.Folder, bool) {
+	id, _ := parseFolderPath(r.URL.Path)
+	if id == "" {
+		WriteError(w, 400, "missing folder id")
+		return nil, false
+	}
+	folder, err := h.folders.Lookup(sess.UserID, id)
+	if err != nil {
+		writeFolderError(w, err)
+		return nil, false
+	}
+	return folder, true
+}
+
+// parseFolderPath разбирает /api/folders/{id} и /api/folders/{id}/grants
+func parseFolderPath(path string) (id, sub string) {
+	id, sub, _ = strings.Cut(strings.TrimPrefix(path, "/api/folders/"), "/")
+	return id, sub
+}
+
+// writeFolderError отвечает на ошибки папок; ошибки схемы папки — как при привязке к документу
+func writeFolderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrFolderNotFound), errors.Is(err, service.ErrDocumentNotFound):
+		WriteError(w, 404, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		WriteError(w, 403, err.Error())
+	case errors.Is(err, service.ErrFolderExists), errors.Is(err, service.ErrFolderNotEmpty), errors.Is(err, service.ErrDocumentExists):
+		WriteError(w, 409, err.Error())
+	case errors.Is(err, service.ErrInvalidFolderName), errors.Is(err, service.ErrFolderCycle), errors.Is(err, service.ErrFolderOwner):
+		WriteError(w, 400, err.Error())
+	default:
+		writeAttachError(w, err)
+	}
+}
+
+// writePlaceError — как writeFolderError, но неизвестная папка в метаданных загрузки — 400
+func writePlaceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrFolderNotFound) {
+		WriteError(w, 400, err.Error())
+		return
+	}
+	writeFolderError(w, err)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"astra-api/internal/cache"
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestFoldersHandler_MoveDocuments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	folders := mocksgen.NewMockFolderServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(3)
+	folders.EXPECT().MoveDocuments("u1", []string{"d1", "d2"}, "f1").Return(nil)
+	folders.EXPECT().MoveDocuments("u1", []string{"d3"}, "f1").Return(fmt.Errorf("document d3: %w", service.ErrFolderOwner))
+
+	h := NewFoldersHandler(sess, folders, mocksgen.NewMockAccessServiceInterface(ctrl), cache.NewCache(0))
+
+	rr := httptest.NewRecorder()
+	h.MoveDocuments(rr, httptest.NewRequest(http.MethodPost, "/api/docs/move?token=t", strings.NewReader(`{"documents": ["d1", "d2"], "folder": "f1"}`)))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"moved":2`) {
+		t.Fatalf("expected code 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.MoveDocuments(rr, httptest.NewRequest(http.MethodPost, "/api/docs/move?token=t", strings.NewReader(`{"documents": ["d3"], "folder": "f1"}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.MoveDocuments(rr, httptest.NewRequest(http.MethodPost, "/api/docs/move?token=t", strings.NewReader(`{"folder": "f1"}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400 for empty list, got %d", rr.Code)
+	}
+}
+
+func TestFoldersHandler_SetGrant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	folders := mocksgen.NewMockFolderServiceInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+
+	folder := &model.Folder{ID: "f1", Owner: "u1"}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u2"}, true).Times(2)
+	folders.EXPECT().Lookup("u2", "f1").Return(folder, nil)
+	folders.EXPECT().Lookup("u2", "f9").Return(nil, service.ErrFolderNotFound)
+	access.EXPECT().SetFolderGrant("u2", folder, model.GrantRequest{Principal: "group:ops", Permission: model.PermissionManage}).Return(nil, service.ErrForbidden)
+
+	h := NewFoldersHandler(sess, folders, access, cache.NewCache(0))
+
+	rr := httptest.NewRecorder()
+	h.SetGrant(rr, httptest.NewRequest(http.MethodPut, "/api/folders/f1/grants?token=t", strings.NewReader(`{"principal": "group:ops", "permission": "manage"}`)))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected code 403, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.SetGrant(rr, httptest.NewRequest(http.MethodPut, "/api/folders/f9/grants?token=t", strings.NewReader(`{"principal": "group:ops", "permission": "read"}`)))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected code 404, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"astra-api/internal/cache"
+	"astra-api/internal/codec"
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"astra-api/internal/storage"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxFSJSONSize ограничивает размер JSON-содержимого в PUT /api/fs
+const maxFSJSONSize = 32 << 20
+
+type FSHandler struct {
+	sessionService service.SessionServiceInterface
+	fs             service.FSServiceInterface
+	blobs          storage.BlobStore
+	cache          *cache.Cache
+}
+
+func NewFSHandler(sessionService service.SessionServiceInterface, fs service.FSServiceInterface, blobs storage.BlobStore, cache *cache.Cache) *FSHandler {
+	return &FSHandler{sessionService: sessionService, fs: fs, blobs: blobs, cache: cache}
+}
+
+// @Summary Документ или каталог по пути
+// @Description Для документа — его содержимое, как GET /api/docs/{id} (включая format и Accept).
+// @Description Для папки или корня — листинг. Корень виден только владельцу, остальное — с уровнем read.
+// @Tags fs
+// @Produce json
+// @Param token query string true "Токен"
+// @Param login path string true "Владелец пространства"
+// @Param path path string false "Путь: имена папок и документа через /"
+// @Success 200 {object} model.APIResponse
+// @Router /api/fs/{login}/{path} [get]
+func (h *FSHandler) Get(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	login, path := parseFSPath(r.URL.Path)
+	if login == "" {
+		WriteError(w, 400, "missing login")
+		return
+	}
+	node, listing, err := h.fs.Get(sess.UserID, login, path)
+	if err != nil {
+		writeFSError(w, err)
+		return
+	}
+	if node.Document != nil {
+		writeDocument(w, r, h.blobs, node.Document)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: listing})
+}
+
+// @Summary Создать или заменить документ по пути
+// @Description Тело — содержимое. Content-Type application/json, application/yaml, text/csv,
+// @Description application/x-ndjson или application/msgpack создаёт JSON-документ, остальное — файл
+// @Description (file=true — всегда файл). Недостающие папки создаются. Замена не меняет вид документа (иначе 409).
+// @Description Тип файла проверяется по содержимому: несовместимый с ним или запрещённый Content-Type — 415.
+// @Tags fs
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param login path string true "Владелец пространства"
+// @Param path path string true "Путь документа"
+// @Param file query bool false "Сохранить тело как файл"
+// @Success 200 {object} model.APIResponse
+// @Router /api/fs/{login}/{path} [put]
+func (h *FSHandler) Put(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPut {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	login, path := parseFSPath(r.URL.Path)
+	if login == "" {
+		WriteError(w, 400, "missing login")
+		return
+	}
+	content, err := readFSContent(w, r)
+	if err != nil {
+		WriteError(w, 400, err.Error())
+		return
+	}
+	doc, created, err := h.fs.Put(sess.UserID, login, path, content, r.Body)
+	if err != nil {
+		writeFSError(w, err)
+		return
+	}
+	h.cache.Invalidate("doc:" + doc.ID)
+	h.cache.InvalidateAll()
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"id": doc.ID, "path": "/" + strings.Trim(path, "/"), "created": created}})
+}
+
+// @Summary Переименовать или перенести по пути
+// @Description В пределах пространства владельца; каталог назначения должен существовать.
+// @Description Переименование — уровень write, перенос в другой каталог — manage.
+// @Tags fs
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param login path string true "Владелец пространства"
+// @Param path path string true "Текущий путь"
+// @Param input body model.FSMoveRequest true "Новый путь"
+// @Success 200 {object} model.APIResponse
+// @Router /api/fs/{login}/{path} [patch]
+func (h *FSHandler) Move(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPatch {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	login, path := parseFSPath(r.URL.Path)
+	if login == "" {
+		WriteError(w, 400, "missing login")
+		return
+	}
+	var req model.FSMoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Path == "" {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	node, err := h.fs.Move(sess.UserID, login, path, req.Path)
+	if err != nil {
+		writeFSError(w, err)
+		return
+	}
+	if node.Document != nil {
+		h.cache.Invalidate("doc:" + node.Document.ID)
+	}
+	h.cache.InvalidateAll()
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"id": fsNodeID(node), "path": node.Path}})
+}
+
+// @Summary Удалить по пути
+// @Description Документ — уровень manage; папка — только пустая
+// @Tags fs
+// @Produce json
+// @Param token query string true "Токен"
+// @Param login path string true "Владелец пространства"
+// @Param path path string true "Путь"
+// @Success 200 {object} model.APIResponse
+// @Router /api/fs/{login}/{path} [delete]
+func (h *FSHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	login, path := parseFSPath(r.URL.Path)
+	if login == "" {
+		WriteError(w, 400, "missing login")
+		return
+	}
+	node, err := h.fs.Delete(sess.UserID, login, path)
+	if err != nil {
+		writeFSError(w, err)
+		return
+	}
+	if node.Document != nil {
+		h.cache.Invalidate("doc:" + node.Document.ID)
+	}
+	h.cache.InvalidateAll()
+	WriteResponse(w, &model.APIResponse{Response: map[string]bool{node.Path: true}})
+}
+
+// readFSContent определяет вид документа по Content-Type и читает JSON-содержимое;
+// тело файла читает сервис
+func readFSContent(w http.ResponseWriter, r *http.Request) (model.FSContent, error) {
+	contentType := r.Header.Get("Content-Type")
+	format, ok := codec.FromMime(contentType)
+	if !ok || r.URL.Query().Get("file") == "true" {
+		return model.FSContent{File: true, Mime: contentType}, nil
+	}
+	body := http.MaxBytesReader(w, r.Body, maxFSJSONSize)
+	if format == codec.FormatJSON {
+		data, err := io.ReadAll(body)
+		if err != nil || !json.Valid(data) {
+			return model.FSContent{}, errors.New("invalid json body")
+		}
+		return model.FSContent{Mime: "application/json", JSON: data}, nil
+	}
+	obj, err := codec.Decode(body, format)
+	if err != nil {
+		return model.FSContent{}, fmt.Errorf("invalid %s data: %v", format, err)
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return model.FSContent{}, fmt.Errorf("invalid %s data: %v", format, err)
+	}
+	return model.FSContent{Mime: "application/json", JSON: data}, nil
+}
+
+// parseFSPath разбирает /api/fs/{login}/{path...}
+func parseFSPath(path string) (login, rest string) {
+	login, rest, _ = strings.Cut(strings.TrimPrefix(path, "/api/fs/"), "/")
+	return login, rest
+}
+
+func fsNodeID(node *model.FSNode) string {
+	if node.Folder != nil {
+		return node.Folder.ID
+	}
+	return node.Document.ID
+}
+
+func writeFSError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrPathNotFound):
+		WriteError(w, 404, err.Error())
+	case errors.Is(err, service.ErrInvalidPath):
+		WriteError(w, 400, err.Error())
+	case errors.Is(err, service.ErrPathExists), errors.Is(err, service.ErrPathKind):
+		WriteError(w, 409, err.Error())
+	case errors.Is(err, service.ErrFileTooLarge):
+		WriteError(w, 413, err.Error())
+	case errors.Is(err, service.ErrStorageQuota), errors.Is(err, service.ErrDocumentQuota):
+		WriteError(w, 507, err.Error())
+	case errors.Is(err, service.ErrMimeMismatch), errors.Is(err, service.ErrMimeNotAllowed):
+		WriteError(w, 415, err.Error())
+	default:
+		writeFolderError(w, err)
+	}
+}
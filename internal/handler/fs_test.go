@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"astra-api/internal/cache"
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestFSHandler_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	fs := mocksgen.NewMockFSServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(3)
+	fs.EXPECT().Get("u1", "alice", "reports/q1.json").Return(&model.FSNode{Document: &model.Document{ID: "d1", JsonData: []byte(`{"total": 5}`)}}, nil, nil)
+	fs.EXPECT().Get("u1", "alice", "reports").Return(&model.FSNode{}, &model.FSListing{Path: "/reports", Entries: []model.FSEntry{{Name: "q1.json", Path: "/reports/q1.json", Type: model.FSEntryDocument}}}, nil)
+	fs.EXPECT().Get("u1", "alice", "missing").Return(nil, nil, service.ErrPathNotFound)
+
+	h := NewFSHandler(sess, fs, newTestBlobStore(t), cache.NewCache(0))
+
+	rr := httptest.NewRecorder()
+	h.Get(rr, httptest.NewRequest(http.MethodGet, "/api/fs/alice/reports/q1.json?token=t", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"total":5`) {
+		t.Fatalf("expected document content, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.Get(rr, httptest.NewRequest(http.MethodGet, "/api/fs/alice/reports?token=t", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"path":"/reports/q1.json"`) {
+		t.Fatalf("expected listing, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.Get(rr, httptest.NewRequest(http.MethodGet, "/api/fs/alice/missing?token=t", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected code 404, got %d", rr.Code)
+	}
+}
+
+// Замена содержимого по пути меняет Last-Modified: условный запрос не получает 304 со старым файлом
+func TestFSHandler_Get_ConditionalAfterOverwrite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	fs := mocksgen.NewMockFSServiceInterface(ctrl)
+	blobs := newTestBlobStore(t)
+	if _, err := blobs.Put("d1", strings.NewReader("new content")); err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	modified := created.Add(time.Hour)
+	doc := &model.Document{ID: "d1", Name: "notes.txt", File: true, Mime: "text/plain", CreatedAt: created, ModifiedAt: &modified}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	fs.EXPECT().Get("u1", "alice", "notes.txt").Return(&model.FSNode{Document: doc}, nil, nil)
+
+	h := NewFSHandler(sess, fs, blobs, cache.NewCache(0))
+	req := httptest.NewRequest(http.MethodGet, "/api/fs/alice/notes.txt?token=t", nil)
+	req.Header.Set("If-Modified-Since", created.Add(time.Minute).Format(http.TimeFormat))
+	rr := httptest.NewRecorder()
+	h.Get(rr, req)
+	if rr.Code != http.StatusOK || rr.Body.String() != "new content" {
+		t.Fatalf("expected new content, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("Last-Modified"); got != modified.Format(http.TimeFormat) {
+		t.Fatalf("expected Last-Modified of the overwrite, got %q", got)
+	}
+}
+
+func TestFSHandler_Get_ETagFollowsBlobKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	fs := mocksgen.NewMockFSServiceInterface(ctrl)
+	blobs := newTestBlobStore(t)
+	if _, err := blobs.Put("k2", strings.NewReader("new content")); err != nil {
+		t.Fatal(err)
+	}
+	key := "k2"
+	doc := &model.Document{ID: "d1", Name: "notes.txt", File: true, Mime: "text/plain", ScanStatus: model.ScanClean, BlobKey: &key}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	fs.EXPECT().Get("u1", "alice", "notes.txt").Return(&model.FSNode{Document: doc}, nil, nil).Times(2)
+	h := NewFSHandler(sess, fs, blobs, cache.NewCache(0))
+
+	// Тег прежнего содержимого больше не совпадает
+	req := httptest.NewRequest(http.MethodGet, "/api/fs/alice/notes.txt?token=t", nil)
+	req.Header.Set("If-None-Match", `"d1"`)
+	rr := httptest.NewRecorder()
+	h.Get(rr, req)
+	if rr.Code != http.StatusOK || rr.Body.String() != "new content" || rr.Header().Get("ETag") != `"k2"` {
+		t.Fatalf("expected new content with ETag \"k2\", got %d %q: %s", rr.Code, rr.Header().Get("ETag"), rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/fs/alice/notes.txt?token=t", nil)
+	req.Header.Set("If-None-Match", `"k2"`)
+	rr = httptest.NewRecorder()
+	h.Get(rr, req)
+	if rr.Code != http.StatusNotModified {
+		t.Fatalf("expected code 304, got %d", rr.Code)
+	}
+}
+
+func TestFSHandler_Put(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	fs := mocksgen.NewMockFSServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(3)
+	fs.EXPECT().Put("u1", "alice", "reports/q1.json", model.FSContent{Mime: "application/json", JSON: []byte(`{"total":5}`)}, gomock.Any()).
+		Return(&model.Document{ID: "d1"}, true, nil)
+	fs.EXPECT().Put("u1", "alice", "reports/logo.png", model.FSContent{File: true, Mime: "image/png"}, gomock.Any()).
+		Return(nil, false, service.ErrPathKind)
+
+	h := NewFSHandler(sess, fs, newTestBlobStore(t), cache.NewCache(0))
+
+	req := httptest.NewRequest(http.MethodPut, "/api/fs/alice/reports/q1.json?token=t", strings.NewReader("total: 5\n"))
+	req.Header.Set("Content-Type", "application/yaml")
+	rr := httptest.NewRecorder()
+	h.Put(rr, req)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"created":true`) {
+		t.Fatalf("expected created document, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/api/fs/alice/reports/logo.png?token=t", strings.NewReader("png"))
+	req.Header.Set("Content-Type", "image/png")
+	rr = httptest.NewRecorder()
+	h.Put(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected code 409, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/api/fs/alice/reports/bad.json?token=t", strings.NewReader("{"))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	h.Put(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type GroupsHandler struct {
+	sessionService service.SessionServiceInterface
+	groupService   service.GroupServiceInterface
+}
+
+func NewGroupsHandler(sessionService service.SessionServiceInterface, groupService service.GroupServiceInterface) *GroupsHandler {
+	return &GroupsHandler{sessionService: sessionService, groupService: groupService}
+}
+
+// @Summary Создать группу
+// @Description Создатель становится владельцем группы
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param input body model.CreateGroupRequest true "Название"
+// @Success 200 {object} model.APIResponse
+// @Router /api/groups [post]
+func (h *GroupsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPost {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	var req model.CreateGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	group, err := h.groupService.Create(sess.UserID, req.Name)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: group})
+}
+
+// @Summary Мои группы
+// @Tags groups
+// @Produce json
+// @Param token query string true "Токен"
+// @Success 200 {object} model.APIResponse
+// @Router /api/groups [get]
+func (h *GroupsHandler) List(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	groups, err := h.groupService.ListByUser(sess.UserID)
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"groups": groups}})
+}
+
+// @Summary Группа и её участники
+// @Tags groups
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID группы"
+// @Success 200 {object} model.APIResponse
+// @Router /api/groups/{id} [get]
+func (h *GroupsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	groupID, _ := parseGroupPath(r.URL.Path)
+	if groupID == "" {
+		WriteError(w, 400, "missing group id")
+		return
+	}
+	group, members, err := h.groupService.Get(sess.UserID, groupID)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"group": group, "members": members}})
+}
+
+// @Summary Удалить группу
+// @Tags groups
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID группы"
+// @Success 200 {object} model.APIResponse
+// @Router /api/groups/{id} [delete]
+func (h *GroupsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	groupID, _ := parseGroupPath(r.URL.Path)
+	if groupID == "" {
+		WriteError(w, 400, "missing group id")
+		return
+	}
+	if err := h.groupService.Delete(sess.UserID, groupID); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Response: map[string]bool{groupID: true}})
+}
+
+// @Summary Добавить участника или сменить роль
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID группы"
+// @Param login path string true "Логин"
+// @Param input body model.GroupMemberRequest false "Роль: member (по умолчанию) или owner"
+// @Success 200 {object} model.APIResponse
+// @Router /api/groups/{id}/members/{login} [put]
+func (h *GroupsHandler) SetMember(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPut {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	groupID, login := parseGroupPath(r.URL.Path)
+	if groupID == "" || login == "" {
+		WriteError(w, 400, "missing group id or login")
+		return
+	}
+	var req model.GroupMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	if err := h.groupService.SetMember(sess.UserID, groupID, login, req.Role); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Response: map[string]bool{login: true}})
+}
+
+// @Summary Исключить участника
+// @Description Владелец может исключить любого, участник — только выйти сам
+// @Tags groups
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID группы"
+// @Param login path string true "Логин"
+// @Success 200 {object} model.APIResponse
+// @Router /api/groups/{id}/members/{login} [delete]
+func (h *GroupsHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	groupID, login := parseGroupPath(r.URL.Path)
+	if groupID == "" || login == "" {
+		WriteError(w, 400, "missing group id or login")
+		return
+	}
+	if err := h.groupService.RemoveMember(sess.UserID, groupID, login); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Response: map[string]bool{login: true}})
+}
+
+// parseGroupPath разбирает /api/groups/{id} и /api/groups/{id}/members/{login}
+func parseGroupPath(path string) (groupID, login string) {
+	parts := strings.Split(strings.TrimPrefix(path, "/api/groups/"), "/")
+	groupID = parts[0]
+	if len(parts) == 3 && parts[1] == "members" {
+		login = parts[2]
+	}
+	return groupID, login
+}
+
+func writeGroupError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrGroupNotFound), errors.Is(err, service.ErrUserNotFound):
+		WriteError(w, 404, err.Error())
+	case errors.Is(err, service.ErrNotGroupOwner):
+		WriteError(w, 403, err.Error())
+	case errors.Is(err, service.ErrGroupExists), errors.Is(err, service.ErrLastGroupOwner):
+		WriteError(w, 409, err.Error())
+	case errors.Is(err, service.ErrInvalidGroupName), errors.Is(err, service.ErrInvalidGroupRole):
+		WriteError(w, 400, err.Error())
+	default:
+		WriteError(w, 500, err.Error())
+	}
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestGroupsHandler_Create_OK(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	groups := mocksgen.NewMockGroupServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	groups.EXPECT().Create("u1", "accounting").Return(&model.Group{ID: "g1", Name: "accounting"}, nil)
+
+	h := NewGroupsHandler(sess, groups)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/groups?token=t", strings.NewReader(`{"name":"accounting"}`))
+
+	h.Create(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+}
+
+func TestGroupsHandler_SetMember_NotOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	groups := mocksgen.NewMockGroupServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u2"}, true)
+	groups.EXPECT().SetMember("u2", "g1", "testuser", "owner").Return(service.ErrNotGroupOwner)
+
+	h := NewGroupsHandler(sess, groups)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/groups/g1/members/testuser?token=t", strings.NewReader(`{"role":"owner"}`))
+
+	h.SetMember(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected code 403, got %d", rr.Code)
+	}
+}
+
+func TestGroupsHandler_RemoveMember_LastOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	groups := mocksgen.NewMockGroupServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	groups.EXPECT().RemoveMember("u1", "g1", "testuser").Return(service.ErrLastGroupOwner)
+
+	h := NewGroupsHandler(sess, groups)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/groups/g1/members/testuser?token=t", nil)
+
+	h.RemoveMember(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected code 409, got %d", rr.Code)
+	}
+}
+
+func TestGroupsHandler_Get_NotMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	groups := mocksgen.NewMockGroupServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u3"}, true)
+	groups.EXPECT().Get("u3", "g1").Return(nil, nil, service.ErrGroupNotFound)
+
+	h := NewGroupsHandler(sess, groups)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/groups/g1?token=t", nil)
+
+	h.Get(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected code 404, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"astra-api/internal/storage"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ShareHandler управляет ссылками на документы и раздаёт документы по ним без сессии
+type ShareHandler struct {
+	sessionService service.SessionServiceInterface
+	docsService    service.DocsServiceInterface
+	access         service.AccessServiceInterface
+	links          service.LinkServiceInterface
+	blobs          storage.BlobStore
+}
+
+func NewShareHandler(sessionService service.SessionServiceInterface, docsService service.DocsServiceInterface, access service.AccessServiceInterface, links service.LinkServiceInterface, blobs storage.BlobStore) *ShareHandler {
+	return &ShareHandler{sessionService: sessionService, docsService: docsService, access: access, links: links, blobs: blobs}
+}
+
+// @Summary Создать ссылку на документ
+// @Description Нужен уровень share. Токен ссылки возвращается только в этом ответе.
+// @Description Без expires_at ссылка живёт SHARE_LINK_TTL. Срок урезается до SHARE_LINK_MAX_TTL и до срока гранта создателя.
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID"
+// @Param input body model.CreateLinkRequest false "Параметры ссылки"
+// @Success 200 {object} model.APIResponse
+// @Router /api/docs/{id}/links [post]
+func (h *ShareHandler) Create(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPost {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	var req model.CreateLinkRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteError(w, 400, "invalid request body")
+			return
+		}
+	}
+	doc, ok := h.loadDocument(w, r, sess)
+	if !ok {
+		return
+	}
+	link, token, err := h.links.Create(sess.UserID, doc, req)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: model.ShareLinkView{
+		ShareLink:         *link,
+		PasswordProtected: link.Protected(),
+		Token:             token,
+		URL:               "/s/" + token,
+	}})
+}
+
+// @Summary Ссылки на документ
+// @Description Нужен уровень share. Токены не возвращаются.
+// @Tags links
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID"
+// @Success 200 {object} model.APIResponse
+// @Router /api/docs/{id}/links [get]
+func (h *ShareHandler) List(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	doc, ok := h.loadDocument(w, r, sess)
+	if !ok {
+		return
+	}
+	links, err := h.links.List(sess.UserID, doc)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+	views := make([]model.ShareLinkView, 0, len(links))
+	for _, link := range links {
+		views = append(views, model.ShareLinkView{ShareLink: link, PasswordProtected: link.Protected()})
+	}
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"links": views}})
+}
+
+// @Summary Отозвать ссылку
+// @Description Нужен уровень share
+// @Tags links
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID"
+// @Param linkID path string true "ID ссылки"
+// @Success 200 {object} model.APIResponse
+// @Router /api/docs/{id}/links/{linkID} [delete]
+func (h *ShareHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	linkID := getLinkIDFromURL(r.URL.Path)
+	if linkID == "" {
+		WriteError(w, 400, "missing link id")
+		return
+	}
+	doc, ok := h.loadDocument(w, r, sess)
+	if !ok {
+		return
+	}
+	if err := h.links.Revoke(sess.UserID, doc, linkID); err != nil {
+		writeLinkError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Response: map[string]bool{linkID: true}})
+}
+
+// @Summary Скачать документ по ссылке
+// @Description Не требует сессии. Пароль передаётся заголовком X-Share-Password или полем password формы (POST).
+// @Description Каждый GET/POST учитывается в счётчике скачиваний, HEAD — нет.
+// @Tags links
+// @Produce octet-stream
+// @Param token path string true "Токен ссылки"
+// @Param X-Share-Password header string false "Пароль ссылки"
+// @Success 200 {file} file
+// @Failure 401 {object} model.APIResponse
+// @Failure 404 {object} model.APIResponse
+// @Failure 410 {object} model.APIResponse
+// @Router /s/{token} [get]
+func (h *ShareHandler) Open(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodPost {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	token := strings.TrimPrefix(r.URL.Path, "/s/")
+	if token == "" || strings.Contains(token, "/") {
+		WriteError(w, 404, "link not found")
+		return
+	}
+	password := r.Header.Get("X-Share-Password")
+	if password == "" && r.Method == http.MethodPost {
+		password = r.PostFormValue("password")
+	}
+	link, doc, err := h.links.Resolve(token, password)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+	if r.Method != http.MethodHead {
+		if err := h.links.CountDownload(link); err != nil {
+			writeLinkError(w, err)
+			return
+		}
+	}
+	// Ссылка может попасть в чужие логи и кэши, ответ по ней не кэшируем
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	writeDocument(w, r, h.blobs, doc)
+}
+
+func (h *ShareHandler) loadDocument(w http.ResponseWriter, r *http.Request, sess model.Session) (*model.Document, bool) {
+	id := getIDFromURL(r.URL.Path)
+	if id == "" {
+		WriteError(w, 400, "missing document id")
+		return nil, false
+	}
+	doc, err := h.docsService.GetByID(id)
+	if err != nil {
+		WriteError(w, 404, "document not found")
+		return nil, false
+	}
+	if !authorizeDocument(w, h.access, sess, doc, model.PermissionRead) {
+		return nil, false
+	}
+	return doc, true
+}
+
+func writeLinkError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidLink):
+		WriteError(w, 404, "link not found")
+	case errors.Is(err, service.ErrLinkExpired), errors.Is(err, service.ErrLinkExhausted):
+		WriteError(w, 410, err.Error())
+	case errors.Is(err, service.ErrLinkPassword):
+		WriteError(w, 401, err.Error())
+	case errors.Is(err, service.ErrInvalidLinkSpec):
+		WriteError(w, 400, err.Error())
+	default:
+		writeAccessError(w, err)
+	}
+}
+
+// getLinkIDFromURL достаёт linkID из /api/docs/{id}/links/{linkID}
+func getLinkIDFromURL(path string) string {
+	parts := strings.Split(path, "/")
+	if len(parts) < 6 || parts[4] != "links" {
+		return ""
+	}
+	return parts[5]
+}
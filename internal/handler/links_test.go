@@ -0,0 +1,117 @@
+package handler
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestShareHandler_Create_OK(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	links := mocksgen.NewMockLinkServiceInterface(ctrl)
+
+	doc := &model.Document{ID: "d1", Owner: "u1"}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	docs.EXPECT().GetByID("d1").Return(doc, nil)
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionManage, nil)
+	links.EXPECT().Create("u1", doc, gomock.Any()).Return(&model.ShareLink{ID: "l1", DocumentID: "d1"}, "abc.def", nil)
+
+	h := NewShareHandler(sess, docs, access, links, nil)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/docs/d1/links?token=t", strings.NewReader(`{"max_downloads":3}`))
+
+	h.Create(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"url":"/s/abc.def"`) {
+		t.Fatalf("expected link url in response, got %s", rr.Body.String())
+	}
+}
+
+func TestShareHandler_Revoke_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	links := mocksgen.NewMockLinkServiceInterface(ctrl)
+
+	doc := &model.Document{ID: "d1", Owner: "u1"}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	docs.EXPECT().GetByID("d1").Return(doc, nil)
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionManage, nil)
+	links.EXPECT().Revoke("u1", doc, "l9").Return(service.ErrInvalidLink)
+
+	h := NewShareHandler(sess, docs, access, links, nil)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/docs/d1/links/l9?token=t", nil)
+
+	h.Revoke(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected code 404, got %d", rr.Code)
+	}
+}
+
+func TestShareHandler_Open_CountsDownload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	links := mocksgen.NewMockLinkServiceInterface(ctrl)
+
+	link := &model.ShareLink{ID: "l1", DocumentID: "d1"}
+	links.EXPECT().Resolve("abc.def", "pw").Return(link, &model.Document{ID: "d1", JsonData: []byte(`{"a":1}`)}, nil)
+	links.EXPECT().CountDownload(link).Return(nil)
+
+	h := NewShareHandler(nil, docs, nil, links, nil)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/s/abc.def", nil)
+	req.Header.Set("X-Share-Password", "pw")
+
+	h.Open(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+	if rr.Header().Get("Cache-Control") != "no-store" {
+		t.Fatal("expected shared response not to be cached")
+	}
+}
+
+func TestShareHandler_Open_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	links := mocksgen.NewMockLinkServiceInterface(ctrl)
+	h := NewShareHandler(nil, nil, nil, links, nil)
+
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{service.ErrInvalidLink, http.StatusNotFound},
+		{service.ErrLinkExpired, http.StatusGone},
+		{service.ErrLinkExhausted, http.StatusGone},
+		{service.ErrLinkPassword, http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		links.EXPECT().Resolve("tok", "").Return(nil, nil, tc.err)
+		rr := httptest.NewRecorder()
+		h.Open(rr, httptest.NewRequest(http.MethodGet, "/s/tok", nil))
+		if rr.Code != tc.code {
+			t.Fatalf("%v: expected code %d, got %d", tc.err, tc.code, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+type MeHandler struct {
+	sessionService service.SessionServiceInterface
+	accountService service.AccountServiceInterface
+	quota          service.QuotaServiceInterface
+}
+
+func NewMeHandler(sessionService service.SessionServiceInterface, accountService service.AccountServiceInterface, quota service.QuotaServiceInterface) *MeHandler {
+	return &MeHandler{sessionService: sessionService, accountService: accountService, quota: quota}
+}
+
+// @Summary Использование места
+// @Description Занятые байты и число документов (включая корзину) и действующие лимиты; 0 — без ограничения.
+// @Description override — личные лимиты, заданные администратором.
+// @Tags me
+// @Produce json
+// @Param token query string true "Токен"
+// @Success 200 {object} model.APIResponse
+// @Router /api/me/usage [get]
+func (h *MeHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	report, err := h.quota.Usage(sess.UserID)
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: report})
+}
+
+// @Summary Выгрузка данных пользователя
+// @Description ZIP-архив: profile.json, documents.json, json/<id>.json и files/<id>/<name>
+// @Tags me
+// @Produce application/zip
+// @Param token query string true "Токен"
+// @Success 200 {file} file
+// @Router /api/me/export [get]
+func (h *MeHandler) Export(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+sess.Login+`-export.zip"`)
+	// Архив пишется прямо в ответ: после первого байта статус уже не поменять
+	if err := h.accountService.Export(sess.UserID, w); err != nil {
+		log.Printf("export of user %s failed: %v", sess.UserID, err)
+	}
+}
+
+// @Summary Удаление аккаунта
+// @Description Требует пароль. Аккаунт, документы и файлы удаляются после льготного периода; вход до его окончания отменяет удаление.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param input body model.DeleteAccountRequest true "Пароль"
+// @Success 200 {object} model.APIResponse
+// @Router /api/me [delete]
+func (h *MeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	if sess.Impersonated() {
+		WriteError(w, 403, "not allowed while impersonating")
+		return
+	}
+	var req model.DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Pswd == "" {
+		WriteError(w, 400, "password required")
+		return
+	}
+	at, err := h.accountService.RequestDeletion(sess.UserID, req.Pswd)
+	if errors.Is(err, service.ErrInvalidPassword) {
+		WriteError(w, 403, "invalid password")
+		return
+	}
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Response: map[string]interface{}{"delete_after": at}})
+}
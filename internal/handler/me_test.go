@@ -0,0 +1,124 @@
+package handler
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestMeHandler_Export_OK(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	accounts := mocksgen.NewMockAccountServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1", Login: "testuser"}, true)
+	accounts.EXPECT().Export("u1", gomock.Any()).DoAndReturn(func(userID string, w io.Writer) error {
+		_, err := w.Write([]byte("PK"))
+		return err
+	})
+
+	h := NewMeHandler(sess, accounts, nil)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/me/export?token=t", nil)
+
+	h.Export(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Fatalf("expected application/zip, got %s", ct)
+	}
+}
+
+func TestMeHandler_Delete_OK(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	accounts := mocksgen.NewMockAccountServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	accounts.EXPECT().RequestDeletion("u1", "Password123!").Return(time.Now().Add(time.Hour), nil)
+
+	h := NewMeHandler(sess, accounts, nil)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/me?token=t", strings.NewReader(`{"pswd":"Password123!"}`))
+
+	h.Delete(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "delete_after") {
+		t.Fatalf("expected delete_after in response, got %s", rr.Body.String())
+	}
+}
+
+func TestMeHandler_Delete_WrongPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	accounts := mocksgen.NewMockAccountServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	accounts.EXPECT().RequestDeletion("u1", "nope").Return(time.Time{}, service.ErrInvalidPassword)
+
+	h := NewMeHandler(sess, accounts, nil)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/me?token=t", strings.NewReader(`{"pswd":"nope"}`))
+
+	h.Delete(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected code 403, got %d", rr.Code)
+	}
+}
+
+func TestMeHandler_Delete_Impersonated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	accounts := mocksgen.NewMockAccountServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1", ImpersonatorID: "a1"}, true)
+
+	h := NewMeHandler(sess, accounts, nil)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/me?token=t", strings.NewReader(`{"pswd":"x"}`))
+
+	h.Delete(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected code 403, got %d", rr.Code)
+	}
+}
+
+func TestMeHandler_Usage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	quota := mocksgen.NewMockQuotaServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	quota.EXPECT().Usage("u1").Return(&model.UsageReport{
+		Usage:  model.Usage{Bytes: 2048, Documents: 3},
+		Limits: model.QuotaLimits{MaxBytes: 4096, MaxDocuments: 10, MaxFileSize: 1024},
+	}, nil)
+
+	h := NewMeHandler(sess, nil, quota)
+	rr := httptest.NewRecorder()
+	h.Usage(rr, httptest.NewRequest(http.MethodGet, "/api/me/usage?token=t", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"bytes":2048`) || !strings.Contains(rr.Body.String(), `"max_bytes":4096`) {
+		t.Fatalf("unexpected response %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"astra-api/internal/jsonschema"
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxSchemaSize ограничивает размер тела PUT /api/schemas/{name}
+const maxSchemaSize = 1 << 20
+
+type SchemasHandler struct {
+	sessionService service.SessionServiceInterface
+	schemas        service.SchemaServiceInterface
+}
+
+func NewSchemasHandler(sessionService service.SessionServiceInterface, schemas service.SchemaServiceInterface) *SchemasHandler {
+	return &SchemasHandler{sessionService: sessionService, schemas: schemas}
+}
+
+// @Summary Мои JSON Schema
+// @Description Без текста схем, с числом привязанных документов
+// @Tags schemas
+// @Produce json
+// @Param token query string true "Токен"
+// @Success 200 {object} model.APIResponse
+// @Router /api/schemas [get]
+func (h *SchemasHandler) List(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	schemas, err := h.schemas.List(sess.UserID)
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"schemas": schemas}})
+}
+
+// @Summary JSON Schema по имени
+// @Tags schemas
+// @Produce json
+// @Param token query string true "Токен"
+// @Param name path string true "Имя схемы"
+// @Success 200 {object} model.APIResponse
+// @Router /api/schemas/{name} [get]
+func (h *SchemasHandler) Get(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	name := getSchemaNameFromURL(r.URL.Path)
+	if name == "" {
+		WriteError(w, 400, "missing schema name")
+		return
+	}
+	schema, err := h.schemas.Get(sess.UserID, name)
+	if err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: schema})
+}
+
+// @Summary Создать или заменить JSON Schema
+// @Description Тело — сама схема (draft 2020-12). Замена отклоняется с 409, если привязанные
+// @Description документы ей не соответствуют; error.details — документы и нарушения.
+// @Tags schemas
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param name path string true "Имя схемы"
+// @Param input body object true "JSON Schema"
+// @Success 200 {object} model.APIResponse
+// @Router /api/schemas/{name} [put]
+func (h *SchemasHandler) Put(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPut {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	name := getSchemaNameFromURL(r.URL.Path)
+	if name == "" {
+		WriteError(w, 400, "missing schema name")
+		return
+	}
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSchemaSize))
+	if err != nil {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	schema, _, err := h.schemas.Put(sess.UserID, name, raw)
+	if err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: schema})
+}
+
+// @Summary Удалить JSON Schema
+// @Description Схему с привязанными документами удалить нельзя (409)
+// @Tags schemas
+// @Produce json
+// @Param token query string true "Токен"
+// @Param name path string true "Имя схемы"
+// @Success 200 {object} model.APIResponse
+// @Router /api/schemas/{name} [delete]
+func (h *SchemasHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	name := getSchemaNameFromURL(r.URL.Path)
+	if name == "" {
+		WriteError(w, 400, "missing schema name")
+		return
+	}
+	if err := h.schemas.Delete(sess.UserID, name); err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Response: map[string]bool{name: true}})
+}
+
+// @Summary Привязать JSON Schema к тегу
+// @Description Схема проверяет все свои JSON-документы с тегом: при привязке (409 с error.details, если
+// @Description какие-то не подходят), при создании и изменении документа, при замене схемы.
+// @Tags schemas
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param tag path string true "Тег"
+// @Param input body model.TagSchemaRequest true "Имя схемы"
+// @Success 200 {object} model.APIResponse
+// @Router /api/tags/{tag}/schema [put]
+func (h *SchemasHandler) AttachTag(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPut {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	tag, ok := tagFromSchemaURL(w, r.URL.Path)
+	if !ok {
+		return
+	}
+	var req model.TagSchemaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Schema == "" {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	if err := h.schemas.AttachTag(sess.UserID, tag, req.Schema); err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: map[string]string{"tag": tag, "schema": req.Schema}})
+}
+
+// @Summary Отвязать JSON Schema от тега
+// @Tags schemas
+// @Produce json
+// @Param token query string true "Токен"
+// @Param tag path string true "Тег"
+// @Success 200 {object} model.APIResponse
+// @Router /api/tags/{tag}/schema [delete]
+func (h *SchemasHandler) DetachTag(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	tag, ok := tagFromSchemaURL(w, r.URL.Path)
+	if !ok {
+		return
+	}
+	if err := h.schemas.AttachTag(sess.UserID, tag, ""); err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Response: map[string]bool{tag: true}})
+}
+
+// tagFromSchemaURL достаёт тег из /api/tags/{tag}/schema (тег может содержать "/")
+// и приводит его к виду, в котором он хранится у документов; false — ответ уже записан
+func tagFromSchemaURL(w http.ResponseWriter, path string) (string, bool) {
+	rest, _ := strings.CutPrefix(path, "/api/tags/")
+	tag, ok := strings.CutSuffix(rest, "/schema")
+	if !ok {
+		WriteError(w, 404, "not found")
+		return "", false
+	}
+	tags, err := model.NormalizeTags([]string{tag})
+	if err != nil {
+		WriteError(w, 400, err.Error())
+		return "", false
+	}
+	return tags[0], true
+}
+
+func getSchemaNameFromURL(path string) string {
+	name := strings.TrimPrefix(path, "/api/schemas/")
+	if strings.Contains(name, "/") {
+		return ""
+	}
+	return name
+}
+
+// writeSchemaError отвечает на ошибки схем и проверки документов; нарушения
+// передаются в error.details
+func writeSchemaError(w http.ResponseWriter, err error) {
+	var verr *jsonschema.ValidationError
+	var incompatible *service.IncompatibleSchemaError
+	switch {
+	case errors.As(err, &verr):
+		WriteErrorDetails(w, 400, err.Error(), verr.Violations)
+	case errors.As(err, &incompatible):
+		WriteErrorDetails(w, 409, err.Error(), incompatible.Conflicts)
+	case errors.Is(err, service.ErrSchemaNotFound):
+		WriteError(w, 404, err.Error())
+	case errors.Is(err, service.ErrSchemaInUse):
+		WriteError(w, 409, err.Error())
+	case errors.Is(err, jsonschema.ErrInvalidSchema), errors.Is(err, service.ErrInvalidSchemaName), errors.Is(err, service.ErrSchemaFileDocument):
+		WriteError(w, 400, err.Error())
+	default:
+		WriteError(w, 500, err.Error())
+	}
+}
+
+// writeAttachError — как writeSchemaError, но неизвестная схема в запросе на документ — 400
+func writeAttachError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrSchemaNotFound) {
+		WriteError(w, 400, err.Error())
+		return
+	}
+	writeSchemaError(w, err)
+}
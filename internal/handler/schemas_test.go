@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"astra-api/internal/cache"
+	"astra-api/internal/jsonschema"
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestSchemasHandler_Put(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	schemas := mocksgen.NewMockSchemaServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	schemas.EXPECT().Put("u1", "service-config", []byte(`{"type": "object"}`)).Return(&model.JSONSchema{ID: "s1", Name: "service-config"}, true, nil)
+	schemas.EXPECT().Put("u1", "service-config", []byte(`{"required": ["replicas"]}`)).Return(nil, false, &service.IncompatibleSchemaError{
+		Conflicts: []model.SchemaConflict{{DocumentID: "d2", Name: "broken.json", Violations: []jsonschema.Violation{{Keyword: "#/required", Message: `missing required property "replicas"`}}}},
+	})
+
+	h := NewSchemasHandler(sess, schemas)
+
+	rr := httptest.NewRecorder()
+	h.Put(rr, httptest.NewRequest(http.MethodPut, "/api/schemas/service-config?token=t", strings.NewReader(`{"type": "object"}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.Put(rr, httptest.NewRequest(http.MethodPut, "/api/schemas/service-config?token=t", strings.NewReader(`{"required": ["replicas"]}`)))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected code 409, got %d", rr.Code)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `"details":[{"document_id":"d2"`) {
+		t.Fatalf("expected conflicting documents in details, got %s", body)
+	}
+}
+
+func TestSchemasHandler_TagSchema(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	schemas := mocksgen.NewMockSchemaServiceInterface(ctrl)
+	h := NewSchemasHandler(sess, schemas)
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).AnyTimes()
+
+	schemas.EXPECT().AttachTag("u1", "team/prod", "service-config").Return(nil)
+	rr := httptest.NewRecorder()
+	h.AttachTag(rr, httptest.NewRequest(http.MethodPut, "/api/tags/Team/Prod/schema?token=t", strings.NewReader(`{"schema":"service-config"}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	schemas.EXPECT().AttachTag("u1", "prod", "service-config").Return(&service.IncompatibleSchemaError{
+		Conflicts: []model.SchemaConflict{{DocumentID: "d2", Name: "broken.json"}},
+	})
+	rr = httptest.NewRecorder()
+	h.AttachTag(rr, httptest.NewRequest(http.MethodPut, "/api/tags/prod/schema?token=t", strings.NewReader(`{"schema":"service-config"}`)))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected code 409, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.AttachTag(rr, httptest.NewRequest(http.MethodPut, "/api/tags/prod/schema?token=t", strings.NewReader(`{}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400 without schema, got %d", rr.Code)
+	}
+
+	schemas.EXPECT().AttachTag("u1", "prod", "").Return(nil)
+	rr = httptest.NewRecorder()
+	h.DetachTag(rr, httptest.NewRequest(http.MethodDelete, "/api/tags/prod/schema?token=t", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+}
+
+func TestDocsHandler_Upload_SchemaViolation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	schemas := mocksgen.NewMockSchemaServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	schemas.EXPECT().Attach(gomock.Any(), "service-config").Return(&jsonschema.ValidationError{
+		Violations: []jsonschema.Violation{{Path: "/replicas", Keyword: "#/properties/replicas/minimum", Message: "must be >= 1"}},
+	})
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, mocksgen.NewMockUserRepositoryInterface(ctrl), newTestBlobStore(t), mocksgen.NewMockAccessServiceInterface(ctrl), schemas, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("meta", `{"name":"config.json","file":false,"schema":"service-config"}`)
+	_ = mw.WriteField("json", `{"replicas":0}`)
+	_ = mw.WriteField("token", "t")
+	_ = mw.Close()
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/docs", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	h.Upload(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400, got %d", rr.Code)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `"path":"/replicas"`) {
+		t.Fatalf("expected violation path in details, got %s", body)
+	}
+}
+
+func TestDocsHandler_Update_SchemaViolation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docs := mocksgen.NewMockDocsServiceInterface(ctrl)
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	schemas := mocksgen.NewMockSchemaServiceInterface(ctrl)
+
+	schemaID := "s1"
+	doc := &model.Document{ID: "doc123", Owner: "u2", SchemaID: &schemaID, JsonData: []byte(`{"replicas": 2}`)}
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	docs.EXPECT().GetByID("doc123").Return(doc, nil)
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionWrite, nil)
+	schemas.EXPECT().Validate(doc).Return(&jsonschema.ValidationError{
+		Violations: []jsonschema.Violation{{Keyword: "#/required", Message: `missing required property "replicas"`}},
+	})
+
+	h := NewDocsHandler(docs, cache.NewCache(0), sess, mocksgen.NewMockUserRepositoryInterface(ctrl), newTestBlobStore(t), access, schemas, nil, newTestQuota(ctrl), newTestMime(), newTestScans(ctrl), newTestCompression())
+
+	rr := httptest.NewRecorder()
+	h.Update(rr, httptest.NewRequest(http.MethodPatch, "/api/docs/doc123?token=t", strings.NewReader(`{"json": {}}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"astra-api/internal/cache"
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type TrashHandler struct {
+	sessionService service.SessionServiceInterface
+	trash          service.TrashServiceInterface
+	cache          *cache.Cache
+}
+
+func NewTrashHandler(sessionService service.SessionServiceInterface, trash service.TrashServiceInterface, cache *cache.Cache) *TrashHandler {
+	return &TrashHandler{sessionService: sessionService, trash: trash, cache: cache}
+}
+
+// @Summary Корзина
+// @Description Свои удалённые документы, недавно удалённые первыми. purge_at — когда документ удалится окончательно.
+// @Tags trash
+// @Produce json
+// @Param token query string true "Токен"
+// @Param limit query int false "Лимит (по умолчанию 20)"
+// @Success 200 {object} model.APIResponse
+// @Router /api/trash [get]
+func (h *TrashHandler) List(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	limit := 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	docs, err := h.trash.List(sess.UserID, limit)
+	if err != nil {
+		WriteError(w, 500, err.Error())
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{"docs": docs}})
+}
+
+// @Summary Восстановить документ из корзины
+// @Description Нужен уровень manage. Документ возвращается на прежнее место (в корень, если папку удалили)
+// @Description и снова проверяется схемами; если путь занят — 409.
+// @Tags trash
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID документа"
+// @Success 200 {object} model.APIResponse
+// @Router /api/trash/{id}/restore [post]
+func (h *TrashHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPost {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	id, _ := parseTrashPath(r.URL.Path)
+	if id == "" {
+		WriteError(w, 400, "missing id")
+		return
+	}
+	doc, err := h.trash.Restore(sess.UserID, id)
+	if err != nil {
+		writeTrashError(w, err)
+		return
+	}
+	h.cache.Invalidate("doc:" + doc.ID)
+	h.cache.InvalidateAll()
+	WriteResponse(w, &model.APIResponse{Data: doc})
+}
+
+// @Summary Удалить документ окончательно
+// @Description Нужен уровень manage; файл документа удаляется вместе с ним
+// @Tags trash
+// @Produce json
+// @Param token query string true "Токен"
+// @Param id path string true "ID документа"
+// @Success 200 {object} model.APIResponse
+// @Router /api/trash/{id} [delete]
+func (h *TrashHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	id, action := parseTrashPath(r.URL.Path)
+	if id == "" {
+		WriteError(w, 400, "missing id")
+		return
+	}
+	if action != "" {
+		WriteError(w, 404, "not found")
+		return
+	}
+	if _, err := h.trash.Purge(sess.UserID, id); err != nil {
+		writeTrashError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Response: map[string]bool{id: true}})
+}
+
+// parseTrashPath разбирает /api/trash/{id}[/action]
+func parseTrashPath(path string) (id, action string) {
+	id, action, _ = strings.Cut(strings.TrimPrefix(path, "/api/trash/"), "/")
+	return id, action
+}
+
+func writeTrashError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrDocumentNotFound):
+		WriteError(w, 404, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		WriteError(w, 403, err.Error())
+	case errors.Is(err, service.ErrDocumentExists):
+		WriteError(w, 409, err.Error())
+	default:
+		writeSchemaError(w, err)
+	}
+}
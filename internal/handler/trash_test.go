@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"astra-api/internal/cache"
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestTrashHandler_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	trash := mocksgen.NewMockTrashServiceInterface(ctrl)
+
+	purgeAt := time.Date(2026, 11, 18, 0, 0, 0, 0, time.UTC)
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	trash.EXPECT().List("u1", 5).Return([]model.TrashEntry{{Document: model.Document{ID: "d1"}, PurgeAt: purgeAt}}, nil)
+
+	h := NewTrashHandler(sess, trash, cache.NewCache(0))
+
+	rr := httptest.NewRecorder()
+	h.List(rr, httptest.NewRequest(http.MethodGet, "/api/trash?token=t&limit=5", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"purge_at":"2026-11-18T00:00:00Z"`) {
+		t.Fatalf("unexpected response %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestTrashHandler_Restore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	trash := mocksgen.NewMockTrashServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(4)
+	trash.EXPECT().Restore("u1", "d1").Return(&model.Document{ID: "d1"}, nil)
+	trash.EXPECT().Restore("u1", "d2").Return(nil, service.ErrDocumentExists)
+	trash.EXPECT().Restore("u1", "d3").Return(nil, service.ErrDocumentNotFound)
+
+	h := NewTrashHandler(sess, trash, cache.NewCache(0))
+
+	for _, tc := range []struct {
+		method, id string
+		code       int
+	}{
+		{http.MethodPost, "d1", http.StatusOK},
+		{http.MethodPost, "d2", http.StatusConflict},
+		{http.MethodPost, "d3", http.StatusNotFound},
+		{http.MethodGet, "d1", http.StatusMethodNotAllowed},
+	} {
+		rr := httptest.NewRecorder()
+		h.Restore(rr, httptest.NewRequest(tc.method, "/api/trash/"+tc.id+"/restore?token=t", nil))
+		if rr.Code != tc.code {
+			t.Fatalf("%s %s: expected code %d, got %d", tc.method, tc.id, tc.code, rr.Code)
+		}
+	}
+}
+
+func TestTrashHandler_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	trash := mocksgen.NewMockTrashServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).Times(2)
+	trash.EXPECT().Purge("u1", "d1").Return(&model.Document{ID: "d1"}, nil)
+	trash.EXPECT().Purge("u1", "d2").Return(nil, service.ErrForbidden)
+
+	h := NewTrashHandler(sess, trash, cache.NewCache(0))
+
+	rr := httptest.NewRecorder()
+	h.Delete(rr, httptest.NewRequest(http.MethodDelete, "/api/trash/d1?token=t", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.Delete(rr, httptest.NewRequest(http.MethodDelete, "/api/trash/d2?token=t", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected code 403, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"astra-api/internal/cache"
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// UploadsHandler выдаёт подписанные ссылки на загрузку и принимает по ним файлы
+type UploadsHandler struct {
+	sessionService service.SessionServiceInterface
+	access         service.AccessServiceInterface
+	folders        service.FolderServiceInterface
+	schemas        service.SchemaServiceInterface
+	uploads        service.UploadServiceInterface
+	cache          *cache.Cache
+}
+
+func NewUploadsHandler(sessionService service.SessionServiceInterface, access service.AccessServiceInterface, folders service.FolderServiceInterface, schemas service.SchemaServiceInterface, uploads service.UploadServiceInterface, cache *cache.Cache) *UploadsHandler {
+	return &UploadsHandler{sessionService: sessionService, access: access, folders: folders, schemas: schemas, uploads: uploads, cache: cache}
+}
+
+// @Summary Ссылка для прямой загрузки файла
+// @Description Создаёт документ и возвращает короткоживущую ссылку, по которой файл загружается
+// @Description запросом PUT без токена сессии. Метаданные те же, что meta у POST /api/docs (без file и format);
+// @Description expires_at-длительность отсчитывается от выдачи ссылки. До загрузки документ не виден,
+// @Description но уже занимает имя в папке (409 — имя занято) и заявленный size в квоте (507 — нет места).
+// @Tags docs
+// @Accept json
+// @Produce json
+// @Param token query string true "Токен"
+// @Param input body model.UploadURLRequest true "Метаданные документа"
+// @Success 200 {object} model.APIResponse
+// @Router /api/docs/upload-url [post]
+func (h *UploadsHandler) CreateURL(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.sessionService.Validate(GetToken(r))
+	if !ok {
+		WriteError(w, 401, "invalid token")
+		return
+	}
+	if r.Method != http.MethodPost {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	var req model.UploadURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "invalid request body")
+		return
+	}
+	doc, ok := newDocument(w, h.access, sess, req.DocumentMeta)
+	if !ok {
+		return
+	}
+	doc.File = true
+	if !placeDocument(w, h.folders, h.schemas, sess, doc, req.DocumentMeta) {
+		return
+	}
+	res, token, err := h.uploads.Reserve(doc, req.Size)
+	if err != nil {
+		writeUploadError(w, err)
+		return
+	}
+	WriteResponse(w, &model.APIResponse{Data: model.UploadURLResponse{
+		ID:        res.ID,
+		URL:       "/api/uploads/" + token,
+		Method:    http.MethodPut,
+		ExpiresAt: res.ExpiresAt,
+	}})
+}
+
+// @Summary Загрузка файла по подписанной ссылке
+// @Description Тело запроса — содержимое файла. Сессия не нужна, ссылка одноразовая.
+// @Tags docs
+// @Accept octet-stream
+// @Produce json
+// @Param token path string true "Токен загрузки"
+// @Success 200 {object} model.APIResponse
+// @Failure 404 {object} model.APIResponse
+// @Failure 410 {object} model.APIResponse
+// @Failure 415 {object} model.APIResponse
+// @Router /api/uploads/{token} [put]
+func (h *UploadsHandler) Put(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		WriteError(w, 405, "method not allowed")
+		return
+	}
+	token := strings.TrimPrefix(r.URL.Path, "/api/uploads/")
+	if token == "" || strings.Contains(token, "/") {
+		WriteError(w, 404, "upload not found")
+		return
+	}
+	doc, err := h.uploads.Complete(token, r.Body, r.Header.Get("Content-Type"))
+	if err != nil {
+		writeUploadError(w, err)
+		return
+	}
+	h.cache.InvalidateAll()
+	WriteResponse(w, &model.APIResponse{Data: map[string]interface{}{
+		"id":   doc.ID,
+		"file": doc.Name,
+		"json": nil,
+	}})
+}
+
+func writeUploadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrUploadNotFound):
+		WriteError(w, 404, "upload not found")
+	case errors.Is(err, service.ErrUploadExpired):
+		WriteError(w, 410, err.Error())
+	case errors.Is(err, service.ErrInvalidUpload), errors.Is(err, service.ErrUploadSize):
+		WriteError(w, 400, err.Error())
+	default:
+		writeDocumentError(w, err)
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"astra-api/internal/cache"
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestUploadsHandler_CreateURL_OK(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	uploads := mocksgen.NewMockUploadServiceInterface(ctrl)
+
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true)
+	access.EXPECT().ResolveGrants([]string{"group:team"}).Return([]model.Grant{{Principal: "group:g1", Permission: model.PermissionRead}}, nil)
+	uploads.EXPECT().Reserve(gomock.Any(), gomock.Any()).DoAndReturn(func(doc *model.Document, size *int64) (*model.UploadReservation, string, error) {
+		if doc.Owner != "u1" || doc.Name != "a.pdf" || !doc.File || len(doc.Grants) != 1 || *doc.Grants[0].GrantedBy != "u1" {
+			t.Fatalf("unexpected document: %+v", doc)
+		}
+		if len(doc.Tags) != 1 || doc.Tags[0] != "q3" || doc.Metadata["customer"] != "acme" || doc.ExpiresAt == nil || size == nil || *size != 10 {
+			t.Fatalf("expected upload metadata on document, got %+v", doc)
+		}
+		return &model.UploadReservation{ID: doc.ID, ExpiresAt: time.Now()}, "abc.def", nil
+	})
+
+	h := NewUploadsHandler(sess, access, nil, nil, uploads, cache.NewCache(time.Minute))
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/docs/upload-url?token=t", strings.NewReader(`{"name":"a.pdf","grants":["group:team"],"tags":["Q3"],"metadata":{"customer":"acme"},"expires_at":"24h","size":10}`))
+
+	h.CreateURL(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"url":"/api/uploads/abc.def"`) {
+		t.Fatalf("expected upload url in response, got %s", rr.Body.String())
+	}
+}
+
+func TestUploadsHandler_CreateURL_FolderAndSchema(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := mocksgen.NewMockSessionServiceInterface(ctrl)
+	folders := mocksgen.NewMockFolderServiceInterface(ctrl)
+	schemas := mocksgen.NewMockSchemaServiceInterface(ctrl)
+	uploads := mocksgen.NewMockUploadServiceInterface(ctrl)
+	h := NewUploadsHandler(sess, nil, folders, schemas, uploads, cache.NewCache(time.Minute))
+	sess.EXPECT().Validate("t").Return(model.Session{UserID: "u1"}, true).AnyTimes()
+
+	folders.EXPECT().Place("u1", gomock.Any(), "f1").Return(nil)
+	uploads.EXPECT().Reserve(gomock.Any(), nil).Return(&model.UploadReservation{ID: "d1", ExpiresAt: time.Now()}, "abc.def", nil)
+	rr := httptest.NewRecorder()
+	h.CreateURL(rr, httptest.NewRequest(http.MethodPost, "/api/docs/upload-url?token=t", strings.NewReader(`{"name":"a.pdf","folder":"f1"}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+
+	// Схема проверяет JSON, а загружается файл — как и в POST /api/docs
+	schemas.EXPECT().Attach(gomock.Any(), "invoice").Return(service.ErrSchemaFileDocument)
+	rr = httptest.NewRecorder()
+	h.CreateURL(rr, httptest.NewRequest(http.MethodPost, "/api/docs/upload-url?token=t", strings.NewReader(`{"name":"a.pdf","schema":"invoice"}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.CreateURL(rr, httptest.NewRequest(http.MethodPost, "/api/docs/upload-url?token=t", strings.NewReader(`{"name":"a.pdf","expires_at":"soon"}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected code 400 for bad expiry, got %d", rr.Code)
+	}
+}
+
+func TestUploadsHandler_Put(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uploads := mocksgen.NewMockUploadServiceInterface(ctrl)
+	h := NewUploadsHandler(nil, nil, nil, nil, uploads, cache.NewCache(time.Minute))
+
+	uploads.EXPECT().Complete("abc.def", gomock.Any(), "application/pdf").Return(&model.Document{ID: "d1", Name: "a.pdf"}, nil)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/uploads/abc.def", strings.NewReader("%PDF"))
+	req.Header.Set("Content-Type", "application/pdf")
+	h.Put(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code 200, got %d", rr.Code)
+	}
+
+	uploads.EXPECT().Complete("old", gomock.Any(), "").Return(nil, service.ErrUploadExpired)
+	rr = httptest.NewRecorder()
+	h.Put(rr, httptest.NewRequest(http.MethodPut, "/api/uploads/old", strings.NewReader("x")))
+	if rr.Code != http.StatusGone {
+		t.Fatalf("expected code 410, got %d", rr.Code)
+	}
+}
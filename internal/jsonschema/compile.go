@@ -0,0 +1,495 @@
+// Package jsonschema проверяет JSON-документы по JSON Schema draft 2020-12.
+//
+// Поддерживаются все проверяющие ключевые слова ядра и словаря validation, кроме
+// unevaluatedProperties/unevaluatedItems и $dynamicRef: схема с ними не компилируется,
+// чтобы не создавать впечатление проверки, которой нет. $ref — только внутри той же
+// схемы ("#", "#/$defs/...", "#anchor"). format проверяется для date-time, date, time,
+// email, uuid, ipv4, ipv6 и uri; остальные форматы, как и неизвестные ключевые слова, игнорируются.
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Draft — единственная поддерживаемая версия $schema
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+var ErrInvalidSchema = errors.New("invalid json schema")
+
+// unsupported — ключевые слова, которые меняют результат проверки, но не реализованы
+var unsupported = []string{"unevaluatedProperties", "unevaluatedItems", "$dynamicRef", "$recursiveRef"}
+
+// Schema — скомпилированная схема, безопасна для одновременного использования
+type Schema struct {
+	root *node
+}
+
+type patternNode struct {
+	re   *regexp.Regexp
+	node *node
+}
+
+type node struct {
+	location string
+	// always задан для булевых схем true/false
+	always *bool
+
+	ref     string
+	refNode *node
+
+	types    []string
+	enum     []interface{}
+	hasEnum  bool
+	constVal interface{}
+	hasConst bool
+
+	multipleOf, maximum, exclusiveMaximum, minimum, exclusiveMinimum *float64
+
+	maxLength, minLength *int
+	pattern              *regexp.Regexp
+	format               string
+
+	prefixItems              []*node
+	items                    *node
+	contains                 *node
+	minContains, maxContains *int
+	maxItems, minItems       *int
+	uniqueItems              bool
+
+	properties           map[string]*node
+	patternProperties    []patternNode
+	additionalProperties *node
+	propertyNames        *node
+	required             []string
+	dependentRequired    map[string][]string
+	dependentSchemas     map[string]*node
+	maxProperties        *int
+	minProperties        *int
+
+	allOf, anyOf, oneOf []*node
+	not                 *node
+	ifNode              *node
+	thenNode, elseNode  *node
+}
+
+type compiler struct {
+	root    interface{}
+	nodes   map[string]*node
+	anchors map[string]string
+	refs    []*node
+}
+
+// Compile разбирает схему из JSON
+func Compile(data []byte) (*Schema, error) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	if obj, ok := raw.(map[string]interface{}); ok {
+		if s, ok := obj["$schema"]; ok && strings.TrimSuffix(fmt.Sprint(s), "#") != Draft {
+			return nil, fmt.Errorf("%w: unsupported $schema %v, expected %s", ErrInvalidSchema, s, Draft)
+		}
+	}
+	c := &compiler{root: raw, nodes: map[string]*node{}, anchors: map[string]string{}}
+	c.collectAnchors(raw, "#")
+	root, err := c.compile(raw, "#")
+	if err != nil {
+		return nil, err
+	}
+	// Ссылки разрешаются после обхода: цель может ещё не быть скомпилирована
+	for i := 0; i < len(c.refs); i++ {
+		n := c.refs[i]
+		target, err := c.resolve(n.ref)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSchema, n.location, err)
+		}
+		n.refNode = target
+	}
+	return &Schema{root: root}, nil
+}
+
+func (c *compiler) collectAnchors(raw interface{}, location string) {
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		if a, ok := v["$anchor"].(string); ok {
+			c.anchors[a] = location
+		}
+		for k, child := range v {
+			c.collectAnchors(child, location+"/"+escapePointer(k))
+		}
+	case []interface{}:
+		for i, child := range v {
+			c.collectAnchors(child, location+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+func (c *compiler) resolve(ref string) (*node, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("only local $ref is supported, got %q", ref)
+	}
+	fragment, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, fmt.Errorf("bad $ref %q", ref)
+	}
+	location := "#" + fragment
+	if fragment != "" && !strings.HasPrefix(fragment, "/") {
+		loc, ok := c.anchors[fragment]
+		if !ok {
+			return nil, fmt.Errorf("unknown anchor in $ref %q", ref)
+		}
+		location = loc
+	}
+	if n, ok := c.nodes[location]; ok {
+		return n, nil
+	}
+	target := c.root
+	if location != "#" {
+		for _, token := range strings.Split(location[2:], "/") {
+			token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+			switch v := target.(type) {
+			case map[string]interface{}:
+				target = v[token]
+			case []interface{}:
+				i, err := strconv.Atoi(token)
+				if err != nil || i < 0 || i >= len(v) {
+					return nil, fmt.Errorf("$ref %q not found", ref)
+				}
+				target = v[i]
+			default:
+				return nil, fmt.Errorf("$ref %q not found", ref)
+			}
+			if target == nil {
+				return nil, fmt.Errorf("$ref %q not found", ref)
+			}
+		}
+	}
+	return c.compile(target, location)
+}
+
+func (c *compiler) compile(raw interface{}, location string) (*node, error) {
+	if n, ok := c.nodes[location]; ok {
+		return n, nil
+	}
+	n := &node{location: location}
+	c.nodes[location] = n
+	switch v := raw.(type) {
+	case bool:
+		n.always = &v
+		return n, nil
+	case map[string]interface{}:
+		return n, c.compileObject(n, v)
+	default:
+		return nil, fmt.Errorf("%w: %s: schema must be an object or a boolean", ErrInvalidSchema, location)
+	}
+}
+
+func (c *compiler) compileObject(n *node, obj map[string]interface{}) error {
+	loc := n.location
+	fail := func(keyword, msg string) error {
+		return fmt.Errorf("%w: %s/%s: %s", ErrInvalidSchema, loc, keyword, msg)
+	}
+	for _, k := range unsupported {
+		if _, ok := obj[k]; ok {
+			return fail(k, "keyword is not supported")
+		}
+	}
+	sub := func(keyword string) (*node, error) {
+		raw, ok := obj[keyword]
+		if !ok {
+			return nil, nil
+		}
+		return c.compile(raw, loc+"/"+keyword)
+	}
+	subList := func(keyword string) ([]*node, error) {
+		raw, ok := obj[keyword]
+		if !ok {
+			return nil, nil
+		}
+		list, ok := raw.([]interface{})
+		if !ok || len(list) == 0 {
+			return nil, fail(keyword, "must be a non-empty array of schemas")
+		}
+		nodes := make([]*node, len(list))
+		for i, item := range list {
+			sn, err := c.compile(item, loc+"/"+keyword+"/"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			nodes[i] = sn
+		}
+		return nodes, nil
+	}
+	subMap := func(keyword string) (map[string]*node, error) {
+		raw, ok := obj[keyword]
+		if !ok {
+			return nil, nil
+		}
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fail(keyword, "must be an object of schemas")
+		}
+		nodes := make(map[string]*node, len(m))
+		for name, item := range m {
+			sn, err := c.compile(item, loc+"/"+keyword+"/"+escapePointer(name))
+			if err != nil {
+				return nil, err
+			}
+			nodes[name] = sn
+		}
+		return nodes, nil
+	}
+	number := func(keyword string) (*float64, error) {
+		raw, ok := obj[keyword]
+		if !ok {
+			return nil, nil
+		}
+		f, ok := raw.(float64)
+		if !ok {
+			return nil, fail(keyword, "must be a number")
+		}
+		return &f, nil
+	}
+	count := func(keyword string) (*int, error) {
+		raw, ok := obj[keyword]
+		if !ok {
+			return nil, nil
+		}
+		f, ok := raw.(float64)
+		if !ok || f < 0 || f != float64(int(f)) {
+			return nil, fail(keyword, "must be a non-negative integer")
+		}
+		i := int(f)
+		return &i, nil
+	}
+	var err error
+
+	if raw, ok := obj["$ref"]; ok {
+		ref, ok := raw.(string)
+		if !ok {
+			return fail("$ref", "must be a string")
+		}
+		n.ref = ref
+		c.refs = append(c.refs, n)
+	}
+	if defs, ok := obj["$defs"]; ok {
+		m, ok := defs.(map[string]interface{})
+		if !ok {
+			return fail("$defs", "must be an object of schemas")
+		}
+		for name, item := range m {
+			if _, err := c.compile(item, loc+"/$defs/"+escapePointer(name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if raw, ok := obj["type"]; ok {
+		switch t := raw.(type) {
+		case string:
+			n.types = []string{t}
+		case []interface{}:
+			for _, item := range t {
+				s, ok := item.(string)
+				if !ok {
+					return fail("type", "must be a string or an array of strings")
+				}
+				n.types = append(n.types, s)
+			}
+		default:
+			return fail("type", "must be a string or an array of strings")
+		}
+		for _, t := range n.types {
+			switch t {
+			case "null", "boolean", "object", "array", "number", "integer", "string":
+			default:
+				return fail("type", fmt.Sprintf("unknown type %q", t))
+			}
+		}
+	}
+	if raw, ok := obj["enum"]; ok {
+		list, ok := raw.([]interface{})
+		if !ok {
+			return fail("enum", "must be an array")
+		}
+		n.enum, n.hasEnum = list, true
+	}
+	if raw, ok := obj["const"]; ok {
+		n.constVal, n.hasConst = raw, true
+	}
+
+	if n.multipleOf, err = number("multipleOf"); err != nil {
+		return err
+	}
+	if n.multipleOf != nil && *n.multipleOf <= 0 {
+		return fail("multipleOf", "must be greater than 0")
+	}
+	if n.maximum, err = number("maximum"); err != nil {
+		return err
+	}
+	if n.exclusiveMaximum, err = number("exclusiveMaximum"); err != nil {
+		return err
+	}
+	if n.minimum, err = number("minimum"); err != nil {
+		return err
+	}
+	if n.exclusiveMinimum, err = number("exclusiveMinimum"); err != nil {
+		return err
+	}
+
+	if n.maxLength, err = count("maxLength"); err != nil {
+		return err
+	}
+	if n.minLength, err = count("minLength"); err != nil {
+		return err
+	}
+	if raw, ok := obj["pattern"]; ok {
+		s, ok := raw.(string)
+		if !ok {
+			return fail("pattern", "must be a string")
+		}
+		if n.pattern, err = regexp.Compile(s); err != nil {
+			return fail("pattern", err.Error())
+		}
+	}
+	if raw, ok := obj["format"]; ok {
+		s, ok := raw.(string)
+		if !ok {
+			return fail("format", "must be a string")
+		}
+		n.format = s
+	}
+
+	if n.prefixItems, err = subList("prefixItems"); err != nil {
+		return err
+	}
+	if n.items, err = sub("items"); err != nil {
+		return err
+	}
+	if n.contains, err = sub("contains"); err != nil {
+		return err
+	}
+	if n.minContains, err = count("minContains"); err != nil {
+		return err
+	}
+	if n.maxContains, err = count("maxContains"); err != nil {
+		return err
+	}
+	if n.maxItems, err = count("maxItems"); err != nil {
+		return err
+	}
+	if n.minItems, err = count("minItems"); err != nil {
+		return err
+	}
+	if raw, ok := obj["uniqueItems"]; ok {
+		b, ok := raw.(bool)
+		if !ok {
+			return fail("uniqueItems", "must be a boolean")
+		}
+		n.uniqueItems = b
+	}
+
+	if n.properties, err = subMap("properties"); err != nil {
+		return err
+	}
+	patterns, err := subMap("patternProperties")
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(patterns))
+	for p := range patterns {
+		keys = append(keys, p)
+	}
+	sort.Strings(keys)
+	for _, p := range keys {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fail("patternProperties", err.Error())
+		}
+		n.patternProperties = append(n.patternProperties, patternNode{re: re, node: patterns[p]})
+	}
+	if n.additionalProperties, err = sub("additionalProperties"); err != nil {
+		return err
+	}
+	if n.propertyNames, err = sub("propertyNames"); err != nil {
+		return err
+	}
+	if raw, ok := obj["required"]; ok {
+		if n.required, ok = stringList(raw); !ok {
+			return fail("required", "must be an array of strings")
+		}
+	}
+	if raw, ok := obj["dependentRequired"]; ok {
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return fail("dependentRequired", "must be an object of string arrays")
+		}
+		n.dependentRequired = make(map[string][]string, len(m))
+		for name, item := range m {
+			list, ok := stringList(item)
+			if !ok {
+				return fail("dependentRequired", "must be an object of string arrays")
+			}
+			n.dependentRequired[name] = list
+		}
+	}
+	if n.dependentSchemas, err = subMap("dependentSchemas"); err != nil {
+		return err
+	}
+	if n.maxProperties, err = count("maxProperties"); err != nil {
+		return err
+	}
+	if n.minProperties, err = count("minProperties"); err != nil {
+		return err
+	}
+
+	if n.allOf, err = subList("allOf"); err != nil {
+		return err
+	}
+	if n.anyOf, err = subList("anyOf"); err != nil {
+		return err
+	}
+	if n.oneOf, err = subList("oneOf"); err != nil {
+		return err
+	}
+	if n.not, err = sub("not"); err != nil {
+		return err
+	}
+	if n.ifNode, err = sub("if"); err != nil {
+		return err
+	}
+	if n.thenNode, err = sub("then"); err != nil {
+		return err
+	}
+	if n.elseNode, err = sub("else"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func stringList(raw interface{}) ([]string, bool) {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, s)
+	}
+	return out, true
+}
+
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
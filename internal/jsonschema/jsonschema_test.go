@@ -0,0 +1,125 @@
+package jsonschema
+
+import (
+	"errors"
+	"testing"
+)
+
+const configSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["service", "replicas"],
+	"additionalProperties": false,
+	"properties": {
+		"service": {"type": "string", "pattern": "^[a-z-]+$"},
+		"replicas": {"type": "integer", "minimum": 1, "maximum": 10},
+		"owner": {"type": "string", "format": "email"},
+		"ports": {"type": "array", "items": {"$ref": "#/$defs/port"}, "uniqueItems": true},
+		"mode": {"enum": ["active", "standby"]},
+		"tls": {"$ref": "#/$defs/tls"}
+	},
+	"if": {"properties": {"mode": {"const": "active"}}, "required": ["mode"]},
+	"then": {"required": ["ports"]},
+	"$defs": {
+		"port": {"type": "integer", "exclusiveMinimum": 0, "exclusiveMaximum": 65536},
+		"tls": {"type": "object", "dependentRequired": {"cert": ["key"]}}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	schema, err := Compile([]byte(configSchema))
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if err := schema.ValidateJSON([]byte(`{"service": "billing", "replicas": 3, "mode": "active", "ports": [80, 443], "owner": "ops@example.com"}`)); err != nil {
+		t.Fatalf("expected valid document, got %v", err)
+	}
+
+	err = schema.ValidateJSON([]byte(`{"service": "Billing", "replicas": 2.5, "extra": 1, "mode": "active", "tls": {"cert": "x"}, "owner": "not an email"}`))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	got := map[string]string{}
+	for _, v := range verr.Violations {
+		got[v.Path] = v.Keyword
+	}
+	want := map[string]string{
+		"/service":  "#/properties/service/pattern",
+		"/replicas": "#/properties/replicas/type",
+		"/extra":    "#/additionalProperties",
+		"":          "#/then/required",
+		"/tls":      "#/$defs/tls/dependentRequired",
+		"/owner":    "#/properties/owner/format",
+	}
+	for path, keyword := range want {
+		if got[path] != keyword {
+			t.Fatalf("expected %s at %q, got %q (all: %+v)", keyword, path, got[path], verr.Violations)
+		}
+	}
+	if len(verr.Violations) != len(want) {
+		t.Fatalf("unexpected violations %+v", verr.Violations)
+	}
+
+	err = schema.ValidateJSON([]byte(`{"service": "a", "replicas": 1, "ports": [80, 80, 70000]}`))
+	if !errors.As(err, &verr) || len(verr.Violations) != 2 || verr.Violations[1].Path != "/ports/2" {
+		t.Fatalf("unexpected violations for ports: %v", err)
+	}
+}
+
+func TestValidate_Combinators(t *testing.T) {
+	schema, err := Compile([]byte(`{
+		"oneOf": [{"type": "integer"}, {"type": "number", "multipleOf": 0.5}],
+		"not": {"const": 0}
+	}`))
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	cases := map[string]bool{`1.5`: true, `2`: false, `0.3`: false, `0`: false, `"x"`: false}
+	for doc, ok := range cases {
+		if err := schema.ValidateJSON([]byte(doc)); (err == nil) != ok {
+			t.Fatalf("%s: expected valid=%v, got %v", doc, ok, err)
+		}
+	}
+}
+
+func TestValidate_RecursiveRef(t *testing.T) {
+	schema, err := Compile([]byte(`{
+		"$defs": {"tree": {"$anchor": "tree", "type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#tree"}}}, "required": ["name"]}},
+		"$ref": "#/$defs/tree"
+	}`))
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	err = schema.ValidateJSON([]byte(`{"name": "root", "children": [{"name": "a"}, {"children": []}]}`))
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) != 1 || verr.Violations[0].Path != "/children/1" {
+		t.Fatalf("unexpected result %v", err)
+	}
+
+	loop, err := Compile([]byte(`{"$ref": "#"}`))
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if err := loop.ValidateJSON([]byte(`{}`)); err == nil {
+		t.Fatal("expected error for endless $ref loop")
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	for _, src := range []string{
+		`[]`,
+		`{"type": "text"}`,
+		`{"pattern": "("}`,
+		`{"minLength": -1}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"$ref": "https://example.com/schema.json"}`,
+		`{"unevaluatedProperties": false}`,
+		`{"$schema": "http://json-schema.org/draft-07/schema#"}`,
+		`{"properties": {"a": 1}}`,
+	} {
+		if _, err := Compile([]byte(src)); !errors.Is(err, ErrInvalidSchema) {
+			t.Fatalf("%s: expected ErrInvalidSchema, got %v", src, err)
+		}
+	}
+}
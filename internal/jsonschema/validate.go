@@ -0,0 +1,399 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxErrors ограничивает число нарушений в одном отчёте
+	maxErrors = 100
+	// maxDepth защищает от схем, которые ссылаются сами на себя без продвижения по документу
+	maxDepth = 256
+)
+
+// Violation — одно нарушение: где в документе (JSON Pointer, "" — корень),
+// какое ключевое слово схемы не выполнено и почему
+type Violation struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+// ValidationError — документ не соответствует схеме
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Violations) == 0 {
+		return "document does not match schema"
+	}
+	v := e.Violations[0]
+	msg := fmt.Sprintf("document does not match schema: %s: %s", displayPath(v.Path), v.Message)
+	if len(e.Violations) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(e.Violations)-1)
+	}
+	return msg
+}
+
+func displayPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+// Validate проверяет документ — результат json.Unmarshal в interface{}.
+// Возвращает *ValidationError со всеми найденными нарушениями (не больше maxErrors).
+func (s *Schema) Validate(doc interface{}) error {
+	v := &validator{}
+	v.validate(s.root, doc, "", 0)
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: v.violations}
+}
+
+// ValidateJSON разбирает и проверяет документ
+func (s *Schema) ValidateJSON(data []byte) error {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return &ValidationError{Violations: []Violation{{Keyword: "#", Message: "invalid json: " + err.Error()}}}
+	}
+	return s.Validate(doc)
+}
+
+type validator struct {
+	violations []Violation
+}
+
+func (v *validator) fail(n *node, keyword, path, format string, args ...interface{}) {
+	if len(v.violations) >= maxErrors {
+		return
+	}
+	v.violations = append(v.violations, Violation{Path: path, Keyword: n.location + "/" + keyword, Message: fmt.Sprintf(format, args...)})
+}
+
+// valid проверяет подсхему, не записывая нарушения: для anyOf, oneOf, not, if и contains
+func (v *validator) valid(n *node, inst interface{}, path string, depth int) bool {
+	probe := &validator{}
+	probe.validate(n, inst, path, depth)
+	return len(probe.violations) == 0
+}
+
+func (v *validator) validate(n *node, inst interface{}, path string, depth int) {
+	if depth > maxDepth {
+		v.fail(n, "$ref", path, "schema recursion is too deep")
+		return
+	}
+	if n.always != nil {
+		if !*n.always {
+			v.violations = append(v.violations, Violation{Path: path, Keyword: n.location, Message: "no value is allowed here"})
+		}
+		return
+	}
+	if n.refNode != nil {
+		v.validate(n.refNode, inst, path, depth+1)
+	}
+
+	if len(n.types) > 0 && !matchesType(n.types, inst) {
+		v.fail(n, "type", path, "expected %s, got %s", strings.Join(n.types, " or "), typeOf(inst))
+	}
+	if n.hasEnum {
+		found := false
+		for _, e := range n.enum {
+			if equal(e, inst) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(n, "enum", path, "value must be one of %s", compact(n.enum))
+		}
+	}
+	if n.hasConst && !equal(n.constVal, inst) {
+		v.fail(n, "const", path, "value must be %s", compact(n.constVal))
+	}
+
+	switch x := inst.(type) {
+	case float64:
+		v.validateNumber(n, x, path)
+	case string:
+		v.validateString(n, x, path)
+	case []interface{}:
+		v.validateArray(n, x, path, depth)
+	case map[string]interface{}:
+		v.validateObject(n, x, path, depth)
+	}
+
+	for _, sub := range n.allOf {
+		v.validate(sub, inst, path, depth+1)
+	}
+	if len(n.anyOf) > 0 {
+		matched := false
+		for _, sub := range n.anyOf {
+			if v.valid(sub, inst, path, depth+1) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(n, "anyOf", path, "value does not match any of the alternatives")
+		}
+	}
+	if len(n.oneOf) > 0 {
+		var matched []string
+		for i, sub := range n.oneOf {
+			if v.valid(sub, inst, path, depth+1) {
+				matched = append(matched, strconv.Itoa(i))
+			}
+		}
+		switch {
+		case len(matched) == 0:
+			v.fail(n, "oneOf", path, "value does not match any of the alternatives")
+		case len(matched) > 1:
+			v.fail(n, "oneOf", path, "value matches more than one alternative: %s", strings.Join(matched, ", "))
+		}
+	}
+	if n.not != nil && v.valid(n.not, inst, path, depth+1) {
+		v.fail(n, "not", path, "value must not match the schema")
+	}
+	if n.ifNode != nil {
+		if v.valid(n.ifNode, inst, path, depth+1) {
+			if n.thenNode != nil {
+				v.validate(n.thenNode, inst, path, depth+1)
+			}
+		} else if n.elseNode != nil {
+			v.validate(n.elseNode, inst, path, depth+1)
+		}
+	}
+}
+
+func (v *validator) validateNumber(n *node, x float64, path string) {
+	if n.multipleOf != nil {
+		q := x / *n.multipleOf
+		if math.IsInf(q, 0) || math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(n, "multipleOf", path, "must be a multiple of %v", *n.multipleOf)
+		}
+	}
+	if n.maximum != nil && x > *n.maximum {
+		v.fail(n, "maximum", path, "must be <= %v", *n.maximum)
+	}
+	if n.exclusiveMaximum != nil && x >= *n.exclusiveMaximum {
+		v.fail(n, "exclusiveMaximum", path, "must be < %v", *n.exclusiveMaximum)
+	}
+	if n.minimum != nil && x < *n.minimum {
+		v.fail(n, "minimum", path, "must be >= %v", *n.minimum)
+	}
+	if n.exclusiveMinimum != nil && x <= *n.exclusiveMinimum {
+		v.fail(n, "exclusiveMinimum", path, "must be > %v", *n.exclusiveMinimum)
+	}
+}
+
+func (v *validator) validateString(n *node, x string, path string) {
+	length := utf8.RuneCountInString(x)
+	if n.maxLength != nil && length > *n.maxLength {
+		v.fail(n, "maxLength", path, "must be at most %d characters", *n.maxLength)
+	}
+	if n.minLength != nil && length < *n.minLength {
+		v.fail(n, "minLength", path, "must be at least %d characters", *n.minLength)
+	}
+	if n.pattern != nil && !n.pattern.MatchString(x) {
+		v.fail(n, "pattern", path, "must match pattern %q", n.pattern.String())
+	}
+	if n.format != "" && !checkFormat(n.format, x) {
+		v.fail(n, "format", path, "must be a valid %s", n.format)
+	}
+}
+
+func (v *validator) validateArray(n *node, x []interface{}, path string, depth int) {
+	if n.maxItems != nil && len(x) > *n.maxItems {
+		v.fail(n, "maxItems", path, "must have at most %d items", *n.maxItems)
+	}
+	if n.minItems != nil && len(x) < *n.minItems {
+		v.fail(n, "minItems", path, "must have at least %d items", *n.minItems)
+	}
+	if n.uniqueItems {
+		for i := 1; i < len(x); i++ {
+			for j := 0; j < i; j++ {
+				if equal(x[i], x[j]) {
+					v.fail(n, "uniqueItems", path, "items %d and %d are equal", j, i)
+					i = len(x)
+					break
+				}
+			}
+		}
+	}
+	for i, item := range x {
+		itemPath := path + "/" + strconv.Itoa(i)
+		if i < len(n.prefixItems) {
+			v.validate(n.prefixItems[i], item, itemPath, depth+1)
+		} else if n.items != nil {
+			v.validate(n.items, item, itemPath, depth+1)
+		}
+	}
+	if n.contains != nil {
+		matches := 0
+		for i, item := range x {
+			if v.valid(n.contains, item, path+"/"+strconv.Itoa(i), depth+1) {
+				matches++
+			}
+		}
+		min := 1
+		if n.minContains != nil {
+			min = *n.minContains
+		}
+		if matches < min {
+			v.fail(n, "contains", path, "must contain at least %d matching items, found %d", min, matches)
+		}
+		if n.maxContains != nil && matches > *n.maxContains {
+			v.fail(n, "maxContains", path, "must contain at most %d matching items, found %d", *n.maxContains, matches)
+		}
+	}
+}
+
+func (v *validator) validateObject(n *node, x map[string]interface{}, path string, depth int) {
+	if n.maxProperties != nil && len(x) > *n.maxProperties {
+		v.fail(n, "maxProperties", path, "must have at most %d properties", *n.maxProperties)
+	}
+	if n.minProperties != nil && len(x) < *n.minProperties {
+		v.fail(n, "minProperties", path, "must have at least %d properties", *n.minProperties)
+	}
+	for _, name := range n.required {
+		if _, ok := x[name]; !ok {
+			v.fail(n, "required", path, "missing required property %q", name)
+		}
+	}
+	keys := make([]string, 0, len(x))
+	for k := range x {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		propPath := path + "/" + escapePointer(k)
+		if deps, ok := n.dependentRequired[k]; ok {
+			for _, dep := range deps {
+				if _, ok := x[dep]; !ok {
+					v.fail(n, "dependentRequired", path, "property %q requires property %q", k, dep)
+				}
+			}
+		}
+		if sub, ok := n.dependentSchemas[k]; ok {
+			v.validate(sub, x, path, depth+1)
+		}
+		if n.propertyNames != nil && !v.valid(n.propertyNames, k, propPath, depth+1) {
+			v.fail(n, "propertyNames", propPath, "property name %q is not allowed", k)
+		}
+		evaluated := false
+		if sub, ok := n.properties[k]; ok {
+			v.validate(sub, x[k], propPath, depth+1)
+			evaluated = true
+		}
+		for _, p := range n.patternProperties {
+			if p.re.MatchString(k) {
+				v.validate(p.node, x[k], propPath, depth+1)
+				evaluated = true
+			}
+		}
+		if !evaluated && n.additionalProperties != nil {
+			if n.additionalProperties.always != nil && !*n.additionalProperties.always {
+				v.fail(n, "additionalProperties", propPath, "property %q is not allowed", k)
+			} else {
+				v.validate(n.additionalProperties, x[k], propPath, depth+1)
+			}
+		}
+	}
+}
+
+func matchesType(types []string, inst interface{}) bool {
+	actual := typeOf(inst)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func typeOf(inst interface{}) string {
+	switch x := inst.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if x == math.Trunc(x) && !math.IsInf(x, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", inst)
+	}
+}
+
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func compact(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(data) > 200 {
+		return string(data[:200]) + "..."
+	}
+	return string(data)
+}
+
+var uuidRe = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func checkFormat(format, s string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", s)
+		if err != nil {
+			_, err = time.Parse("15:04:05.999999999Z07:00", s)
+		}
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "uuid":
+		return uuidRe.MatchString(s)
+	case "ipv4":
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+	case "ipv6":
+		return net.ParseIP(s) != nil && strings.Contains(s, ":")
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	default:
+		return true
+	}
+}
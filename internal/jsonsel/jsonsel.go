@@ -0,0 +1,217 @@
+// Package jsonsel выбирает части JSON-документа по JSON Pointer (RFC 6901)
+// или по подмножеству JSONPath: $, .key, ['key'], [n], [-n], [*], .*, ..key и ..*.
+package jsonsel
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidSelector = errors.New("invalid selector")
+
+type stepKind int
+
+const (
+	stepKey stepKind = iota
+	stepIndex
+	stepWildcard
+)
+
+type step struct {
+	kind      stepKind
+	key       string
+	index     int
+	recursive bool
+}
+
+// Selector — разобранное выражение. Документ — результат json.Unmarshal в interface{}.
+type Selector struct {
+	expr    string
+	pointer []string
+	path    []step
+	isPath  bool
+}
+
+// Parse разбирает выражение: начинающееся с $ — JSONPath, пустое или с / — JSON Pointer
+func Parse(expr string) (*Selector, error) {
+	switch {
+	case strings.HasPrefix(expr, "$"):
+		path, err := parsePath(expr[1:])
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidSelector, expr, err)
+		}
+		return &Selector{expr: expr, path: path, isPath: true}, nil
+	case expr == "":
+		return &Selector{expr: expr}, nil
+	case strings.HasPrefix(expr, "/"):
+		var pointer []string
+		for _, token := range strings.Split(expr[1:], "/") {
+			pointer = append(pointer, strings.NewReplacer("~1", "/", "~0", "~").Replace(token))
+		}
+		return &Selector{expr: expr, pointer: pointer}, nil
+	default:
+		return nil, fmt.Errorf("%w %q: expected JSON Pointer (/a/b) or JSONPath ($.a.b)", ErrInvalidSelector, expr)
+	}
+}
+
+func (s *Selector) String() string {
+	return s.expr
+}
+
+// Select возвращает выбранную часть документа. Для JSON Pointer — одно значение
+// (found=false, если пути нет), для JSONPath — список совпадений, возможно пустой.
+func (s *Selector) Select(doc interface{}) (value interface{}, found bool) {
+	if s.isPath {
+		matches := []interface{}{doc}
+		for _, st := range s.path {
+			matches = apply(matches, st)
+		}
+		return matches, true
+	}
+	cur := doc
+	for _, token := range s.pointer {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			next, ok := v[token]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) || (len(token) > 1 && token[0] == '0') {
+				return nil, false
+			}
+			cur = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func apply(nodes []interface{}, st step) []interface{} {
+	if st.recursive {
+		var all []interface{}
+		for _, n := range nodes {
+			all = descendants(all, n)
+		}
+		nodes = all
+	}
+	out := []interface{}{}
+	for _, n := range nodes {
+		switch v := n.(type) {
+		case map[string]interface{}:
+			switch st.kind {
+			case stepKey:
+				if child, ok := v[st.key]; ok {
+					out = append(out, child)
+				}
+			case stepWildcard:
+				for _, key := range sortedKeys(v) {
+					out = append(out, v[key])
+				}
+			}
+		case []interface{}:
+			switch st.kind {
+			case stepIndex:
+				i := st.index
+				if i < 0 {
+					i += len(v)
+				}
+				if i >= 0 && i < len(v) {
+					out = append(out, v[i])
+				}
+			case stepWildcard:
+				out = append(out, v...)
+			}
+		}
+	}
+	return out
+}
+
+// descendants добавляет узел и всех его потомков в порядке обхода в глубину
+func descendants(acc []interface{}, n interface{}) []interface{} {
+	acc = append(acc, n)
+	switch v := n.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			acc = descendants(acc, v[key])
+		}
+	case []interface{}:
+		for _, child := range v {
+			acc = descendants(acc, child)
+		}
+	}
+	return acc
+}
+
+func parsePath(s string) ([]step, error) {
+	var steps []step
+	for len(s) > 0 {
+		var st step
+		switch {
+		case strings.HasPrefix(s, ".."):
+			st.recursive = true
+			s = s[2:]
+			if strings.HasPrefix(s, "[") {
+				break
+			}
+			fallthrough
+		case strings.HasPrefix(s, "."):
+			s = strings.TrimPrefix(s, ".")
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			name := s[:end]
+			s = s[end:]
+			switch name {
+			case "":
+				return nil, errors.New("empty key")
+			case "*":
+				st.kind = stepWildcard
+			default:
+				st.kind, st.key = stepKey, name
+			}
+			steps = append(steps, st)
+			continue
+		}
+		if !strings.HasPrefix(s, "[") {
+			return nil, fmt.Errorf("unexpected %q", s)
+		}
+		end := strings.Index(s, "]")
+		if end < 0 {
+			return nil, errors.New("unclosed [")
+		}
+		inner := strings.TrimSpace(s[1:end])
+		s = s[end+1:]
+		switch {
+		case inner == "*":
+			st.kind = stepWildcard
+		case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+			st.kind, st.key = stepKey, inner[1:len(inner)-1]
+		default:
+			i, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("bad subscript [%s]", inner)
+			}
+			st.kind, st.index = stepIndex, i
+		}
+		steps = append(steps, st)
+	}
+	return steps, nil
+}
+
+// sortedKeys даёт стабильный порядок совпадений при обходе объектов
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
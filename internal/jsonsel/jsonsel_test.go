@@ -0,0 +1,90 @@
+package jsonsel
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testDoc = `{
+	"name": "report",
+	"a/b": 1,
+	"meta": {"priority": 3, "tags": ["x", "y"]},
+	"items": [{"name": "first", "qty": 1}, {"name": "second", "qty": 2}]
+}`
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("bad test json: %v", err)
+	}
+	return v
+}
+
+func TestSelect_Pointer(t *testing.T) {
+	doc := decode(t, testDoc)
+	cases := []struct {
+		expr  string
+		want  string
+		found bool
+	}{
+		{"/meta/priority", `3`, true},
+		{"/items/1/name", `"second"`, true},
+		{"/a~1b", `1`, true},
+		{"/meta/tags/01", `null`, false},
+		{"/missing", `null`, false},
+	}
+	for _, tc := range cases {
+		sel, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.expr, err)
+		}
+		got, found := sel.Select(doc)
+		if found != tc.found || (found && !reflect.DeepEqual(got, decode(t, tc.want))) {
+			t.Fatalf("%s: got %v (found=%v)", tc.expr, got, found)
+		}
+	}
+	sel, err := Parse("")
+	if err != nil {
+		t.Fatalf("empty pointer: %v", err)
+	}
+	if got, _ := sel.Select(doc); !reflect.DeepEqual(got, doc) {
+		t.Fatal("expected empty pointer to select the whole document")
+	}
+}
+
+func TestSelect_Path(t *testing.T) {
+	doc := decode(t, testDoc)
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{"$.meta.priority", `[3]`},
+		{"$.items[*].name", `["first", "second"]`},
+		{"$.items[-1].qty", `[2]`},
+		{"$['meta']['tags'][0]", `["x"]`},
+		{"$..name", `["report", "first", "second"]`},
+		{"$.meta.*", `[3, ["x", "y"]]`},
+		{"$.nothing", `[]`},
+	}
+	for _, tc := range cases {
+		sel, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.expr, err)
+		}
+		got, _ := sel.Select(doc)
+		if !reflect.DeepEqual(got, decode(t, tc.want)) {
+			t.Fatalf("%s: got %v, want %s", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{"meta", "$.", "$[", "$[abc]", "$..", "$meta"} {
+		if _, err := Parse(expr); !errors.Is(err, ErrInvalidSelector) {
+			t.Fatalf("%q: expected ErrInvalidSelector, got %v", expr, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestAuditMiddleware_RecordsImpersonatedRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditRepo := mocksgen.NewMockAuditRepositoryInterface(ctrl)
+	auditMiddleware := NewAuditMiddleware(auditRepo)
+
+	auditRepo.EXPECT().Create(gomock.Any()).Do(func(entry *model.AuditEntry) {
+		if entry.ActorID != "admin1" || entry.UserID != "u1" {
+			t.Fatalf("unexpected audit identities: %+v", entry)
+		}
+		if entry.Method != http.MethodDelete || entry.Path != "/api/docs/d1" || entry.Status != http.StatusNotFound {
+			t.Fatalf("unexpected audit request data: %+v", entry)
+		}
+	}).Return(nil)
+
+	handler := auditMiddleware.RecordImpersonation(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	session := &model.Session{UserID: "u1", ImpersonatorID: "admin1", ImpersonatorLogin: "adminuser"}
+	req := httptest.NewRequest(http.MethodDelete, "/api/docs/d1?token=secret", nil)
+	req = req.WithContext(context.WithValue(req.Context(), SessionContextKey, session))
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Header().Get(ImpersonatedByHeader) != "adminuser" {
+		t.Fatalf("expected %s header, got %q", ImpersonatedByHeader, rr.Header().Get(ImpersonatedByHeader))
+	}
+}
+
+func TestAuditMiddleware_RegularSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditRepo := mocksgen.NewMockAuditRepositoryInterface(ctrl)
+	auditMiddleware := NewAuditMiddleware(auditRepo)
+
+	handler := auditMiddleware.RecordImpersonation(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/docs", nil)
+	req = req.WithContext(context.WithValue(req.Context(), SessionContextKey, &model.Session{UserID: "u1"}))
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Header().Get(ImpersonatedByHeader) != "" {
+		t.Fatal("expected no impersonation header for a regular session")
+	}
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"astra-api/internal/auth"
+	"astra-api/internal/model"
 	"astra-api/internal/repository"
 	"astra-api/internal/service"
 	"context"
@@ -34,7 +36,7 @@ func NewAuthMiddleware(sessionService service.SessionServiceInterface, userRepo
 // RequireAuth is middleware that requires authentication
 func (m *AuthMiddleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		token := getToken(r)
+		token := auth.Default.Token(r)
 		if token == "" {
 			http.Error(w, `{"error":"missing authentication token"}`, http.StatusUnauthorized)
 			return
@@ -46,10 +48,21 @@ func (m *AuthMiddleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		user, err := m.userRepo.GetByID(session.UserID)
-		if err != nil {
-			http.Error(w, `{"error":"user not found"}`, http.StatusUnauthorized)
-			return
+		var user *model.User
+		if session.Stateless {
+			// Signed tokens already carry the user identity, no need to hit the DB
+			user = &model.User{ID: session.UserID, Login: session.Login, Roles: session.Roles}
+		} else {
+			var err error
+			user, err = m.userRepo.GetByID(session.UserID)
+			if err != nil {
+				http.Error(w, `{"error":"user not found"}`, http.StatusUnauthorized)
+				return
+			}
+			if session.Impersonated() {
+				// Impersonation never grants the target's admin role
+				user.Roles = session.Roles
+			}
 		}
 
 		// Add user and session to context
@@ -59,19 +72,3 @@ func (m *AuthMiddleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
-
-// getToken extracts token from request (query param, header, or form)
-func getToken(r *http.Request) string {
-	if token := r.URL.Query().Get("token"); token != "" {
-		return token
-	}
-	if token := r.Header.Get("Authorization"); token != "" {
-		return token
-	}
-	if err := r.ParseMultipartForm(32 << 20); err == nil {
-		if token := r.FormValue("token"); token != "" {
-			return token
-		}
-	}
-	return ""
-}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"astra-api/internal/auth"
 	mocksgen "astra-api/internal/mocks/gomock"
 	"astra-api/internal/model"
 	"bytes"
@@ -227,6 +228,132 @@ func TestAuthMiddleware_RequireAuth_TokenFromForm(t *testing.T) {
 	}
 }
 
+func TestAuthMiddleware_RequireAuth_StatelessSkipsUserLookup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessionService := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	authMiddleware := NewAuthMiddleware(sessionService, userRepo)
+
+	session := &model.Session{
+		Token:     "signedtoken",
+		UserID:    "u1",
+		Login:     "testuser",
+		Roles:     []string{model.RoleAdmin},
+		Stateless: true,
+	}
+
+	sessionService.EXPECT().Get("signedtoken").Return(session, true)
+	// userRepo.GetByID must not be called for stateless sessions
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := r.Context().Value(UserContextKey).(*model.User)
+		if !ok {
+			t.Fatal("expected user in context")
+		}
+		if user.ID != "u1" || !user.HasRole(model.RoleAdmin) {
+			t.Fatalf("unexpected user in context: %+v", user)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler := authMiddleware.RequireAuth(next)
+
+	req := httptest.NewRequest(http.MethodGet, "/test?token=signedtoken", nil)
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+}
+
+func TestAuthMiddleware_RequireAuth_BearerHeader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessionService := mocksgen.NewMockSessionServiceInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	authMiddleware := NewAuthMiddleware(sessionService, userRepo)
+
+	user := &model.User{ID: uuid.New().String(), Login: "testuser"}
+	sessionService.EXPECT().Get("xyz").Return(&model.Session{Token: "xyz", UserID: user.ID}, true)
+	userRepo.EXPECT().GetByID(user.ID).Return(user, nil)
+
+	handler := authMiddleware.RequireAuth(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer xyz")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+}
+
+func TestCSRF_CookieWithoutHeader(t *testing.T) {
+	handler := CSRF(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler should not be called")
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/docs/d1", nil)
+	req.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: "tok"})
+	req.AddCookie(&http.Cookie{Name: auth.CSRFCookieName, Value: "csrf"})
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d", rr.Code)
+	}
+}
+
+func TestCSRF_CookieWithHeader(t *testing.T) {
+	handler := CSRF(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/docs/d1", nil)
+	req.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: "tok"})
+	req.AddCookie(&http.Cookie{Name: auth.CSRFCookieName, Value: "csrf"})
+	req.Header.Set(auth.CSRFHeaderName, "csrf")
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+}
+
+func TestCSRF_SafeMethodAndBearer(t *testing.T) {
+	handler := CSRF(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	get := httptest.NewRequest(http.MethodGet, "/api/docs", nil)
+	get.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: "tok"})
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, get)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected GET to pass, got %d", rr.Code)
+	}
+
+	del := httptest.NewRequest(http.MethodDelete, "/api/docs/d1", nil)
+	del.Header.Set("Authorization", "Bearer tok")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, del)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected bearer-authenticated DELETE to pass, got %d", rr.Code)
+	}
+}
+
 func TestLoggingMiddleware(t *testing.T) {
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"astra-api/internal/compress"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// CompressMinSize is the smallest response body worth compressing: below it the
+// gzip header and the CPU time outweigh the saved bytes
+const CompressMinSize = 1024
+
+// encoder is a content coding the middleware can produce
+type encoder struct {
+	name  string
+	start func(w http.ResponseWriter) compressor
+}
+
+type compressor interface {
+	Write(p []byte) (int, error)
+	Flush() error
+	Close() error
+}
+
+// encoders are listed in order of preference: brotli compresses text better than
+// gzip, gzip remains for clients that don't accept br
+var encoders = []encoder{
+	{name: "br", start: func(w http.ResponseWriter) compressor { return brotli.NewWriter(w) }},
+	{name: compress.Gzip, start: func(w http.ResponseWriter) compressor { return gzip.NewWriter(w) }},
+}
+
+// Compress compresses responses for clients that accept it in Accept-Encoding.
+// Only compressible content types (see compress.Compressible) of at least
+// CompressMinSize bytes are compressed. Responses that already carry a
+// Content-Encoding (such as files stored compressed), partial content for Range
+// requests, 204 and 304 pass through unchanged. HEAD responses get the headers a
+// GET would get.
+func Compress(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cw := &compressWriter{ResponseWriter: w, status: http.StatusOK, head: r.Method == http.MethodHead}
+		// Ranges address the identity representation, so a 200 answer to a Range
+		// request is not compressed either
+		if r.Header.Get("Range") == "" {
+			cw.encoder = negotiate(r.Header.Get("Accept-Encoding"))
+		}
+		defer cw.finish()
+		next.ServeHTTP(cw, r)
+	}
+}
+
+func negotiate(acceptEncoding string) *encoder {
+	for i := range encoders {
+		if compress.Accepts(acceptEncoding, encoders[i].name) {
+			return &encoders[i]
+		}
+	}
+	return nil
+}
+
+// compressWriter buffers the beginning of the body until it knows whether the
+// response is large enough to compress, then commits the headers
+type compressWriter struct {
+	http.ResponseWriter
+	encoder   *encoder
+	head      bool
+	status    int
+	buf       []byte
+	committed bool
+	out       compressor
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.committed {
+		return
+	}
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		// Informational responses are sent immediately and don't end the headers
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	cw.status = code
+	if code == http.StatusSwitchingProtocols {
+		cw.commit(true)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.committed {
+		cw.buf = append(cw.buf, p...)
+		if len(cw.buf) < CompressMinSize {
+			return len(p), nil
+		}
+		if err := cw.commit(false); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if cw.out != nil {
+		return cw.out.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// Flush commits the headers: a handler that flushes is streaming and won't wait
+// for CompressMinSize bytes
+func (cw *compressWriter) Flush() {
+	if !cw.committed {
+		_ = cw.commit(false)
+	}
+	if cw.out != nil {
+		_ = cw.out.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *compressWriter) finish() {
+	if !cw.committed {
+		_ = cw.commit(true)
+	}
+	if cw.out != nil {
+		_ = cw.out.Close()
+	}
+}
+
+// commit decides on the coding, writes the headers and the buffered body. final
+// means the handler has returned and the buffer holds the whole body.
+func (cw *compressWriter) commit(final bool) error {
+	cw.committed = true
+	h := cw.Header()
+	if h.Get("Content-Type") == "" && len(cw.buf) > 0 && bodyAllowed(cw.status) {
+		// net/http would sniff the compressed bytes otherwise
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	eligible := bodyAllowed(cw.status) && cw.status != http.StatusPartialContent &&
+		h.Get("Content-Encoding") == "" && compress.Compressible(h.Get("Content-Type"))
+	if eligible {
+		// The response depends on Accept-Encoding even when it is sent as is
+		addVary(h, "Accept-Encoding")
+	}
+	size := int64(len(cw.buf))
+	if n, err := strconv.ParseInt(h.Get("Content-Length"), 10, 64); err == nil {
+		size = n
+	} else if !final {
+		size = CompressMinSize
+	}
+	if !eligible || cw.encoder == nil || size < CompressMinSize {
+		cw.ResponseWriter.WriteHeader(cw.status)
+		_, err := cw.ResponseWriter.Write(cw.buf)
+		cw.buf = nil
+		return err
+	}
+
+	h.Del("Content-Length")
+	h.Set("Content-Encoding", cw.encoder.name)
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		// The compressed body is a different sequence of bytes
+		h.Set("ETag", "W/"+etag)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if cw.head {
+		cw.buf = nil
+		return nil
+	}
+	cw.out = cw.encoder.start(cw.ResponseWriter)
+	_, err := cw.out.Write(cw.buf)
+	cw.buf = nil
+	return err
+}
+
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// addVary adds a field to Vary unless it is already listed
+func addVary(h http.Header, field string) {
+	for _, v := range h.Values("Vary") {
+		for _, f := range strings.Split(v, ",") {
+			if f = strings.TrimSpace(f); f == "*" || strings.EqualFold(f, field) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", field)
+}
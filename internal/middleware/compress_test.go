@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+func gunzip(t *testing.T, body []byte) string {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("body is not gzip: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("cannot decompress body: %v", err)
+	}
+	return string(data)
+}
+
+func unbrotli(t *testing.T, body []byte) string {
+	t.Helper()
+	data, err := io.ReadAll(brotli.NewReader(bytes.NewReader(body)))
+	if err != nil {
+		t.Fatalf("body is not brotli: %v", err)
+	}
+	return string(data)
+}
+
+func serveCompressed(handler http.HandlerFunc, method, acceptEncoding string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/docs", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rr := httptest.NewRecorder()
+	Compress(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCompress_Negotiation(t *testing.T) {
+	body := `{"data":[` + strings.Repeat(`{"id":"doc","name":"report.json"},`, 100) + `{}]}`
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// Several small writes, as json.Encoder does
+		for i := 0; i < len(body); i += 100 {
+			_, _ = io.WriteString(w, body[i:min(i+100, len(body))])
+		}
+	}
+
+	// brotli is preferred whatever order the client lists the codings in
+	for _, accept := range []string{"br, gzip", "gzip, br", "br"} {
+		rr := serveCompressed(handler, http.MethodGet, accept, nil)
+		if rr.Code != http.StatusOK || rr.Header().Get("Content-Encoding") != "br" || rr.Header().Get("Vary") != "Accept-Encoding" {
+			t.Fatalf("%q: expected brotli response, got %d %v", accept, rr.Code, rr.Header())
+		}
+		if got := unbrotli(t, rr.Body.Bytes()); got != body {
+			t.Fatalf("%q: unexpected body %q", accept, got)
+		}
+	}
+
+	rr := serveCompressed(handler, http.MethodGet, "br;q=0, gzip", nil)
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Encoding") != "gzip" || rr.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("expected gzip response, got %d %v", rr.Code, rr.Header())
+	}
+	if got := gunzip(t, rr.Body.Bytes()); got != body {
+		t.Fatalf("unexpected body %q", got)
+	}
+
+	for _, accept := range []string{"", "deflate", "gzip;q=0"} {
+		rr = serveCompressed(handler, http.MethodGet, accept, nil)
+		if rr.Header().Get("Content-Encoding") != "" || rr.Body.String() != body {
+			t.Fatalf("%q: expected identity response, got %v", accept, rr.Header())
+		}
+		if rr.Header().Get("Vary") != "Accept-Encoding" {
+			t.Fatalf("%q: expected Vary on a compressible response", accept)
+		}
+	}
+}
+
+func TestCompress_Skips(t *testing.T) {
+	large := strings.Repeat("a", 4*CompressMinSize)
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		body    string
+	}{
+		{"small body", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"response":{"id":true}}`)
+		}, `{"response":{"id":true}}`},
+		{"compressed type", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = io.WriteString(w, large)
+		}, large},
+		{"already encoded", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = io.WriteString(w, large)
+		}, large},
+		{"not modified", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusNotModified)
+		}, ""},
+	}
+	for _, c := range cases {
+		rr := serveCompressed(c.handler, http.MethodGet, "gzip", nil)
+		if rr.Body.String() != c.body {
+			t.Errorf("%s: unexpected body of %d bytes", c.name, rr.Body.Len())
+		}
+		if c.name != "already encoded" && rr.Header().Get("Content-Encoding") != "" {
+			t.Errorf("%s: expected no Content-Encoding, got %q", c.name, rr.Header().Get("Content-Encoding"))
+		}
+	}
+}
+
+func TestCompress_ServeContent(t *testing.T) {
+	content := strings.Repeat("line of text\n", 1000)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "log.txt", time.Time{}, strings.NewReader(content))
+	}
+
+	rr := serveCompressed(handler, http.MethodGet, "gzip", nil)
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Encoding") != "gzip" || rr.Header().Get("Content-Length") != "" {
+		t.Fatalf("expected gzip response without Content-Length, got %d %v", rr.Code, rr.Header())
+	}
+	if rr.Header().Get("ETag") != `W/"v1"` {
+		t.Fatalf("expected weak ETag, got %q", rr.Header().Get("ETag"))
+	}
+	if got := gunzip(t, rr.Body.Bytes()); got != content {
+		t.Fatal("unexpected body")
+	}
+
+	// HEAD gets the headers of GET without the body
+	rr = serveCompressed(handler, http.MethodHead, "gzip", nil)
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Encoding") != "gzip" || rr.Body.Len() != 0 {
+		t.Fatalf("unexpected HEAD response %d %v, body %d bytes", rr.Code, rr.Header(), rr.Body.Len())
+	}
+
+	// Ranges address the identity bytes, so the answer is not compressed
+	rr = serveCompressed(handler, http.MethodGet, "gzip", map[string]string{"Range": "bytes=13-25"})
+	if rr.Code != http.StatusPartialContent || rr.Header().Get("Content-Encoding") != "" || rr.Body.String() != content[13:26] {
+		t.Fatalf("unexpected range response %d %v %q", rr.Code, rr.Header(), rr.Body.String())
+	}
+}
+
+func TestCompress_SniffsContentType(t *testing.T) {
+	body := "<!DOCTYPE html><html><body>" + strings.Repeat("<p>text</p>", 200) + "</body></html>"
+	rr := serveCompressed(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}, http.MethodGet, "gzip", nil)
+	if rr.Header().Get("Content-Type") != "text/html; charset=utf-8" || rr.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected sniffed type of the uncompressed body, got %v", rr.Header())
+	}
+	if got := gunzip(t, rr.Body.Bytes()); got != body {
+		t.Fatal("unexpected body")
+	}
+}
+
+func TestCompress_Flush(t *testing.T) {
+	rr := serveCompressed(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = io.WriteString(w, "{\"n\":1}\n")
+		w.(http.Flusher).Flush()
+		_, _ = io.WriteString(w, "{\"n\":2}\n")
+	}, http.MethodGet, "gzip", nil)
+	if !rr.Flushed || rr.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a flushed gzip stream, got %v", rr.Header())
+	}
+	if got := gunzip(t, rr.Body.Bytes()); got != "{\"n\":1}\n{\"n\":2}\n" {
+		t.Fatalf("unexpected body %q", got)
+	}
+}
@@ -11,8 +11,10 @@ package mocks
 
 import (
 	model "astra-api/internal/model"
+	storage "astra-api/internal/storage"
 	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -69,6 +71,21 @@ func (mr *MockUserRepositoryInterfaceMockRecorder) CreateTx(tx, user any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTx", reflect.TypeOf((*MockUserRepositoryInterface)(nil).CreateTx), tx, user)
 }
 
+// DeleteDueTx mocks base method.
+func (m *MockUserRepositoryInterface) DeleteDueTx(tx *sql.Tx, id string, now time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDueTx", tx, id, now)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteDueTx indicates an expected call of DeleteDueTx.
+func (mr *MockUserRepositoryInterfaceMockRecorder) DeleteDueTx(tx, id, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDueTx", reflect.TypeOf((*MockUserRepositoryInterface)(nil).DeleteDueTx), tx, id, now)
+}
+
 // GetByID mocks base method.
 func (m *MockUserRepositoryInterface) GetByID(id string) (*model.User, error) {
 	m.ctrl.T.Helper()
@@ -99,6 +116,50 @@ func (mr *MockUserRepositoryInterfaceMockRecorder) GetByLogin(login any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByLogin", reflect.TypeOf((*MockUserRepositoryInterface)(nil).GetByLogin), login)
 }
 
+// ListDeletionDue mocks base method.
+func (m *MockUserRepositoryInterface) ListDeletionDue(now time.Time) ([]model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeletionDue", now)
+	ret0, _ := ret[0].([]model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeletionDue indicates an expected call of ListDeletionDue.
+func (mr *MockUserRepositoryInterfaceMockRecorder) ListDeletionDue(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletionDue", reflect.TypeOf((*MockUserRepositoryInterface)(nil).ListDeletionDue), now)
+}
+
+// LockDeletionDueTx mocks base method.
+func (m *MockUserRepositoryInterface) LockDeletionDueTx(tx *sql.Tx, id string, now time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockDeletionDueTx", tx, id, now)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockDeletionDueTx indicates an expected call of LockDeletionDueTx.
+func (mr *MockUserRepositoryInterfaceMockRecorder) LockDeletionDueTx(tx, id, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockDeletionDueTx", reflect.TypeOf((*MockUserRepositoryInterface)(nil).LockDeletionDueTx), tx, id, now)
+}
+
+// ScheduleDeletion mocks base method.
+func (m *MockUserRepositoryInterface) ScheduleDeletion(id string, at *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleDeletion", id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleDeletion indicates an expected call of ScheduleDeletion.
+func (mr *MockUserRepositoryInterfaceMockRecorder) ScheduleDeletion(id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleDeletion", reflect.TypeOf((*MockUserRepositoryInterface)(nil).ScheduleDeletion), id, at)
+}
+
 // MockDocumentRepositoryInterface is a mock of DocumentRepositoryInterface interface.
 type MockDocumentRepositoryInterface struct {
 	ctrl     *gomock.Controller
@@ -123,6 +184,36 @@ func (m *MockDocumentRepositoryInterface) EXPECT() *MockDocumentRepositoryInterf
 	return m.recorder
 }
 
+// CompleteUpload mocks base method.
+func (m *MockDocumentRepositoryInterface) CompleteUpload(doc *model.Document) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteUpload", doc)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteUpload indicates an expected call of CompleteUpload.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) CompleteUpload(doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteUpload", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).CompleteUpload), doc)
+}
+
+// CountTags mocks base method.
+func (m *MockDocumentRepositoryInterface) CountTags(userID string, principals []string, owned, shared bool) ([]model.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTags", userID, principals, owned, shared)
+	ret0, _ := ret[0].([]model.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTags indicates an expected call of CountTags.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) CountTags(userID, principals, owned, shared any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTags", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).CountTags), userID, principals, owned, shared)
+}
+
 // Create mocks base method.
 func (m *MockDocumentRepositoryInterface) Create(doc *model.Document) error {
 	m.ctrl.T.Helper()
@@ -179,6 +270,20 @@ func (mr *MockDocumentRepositoryInterfaceMockRecorder) DeleteTx(tx, id any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTx", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).DeleteTx), tx, id)
 }
 
+// ForEachByOwner mocks base method.
+func (m *MockDocumentRepositoryInterface) ForEachByOwner(owner string, fn func(*model.Document) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEachByOwner", owner, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachByOwner indicates an expected call of ForEachByOwner.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) ForEachByOwner(owner, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachByOwner", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).ForEachByOwner), owner, fn)
+}
+
 // GetByID mocks base method.
 func (m *MockDocumentRepositoryInterface) GetByID(id string) (*model.Document, error) {
 	m.ctrl.T.Helper()
@@ -194,17 +299,1564 @@ func (mr *MockDocumentRepositoryInterfaceMockRecorder) GetByID(id any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).GetByID), id)
 }
 
+// GetByIDTx mocks base method.
+func (m *MockDocumentRepositoryInterface) GetByIDTx(tx *sql.Tx, id string) (*model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDTx", tx, id)
+	ret0, _ := ret[0].(*model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDTx indicates an expected call of GetByIDTx.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) GetByIDTx(tx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDTx", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).GetByIDTx), tx, id)
+}
+
+// GetByPath mocks base method.
+func (m *MockDocumentRepositoryInterface) GetByPath(owner string, folderID *string, name string) (*model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByPath", owner, folderID, name)
+	ret0, _ := ret[0].(*model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByPath indicates an expected call of GetByPath.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) GetByPath(owner, folderID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPath", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).GetByPath), owner, folderID, name)
+}
+
+// GetTrashed mocks base method.
+func (m *MockDocumentRepositoryInterface) GetTrashed(id string) (*model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrashed", id)
+	ret0, _ := ret[0].(*model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrashed indicates an expected call of GetTrashed.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) GetTrashed(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrashed", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).GetTrashed), id)
+}
+
 // List mocks base method.
-func (m *MockDocumentRepositoryInterface) List(owner string, limit int) ([]model.Document, error) {
+func (m *MockDocumentRepositoryInterface) List(owner string, q model.ListQuery) ([]model.Document, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", owner, limit)
+	ret := m.ctrl.Call(m, "List", owner, q)
 	ret0, _ := ret[0].([]model.Document)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockDocumentRepositoryInterfaceMockRecorder) List(owner, limit any) *gomock.Call {
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) List(owner, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).List), owner, q)
+}
+
+// ListAccessible mocks base method.
+func (m *MockDocumentRepositoryInterface) ListAccessible(userID string, principals []string, includeOwned bool, q model.ListQuery) ([]model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccessible", userID, principals, includeOwned, q)
+	ret0, _ := ret[0].([]model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccessible indicates an expected call of ListAccessible.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) ListAccessible(userID, principals, includeOwned, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccessible", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).ListAccessible), userID, principals, includeOwned, q)
+}
+
+// ListExpired mocks base method.
+func (m *MockDocumentRepositoryInterface) ListExpired(now time.Time, limit int) ([]model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpired", now, limit)
+	ret0, _ := ret[0].([]model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpired indicates an expected call of ListExpired.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) ListExpired(now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpired", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).ListExpired), now, limit)
+}
+
+// ListLegacyFiles mocks base method.
+func (m *MockDocumentRepositoryInterface) ListLegacyFiles(after *model.Document, limit int) ([]model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLegacyFiles", after, limit)
+	ret0, _ := ret[0].([]model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLegacyFiles indicates an expected call of ListLegacyFiles.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) ListLegacyFiles(after, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLegacyFiles", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).ListLegacyFiles), after, limit)
+}
+
+// ListPendingScan mocks base method.
+func (m *MockDocumentRepositoryInterface) ListPendingScan(before time.Time, limit int) ([]model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingScan", before, limit)
+	ret0, _ := ret[0].([]model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingScan indicates an expected call of ListPendingScan.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) ListPendingScan(before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingScan", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).ListPendingScan), before, limit)
+}
+
+// ListTrash mocks base method.
+func (m *MockDocumentRepositoryInterface) ListTrash(owner string, limit int) ([]model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", owner, limit)
+	ret0, _ := ret[0].([]model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) ListTrash(owner, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).ListTrash), owner, limit)
+}
+
+// ListTrashedBefore mocks base method.
+func (m *MockDocumentRepositoryInterface) ListTrashedBefore(before time.Time, limit int) ([]model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrashedBefore", before, limit)
+	ret0, _ := ret[0].([]model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrashedBefore indicates an expected call of ListTrashedBefore.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) ListTrashedBefore(before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrashedBefore", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).ListTrashedBefore), before, limit)
+}
+
+// ListUnsized mocks base method.
+func (m *MockDocumentRepositoryInterface) ListUnsized(limit int) ([]model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUnsized", limit)
+	ret0, _ := ret[0].([]model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnsized indicates an expected call of ListUnsized.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) ListUnsized(limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnsized", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).ListUnsized), limit)
+}
+
+// ListVisible mocks base method.
+func (m *MockDocumentRepositoryInterface) ListVisible(owner string, principals []string, q model.ListQuery) ([]model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVisible", owner, principals, q)
+	ret0, _ := ret[0].([]model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVisible indicates an expected call of ListVisible.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) ListVisible(owner, principals, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVisible", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).ListVisible), owner, principals, q)
+}
+
+// MoveTx mocks base method.
+func (m *MockDocumentRepositoryInterface) MoveTx(tx *sql.Tx, id string, folderID *string, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveTx", tx, id, folderID, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveTx indicates an expected call of MoveTx.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) MoveTx(tx, id, folderID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveTx", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).MoveTx), tx, id, folderID, name)
+}
+
+// Restore mocks base method.
+func (m *MockDocumentRepositoryInterface) Restore(id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) Restore(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).Restore), id)
+}
+
+// Search mocks base method.
+func (m *MockDocumentRepositoryInterface) Search(userID string, principals []string, query string, limit int) ([]model.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", userID, principals, query, limit)
+	ret0, _ := ret[0].([]model.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) Search(userID, principals, query, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).Search), userID, principals, query, limit)
+}
+
+// SetScanResult mocks base method.
+func (m *MockDocumentRepositoryInterface) SetScanResult(id string, queuedAt time.Time, status string, signature *string, scannedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetScanResult", id, queuedAt, status, signature, scannedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetScanResult indicates an expected call of SetScanResult.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) SetScanResult(id, queuedAt, status, signature, scannedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetScanResult", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).SetScanResult), id, queuedAt, status, signature, scannedAt)
+}
+
+// SetSize mocks base method.
+func (m *MockDocumentRepositoryInterface) SetSize(id string, size int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSize", id, size)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSize indicates an expected call of SetSize.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) SetSize(id, size any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSize", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).SetSize), id, size)
+}
+
+// Trash mocks base method.
+func (m *MockDocumentRepositoryInterface) Trash(id string, at time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trash", id, at)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Trash indicates an expected call of Trash.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) Trash(id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trash", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).Trash), id, at)
+}
+
+// Update mocks base method.
+func (m *MockDocumentRepositoryInterface) Update(doc *model.Document) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", doc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) Update(doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).Update), doc)
+}
+
+// UpdateFile mocks base method.
+func (m *MockDocumentRepositoryInterface) UpdateFile(doc *model.Document) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFile", doc)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFile indicates an expected call of UpdateFile.
+func (mr *MockDocumentRepositoryInterfaceMockRecorder) UpdateFile(doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFile", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).UpdateFile), doc)
+}
+
+// MockRefreshTokenRepositoryInterface is a mock of RefreshTokenRepositoryInterface interface.
+type MockRefreshTokenRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefreshTokenRepositoryInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockRefreshTokenRepositoryInterfaceMockRecorder is the mock recorder for MockRefreshTokenRepositoryInterface.
+type MockRefreshTokenRepositoryInterfaceMockRecorder struct {
+	mock *MockRefreshTokenRepositoryInterface
+}
+
+// NewMockRefreshTokenRepositoryInterface creates a new mock instance.
+func NewMockRefreshTokenRepositoryInterface(ctrl *gomock.Controller) *MockRefreshTokenRepositoryInterface {
+	mock := &MockRefreshTokenRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockRefreshTokenRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefreshTokenRepositoryInterface) EXPECT() *MockRefreshTokenRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRefreshTokenRepositoryInterface) Create(token *model.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRefreshTokenRepositoryInterfaceMockRecorder) Create(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRefreshTokenRepositoryInterface)(nil).Create), token)
+}
+
+// GetByHash mocks base method.
+func (m *MockRefreshTokenRepositoryInterface) GetByHash(hash string) (*model.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", hash)
+	ret0, _ := ret[0].(*model.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash.
+func (mr *MockRefreshTokenRepositoryInterfaceMockRecorder) GetByHash(hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockRefreshTokenRepositoryInterface)(nil).GetByHash), hash)
+}
+
+// ListAccessRevocations mocks base method.
+func (m *MockRefreshTokenRepositoryInterface) ListAccessRevocations(now, since time.Time) ([]model.RevokedAccessToken, []model.AccessCutoff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccessRevocations", now, since)
+	ret0, _ := ret[0].([]model.RevokedAccessToken)
+	ret1, _ := ret[1].([]model.AccessCutoff)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListAccessRevocations indicates an expected call of ListAccessRevocations.
+func (mr *MockRefreshTokenRepositoryInterfaceMockRecorder) ListAccessRevocations(now, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccessRevocations", reflect.TypeOf((*MockRefreshTokenRepositoryInterface)(nil).ListAccessRevocations), now, since)
+}
+
+// MarkUsed mocks base method.
+func (m *MockRefreshTokenRepositoryInterface) MarkUsed(id string, at time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", id, at)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkUsed indicates an expected call of MarkUsed.
+func (mr *MockRefreshTokenRepositoryInterfaceMockRecorder) MarkUsed(id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockRefreshTokenRepositoryInterface)(nil).MarkUsed), id, at)
+}
+
+// RevokeAccess mocks base method.
+func (m *MockRefreshTokenRepositoryInterface) RevokeAccess(tokenID string, expiresAt, now time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccess", tokenID, expiresAt, now)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAccess indicates an expected call of RevokeAccess.
+func (mr *MockRefreshTokenRepositoryInterfaceMockRecorder) RevokeAccess(tokenID, expiresAt, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccess", reflect.TypeOf((*MockRefreshTokenRepositoryInterface)(nil).RevokeAccess), tokenID, expiresAt, now)
+}
+
+// RevokeAccessBefore mocks base method.
+func (m *MockRefreshTokenRepositoryInterface) RevokeAccessBefore(userID string, notBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessBefore", userID, notBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessBefore indicates an expected call of RevokeAccessBefore.
+func (mr *MockRefreshTokenRepositoryInterfaceMockRecorder) RevokeAccessBefore(userID, notBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessBefore", reflect.TypeOf((*MockRefreshTokenRepositoryInterface)(nil).RevokeAccessBefore), userID, notBefore)
+}
+
+// RevokeByUser mocks base method.
+func (m *MockRefreshTokenRepositoryInterface) RevokeByUser(userID string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeByUser", userID, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeByUser indicates an expected call of RevokeByUser.
+func (mr *MockRefreshTokenRepositoryInterfaceMockRecorder) RevokeByUser(userID, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeByUser", reflect.TypeOf((*MockRefreshTokenRepositoryInterface)(nil).RevokeByUser), userID, at)
+}
+
+// RevokeFamily mocks base method.
+func (m *MockRefreshTokenRepositoryInterface) RevokeFamily(familyID string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeFamily", familyID, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeFamily indicates an expected call of RevokeFamily.
+func (mr *MockRefreshTokenRepositoryInterfaceMockRecorder) RevokeFamily(familyID, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFamily", reflect.TypeOf((*MockRefreshTokenRepositoryInterface)(nil).RevokeFamily), familyID, at)
+}
+
+// MockAuditRepositoryInterface is a mock of AuditRepositoryInterface interface.
+type MockAuditRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockAuditRepositoryInterfaceMockRecorder is the mock recorder for MockAuditRepositoryInterface.
+type MockAuditRepositoryInterfaceMockRecorder struct {
+	mock *MockAuditRepositoryInterface
+}
+
+// NewMockAuditRepositoryInterface creates a new mock instance.
+func NewMockAuditRepositoryInterface(ctrl *gomock.Controller) *MockAuditRepositoryInterface {
+	mock := &MockAuditRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepositoryInterface) EXPECT() *MockAuditRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAuditRepositoryInterface) Create(entry *model.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAuditRepositoryInterfaceMockRecorder) Create(entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuditRepositoryInterface)(nil).Create), entry)
+}
+
+// List mocks base method.
+func (m *MockAuditRepositoryInterface) List(actorID string, limit int) ([]model.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", actorID, limit)
+	ret0, _ := ret[0].([]model.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditRepositoryInterfaceMockRecorder) List(actorID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditRepositoryInterface)(nil).List), actorID, limit)
+}
+
+// MockGroupRepositoryInterface is a mock of GroupRepositoryInterface interface.
+type MockGroupRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockGroupRepositoryInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockGroupRepositoryInterfaceMockRecorder is the mock recorder for MockGroupRepositoryInterface.
+type MockGroupRepositoryInterfaceMockRecorder struct {
+	mock *MockGroupRepositoryInterface
+}
+
+// NewMockGroupRepositoryInterface creates a new mock instance.
+func NewMockGroupRepositoryInterface(ctrl *gomock.Controller) *MockGroupRepositoryInterface {
+	mock := &MockGroupRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockGroupRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGroupRepositoryInterface) EXPECT() *MockGroupRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockGroupRepositoryInterface) Create(group *model.Group, ownerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", group, ownerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockGroupRepositoryInterfaceMockRecorder) Create(group, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockGroupRepositoryInterface)(nil).Create), group, ownerID)
+}
+
+// Delete mocks base method.
+func (m *MockGroupRepositoryInterface) Delete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockGroupRepositoryInterfaceMockRecorder) Delete(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockGroupRepositoryInterface)(nil).Delete), id)
+}
+
+// GetByID mocks base method.
+func (m *MockGroupRepositoryInterface) GetByID(id string) (*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", id)
+	ret0, _ := ret[0].(*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockGroupRepositoryInterfaceMockRecorder) GetByID(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockGroupRepositoryInterface)(nil).GetByID), id)
+}
+
+// GetByName mocks base method.
+func (m *MockGroupRepositoryInterface) GetByName(name string) (*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByName", name)
+	ret0, _ := ret[0].(*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByName indicates an expected call of GetByName.
+func (mr *MockGroupRepositoryInterfaceMockRecorder) GetByName(name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockGroupRepositoryInterface)(nil).GetByName), name)
+}
+
+// ListByUser mocks base method.
+func (m *MockGroupRepositoryInterface) ListByUser(userID string) ([]model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", userID)
+	ret0, _ := ret[0].([]model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockGroupRepositoryInterfaceMockRecorder) ListByUser(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockGroupRepositoryInterface)(nil).ListByUser), userID)
+}
+
+// ListMembers mocks base method.
+func (m *MockGroupRepositoryInterface) ListMembers(groupID string) ([]model.GroupMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", groupID)
+	ret0, _ := ret[0].([]model.GroupMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockGroupRepositoryInterfaceMockRecorder) ListMembers(groupID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockGroupRepositoryInterface)(nil).ListMembers), groupID)
+}
+
+// RemoveMember mocks base method.
+func (m *MockGroupRepositoryInterface) RemoveMember(groupID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", groupID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockGroupRepositoryInterfaceMockRecorder) RemoveMember(groupID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockGroupRepositoryInterface)(nil).RemoveMember), groupID, userID)
+}
+
+// SetMember mocks base method.
+func (m *MockGroupRepositoryInterface) SetMember(groupID, userID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMember", groupID, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMember indicates an expected call of SetMember.
+func (mr *MockGroupRepositoryInterfaceMockRecorder) SetMember(groupID, userID, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMember", reflect.TypeOf((*MockGroupRepositoryInterface)(nil).SetMember), groupID, userID, role)
+}
+
+// MockGrantRepositoryInterface is a mock of GrantRepositoryInterface interface.
+type MockGrantRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockGrantRepositoryInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockGrantRepositoryInterfaceMockRecorder is the mock recorder for MockGrantRepositoryInterface.
+type MockGrantRepositoryInterfaceMockRecorder struct {
+	mock *MockGrantRepositoryInterface
+}
+
+// NewMockGrantRepositoryInterface creates a new mock instance.
+func NewMockGrantRepositoryInterface(ctrl *gomock.Controller) *MockGrantRepositoryInterface {
+	mock := &MockGrantRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockGrantRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGrantRepositoryInterface) EXPECT() *MockGrantRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockGrantRepositoryInterface) Delete(documentID, principal string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", documentID, principal)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockGrantRepositoryInterfaceMockRecorder) Delete(documentID, principal any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockGrantRepositoryInterface)(nil).Delete), documentID, principal)
+}
+
+// DeleteFolder mocks base method.
+func (m *MockGrantRepositoryInterface) DeleteFolder(folderID, principal string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFolder", folderID, principal)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFolder indicates an expected call of DeleteFolder.
+func (mr *MockGrantRepositoryInterfaceMockRecorder) DeleteFolder(folderID, principal any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFolder", reflect.TypeOf((*MockGrantRepositoryInterface)(nil).DeleteFolder), folderID, principal)
+}
+
+// List mocks base method.
+func (m *MockGrantRepositoryInterface) List(documentID string) ([]model.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", documentID)
+	ret0, _ := ret[0].([]model.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockGrantRepositoryInterfaceMockRecorder) List(documentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockGrantRepositoryInterface)(nil).List), documentID)
+}
+
+// ListFolder mocks base method.
+func (m *MockGrantRepositoryInterface) ListFolder(folderID string) ([]model.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFolder", folderID)
+	ret0, _ := ret[0].([]model.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFolder indicates an expected call of ListFolder.
+func (mr *MockGrantRepositoryInterfaceMockRecorder) ListFolder(folderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFolder", reflect.TypeOf((*MockGrantRepositoryInterface)(nil).ListFolder), folderID)
+}
+
+// ListInherited mocks base method.
+func (m *MockGrantRepositoryInterface) ListInherited(folderID string) ([]model.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInherited", folderID)
+	ret0, _ := ret[0].([]model.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInherited indicates an expected call of ListInherited.
+func (mr *MockGrantRepositoryInterfaceMockRecorder) ListInherited(folderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInherited", reflect.TypeOf((*MockGrantRepositoryInterface)(nil).ListInherited), folderID)
+}
+
+// Set mocks base method.
+func (m *MockGrantRepositoryInterface) Set(grant *model.Grant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", grant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockGrantRepositoryInterfaceMockRecorder) Set(grant any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockGrantRepositoryInterface)(nil).Set), grant)
+}
+
+// SetFolder mocks base method.
+func (m *MockGrantRepositoryInterface) SetFolder(grant *model.Grant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFolder", grant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFolder indicates an expected call of SetFolder.
+func (mr *MockGrantRepositoryInterfaceMockRecorder) SetFolder(grant any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFolder", reflect.TypeOf((*MockGrantRepositoryInterface)(nil).SetFolder), grant)
+}
+
+// MockShareLinkRepositoryInterface is a mock of ShareLinkRepositoryInterface interface.
+type MockShareLinkRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockShareLinkRepositoryInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockShareLinkRepositoryInterfaceMockRecorder is the mock recorder for MockShareLinkRepositoryInterface.
+type MockShareLinkRepositoryInterfaceMockRecorder struct {
+	mock *MockShareLinkRepositoryInterface
+}
+
+// NewMockShareLinkRepositoryInterface creates a new mock instance.
+func NewMockShareLinkRepositoryInterface(ctrl *gomock.Controller) *MockShareLinkRepositoryInterface {
+	mock := &MockShareLinkRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockShareLinkRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShareLinkRepositoryInterface) EXPECT() *MockShareLinkRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CountDownload mocks base method.
+func (m *MockShareLinkRepositoryInterface) CountDownload(id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDownload", id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDownload indicates an expected call of CountDownload.
+func (mr *MockShareLinkRepositoryInterfaceMockRecorder) CountDownload(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDownload", reflect.TypeOf((*MockShareLinkRepositoryInterface)(nil).CountDownload), id)
+}
+
+// Create mocks base method.
+func (m *MockShareLinkRepositoryInterface) Create(link *model.ShareLink) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockShareLinkRepositoryInterfaceMockRecorder) Create(link any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockShareLinkRepositoryInterface)(nil).Create), link)
+}
+
+// GetByID mocks base method.
+func (m *MockShareLinkRepositoryInterface) GetByID(id string) (*model.ShareLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", id)
+	ret0, _ := ret[0].(*model.ShareLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockShareLinkRepositoryInterfaceMockRecorder) GetByID(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockShareLinkRepositoryInterface)(nil).GetByID), id)
+}
+
+// ListByDocument mocks base method.
+func (m *MockShareLinkRepositoryInterface) ListByDocument(documentID string) ([]model.ShareLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByDocument", documentID)
+	ret0, _ := ret[0].([]model.ShareLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByDocument indicates an expected call of ListByDocument.
+func (mr *MockShareLinkRepositoryInterfaceMockRecorder) ListByDocument(documentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByDocument", reflect.TypeOf((*MockShareLinkRepositoryInterface)(nil).ListByDocument), documentID)
+}
+
+// Revoke mocks base method.
+func (m *MockShareLinkRepositoryInterface) Revoke(id, documentID string, at time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", id, documentID, at)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockShareLinkRepositoryInterfaceMockRecorder) Revoke(id, documentID, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockShareLinkRepositoryInterface)(nil).Revoke), id, documentID, at)
+}
+
+// MockUploadReservationRepositoryInterface is a mock of UploadReservationRepositoryInterface interface.
+type MockUploadReservationRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockUploadReservationRepositoryInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockUploadReservationRepositoryInterfaceMockRecorder is the mock recorder for MockUploadReservationRepositoryInterface.
+type MockUploadReservationRepositoryInterfaceMockRecorder struct {
+	mock *MockUploadReservationRepositoryInterface
+}
+
+// NewMockUploadReservationRepositoryInterface creates a new mock instance.
+func NewMockUploadReservationRepositoryInterface(ctrl *gomock.Controller) *MockUploadReservationRepositoryInterface {
+	mock := &MockUploadReservationRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockUploadReservationRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUploadReservationRepositoryInterface) EXPECT() *MockUploadReservationRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CreateTx mocks base method.
+func (m *MockUploadReservationRepositoryInterface) CreateTx(tx *sql.Tx, res *model.UploadReservation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTx", tx, res)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTx indicates an expected call of CreateTx.
+func (mr *MockUploadReservationRepositoryInterfaceMockRecorder) CreateTx(tx, res any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTx", reflect.TypeOf((*MockUploadReservationRepositoryInterface)(nil).CreateTx), tx, res)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockUploadReservationRepositoryInterface) DeleteExpired(now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockUploadReservationRepositoryInterfaceMockRecorder) DeleteExpired(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockUploadReservationRepositoryInterface)(nil).DeleteExpired), now)
+}
+
+// Take mocks base method.
+func (m *MockUploadReservationRepositoryInterface) Take(id string, now time.Time) (*model.UploadReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Take", id, now)
+	ret0, _ := ret[0].(*model.UploadReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Take indicates an expected call of Take.
+func (mr *MockUploadReservationRepositoryInterfaceMockRecorder) Take(id, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Take", reflect.TypeOf((*MockUploadReservationRepositoryInterface)(nil).Take), id, now)
+}
+
+// MockSchemaRepositoryInterface is a mock of SchemaRepositoryInterface interface.
+type MockSchemaRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSchemaRepositoryInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockSchemaRepositoryInterfaceMockRecorder is the mock recorder for MockSchemaRepositoryInterface.
+type MockSchemaRepositoryInterfaceMockRecorder struct {
+	mock *MockSchemaRepositoryInterface
+}
+
+// NewMockSchemaRepositoryInterface creates a new mock instance.
+func NewMockSchemaRepositoryInterface(ctrl *gomock.Controller) *MockSchemaRepositoryInterface {
+	mock := &MockSchemaRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockSchemaRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSchemaRepositoryInterface) EXPECT() *MockSchemaRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// AttachTag mocks base method.
+func (m *MockSchemaRepositoryInterface) AttachTag(owner, tag, schemaID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachTag", owner, tag, schemaID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AttachTag indicates an expected call of AttachTag.
+func (mr *MockSchemaRepositoryInterfaceMockRecorder) AttachTag(owner, tag, schemaID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachTag", reflect.TypeOf((*MockSchemaRepositoryInterface)(nil).AttachTag), owner, tag, schemaID)
+}
+
+// Create mocks base method.
+func (m *MockSchemaRepositoryInterface) Create(schema *model.JSONSchema) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", schema)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSchemaRepositoryInterfaceMockRecorder) Create(schema any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSchemaRepositoryInterface)(nil).Create), schema)
+}
+
+// Delete mocks base method.
+func (m *MockSchemaRepositoryInterface) Delete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSchemaRepositoryInterfaceMockRecorder) Delete(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSchemaRepositoryInterface)(nil).Delete), id)
+}
+
+// DetachTag mocks base method.
+func (m *MockSchemaRepositoryInterface) DetachTag(owner, tag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetachTag", owner, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DetachTag indicates an expected call of DetachTag.
+func (mr *MockSchemaRepositoryInterfaceMockRecorder) DetachTag(owner, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachTag", reflect.TypeOf((*MockSchemaRepositoryInterface)(nil).DetachTag), owner, tag)
+}
+
+// ForEachDocument mocks base method.
+func (m *MockSchemaRepositoryInterface) ForEachDocument(schemaID string, fn func(*model.Document) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEachDocument", schemaID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachDocument indicates an expected call of ForEachDocument.
+func (mr *MockSchemaRepositoryInterfaceMockRecorder) ForEachDocument(schemaID, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachDocument", reflect.TypeOf((*MockSchemaRepositoryInterface)(nil).ForEachDocument), schemaID, fn)
+}
+
+// ForEachInFolder mocks base method.
+func (m *MockSchemaRepositoryInterface) ForEachInFolder(folderID string, fn func(*model.Document) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEachInFolder", folderID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachInFolder indicates an expected call of ForEachInFolder.
+func (mr *MockSchemaRepositoryInterfaceMockRecorder) ForEachInFolder(folderID, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachInFolder", reflect.TypeOf((*MockSchemaRepositoryInterface)(nil).ForEachInFolder), folderID, fn)
+}
+
+// ForEachTagged mocks base method.
+func (m *MockSchemaRepositoryInterface) ForEachTagged(owner, tag string, fn func(*model.Document) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEachTagged", owner, tag, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachTagged indicates an expected call of ForEachTagged.
+func (mr *MockSchemaRepositoryInterfaceMockRecorder) ForEachTagged(owner, tag, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachTagged", reflect.TypeOf((*MockSchemaRepositoryInterface)(nil).ForEachTagged), owner, tag, fn)
+}
+
+// GetByFolder mocks base method.
+func (m *MockSchemaRepositoryInterface) GetByFolder(folderID string) (*model.JSONSchema, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByFolder", folderID)
+	ret0, _ := ret[0].(*model.JSONSchema)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByFolder indicates an expected call of GetByFolder.
+func (mr *MockSchemaRepositoryInterfaceMockRecorder) GetByFolder(folderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByFolder", reflect.TypeOf((*MockSchemaRepositoryInterface)(nil).GetByFolder), folderID)
+}
+
+// GetByID mocks base method.
+func (m *MockSchemaRepositoryInterface) GetByID(id string) (*model.JSONSchema, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", id)
+	ret0, _ := ret[0].(*model.JSONSchema)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockSchemaRepositoryInterfaceMockRecorder) GetByID(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockSchemaRepositoryInterface)(nil).GetByID), id)
+}
+
+// GetByName mocks base method.
+func (m *MockSchemaRepositoryInterface) GetByName(owner, name string) (*model.JSONSchema, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByName", owner, name)
+	ret0, _ := ret[0].(*model.JSONSchema)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByName indicates an expected call of GetByName.
+func (mr *MockSchemaRepositoryInterfaceMockRecorder) GetByName(owner, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockSchemaRepositoryInterface)(nil).GetByName), owner, name)
+}
+
+// ListByOwner mocks base method.
+func (m *MockSchemaRepositoryInterface) ListByOwner(owner string) ([]model.JSONSchema, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByOwner", owner)
+	ret0, _ := ret[0].([]model.JSONSchema)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByOwner indicates an expected call of ListByOwner.
+func (mr *MockSchemaRepositoryInterfaceMockRecorder) ListByOwner(owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByOwner", reflect.TypeOf((*MockSchemaRepositoryInterface)(nil).ListByOwner), owner)
+}
+
+// ListByTags mocks base method.
+func (m *MockSchemaRepositoryInterface) ListByTags(owner string, tags []string) ([]model.JSONSchema, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByTags", owner, tags)
+	ret0, _ := ret[0].([]model.JSONSchema)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByTags indicates an expected call of ListByTags.
+func (mr *MockSchemaRepositoryInterfaceMockRecorder) ListByTags(owner, tags any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByTags", reflect.TypeOf((*MockSchemaRepositoryInterface)(nil).ListByTags), owner, tags)
+}
+
+// Update mocks base method.
+func (m *MockSchemaRepositoryInterface) Update(schema *model.JSONSchema) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", schema)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockSchemaRepositoryInterfaceMockRecorder) Update(schema any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSchemaRepositoryInterface)(nil).Update), schema)
+}
+
+// MockFolderRepositoryInterface is a mock of FolderRepositoryInterface interface.
+type MockFolderRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockFolderRepositoryInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockFolderRepositoryInterfaceMockRecorder is the mock recorder for MockFolderRepositoryInterface.
+type MockFolderRepositoryInterfaceMockRecorder struct {
+	mock *MockFolderRepositoryInterface
+}
+
+// NewMockFolderRepositoryInterface creates a new mock instance.
+func NewMockFolderRepositoryInterface(ctrl *gomock.Controller) *MockFolderRepositoryInterface {
+	mock := &MockFolderRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockFolderRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFolderRepositoryInterface) EXPECT() *MockFolderRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockFolderRepositoryInterface) Create(folder *model.Folder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", folder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockFolderRepositoryInterfaceMockRecorder) Create(folder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFolderRepositoryInterface)(nil).Create), folder)
+}
+
+// Delete mocks base method.
+func (m *MockFolderRepositoryInterface) Delete(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFolderRepositoryInterfaceMockRecorder) Delete(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFolderRepositoryInterface)(nil).Delete), id)
+}
+
+// GetByID mocks base method.
+func (m *MockFolderRepositoryInterface) GetByID(id string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", id)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockFolderRepositoryInterfaceMockRecorder) GetByID(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockFolderRepositoryInterface)(nil).GetByID), id)
+}
+
+// GetByIDTx mocks base method.
+func (m *MockFolderRepositoryInterface) GetByIDTx(tx *sql.Tx, id string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDTx", tx, id)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDTx indicates an expected call of GetByIDTx.
+func (mr *MockFolderRepositoryInterfaceMockRecorder) GetByIDTx(tx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDTx", reflect.TypeOf((*MockFolderRepositoryInterface)(nil).GetByIDTx), tx, id)
+}
+
+// GetByName mocks base method.
+func (m *MockFolderRepositoryInterface) GetByName(owner string, parentID *string, name string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByName", owner, parentID, name)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByName indicates an expected call of GetByName.
+func (mr *MockFolderRepositoryInterfaceMockRecorder) GetByName(owner, parentID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockFolderRepositoryInterface)(nil).GetByName), owner, parentID, name)
+}
+
+// IsEmpty mocks base method.
+func (m *MockFolderRepositoryInterface) IsEmpty(id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEmpty", id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsEmpty indicates an expected call of IsEmpty.
+func (mr *MockFolderRepositoryInterfaceMockRecorder) IsEmpty(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEmpty", reflect.TypeOf((*MockFolderRepositoryInterface)(nil).IsEmpty), id)
+}
+
+// ListChildren mocks base method.
+func (m *MockFolderRepositoryInterface) ListChildren(owner string, parentID *string) ([]model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChildren", owner, parentID)
+	ret0, _ := ret[0].([]model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChildren indicates an expected call of ListChildren.
+func (mr *MockFolderRepositoryInterfaceMockRecorder) ListChildren(owner, parentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChildren", reflect.TypeOf((*MockFolderRepositoryInterface)(nil).ListChildren), owner, parentID)
+}
+
+// ListDocuments mocks base method.
+func (m *MockFolderRepositoryInterface) ListDocuments(owner string, folderID *string) ([]model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDocuments", owner, folderID)
+	ret0, _ := ret[0].([]model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDocuments indicates an expected call of ListDocuments.
+func (mr *MockFolderRepositoryInterfaceMockRecorder) ListDocuments(owner, folderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDocuments", reflect.TypeOf((*MockFolderRepositoryInterface)(nil).ListDocuments), owner, folderID)
+}
+
+// ListShared mocks base method.
+func (m *MockFolderRepositoryInterface) ListShared(userID string, principals []string) ([]model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShared", userID, principals)
+	ret0, _ := ret[0].([]model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShared indicates an expected call of ListShared.
+func (mr *MockFolderRepositoryInterfaceMockRecorder) ListShared(userID, principals any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShared", reflect.TypeOf((*MockFolderRepositoryInterface)(nil).ListShared), userID, principals)
+}
+
+// MoveTx mocks base method.
+func (m *MockFolderRepositoryInterface) MoveTx(tx *sql.Tx, folder *model.Folder, parentID *string, path []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveTx", tx, folder, parentID, path)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveTx indicates an expected call of MoveTx.
+func (mr *MockFolderRepositoryInterfaceMockRecorder) MoveTx(tx, folder, parentID, path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveTx", reflect.TypeOf((*MockFolderRepositoryInterface)(nil).MoveTx), tx, folder, parentID, path)
+}
+
+// UpdateTx mocks base method.
+func (m *MockFolderRepositoryInterface) UpdateTx(tx *sql.Tx, folder *model.Folder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTx", tx, folder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTx indicates an expected call of UpdateTx.
+func (mr *MockFolderRepositoryInterfaceMockRecorder) UpdateTx(tx, folder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTx", reflect.TypeOf((*MockFolderRepositoryInterface)(nil).UpdateTx), tx, folder)
+}
+
+// MockQuotaRepositoryInterface is a mock of QuotaRepositoryInterface interface.
+type MockQuotaRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuotaRepositoryInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockQuotaRepositoryInterfaceMockRecorder is the mock recorder for MockQuotaRepositoryInterface.
+type MockQuotaRepositoryInterfaceMockRecorder struct {
+	mock *MockQuotaRepositoryInterface
+}
+
+// NewMockQuotaRepositoryInterface creates a new mock instance.
+func NewMockQuotaRepositoryInterface(ctrl *gomock.Controller) *MockQuotaRepositoryInterface {
+	mock := &MockQuotaRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockQuotaRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuotaRepositoryInterface) EXPECT() *MockQuotaRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// DeleteOverride mocks base method.
+func (m *MockQuotaRepositoryInterface) DeleteOverride(userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOverride", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOverride indicates an expected call of DeleteOverride.
+func (mr *MockQuotaRepositoryInterfaceMockRecorder) DeleteOverride(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOverride", reflect.TypeOf((*MockQuotaRepositoryInterface)(nil).DeleteOverride), userID)
+}
+
+// GetOverride mocks base method.
+func (m *MockQuotaRepositoryInterface) GetOverride(userID string) (*model.QuotaOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverride", userID)
+	ret0, _ := ret[0].(*model.QuotaOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverride indicates an expected call of GetOverride.
+func (mr *MockQuotaRepositoryInterfaceMockRecorder) GetOverride(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverride", reflect.TypeOf((*MockQuotaRepositoryInterface)(nil).GetOverride), userID)
+}
+
+// GetUsage mocks base method.
+func (m *MockQuotaRepositoryInterface) GetUsage(userID string) (*model.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsage", userID)
+	ret0, _ := ret[0].(*model.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsage indicates an expected call of GetUsage.
+func (mr *MockQuotaRepositoryInterfaceMockRecorder) GetUsage(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockQuotaRepositoryInterface)(nil).GetUsage), userID)
+}
+
+// SetDefaults mocks base method.
+func (m *MockQuotaRepositoryInterface) SetDefaults(limits model.QuotaLimits) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDefaults", limits)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDefaults indicates an expected call of SetDefaults.
+func (mr *MockQuotaRepositoryInterfaceMockRecorder) SetDefaults(limits any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaults", reflect.TypeOf((*MockQuotaRepositoryInterface)(nil).SetDefaults), limits)
+}
+
+// SetOverride mocks base method.
+func (m *MockQuotaRepositoryInterface) SetOverride(o *model.QuotaOverride) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOverride", o)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetOverride indicates an expected call of SetOverride.
+func (mr *MockQuotaRepositoryInterfaceMockRecorder) SetOverride(o any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOverride", reflect.TypeOf((*MockQuotaRepositoryInterface)(nil).SetOverride), o)
+}
+
+// MockBlobKeyRepositoryInterface is a mock of BlobKeyRepositoryInterface interface.
+type MockBlobKeyRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobKeyRepositoryInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockBlobKeyRepositoryInterfaceMockRecorder is the mock recorder for MockBlobKeyRepositoryInterface.
+type MockBlobKeyRepositoryInterfaceMockRecorder struct {
+	mock *MockBlobKeyRepositoryInterface
+}
+
+// NewMockBlobKeyRepositoryInterface creates a new mock instance.
+func NewMockBlobKeyRepositoryInterface(ctrl *gomock.Controller) *MockBlobKeyRepositoryInterface {
+	mock := &MockBlobKeyRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockBlobKeyRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlobKeyRepositoryInterface) EXPECT() *MockBlobKeyRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// DeleteKey mocks base method.
+func (m *MockBlobKeyRepositoryInterface) DeleteKey(blobKey, version string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteKey", blobKey, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteKey indicates an expected call of DeleteKey.
+func (mr *MockBlobKeyRepositoryInterfaceMockRecorder) DeleteKey(blobKey, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKey", reflect.TypeOf((*MockBlobKeyRepositoryInterface)(nil).DeleteKey), blobKey, version)
+}
+
+// DeleteKeys mocks base method.
+func (m *MockBlobKeyRepositoryInterface) DeleteKeys(blobKey, keep string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteKeys", blobKey, keep)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteKeys indicates an expected call of DeleteKeys.
+func (mr *MockBlobKeyRepositoryInterfaceMockRecorder) DeleteKeys(blobKey, keep any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKeys", reflect.TypeOf((*MockBlobKeyRepositoryInterface)(nil).DeleteKeys), blobKey, keep)
+}
+
+// GetKey mocks base method.
+func (m *MockBlobKeyRepositoryInterface) GetKey(blobKey, version string) (*storage.WrappedKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKey", blobKey, version)
+	ret0, _ := ret[0].(*storage.WrappedKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKey indicates an expected call of GetKey.
+func (mr *MockBlobKeyRepositoryInterfaceMockRecorder) GetKey(blobKey, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKey", reflect.TypeOf((*MockBlobKeyRepositoryInterface)(nil).GetKey), blobKey, version)
+}
+
+// ListStale mocks base method.
+func (m *MockBlobKeyRepositoryInterface) ListStale(masterKeyID string, limit int) ([]storage.WrappedKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStale", masterKeyID, limit)
+	ret0, _ := ret[0].([]storage.WrappedKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStale indicates an expected call of ListStale.
+func (mr *MockBlobKeyRepositoryInterfaceMockRecorder) ListStale(masterKeyID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStale", reflect.TypeOf((*MockBlobKeyRepositoryInterface)(nil).ListStale), masterKeyID, limit)
+}
+
+// PutKey mocks base method.
+func (m *MockBlobKeyRepositoryInterface) PutKey(k *storage.WrappedKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutKey", k)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutKey indicates an expected call of PutKey.
+func (mr *MockBlobKeyRepositoryInterfaceMockRecorder) PutKey(k any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutKey", reflect.TypeOf((*MockBlobKeyRepositoryInterface)(nil).PutKey), k)
+}
+
+// UpdateKey mocks base method.
+func (m *MockBlobKeyRepositoryInterface) UpdateKey(k *storage.WrappedKey, oldMasterKeyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateKey", k, oldMasterKeyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateKey indicates an expected call of UpdateKey.
+func (mr *MockBlobKeyRepositoryInterfaceMockRecorder) UpdateKey(k, oldMasterKeyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateKey", reflect.TypeOf((*MockBlobKeyRepositoryInterface)(nil).UpdateKey), k, oldMasterKeyID)
+}
+
+// MockTxManagerInterface is a mock of TxManagerInterface interface.
+type MockTxManagerInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxManagerInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockTxManagerInterfaceMockRecorder is the mock recorder for MockTxManagerInterface.
+type MockTxManagerInterfaceMockRecorder struct {
+	mock *MockTxManagerInterface
+}
+
+// NewMockTxManagerInterface creates a new mock instance.
+func NewMockTxManagerInterface(ctrl *gomock.Controller) *MockTxManagerInterface {
+	mock := &MockTxManagerInterface{ctrl: ctrl}
+	mock.recorder = &MockTxManagerInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxManagerInterface) EXPECT() *MockTxManagerInterfaceMockRecorder {
+	return m.recorder
+}
+
+// InTx mocks base method.
+func (m *MockTxManagerInterface) InTx(fn func(*sql.Tx) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InTx", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InTx indicates an expected call of InTx.
+func (mr *MockTxManagerInterfaceMockRecorder) InTx(fn any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDocumentRepositoryInterface)(nil).List), owner, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InTx", reflect.TypeOf((*MockTxManagerInterface)(nil).InTx), fn)
 }
@@ -10,8 +10,11 @@
 package mocks
 
 import (
+	compress "astra-api/internal/compress"
 	model "astra-api/internal/model"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -94,6 +97,21 @@ func (m *MockDocsServiceInterface) EXPECT() *MockDocsServiceInterfaceMockRecorde
 	return m.recorder
 }
 
+// CountTags mocks base method.
+func (m *MockDocsServiceInterface) CountTags(userID string, principals []string, owned, shared bool) ([]model.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTags", userID, principals, owned, shared)
+	ret0, _ := ret[0].([]model.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTags indicates an expected call of CountTags.
+func (mr *MockDocsServiceInterfaceMockRecorder) CountTags(userID, principals, owned, shared any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTags", reflect.TypeOf((*MockDocsServiceInterface)(nil).CountTags), userID, principals, owned, shared)
+}
+
 // Create mocks base method.
 func (m *MockDocsServiceInterface) Create(doc *model.Document) error {
 	m.ctrl.T.Helper()
@@ -138,98 +156,1646 @@ func (mr *MockDocsServiceInterfaceMockRecorder) GetByID(id any) *gomock.Call {
 }
 
 // List mocks base method.
-func (m *MockDocsServiceInterface) List(owner string, limit int) ([]model.Document, error) {
+func (m *MockDocsServiceInterface) List(owner string, q model.ListQuery) ([]model.Document, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", owner, limit)
+	ret := m.ctrl.Call(m, "List", owner, q)
 	ret0, _ := ret[0].([]model.Document)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockDocsServiceInterfaceMockRecorder) List(owner, limit any) *gomock.Call {
+func (mr *MockDocsServiceInterfaceMockRecorder) List(owner, q any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDocsServiceInterface)(nil).List), owner, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDocsServiceInterface)(nil).List), owner, q)
 }
 
-// MockSessionServiceInterface is a mock of SessionServiceInterface interface.
-type MockSessionServiceInterface struct {
+// ListAccessible mocks base method.
+func (m *MockDocsServiceInterface) ListAccessible(userID string, principals []string, includeOwned bool, q model.ListQuery) ([]model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccessible", userID, principals, includeOwned, q)
+	ret0, _ := ret[0].([]model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccessible indicates an expected call of ListAccessible.
+func (mr *MockDocsServiceInterfaceMockRecorder) ListAccessible(userID, principals, includeOwned, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccessible", reflect.TypeOf((*MockDocsServiceInterface)(nil).ListAccessible), userID, principals, includeOwned, q)
+}
+
+// ListVisible mocks base method.
+func (m *MockDocsServiceInterface) ListVisible(owner string, principals []string, q model.ListQuery) ([]model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVisible", owner, principals, q)
+	ret0, _ := ret[0].([]model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVisible indicates an expected call of ListVisible.
+func (mr *MockDocsServiceInterfaceMockRecorder) ListVisible(owner, principals, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVisible", reflect.TypeOf((*MockDocsServiceInterface)(nil).ListVisible), owner, principals, q)
+}
+
+// Search mocks base method.
+func (m *MockDocsServiceInterface) Search(userID string, principals []string, query string, limit int) ([]model.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", userID, principals, query, limit)
+	ret0, _ := ret[0].([]model.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockDocsServiceInterfaceMockRecorder) Search(userID, principals, query, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockDocsServiceInterface)(nil).Search), userID, principals, query, limit)
+}
+
+// Update mocks base method.
+func (m *MockDocsServiceInterface) Update(doc *model.Document) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", doc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDocsServiceInterfaceMockRecorder) Update(doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDocsServiceInterface)(nil).Update), doc)
+}
+
+// MockTrashServiceInterface is a mock of TrashServiceInterface interface.
+type MockTrashServiceInterface struct {
 	ctrl     *gomock.Controller
-	recorder *MockSessionServiceInterfaceMockRecorder
+	recorder *MockTrashServiceInterfaceMockRecorder
 	isgomock struct{}
 }
 
-// MockSessionServiceInterfaceMockRecorder is the mock recorder for MockSessionServiceInterface.
-type MockSessionServiceInterfaceMockRecorder struct {
-	mock *MockSessionServiceInterface
+// MockTrashServiceInterfaceMockRecorder is the mock recorder for MockTrashServiceInterface.
+type MockTrashServiceInterfaceMockRecorder struct {
+	mock *MockTrashServiceInterface
 }
 
-// NewMockSessionServiceInterface creates a new mock instance.
-func NewMockSessionServiceInterface(ctrl *gomock.Controller) *MockSessionServiceInterface {
-	mock := &MockSessionServiceInterface{ctrl: ctrl}
-	mock.recorder = &MockSessionServiceInterfaceMockRecorder{mock}
+// NewMockTrashServiceInterface creates a new mock instance.
+func NewMockTrashServiceInterface(ctrl *gomock.Controller) *MockTrashServiceInterface {
+	mock := &MockTrashServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockTrashServiceInterfaceMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockSessionServiceInterface) EXPECT() *MockSessionServiceInterfaceMockRecorder {
+func (m *MockTrashServiceInterface) EXPECT() *MockTrashServiceInterfaceMockRecorder {
 	return m.recorder
 }
 
-// Create mocks base method.
-func (m *MockSessionServiceInterface) Create(userID, login string) string {
+// List mocks base method.
+func (m *MockTrashServiceInterface) List(userID string, limit int) ([]model.TrashEntry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", userID, login)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "List", userID, limit)
+	ret0, _ := ret[0].([]model.TrashEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTrashServiceInterfaceMockRecorder) List(userID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTrashServiceInterface)(nil).List), userID, limit)
+}
+
+// Purge mocks base method.
+func (m *MockTrashServiceInterface) Purge(actorID, id string) (*model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", actorID, id)
+	ret0, _ := ret[0].(*model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockTrashServiceInterfaceMockRecorder) Purge(actorID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockTrashServiceInterface)(nil).Purge), actorID, id)
+}
+
+// PurgeExpired mocks base method.
+func (m *MockTrashServiceInterface) PurgeExpired(now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpired", now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpired indicates an expected call of PurgeExpired.
+func (mr *MockTrashServiceInterfaceMockRecorder) PurgeExpired(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpired", reflect.TypeOf((*MockTrashServiceInterface)(nil).PurgeExpired), now)
+}
+
+// Restore mocks base method.
+func (m *MockTrashServiceInterface) Restore(actorID, id string) (*model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", actorID, id)
+	ret0, _ := ret[0].(*model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockTrashServiceInterfaceMockRecorder) Restore(actorID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockTrashServiceInterface)(nil).Restore), actorID, id)
+}
+
+// MockReaperServiceInterface is a mock of ReaperServiceInterface interface.
+type MockReaperServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockReaperServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockReaperServiceInterfaceMockRecorder is the mock recorder for MockReaperServiceInterface.
+type MockReaperServiceInterfaceMockRecorder struct {
+	mock *MockReaperServiceInterface
+}
+
+// NewMockReaperServiceInterface creates a new mock instance.
+func NewMockReaperServiceInterface(ctrl *gomock.Controller) *MockReaperServiceInterface {
+	mock := &MockReaperServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockReaperServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReaperServiceInterface) EXPECT() *MockReaperServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Reap mocks base method.
+func (m *MockReaperServiceInterface) Reap(now time.Time) (model.ReapResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reap", now)
+	ret0, _ := ret[0].(model.ReapResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reap indicates an expected call of Reap.
+func (mr *MockReaperServiceInterfaceMockRecorder) Reap(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reap", reflect.TypeOf((*MockReaperServiceInterface)(nil).Reap), now)
+}
+
+// Stats mocks base method.
+func (m *MockReaperServiceInterface) Stats() model.ReaperStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(model.ReaperStats)
 	return ret0
 }
 
-// Create indicates an expected call of Create.
-func (mr *MockSessionServiceInterfaceMockRecorder) Create(userID, login any) *gomock.Call {
+// Stats indicates an expected call of Stats.
+func (mr *MockReaperServiceInterfaceMockRecorder) Stats() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSessionServiceInterface)(nil).Create), userID, login)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockReaperServiceInterface)(nil).Stats))
 }
 
-// Delete mocks base method.
-func (m *MockSessionServiceInterface) Delete(token string) bool {
+// MockQuotaServiceInterface is a mock of QuotaServiceInterface interface.
+type MockQuotaServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuotaServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockQuotaServiceInterfaceMockRecorder is the mock recorder for MockQuotaServiceInterface.
+type MockQuotaServiceInterfaceMockRecorder struct {
+	mock *MockQuotaServiceInterface
+}
+
+// NewMockQuotaServiceInterface creates a new mock instance.
+func NewMockQuotaServiceInterface(ctrl *gomock.Controller) *MockQuotaServiceInterface {
+	mock := &MockQuotaServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockQuotaServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuotaServiceInterface) EXPECT() *MockQuotaServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// BackfillSizes mocks base method.
+func (m *MockQuotaServiceInterface) BackfillSizes(batch int) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", token)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "BackfillSizes", batch)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackfillSizes indicates an expected call of BackfillSizes.
+func (mr *MockQuotaServiceInterfaceMockRecorder) BackfillSizes(batch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackfillSizes", reflect.TypeOf((*MockQuotaServiceInterface)(nil).BackfillSizes), batch)
+}
+
+// Check mocks base method.
+func (m *MockQuotaServiceInterface) Check(ownerID string, newDocs int, size, replaced int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ownerID, newDocs, size, replaced)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Delete indicates an expected call of Delete.
-func (mr *MockSessionServiceInterfaceMockRecorder) Delete(token any) *gomock.Call {
+// Check indicates an expected call of Check.
+func (mr *MockQuotaServiceInterfaceMockRecorder) Check(ownerID, newDocs, size, replaced any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSessionServiceInterface)(nil).Delete), token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockQuotaServiceInterface)(nil).Check), ownerID, newDocs, size, replaced)
 }
 
-// Get mocks base method.
-func (m *MockSessionServiceInterface) Get(token string) (*model.Session, bool) {
+// Reader mocks base method.
+func (m *MockQuotaServiceInterface) Reader(ownerID string, newDocs int, replaced int64, r io.Reader) (io.Reader, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", token)
-	ret0, _ := ret[0].(*model.Session)
-	ret1, _ := ret[1].(bool)
+	ret := m.ctrl.Call(m, "Reader", ownerID, newDocs, replaced, r)
+	ret0, _ := ret[0].(io.Reader)
+	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Get indicates an expected call of Get.
-func (mr *MockSessionServiceInterfaceMockRecorder) Get(token any) *gomock.Call {
+// Reader indicates an expected call of Reader.
+func (mr *MockQuotaServiceInterfaceMockRecorder) Reader(ownerID, newDocs, replaced, r any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSessionServiceInterface)(nil).Get), token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reader", reflect.TypeOf((*MockQuotaServiceInterface)(nil).Reader), ownerID, newDocs, replaced, r)
 }
 
-// Validate mocks base method.
-func (m *MockSessionServiceInterface) Validate(token string) (model.Session, bool) {
+// SaveDefaults mocks base method.
+func (m *MockQuotaServiceInterface) SaveDefaults() error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Validate", token)
-	ret0, _ := ret[0].(model.Session)
-	ret1, _ := ret[1].(bool)
+	ret := m.ctrl.Call(m, "SaveDefaults")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDefaults indicates an expected call of SaveDefaults.
+func (mr *MockQuotaServiceInterfaceMockRecorder) SaveDefaults() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDefaults", reflect.TypeOf((*MockQuotaServiceInterface)(nil).SaveDefaults))
+}
+
+// SetOverride mocks base method.
+func (m *MockQuotaServiceInterface) SetOverride(userID string, o *model.QuotaOverride) (*model.UsageReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOverride", userID, o)
+	ret0, _ := ret[0].(*model.UsageReport)
+	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Validate indicates an expected call of Validate.
-func (mr *MockSessionServiceInterfaceMockRecorder) Validate(token any) *gomock.Call {
+// SetOverride indicates an expected call of SetOverride.
+func (mr *MockQuotaServiceInterfaceMockRecorder) SetOverride(userID, o any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockSessionServiceInterface)(nil).Validate), token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOverride", reflect.TypeOf((*MockQuotaServiceInterface)(nil).SetOverride), userID, o)
+}
+
+// Usage mocks base method.
+func (m *MockQuotaServiceInterface) Usage(userID string) (*model.UsageReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Usage", userID)
+	ret0, _ := ret[0].(*model.UsageReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Usage indicates an expected call of Usage.
+func (mr *MockQuotaServiceInterfaceMockRecorder) Usage(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Usage", reflect.TypeOf((*MockQuotaServiceInterface)(nil).Usage), userID)
+}
+
+// MockMimeServiceInterface is a mock of MimeServiceInterface interface.
+type MockMimeServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockMimeServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockMimeServiceInterfaceMockRecorder is the mock recorder for MockMimeServiceInterface.
+type MockMimeServiceInterfaceMockRecorder struct {
+	mock *MockMimeServiceInterface
+}
+
+// NewMockMimeServiceInterface creates a new mock instance.
+func NewMockMimeServiceInterface(ctrl *gomock.Controller) *MockMimeServiceInterface {
+	mock := &MockMimeServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockMimeServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMimeServiceInterface) EXPECT() *MockMimeServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Inspect mocks base method.
+func (m *MockMimeServiceInterface) Inspect(doc *model.Document, body io.Reader) (io.Reader, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Inspect", doc, body)
+	ret0, _ := ret[0].(io.Reader)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Inspect indicates an expected call of Inspect.
+func (mr *MockMimeServiceInterfaceMockRecorder) Inspect(doc, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inspect", reflect.TypeOf((*MockMimeServiceInterface)(nil).Inspect), doc, body)
+}
+
+// MockCompressionServiceInterface is a mock of CompressionServiceInterface interface.
+type MockCompressionServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockCompressionServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockCompressionServiceInterfaceMockRecorder is the mock recorder for MockCompressionServiceInterface.
+type MockCompressionServiceInterfaceMockRecorder struct {
+	mock *MockCompressionServiceInterface
+}
+
+// NewMockCompressionServiceInterface creates a new mock instance.
+func NewMockCompressionServiceInterface(ctrl *gomock.Controller) *MockCompressionServiceInterface {
+	mock := &MockCompressionServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockCompressionServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCompressionServiceInterface) EXPECT() *MockCompressionServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Pack mocks base method.
+func (m *MockCompressionServiceInterface) Pack(doc *model.Document, body io.Reader) (*compress.Packed, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pack", doc, body)
+	ret0, _ := ret[0].(*compress.Packed)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pack indicates an expected call of Pack.
+func (mr *MockCompressionServiceInterfaceMockRecorder) Pack(doc, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pack", reflect.TypeOf((*MockCompressionServiceInterface)(nil).Pack), doc, body)
+}
+
+// MockScanServiceInterface is a mock of ScanServiceInterface interface.
+type MockScanServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockScanServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockScanServiceInterfaceMockRecorder is the mock recorder for MockScanServiceInterface.
+type MockScanServiceInterfaceMockRecorder struct {
+	mock *MockScanServiceInterface
+}
+
+// NewMockScanServiceInterface creates a new mock instance.
+func NewMockScanServiceInterface(ctrl *gomock.Controller) *MockScanServiceInterface {
+	mock := &MockScanServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockScanServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScanServiceInterface) EXPECT() *MockScanServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Queue mocks base method.
+func (m *MockScanServiceInterface) Queue(doc *model.Document) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Queue", doc)
+}
+
+// Queue indicates an expected call of Queue.
+func (mr *MockScanServiceInterfaceMockRecorder) Queue(doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Queue", reflect.TypeOf((*MockScanServiceInterface)(nil).Queue), doc)
+}
+
+// Scan mocks base method.
+func (m *MockScanServiceInterface) Scan(doc *model.Document) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Scan", doc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockScanServiceInterfaceMockRecorder) Scan(doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockScanServiceInterface)(nil).Scan), doc)
+}
+
+// ScanPending mocks base method.
+func (m *MockScanServiceInterface) ScanPending(before time.Time, batch int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanPending", before, batch)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanPending indicates an expected call of ScanPending.
+func (mr *MockScanServiceInterfaceMockRecorder) ScanPending(before, batch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanPending", reflect.TypeOf((*MockScanServiceInterface)(nil).ScanPending), before, batch)
+}
+
+// MockSessionServiceInterface is a mock of SessionServiceInterface interface.
+type MockSessionServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockSessionServiceInterfaceMockRecorder is the mock recorder for MockSessionServiceInterface.
+type MockSessionServiceInterfaceMockRecorder struct {
+	mock *MockSessionServiceInterface
+}
+
+// NewMockSessionServiceInterface creates a new mock instance.
+func NewMockSessionServiceInterface(ctrl *gomock.Controller) *MockSessionServiceInterface {
+	mock := &MockSessionServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockSessionServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionServiceInterface) EXPECT() *MockSessionServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockSessionServiceInterface) Create(userID, login string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", userID, login)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSessionServiceInterfaceMockRecorder) Create(userID, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSessionServiceInterface)(nil).Create), userID, login)
+}
+
+// CreateImpersonation mocks base method.
+func (m *MockSessionServiceInterface) CreateImpersonation(actor model.Session, target *model.User, ttl time.Duration) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImpersonation", actor, target, ttl)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// CreateImpersonation indicates an expected call of CreateImpersonation.
+func (mr *MockSessionServiceInterfaceMockRecorder) CreateImpersonation(actor, target, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImpersonation", reflect.TypeOf((*MockSessionServiceInterface)(nil).CreateImpersonation), actor, target, ttl)
+}
+
+// Delete mocks base method.
+func (m *MockSessionServiceInterface) Delete(token string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", token)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSessionServiceInterfaceMockRecorder) Delete(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSessionServiceInterface)(nil).Delete), token)
+}
+
+// DeleteByUser mocks base method.
+func (m *MockSessionServiceInterface) DeleteByUser(userID string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteByUser", userID)
+}
+
+// DeleteByUser indicates an expected call of DeleteByUser.
+func (mr *MockSessionServiceInterfaceMockRecorder) DeleteByUser(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUser", reflect.TypeOf((*MockSessionServiceInterface)(nil).DeleteByUser), userID)
+}
+
+// Get mocks base method.
+func (m *MockSessionServiceInterface) Get(token string) (*model.Session, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", token)
+	ret0, _ := ret[0].(*model.Session)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockSessionServiceInterfaceMockRecorder) Get(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSessionServiceInterface)(nil).Get), token)
+}
+
+// Validate mocks base method.
+func (m *MockSessionServiceInterface) Validate(token string) (model.Session, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", token)
+	ret0, _ := ret[0].(model.Session)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockSessionServiceInterfaceMockRecorder) Validate(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockSessionServiceInterface)(nil).Validate), token)
+}
+
+// MockTokenServiceInterface is a mock of TokenServiceInterface interface.
+type MockTokenServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockTokenServiceInterfaceMockRecorder is the mock recorder for MockTokenServiceInterface.
+type MockTokenServiceInterfaceMockRecorder struct {
+	mock *MockTokenServiceInterface
+}
+
+// NewMockTokenServiceInterface creates a new mock instance.
+func NewMockTokenServiceInterface(ctrl *gomock.Controller) *MockTokenServiceInterface {
+	mock := &MockTokenServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockTokenServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenServiceInterface) EXPECT() *MockTokenServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockTokenServiceInterface) Create(userID, login string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", userID, login)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTokenServiceInterfaceMockRecorder) Create(userID, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTokenServiceInterface)(nil).Create), userID, login)
+}
+
+// CreateImpersonation mocks base method.
+func (m *MockTokenServiceInterface) CreateImpersonation(actor model.Session, target *model.User, ttl time.Duration) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImpersonation", actor, target, ttl)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// CreateImpersonation indicates an expected call of CreateImpersonation.
+func (mr *MockTokenServiceInterfaceMockRecorder) CreateImpersonation(actor, target, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImpersonation", reflect.TypeOf((*MockTokenServiceInterface)(nil).CreateImpersonation), actor, target, ttl)
+}
+
+// CreatePair mocks base method.
+func (m *MockTokenServiceInterface) CreatePair(userID, login string) (*model.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePair", userID, login)
+	ret0, _ := ret[0].(*model.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePair indicates an expected call of CreatePair.
+func (mr *MockTokenServiceInterfaceMockRecorder) CreatePair(userID, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePair", reflect.TypeOf((*MockTokenServiceInterface)(nil).CreatePair), userID, login)
+}
+
+// Delete mocks base method.
+func (m *MockTokenServiceInterface) Delete(token string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", token)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTokenServiceInterfaceMockRecorder) Delete(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTokenServiceInterface)(nil).Delete), token)
+}
+
+// DeleteByUser mocks base method.
+func (m *MockTokenServiceInterface) DeleteByUser(userID string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteByUser", userID)
+}
+
+// DeleteByUser indicates an expected call of DeleteByUser.
+func (mr *MockTokenServiceInterfaceMockRecorder) DeleteByUser(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUser", reflect.TypeOf((*MockTokenServiceInterface)(nil).DeleteByUser), userID)
+}
+
+// Get mocks base method.
+func (m *MockTokenServiceInterface) Get(token string) (*model.Session, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", token)
+	ret0, _ := ret[0].(*model.Session)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockTokenServiceInterfaceMockRecorder) Get(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTokenServiceInterface)(nil).Get), token)
+}
+
+// Refresh mocks base method.
+func (m *MockTokenServiceInterface) Refresh(refreshToken string) (*model.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", refreshToken)
+	ret0, _ := ret[0].(*model.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockTokenServiceInterfaceMockRecorder) Refresh(refreshToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockTokenServiceInterface)(nil).Refresh), refreshToken)
+}
+
+// Validate mocks base method.
+func (m *MockTokenServiceInterface) Validate(token string) (model.Session, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", token)
+	ret0, _ := ret[0].(model.Session)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockTokenServiceInterfaceMockRecorder) Validate(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockTokenServiceInterface)(nil).Validate), token)
+}
+
+// MockAccountServiceInterface is a mock of AccountServiceInterface interface.
+type MockAccountServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockAccountServiceInterfaceMockRecorder is the mock recorder for MockAccountServiceInterface.
+type MockAccountServiceInterfaceMockRecorder struct {
+	mock *MockAccountServiceInterface
+}
+
+// NewMockAccountServiceInterface creates a new mock instance.
+func NewMockAccountServiceInterface(ctrl *gomock.Controller) *MockAccountServiceInterface {
+	mock := &MockAccountServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockAccountServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountServiceInterface) EXPECT() *MockAccountServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockAccountServiceInterface) Export(userID string, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", userID, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockAccountServiceInterfaceMockRecorder) Export(userID, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockAccountServiceInterface)(nil).Export), userID, w)
+}
+
+// Purge mocks base method.
+func (m *MockAccountServiceInterface) Purge(userID string, now time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", userID, now)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockAccountServiceInterfaceMockRecorder) Purge(userID, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockAccountServiceInterface)(nil).Purge), userID, now)
+}
+
+// PurgeDue mocks base method.
+func (m *MockAccountServiceInterface) PurgeDue(now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDue", now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDue indicates an expected call of PurgeDue.
+func (mr *MockAccountServiceInterfaceMockRecorder) PurgeDue(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDue", reflect.TypeOf((*MockAccountServiceInterface)(nil).PurgeDue), now)
+}
+
+// RequestDeletion mocks base method.
+func (m *MockAccountServiceInterface) RequestDeletion(userID, password string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestDeletion", userID, password)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestDeletion indicates an expected call of RequestDeletion.
+func (mr *MockAccountServiceInterfaceMockRecorder) RequestDeletion(userID, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestDeletion", reflect.TypeOf((*MockAccountServiceInterface)(nil).RequestDeletion), userID, password)
+}
+
+// MockGroupServiceInterface is a mock of GroupServiceInterface interface.
+type MockGroupServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockGroupServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockGroupServiceInterfaceMockRecorder is the mock recorder for MockGroupServiceInterface.
+type MockGroupServiceInterfaceMockRecorder struct {
+	mock *MockGroupServiceInterface
+}
+
+// NewMockGroupServiceInterface creates a new mock instance.
+func NewMockGroupServiceInterface(ctrl *gomock.Controller) *MockGroupServiceInterface {
+	mock := &MockGroupServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockGroupServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGroupServiceInterface) EXPECT() *MockGroupServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockGroupServiceInterface) Create(ownerID, name string) (*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ownerID, name)
+	ret0, _ := ret[0].(*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockGroupServiceInterfaceMockRecorder) Create(ownerID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockGroupServiceInterface)(nil).Create), ownerID, name)
+}
+
+// Delete mocks base method.
+func (m *MockGroupServiceInterface) Delete(actorID, groupID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", actorID, groupID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockGroupServiceInterfaceMockRecorder) Delete(actorID, groupID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockGroupServiceInterface)(nil).Delete), actorID, groupID)
+}
+
+// Get mocks base method.
+func (m *MockGroupServiceInterface) Get(actorID, groupID string) (*model.Group, []model.GroupMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", actorID, groupID)
+	ret0, _ := ret[0].(*model.Group)
+	ret1, _ := ret[1].([]model.GroupMember)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockGroupServiceInterfaceMockRecorder) Get(actorID, groupID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockGroupServiceInterface)(nil).Get), actorID, groupID)
+}
+
+// ListByUser mocks base method.
+func (m *MockGroupServiceInterface) ListByUser(userID string) ([]model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", userID)
+	ret0, _ := ret[0].([]model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockGroupServiceInterfaceMockRecorder) ListByUser(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockGroupServiceInterface)(nil).ListByUser), userID)
+}
+
+// RemoveMember mocks base method.
+func (m *MockGroupServiceInterface) RemoveMember(actorID, groupID, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", actorID, groupID, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockGroupServiceInterfaceMockRecorder) RemoveMember(actorID, groupID, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockGroupServiceInterface)(nil).RemoveMember), actorID, groupID, login)
+}
+
+// SetMember mocks base method.
+func (m *MockGroupServiceInterface) SetMember(actorID, groupID, login, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMember", actorID, groupID, login, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMember indicates an expected call of SetMember.
+func (mr *MockGroupServiceInterfaceMockRecorder) SetMember(actorID, groupID, login, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMember", reflect.TypeOf((*MockGroupServiceInterface)(nil).SetMember), actorID, groupID, login, role)
+}
+
+// MockAccessServiceInterface is a mock of AccessServiceInterface interface.
+type MockAccessServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccessServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockAccessServiceInterfaceMockRecorder is the mock recorder for MockAccessServiceInterface.
+type MockAccessServiceInterfaceMockRecorder struct {
+	mock *MockAccessServiceInterface
+}
+
+// NewMockAccessServiceInterface creates a new mock instance.
+func NewMockAccessServiceInterface(ctrl *gomock.Controller) *MockAccessServiceInterface {
+	mock := &MockAccessServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockAccessServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccessServiceInterface) EXPECT() *MockAccessServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Explain mocks base method.
+func (m *MockAccessServiceInterface) Explain(actorID string, doc *model.Document) ([]model.AccessEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Explain", actorID, doc)
+	ret0, _ := ret[0].([]model.AccessEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Explain indicates an expected call of Explain.
+func (mr *MockAccessServiceInterfaceMockRecorder) Explain(actorID, doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Explain", reflect.TypeOf((*MockAccessServiceInterface)(nil).Explain), actorID, doc)
+}
+
+// FolderPermission mocks base method.
+func (m *MockAccessServiceInterface) FolderPermission(userID string, folder *model.Folder) (model.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FolderPermission", userID, folder)
+	ret0, _ := ret[0].(model.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FolderPermission indicates an expected call of FolderPermission.
+func (mr *MockAccessServiceInterfaceMockRecorder) FolderPermission(userID, folder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FolderPermission", reflect.TypeOf((*MockAccessServiceInterface)(nil).FolderPermission), userID, folder)
+}
+
+// ListFolderGrants mocks base method.
+func (m *MockAccessServiceInterface) ListFolderGrants(actorID string, folder *model.Folder) ([]model.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFolderGrants", actorID, folder)
+	ret0, _ := ret[0].([]model.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFolderGrants indicates an expected call of ListFolderGrants.
+func (mr *MockAccessServiceInterfaceMockRecorder) ListFolderGrants(actorID, folder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFolderGrants", reflect.TypeOf((*MockAccessServiceInterface)(nil).ListFolderGrants), actorID, folder)
+}
+
+// ListGrants mocks base method.
+func (m *MockAccessServiceInterface) ListGrants(actorID string, doc *model.Document) ([]model.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGrants", actorID, doc)
+	ret0, _ := ret[0].([]model.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGrants indicates an expected call of ListGrants.
+func (mr *MockAccessServiceInterfaceMockRecorder) ListGrants(actorID, doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGrants", reflect.TypeOf((*MockAccessServiceInterface)(nil).ListGrants), actorID, doc)
+}
+
+// Permission mocks base method.
+func (m *MockAccessServiceInterface) Permission(userID string, doc *model.Document) (model.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Permission", userID, doc)
+	ret0, _ := ret[0].(model.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Permission indicates an expected call of Permission.
+func (mr *MockAccessServiceInterfaceMockRecorder) Permission(userID, doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Permission", reflect.TypeOf((*MockAccessServiceInterface)(nil).Permission), userID, doc)
+}
+
+// PermissionExpiry mocks base method.
+func (m *MockAccessServiceInterface) PermissionExpiry(userID string, doc *model.Document, perm model.Permission) (*time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PermissionExpiry", userID, doc, perm)
+	ret0, _ := ret[0].(*time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PermissionExpiry indicates an expected call of PermissionExpiry.
+func (mr *MockAccessServiceInterfaceMockRecorder) PermissionExpiry(userID, doc, perm any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PermissionExpiry", reflect.TypeOf((*MockAccessServiceInterface)(nil).PermissionExpiry), userID, doc, perm)
+}
+
+// Principals mocks base method.
+func (m *MockAccessServiceInterface) Principals(userID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Principals", userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Principals indicates an expected call of Principals.
+func (mr *MockAccessServiceInterfaceMockRecorder) Principals(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Principals", reflect.TypeOf((*MockAccessServiceInterface)(nil).Principals), userID)
+}
+
+// ResolveGrants mocks base method.
+func (m *MockAccessServiceInterface) ResolveGrants(principals []string) ([]model.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveGrants", principals)
+	ret0, _ := ret[0].([]model.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveGrants indicates an expected call of ResolveGrants.
+func (mr *MockAccessServiceInterfaceMockRecorder) ResolveGrants(principals any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveGrants", reflect.TypeOf((*MockAccessServiceInterface)(nil).ResolveGrants), principals)
+}
+
+// RevokeFolderGrant mocks base method.
+func (m *MockAccessServiceInterface) RevokeFolderGrant(actorID string, folder *model.Folder, principal string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeFolderGrant", actorID, folder, principal)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeFolderGrant indicates an expected call of RevokeFolderGrant.
+func (mr *MockAccessServiceInterfaceMockRecorder) RevokeFolderGrant(actorID, folder, principal any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFolderGrant", reflect.TypeOf((*MockAccessServiceInterface)(nil).RevokeFolderGrant), actorID, folder, principal)
+}
+
+// RevokeGrant mocks base method.
+func (m *MockAccessServiceInterface) RevokeGrant(actorID string, doc *model.Document, principal string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeGrant", actorID, doc, principal)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeGrant indicates an expected call of RevokeGrant.
+func (mr *MockAccessServiceInterfaceMockRecorder) RevokeGrant(actorID, doc, principal any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeGrant", reflect.TypeOf((*MockAccessServiceInterface)(nil).RevokeGrant), actorID, doc, principal)
+}
+
+// SetFolderGrant mocks base method.
+func (m *MockAccessServiceInterface) SetFolderGrant(actorID string, folder *model.Folder, req model.GrantRequest) (*model.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFolderGrant", actorID, folder, req)
+	ret0, _ := ret[0].(*model.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFolderGrant indicates an expected call of SetFolderGrant.
+func (mr *MockAccessServiceInterfaceMockRecorder) SetFolderGrant(actorID, folder, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFolderGrant", reflect.TypeOf((*MockAccessServiceInterface)(nil).SetFolderGrant), actorID, folder, req)
+}
+
+// SetGrant mocks base method.
+func (m *MockAccessServiceInterface) SetGrant(actorID string, doc *model.Document, req model.GrantRequest) (*model.Grant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetGrant", actorID, doc, req)
+	ret0, _ := ret[0].(*model.Grant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetGrant indicates an expected call of SetGrant.
+func (mr *MockAccessServiceInterfaceMockRecorder) SetGrant(actorID, doc, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGrant", reflect.TypeOf((*MockAccessServiceInterface)(nil).SetGrant), actorID, doc, req)
+}
+
+// MockLinkServiceInterface is a mock of LinkServiceInterface interface.
+type MockLinkServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockLinkServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockLinkServiceInterfaceMockRecorder is the mock recorder for MockLinkServiceInterface.
+type MockLinkServiceInterfaceMockRecorder struct {
+	mock *MockLinkServiceInterface
+}
+
+// NewMockLinkServiceInterface creates a new mock instance.
+func NewMockLinkServiceInterface(ctrl *gomock.Controller) *MockLinkServiceInterface {
+	mock := &MockLinkServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockLinkServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLinkServiceInterface) EXPECT() *MockLinkServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CountDownload mocks base method.
+func (m *MockLinkServiceInterface) CountDownload(link *model.ShareLink) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDownload", link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CountDownload indicates an expected call of CountDownload.
+func (mr *MockLinkServiceInterfaceMockRecorder) CountDownload(link any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDownload", reflect.TypeOf((*MockLinkServiceInterface)(nil).CountDownload), link)
+}
+
+// Create mocks base method.
+func (m *MockLinkServiceInterface) Create(actorID string, doc *model.Document, req model.CreateLinkRequest) (*model.ShareLink, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", actorID, doc, req)
+	ret0, _ := ret[0].(*model.ShareLink)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockLinkServiceInterfaceMockRecorder) Create(actorID, doc, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockLinkServiceInterface)(nil).Create), actorID, doc, req)
+}
+
+// List mocks base method.
+func (m *MockLinkServiceInterface) List(actorID string, doc *model.Document) ([]model.ShareLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", actorID, doc)
+	ret0, _ := ret[0].([]model.ShareLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockLinkServiceInterfaceMockRecorder) List(actorID, doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockLinkServiceInterface)(nil).List), actorID, doc)
+}
+
+// Resolve mocks base method.
+func (m *MockLinkServiceInterface) Resolve(token, password string) (*model.ShareLink, *model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", token, password)
+	ret0, _ := ret[0].(*model.ShareLink)
+	ret1, _ := ret[1].(*model.Document)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockLinkServiceInterfaceMockRecorder) Resolve(token, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockLinkServiceInterface)(nil).Resolve), token, password)
+}
+
+// Revoke mocks base method.
+func (m *MockLinkServiceInterface) Revoke(actorID string, doc *model.Document, linkID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", actorID, doc, linkID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockLinkServiceInterfaceMockRecorder) Revoke(actorID, doc, linkID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockLinkServiceInterface)(nil).Revoke), actorID, doc, linkID)
+}
+
+// MockUploadServiceInterface is a mock of UploadServiceInterface interface.
+type MockUploadServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockUploadServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockUploadServiceInterfaceMockRecorder is the mock recorder for MockUploadServiceInterface.
+type MockUploadServiceInterfaceMockRecorder struct {
+	mock *MockUploadServiceInterface
+}
+
+// NewMockUploadServiceInterface creates a new mock instance.
+func NewMockUploadServiceInterface(ctrl *gomock.Controller) *MockUploadServiceInterface {
+	mock := &MockUploadServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockUploadServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUploadServiceInterface) EXPECT() *MockUploadServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Complete mocks base method.
+func (m *MockUploadServiceInterface) Complete(token string, body io.Reader, contentType string) (*model.Document, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", token, body, contentType)
+	ret0, _ := ret[0].(*model.Document)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockUploadServiceInterfaceMockRecorder) Complete(token, body, contentType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockUploadServiceInterface)(nil).Complete), token, body, contentType)
+}
+
+// PurgeExpired mocks base method.
+func (m *MockUploadServiceInterface) PurgeExpired(now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpired", now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpired indicates an expected call of PurgeExpired.
+func (mr *MockUploadServiceInterfaceMockRecorder) PurgeExpired(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpired", reflect.TypeOf((*MockUploadServiceInterface)(nil).PurgeExpired), now)
+}
+
+// Reserve mocks base method.
+func (m *MockUploadServiceInterface) Reserve(doc *model.Document, size *int64) (*model.UploadReservation, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", doc, size)
+	ret0, _ := ret[0].(*model.UploadReservation)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockUploadServiceInterfaceMockRecorder) Reserve(doc, size any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockUploadServiceInterface)(nil).Reserve), doc, size)
+}
+
+// MockFolderServiceInterface is a mock of FolderServiceInterface interface.
+type MockFolderServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockFolderServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockFolderServiceInterfaceMockRecorder is the mock recorder for MockFolderServiceInterface.
+type MockFolderServiceInterfaceMockRecorder struct {
+	mock *MockFolderServiceInterface
+}
+
+// NewMockFolderServiceInterface creates a new mock instance.
+func NewMockFolderServiceInterface(ctrl *gomock.Controller) *MockFolderServiceInterface {
+	mock := &MockFolderServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockFolderServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFolderServiceInterface) EXPECT() *MockFolderServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockFolderServiceInterface) Create(actorID string, req model.CreateFolderRequest) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", actorID, req)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockFolderServiceInterfaceMockRecorder) Create(actorID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFolderServiceInterface)(nil).Create), actorID, req)
+}
+
+// Delete mocks base method.
+func (m *MockFolderServiceInterface) Delete(actorID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", actorID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFolderServiceInterfaceMockRecorder) Delete(actorID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFolderServiceInterface)(nil).Delete), actorID, id)
+}
+
+// Get mocks base method.
+func (m *MockFolderServiceInterface) Get(actorID, id string) (*model.FolderContents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", actorID, id)
+	ret0, _ := ret[0].(*model.FolderContents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockFolderServiceInterfaceMockRecorder) Get(actorID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockFolderServiceInterface)(nil).Get), actorID, id)
+}
+
+// List mocks base method.
+func (m *MockFolderServiceInterface) List(userID string) ([]model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", userID)
+	ret0, _ := ret[0].([]model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockFolderServiceInterfaceMockRecorder) List(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFolderServiceInterface)(nil).List), userID)
+}
+
+// Lookup mocks base method.
+func (m *MockFolderServiceInterface) Lookup(actorID, id string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lookup", actorID, id)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lookup indicates an expected call of Lookup.
+func (mr *MockFolderServiceInterfaceMockRecorder) Lookup(actorID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lookup", reflect.TypeOf((*MockFolderServiceInterface)(nil).Lookup), actorID, id)
+}
+
+// MoveDocuments mocks base method.
+func (m *MockFolderServiceInterface) MoveDocuments(actorID string, ids []string, folderID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveDocuments", actorID, ids, folderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveDocuments indicates an expected call of MoveDocuments.
+func (mr *MockFolderServiceInterfaceMockRecorder) MoveDocuments(actorID, ids, folderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveDocuments", reflect.TypeOf((*MockFolderServiceInterface)(nil).MoveDocuments), actorID, ids, folderID)
+}
+
+// Place mocks base method.
+func (m *MockFolderServiceInterface) Place(actorID string, doc *model.Document, folderID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Place", actorID, doc, folderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Place indicates an expected call of Place.
+func (mr *MockFolderServiceInterfaceMockRecorder) Place(actorID, doc, folderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Place", reflect.TypeOf((*MockFolderServiceInterface)(nil).Place), actorID, doc, folderID)
+}
+
+// Update mocks base method.
+func (m *MockFolderServiceInterface) Update(actorID, id string, req model.UpdateFolderRequest) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", actorID, id, req)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockFolderServiceInterfaceMockRecorder) Update(actorID, id, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockFolderServiceInterface)(nil).Update), actorID, id, req)
+}
+
+// MockFSServiceInterface is a mock of FSServiceInterface interface.
+type MockFSServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockFSServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockFSServiceInterfaceMockRecorder is the mock recorder for MockFSServiceInterface.
+type MockFSServiceInterfaceMockRecorder struct {
+	mock *MockFSServiceInterface
+}
+
+// NewMockFSServiceInterface creates a new mock instance.
+func NewMockFSServiceInterface(ctrl *gomock.Controller) *MockFSServiceInterface {
+	mock := &MockFSServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockFSServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFSServiceInterface) EXPECT() *MockFSServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockFSServiceInterface) Delete(actorID, login, path string) (*model.FSNode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", actorID, login, path)
+	ret0, _ := ret[0].(*model.FSNode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFSServiceInterfaceMockRecorder) Delete(actorID, login, path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFSServiceInterface)(nil).Delete), actorID, login, path)
+}
+
+// Get mocks base method.
+func (m *MockFSServiceInterface) Get(actorID, login, path string) (*model.FSNode, *model.FSListing, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", actorID, login, path)
+	ret0, _ := ret[0].(*model.FSNode)
+	ret1, _ := ret[1].(*model.FSListing)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockFSServiceInterfaceMockRecorder) Get(actorID, login, path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockFSServiceInterface)(nil).Get), actorID, login, path)
+}
+
+// Move mocks base method.
+func (m *MockFSServiceInterface) Move(actorID, login, from, to string) (*model.FSNode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Move", actorID, login, from, to)
+	ret0, _ := ret[0].(*model.FSNode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Move indicates an expected call of Move.
+func (mr *MockFSServiceInterfaceMockRecorder) Move(actorID, login, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Move", reflect.TypeOf((*MockFSServiceInterface)(nil).Move), actorID, login, from, to)
+}
+
+// Put mocks base method.
+func (m *MockFSServiceInterface) Put(actorID, login, path string, content model.FSContent, body io.Reader) (*model.Document, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", actorID, login, path, content, body)
+	ret0, _ := ret[0].(*model.Document)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockFSServiceInterfaceMockRecorder) Put(actorID, login, path, content, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockFSServiceInterface)(nil).Put), actorID, login, path, content, body)
+}
+
+// MockSchemaServiceInterface is a mock of SchemaServiceInterface interface.
+type MockSchemaServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSchemaServiceInterfaceMockRecorder
+	isgomock struct{}
+}
+
+// MockSchemaServiceInterfaceMockRecorder is the mock recorder for MockSchemaServiceInterface.
+type MockSchemaServiceInterfaceMockRecorder struct {
+	mock *MockSchemaServiceInterface
+}
+
+// NewMockSchemaServiceInterface creates a new mock instance.
+func NewMockSchemaServiceInterface(ctrl *gomock.Controller) *MockSchemaServiceInterface {
+	mock := &MockSchemaServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockSchemaServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSchemaServiceInterface) EXPECT() *MockSchemaServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Attach mocks base method.
+func (m *MockSchemaServiceInterface) Attach(doc *model.Document, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Attach", doc, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Attach indicates an expected call of Attach.
+func (mr *MockSchemaServiceInterfaceMockRecorder) Attach(doc, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Attach", reflect.TypeOf((*MockSchemaServiceInterface)(nil).Attach), doc, name)
+}
+
+// AttachFolder mocks base method.
+func (m *MockSchemaServiceInterface) AttachFolder(folder *model.Folder, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachFolder", folder, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AttachFolder indicates an expected call of AttachFolder.
+func (mr *MockSchemaServiceInterfaceMockRecorder) AttachFolder(folder, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachFolder", reflect.TypeOf((*MockSchemaServiceInterface)(nil).AttachFolder), folder, name)
+}
+
+// AttachTag mocks base method.
+func (m *MockSchemaServiceInterface) AttachTag(ownerID, tag, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachTag", ownerID, tag, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AttachTag indicates an expected call of AttachTag.
+func (mr *MockSchemaServiceInterfaceMockRecorder) AttachTag(ownerID, tag, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachTag", reflect.TypeOf((*MockSchemaServiceInterface)(nil).AttachTag), ownerID, tag, name)
+}
+
+// Delete mocks base method.
+func (m *MockSchemaServiceInterface) Delete(ownerID, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ownerID, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSchemaServiceInterfaceMockRecorder) Delete(ownerID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSchemaServiceInterface)(nil).Delete), ownerID, name)
+}
+
+// Get mocks base method.
+func (m *MockSchemaServiceInterface) Get(ownerID, name string) (*model.JSONSchema, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ownerID, name)
+	ret0, _ := ret[0].(*model.JSONSchema)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockSchemaServiceInterfaceMockRecorder) Get(ownerID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSchemaServiceInterface)(nil).Get), ownerID, name)
+}
+
+// List mocks base method.
+func (m *MockSchemaServiceInterface) List(ownerID string) ([]model.JSONSchema, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ownerID)
+	ret0, _ := ret[0].([]model.JSONSchema)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockSchemaServiceInterfaceMockRecorder) List(ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSchemaServiceInterface)(nil).List), ownerID)
+}
+
+// Put mocks base method.
+func (m *MockSchemaServiceInterface) Put(ownerID, name string, raw []byte) (*model.JSONSchema, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ownerID, name, raw)
+	ret0, _ := ret[0].(*model.JSONSchema)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockSchemaServiceInterfaceMockRecorder) Put(ownerID, name, raw any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockSchemaServiceInterface)(nil).Put), ownerID, name, raw)
+}
+
+// Validate mocks base method.
+func (m *MockSchemaServiceInterface) Validate(doc *model.Document) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", doc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockSchemaServiceInterfaceMockRecorder) Validate(doc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockSchemaServiceInterface)(nil).Validate), doc)
 }
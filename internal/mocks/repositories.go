@@ -2,14 +2,20 @@ package mocks
 
 import (
 	"astra-api/internal/model"
+	"astra-api/internal/storage"
 	"database/sql"
+	"time"
 )
 
 type UserRepositoryMock struct {
-	CreateFunc     func(user *model.User) error
-	CreateTxFunc   func(tx *sql.Tx, user *model.User) error
-	GetByLoginFunc func(login string) (*model.User, error)
-	GetByIDFunc    func(id string) (*model.User, error)
+	CreateFunc            func(user *model.User) error
+	CreateTxFunc          func(tx *sql.Tx, user *model.User) error
+	GetByLoginFunc        func(login string) (*model.User, error)
+	GetByIDFunc           func(id string) (*model.User, error)
+	ScheduleDeletionFunc  func(id string, at *time.Time) error
+	ListDeletionDueFunc   func(now time.Time) ([]model.User, error)
+	LockDeletionDueTxFunc func(tx *sql.Tx, id string, now time.Time) (bool, error)
+	DeleteDueTxFunc       func(tx *sql.Tx, id string, now time.Time) (bool, error)
 }
 
 func (m *UserRepositoryMock) Create(user *model.User) error { return m.CreateFunc(user) }
@@ -20,25 +26,408 @@ func (m *UserRepositoryMock) GetByLogin(login string) (*model.User, error) {
 	return m.GetByLoginFunc(login)
 }
 func (m *UserRepositoryMock) GetByID(id string) (*model.User, error) { return m.GetByIDFunc(id) }
+func (m *UserRepositoryMock) ScheduleDeletion(id string, at *time.Time) error {
+	return m.ScheduleDeletionFunc(id, at)
+}
+func (m *UserRepositoryMock) ListDeletionDue(now time.Time) ([]model.User, error) {
+	return m.ListDeletionDueFunc(now)
+}
+func (m *UserRepositoryMock) LockDeletionDueTx(tx *sql.Tx, id string, now time.Time) (bool, error) {
+	return m.LockDeletionDueTxFunc(tx, id, now)
+}
+func (m *UserRepositoryMock) DeleteDueTx(tx *sql.Tx, id string, now time.Time) (bool, error) {
+	return m.DeleteDueTxFunc(tx, id, now)
+}
 
 type DocumentRepositoryMock struct {
-	CreateFunc   func(doc *model.Document) error
-	CreateTxFunc func(tx *sql.Tx, doc *model.Document) error
-	ListFunc     func(owner string, limit int) ([]model.Document, error)
-	GetByIDFunc  func(id string) (*model.Document, error)
-	DeleteFunc   func(id string) error
-	DeleteTxFunc func(tx *sql.Tx, id string) error
+	CreateFunc            func(doc *model.Document) error
+	CreateTxFunc          func(tx *sql.Tx, doc *model.Document) error
+	ListFunc              func(owner string, q model.ListQuery) ([]model.Document, error)
+	ListVisibleFunc       func(owner string, principals []string, q model.ListQuery) ([]model.Document, error)
+	ListAccessibleFunc    func(userID string, principals []string, includeOwned bool, q model.ListQuery) ([]model.Document, error)
+	SearchFunc            func(userID string, principals []string, query string, limit int) ([]model.SearchResult, error)
+	CountTagsFunc         func(userID string, principals []string, owned, shared bool) ([]model.TagCount, error)
+	UpdateFunc            func(doc *model.Document) error
+	UpdateFileFunc        func(doc *model.Document) (string, error)
+	ForEachByOwnerFunc    func(owner string, fn func(doc *model.Document) error) error
+	GetByIDFunc           func(id string) (*model.Document, error)
+	GetByIDTxFunc         func(tx *sql.Tx, id string) (*model.Document, error)
+	DeleteFunc            func(id string) error
+	ListLegacyFilesFunc   func(after *model.Document, limit int) ([]model.Document, error)
+	CompleteUploadFunc    func(doc *model.Document) (bool, error)
+	TrashFunc             func(id string, at time.Time) (bool, error)
+	RestoreFunc           func(id string) (bool, error)
+	GetTrashedFunc        func(id string) (*model.Document, error)
+	ListTrashFunc         func(owner string, limit int) ([]model.Document, error)
+	ListTrashedBeforeFunc func(before time.Time, limit int) ([]model.Document, error)
+	ListExpiredFunc       func(now time.Time, limit int) ([]model.Document, error)
+	ListUnsizedFunc       func(limit int) ([]model.Document, error)
+	SetSizeFunc           func(id string, size int64) error
+	ListPendingScanFunc   func(before time.Time, limit int) ([]model.Document, error)
+	SetScanResultFunc     func(id string, queuedAt time.Time, status string, signature *string, scannedAt time.Time) (bool, error)
+	GetByPathFunc         func(owner string, folderID *string, name string) (*model.Document, error)
+	MoveTxFunc            func(tx *sql.Tx, id string, folderID *string, name string) error
+	DeleteTxFunc          func(tx *sql.Tx, id string) error
 }
 
 func (m *DocumentRepositoryMock) Create(doc *model.Document) error { return m.CreateFunc(doc) }
 func (m *DocumentRepositoryMock) CreateTx(tx *sql.Tx, doc *model.Document) error {
 	return m.CreateTxFunc(tx, doc)
 }
-func (m *DocumentRepositoryMock) List(owner string, limit int) ([]model.Document, error) {
-	return m.ListFunc(owner, limit)
+func (m *DocumentRepositoryMock) List(owner string, q model.ListQuery) ([]model.Document, error) {
+	return m.ListFunc(owner, q)
+}
+func (m *DocumentRepositoryMock) ListVisible(owner string, principals []string, q model.ListQuery) ([]model.Document, error) {
+	return m.ListVisibleFunc(owner, principals, q)
+}
+func (m *DocumentRepositoryMock) ListAccessible(userID string, principals []string, includeOwned bool, q model.ListQuery) ([]model.Document, error) {
+	return m.ListAccessibleFunc(userID, principals, includeOwned, q)
+}
+func (m *DocumentRepositoryMock) Search(userID string, principals []string, query string, limit int) ([]model.SearchResult, error) {
+	return m.SearchFunc(userID, principals, query, limit)
+}
+func (m *DocumentRepositoryMock) CountTags(userID string, principals []string, owned, shared bool) ([]model.TagCount, error) {
+	return m.CountTagsFunc(userID, principals, owned, shared)
+}
+func (m *DocumentRepositoryMock) Update(doc *model.Document) error { return m.UpdateFunc(doc) }
+func (m *DocumentRepositoryMock) UpdateFile(doc *model.Document) (string, error) {
+	return m.UpdateFileFunc(doc)
+}
+func (m *DocumentRepositoryMock) ForEachByOwner(owner string, fn func(doc *model.Document) error) error {
+	return m.ForEachByOwnerFunc(owner, fn)
 }
 func (m *DocumentRepositoryMock) GetByID(id string) (*model.Document, error) {
 	return m.GetByIDFunc(id)
 }
-func (m *DocumentRepositoryMock) Delete(id string) error               { return m.DeleteFunc(id) }
+func (m *DocumentRepositoryMock) GetByIDTx(tx *sql.Tx, id string) (*model.Document, error) {
+	return m.GetByIDTxFunc(tx, id)
+}
+func (m *DocumentRepositoryMock) Delete(id string) error { return m.DeleteFunc(id) }
+func (m *DocumentRepositoryMock) ListLegacyFiles(after *model.Document, limit int) ([]model.Document, error) {
+	return m.ListLegacyFilesFunc(after, limit)
+}
+func (m *DocumentRepositoryMock) CompleteUpload(doc *model.Document) (bool, error) {
+	return m.CompleteUploadFunc(doc)
+}
+func (m *DocumentRepositoryMock) Trash(id string, at time.Time) (bool, error) {
+	return m.TrashFunc(id, at)
+}
+func (m *DocumentRepositoryMock) Restore(id string) (bool, error) { return m.RestoreFunc(id) }
+func (m *DocumentRepositoryMock) GetTrashed(id string) (*model.Document, error) {
+	return m.GetTrashedFunc(id)
+}
+func (m *DocumentRepositoryMock) ListTrash(owner string, limit int) ([]model.Document, error) {
+	return m.ListTrashFunc(owner, limit)
+}
+func (m *DocumentRepositoryMock) ListTrashedBefore(before time.Time, limit int) ([]model.Document, error) {
+	return m.ListTrashedBeforeFunc(before, limit)
+}
+func (m *DocumentRepositoryMock) ListExpired(now time.Time, limit int) ([]model.Document, error) {
+	return m.ListExpiredFunc(now, limit)
+}
+func (m *DocumentRepositoryMock) ListUnsized(limit int) ([]model.Document, error) {
+	return m.ListUnsizedFunc(limit)
+}
+func (m *DocumentRepositoryMock) SetSize(id string, size int64) error { return m.SetSizeFunc(id, size) }
+func (m *DocumentRepositoryMock) ListPendingScan(before time.Time, limit int) ([]model.Document, error) {
+	return m.ListPendingScanFunc(before, limit)
+}
+func (m *DocumentRepositoryMock) SetScanResult(id string, queuedAt time.Time, status string, signature *string, scannedAt time.Time) (bool, error) {
+	return m.SetScanResultFunc(id, queuedAt, status, signature, scannedAt)
+}
+func (m *DocumentRepositoryMock) GetByPath(owner string, folderID *string, name string) (*model.Document, error) {
+	return m.GetByPathFunc(owner, folderID, name)
+}
+func (m *DocumentRepositoryMock) MoveTx(tx *sql.Tx, id string, folderID *string, name string) error {
+	return m.MoveTxFunc(tx, id, folderID, name)
+}
 func (m *DocumentRepositoryMock) DeleteTx(tx *sql.Tx, id string) error { return m.DeleteTxFunc(tx, id) }
+
+type RefreshTokenRepositoryMock struct {
+	CreateFunc       func(token *model.RefreshToken) error
+	GetByHashFunc    func(hash string) (*model.RefreshToken, error)
+	MarkUsedFunc     func(id string, at time.Time) (bool, error)
+	RevokeFamilyFunc func(familyID string, at time.Time) error
+	RevokeByUserFunc func(userID string, at time.Time) error
+
+	RevokeAccessFunc          func(tokenID string, expiresAt, now time.Time) (bool, error)
+	RevokeAccessBeforeFunc    func(userID string, notBefore time.Time) error
+	ListAccessRevocationsFunc func(now, since time.Time) ([]model.RevokedAccessToken, []model.AccessCutoff, error)
+}
+
+func (m *RefreshTokenRepositoryMock) Create(token *model.RefreshToken) error {
+	return m.CreateFunc(token)
+}
+func (m *RefreshTokenRepositoryMock) GetByHash(hash string) (*model.RefreshToken, error) {
+	return m.GetByHashFunc(hash)
+}
+func (m *RefreshTokenRepositoryMock) MarkUsed(id string, at time.Time) (bool, error) {
+	return m.MarkUsedFunc(id, at)
+}
+func (m *RefreshTokenRepositoryMock) RevokeFamily(familyID string, at time.Time) error {
+	return m.RevokeFamilyFunc(familyID, at)
+}
+func (m *RefreshTokenRepositoryMock) RevokeByUser(userID string, at time.Time) error {
+	return m.RevokeByUserFunc(userID, at)
+}
+func (m *RefreshTokenRepositoryMock) RevokeAccess(tokenID string, expiresAt, now time.Time) (bool, error) {
+	return m.RevokeAccessFunc(tokenID, expiresAt, now)
+}
+func (m *RefreshTokenRepositoryMock) RevokeAccessBefore(userID string, notBefore time.Time) error {
+	return m.RevokeAccessBeforeFunc(userID, notBefore)
+}
+func (m *RefreshTokenRepositoryMock) ListAccessRevocations(now, since time.Time) ([]model.RevokedAccessToken, []model.AccessCutoff, error) {
+	return m.ListAccessRevocationsFunc(now, since)
+}
+
+type AuditRepositoryMock struct {
+	CreateFunc func(entry *model.AuditEntry) error
+	ListFunc   func(actorID string, limit int) ([]model.AuditEntry, error)
+}
+
+func (m *AuditRepositoryMock) Create(entry *model.AuditEntry) error { return m.CreateFunc(entry) }
+func (m *AuditRepositoryMock) List(actorID string, limit int) ([]model.AuditEntry, error) {
+	return m.ListFunc(actorID, limit)
+}
+
+type GroupRepositoryMock struct {
+	CreateFunc       func(group *model.Group, ownerID string) error
+	GetByIDFunc      func(id string) (*model.Group, error)
+	GetByNameFunc    func(name string) (*model.Group, error)
+	DeleteFunc       func(id string) error
+	ListByUserFunc   func(userID string) ([]model.Group, error)
+	SetMemberFunc    func(groupID, userID, role string) error
+	RemoveMemberFunc func(groupID, userID string) error
+	ListMembersFunc  func(groupID string) ([]model.GroupMember, error)
+}
+
+func (m *GroupRepositoryMock) Create(group *model.Group, ownerID string) error {
+	return m.CreateFunc(group, ownerID)
+}
+func (m *GroupRepositoryMock) GetByID(id string) (*model.Group, error) { return m.GetByIDFunc(id) }
+func (m *GroupRepositoryMock) GetByName(name string) (*model.Group, error) {
+	return m.GetByNameFunc(name)
+}
+func (m *GroupRepositoryMock) Delete(id string) error { return m.DeleteFunc(id) }
+func (m *GroupRepositoryMock) ListByUser(userID string) ([]model.Group, error) {
+	return m.ListByUserFunc(userID)
+}
+func (m *GroupRepositoryMock) SetMember(groupID, userID, role string) error {
+	return m.SetMemberFunc(groupID, userID, role)
+}
+func (m *GroupRepositoryMock) RemoveMember(groupID, userID string) error {
+	return m.RemoveMemberFunc(groupID, userID)
+}
+func (m *GroupRepositoryMock) ListMembers(groupID string) ([]model.GroupMember, error) {
+	return m.ListMembersFunc(groupID)
+}
+
+type GrantRepositoryMock struct {
+	ListFunc          func(documentID string) ([]model.Grant, error)
+	SetFunc           func(grant *model.Grant) error
+	DeleteFunc        func(documentID, principal string) error
+	ListFolderFunc    func(folderID string) ([]model.Grant, error)
+	ListInheritedFunc func(folderID string) ([]model.Grant, error)
+	SetFolderFunc     func(grant *model.Grant) error
+	DeleteFolderFunc  func(folderID, principal string) error
+}
+
+func (m *GrantRepositoryMock) List(documentID string) ([]model.Grant, error) {
+	return m.ListFunc(documentID)
+}
+func (m *GrantRepositoryMock) Set(grant *model.Grant) error { return m.SetFunc(grant) }
+func (m *GrantRepositoryMock) Delete(documentID, principal string) error {
+	return m.DeleteFunc(documentID, principal)
+}
+func (m *GrantRepositoryMock) ListFolder(folderID string) ([]model.Grant, error) {
+	return m.ListFolderFunc(folderID)
+}
+func (m *GrantRepositoryMock) ListInherited(folderID string) ([]model.Grant, error) {
+	return m.ListInheritedFunc(folderID)
+}
+func (m *GrantRepositoryMock) SetFolder(grant *model.Grant) error { return m.SetFolderFunc(grant) }
+func (m *GrantRepositoryMock) DeleteFolder(folderID, principal string) error {
+	return m.DeleteFolderFunc(folderID, principal)
+}
+
+type ShareLinkRepositoryMock struct {
+	CreateFunc         func(link *model.ShareLink) error
+	GetByIDFunc        func(id string) (*model.ShareLink, error)
+	ListByDocumentFunc func(documentID string) ([]model.ShareLink, error)
+	RevokeFunc         func(id, documentID string, at time.Time) (bool, error)
+	CountDownloadFunc  func(id string) (bool, error)
+}
+
+func (m *ShareLinkRepositoryMock) Create(link *model.ShareLink) error { return m.CreateFunc(link) }
+func (m *ShareLinkRepositoryMock) GetByID(id string) (*model.ShareLink, error) {
+	return m.GetByIDFunc(id)
+}
+func (m *ShareLinkRepositoryMock) ListByDocument(documentID string) ([]model.ShareLink, error) {
+	return m.ListByDocumentFunc(documentID)
+}
+func (m *ShareLinkRepositoryMock) Revoke(id, documentID string, at time.Time) (bool, error) {
+	return m.RevokeFunc(id, documentID, at)
+}
+func (m *ShareLinkRepositoryMock) CountDownload(id string) (bool, error) {
+	return m.CountDownloadFunc(id)
+}
+
+type UploadReservationRepositoryMock struct {
+	CreateTxFunc      func(tx *sql.Tx, res *model.UploadReservation) error
+	TakeFunc          func(id string, now time.Time) (*model.UploadReservation, error)
+	DeleteExpiredFunc func(now time.Time) (int64, error)
+}
+
+func (m *UploadReservationRepositoryMock) CreateTx(tx *sql.Tx, res *model.UploadReservation) error {
+	return m.CreateTxFunc(tx, res)
+}
+func (m *UploadReservationRepositoryMock) Take(id string, now time.Time) (*model.UploadReservation, error) {
+	return m.TakeFunc(id, now)
+}
+func (m *UploadReservationRepositoryMock) DeleteExpired(now time.Time) (int64, error) {
+	return m.DeleteExpiredFunc(now)
+}
+
+type QuotaRepositoryMock struct {
+	GetUsageFunc       func(userID string) (*model.Usage, error)
+	SetDefaultsFunc    func(limits model.QuotaLimits) error
+	GetOverrideFunc    func(userID string) (*model.QuotaOverride, error)
+	SetOverrideFunc    func(o *model.QuotaOverride) error
+	DeleteOverrideFunc func(userID string) error
+}
+
+func (m *QuotaRepositoryMock) GetUsage(userID string) (*model.Usage, error) {
+	return m.GetUsageFunc(userID)
+}
+func (m *QuotaRepositoryMock) SetDefaults(limits model.QuotaLimits) error {
+	return m.SetDefaultsFunc(limits)
+}
+func (m *QuotaRepositoryMock) GetOverride(userID string) (*model.QuotaOverride, error) {
+	return m.GetOverrideFunc(userID)
+}
+func (m *QuotaRepositoryMock) SetOverride(o *model.QuotaOverride) error { return m.SetOverrideFunc(o) }
+func (m *QuotaRepositoryMock) DeleteOverride(userID string) error {
+	return m.DeleteOverrideFunc(userID)
+}
+
+type BlobKeyRepositoryMock struct {
+	PutKeyFunc     func(k *storage.WrappedKey) error
+	GetKeyFunc     func(blobKey, version string) (*storage.WrappedKey, error)
+	DeleteKeyFunc  func(blobKey, version string) error
+	DeleteKeysFunc func(blobKey, keep string) error
+	ListStaleFunc  func(masterKeyID string, limit int) ([]storage.WrappedKey, error)
+	UpdateKeyFunc  func(k *storage.WrappedKey, oldMasterKeyID string) error
+}
+
+func (m *BlobKeyRepositoryMock) PutKey(k *storage.WrappedKey) error { return m.PutKeyFunc(k) }
+func (m *BlobKeyRepositoryMock) GetKey(blobKey, version string) (*storage.WrappedKey, error) {
+	return m.GetKeyFunc(blobKey, version)
+}
+func (m *BlobKeyRepositoryMock) DeleteKey(blobKey, version string) error {
+	return m.DeleteKeyFunc(blobKey, version)
+}
+func (m *BlobKeyRepositoryMock) DeleteKeys(blobKey, keep string) error {
+	return m.DeleteKeysFunc(blobKey, keep)
+}
+func (m *BlobKeyRepositoryMock) ListStale(masterKeyID string, limit int) ([]storage.WrappedKey, error) {
+	return m.ListStaleFunc(masterKeyID, limit)
+}
+func (m *BlobKeyRepositoryMock) UpdateKey(k *storage.WrappedKey, oldMasterKeyID string) error {
+	return m.UpdateKeyFunc(k, oldMasterKeyID)
+}
+
+type SchemaRepositoryMock struct {
+	CreateFunc          func(schema *model.JSONSchema) error
+	UpdateFunc          func(schema *model.JSONSchema) error
+	GetByIDFunc         func(id string) (*model.JSONSchema, error)
+	GetByNameFunc       func(owner, name string) (*model.JSONSchema, error)
+	ListByOwnerFunc     func(owner string) ([]model.JSONSchema, error)
+	DeleteFunc          func(id string) error
+	GetByFolderFunc     func(folderID string) (*model.JSONSchema, error)
+	AttachTagFunc       func(owner, tag, schemaID string) error
+	DetachTagFunc       func(owner, tag string) error
+	ListByTagsFunc      func(owner string, tags []string) ([]model.JSONSchema, error)
+	ForEachDocumentFunc func(schemaID string, fn func(doc *model.Document) error) error
+	ForEachInFolderFunc func(folderID string, fn func(doc *model.Document) error) error
+	ForEachTaggedFunc   func(owner, tag string, fn func(doc *model.Document) error) error
+}
+
+func (m *SchemaRepositoryMock) Create(schema *model.JSONSchema) error { return m.CreateFunc(schema) }
+func (m *SchemaRepositoryMock) Update(schema *model.JSONSchema) error { return m.UpdateFunc(schema) }
+func (m *SchemaRepositoryMock) GetByID(id string) (*model.JSONSchema, error) {
+	return m.GetByIDFunc(id)
+}
+func (m *SchemaRepositoryMock) GetByName(owner, name string) (*model.JSONSchema, error) {
+	return m.GetByNameFunc(owner, name)
+}
+func (m *SchemaRepositoryMock) ListByOwner(owner string) ([]model.JSONSchema, error) {
+	return m.ListByOwnerFunc(owner)
+}
+func (m *SchemaRepositoryMock) Delete(id string) error { return m.DeleteFunc(id) }
+func (m *SchemaRepositoryMock) GetByFolder(folderID string) (*model.JSONSchema, error) {
+	return m.GetByFolderFunc(folderID)
+}
+func (m *SchemaRepositoryMock) ForEachDocument(schemaID string, fn func(doc *model.Document) error) error {
+	return m.ForEachDocumentFunc(schemaID, fn)
+}
+func (m *SchemaRepositoryMock) AttachTag(owner, tag, schemaID string) error {
+	return m.AttachTagFunc(owner, tag, schemaID)
+}
+func (m *SchemaRepositoryMock) DetachTag(owner, tag string) error { return m.DetachTagFunc(owner, tag) }
+func (m *SchemaRepositoryMock) ListByTags(owner string, tags []string) ([]model.JSONSchema, error) {
+	return m.ListByTagsFunc(owner, tags)
+}
+func (m *SchemaRepositoryMock) ForEachInFolder(folderID string, fn func(doc *model.Document) error) error {
+	return m.ForEachInFolderFunc(folderID, fn)
+}
+func (m *SchemaRepositoryMock) ForEachTagged(owner, tag string, fn func(doc *model.Document) error) error {
+	return m.ForEachTaggedFunc(owner, tag, fn)
+}
+
+type FolderRepositoryMock struct {
+	CreateFunc        func(folder *model.Folder) error
+	GetByIDFunc       func(id string) (*model.Folder, error)
+	GetByNameFunc     func(owner string, parentID *string, name string) (*model.Folder, error)
+	ListChildrenFunc  func(owner string, parentID *string) ([]model.Folder, error)
+	ListSharedFunc    func(userID string, principals []string) ([]model.Folder, error)
+	ListDocumentsFunc func(owner string, folderID *string) ([]model.Document, error)
+	GetByIDTxFunc     func(tx *sql.Tx, id string) (*model.Folder, error)
+	UpdateTxFunc      func(tx *sql.Tx, folder *model.Folder) error
+	MoveTxFunc        func(tx *sql.Tx, folder *model.Folder, parentID *string, path []string) error
+	IsEmptyFunc       func(id string) (bool, error)
+	DeleteFunc        func(id string) error
+}
+
+func (m *FolderRepositoryMock) Create(folder *model.Folder) error { return m.CreateFunc(folder) }
+func (m *FolderRepositoryMock) GetByID(id string) (*model.Folder, error) {
+	return m.GetByIDFunc(id)
+}
+func (m *FolderRepositoryMock) GetByName(owner string, parentID *string, name string) (*model.Folder, error) {
+	return m.GetByNameFunc(owner, parentID, name)
+}
+func (m *FolderRepositoryMock) ListChildren(owner string, parentID *string) ([]model.Folder, error) {
+	return m.ListChildrenFunc(owner, parentID)
+}
+func (m *FolderRepositoryMock) ListShared(userID string, principals []string) ([]model.Folder, error) {
+	return m.ListSharedFunc(userID, principals)
+}
+func (m *FolderRepositoryMock) ListDocuments(owner string, folderID *string) ([]model.Document, error) {
+	return m.ListDocumentsFunc(owner, folderID)
+}
+func (m *FolderRepositoryMock) GetByIDTx(tx *sql.Tx, id string) (*model.Folder, error) {
+	return m.GetByIDTxFunc(tx, id)
+}
+func (m *FolderRepositoryMock) UpdateTx(tx *sql.Tx, folder *model.Folder) error {
+	return m.UpdateTxFunc(tx, folder)
+}
+func (m *FolderRepositoryMock) MoveTx(tx *sql.Tx, folder *model.Folder, parentID *string, path []string) error {
+	return m.MoveTxFunc(tx, folder, parentID, path)
+}
+func (m *FolderRepositoryMock) IsEmpty(id string) (bool, error) { return m.IsEmptyFunc(id) }
+func (m *FolderRepositoryMock) Delete(id string) error          { return m.DeleteFunc(id) }
+
+type TxManagerMock struct {
+	InTxFunc func(fn func(tx *sql.Tx) error) error
+}
+
+func (m *TxManagerMock) InTx(fn func(tx *sql.Tx) error) error { return m.InTxFunc(fn) }
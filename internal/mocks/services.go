@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"astra-api/internal/model"
+	"time"
 )
 
 type AuthServiceMock struct {
@@ -18,29 +19,62 @@ func (m *AuthServiceMock) Authenticate(login, password string) (*model.User, err
 }
 
 type DocsServiceMock struct {
-	CreateFunc  func(doc *model.Document) error
-	ListFunc    func(owner string, limit int) ([]model.Document, error)
-	GetByIDFunc func(id string) (*model.Document, error)
-	DeleteFunc  func(id string) error
+	CreateFunc         func(doc *model.Document) error
+	ListFunc           func(owner string, q model.ListQuery) ([]model.Document, error)
+	ListVisibleFunc    func(owner string, principals []string, q model.ListQuery) ([]model.Document, error)
+	ListAccessibleFunc func(userID string, principals []string, includeOwned bool, q model.ListQuery) ([]model.Document, error)
+	SearchFunc         func(userID string, principals []string, query string, limit int) ([]model.SearchResult, error)
+	GetByIDFunc        func(id string) (*model.Document, error)
+	UpdateFunc         func(doc *model.Document) error
+	DeleteFunc         func(id string) error
 }
 
 func (m *DocsServiceMock) Create(doc *model.Document) error { return m.CreateFunc(doc) }
-func (m *DocsServiceMock) List(owner string, limit int) ([]model.Document, error) {
-	return m.ListFunc(owner, limit)
+func (m *DocsServiceMock) List(owner string, q model.ListQuery) ([]model.Document, error) {
+	return m.ListFunc(owner, q)
+}
+func (m *DocsServiceMock) ListVisible(owner string, principals []string, q model.ListQuery) ([]model.Document, error) {
+	return m.ListVisibleFunc(owner, principals, q)
+}
+func (m *DocsServiceMock) ListAccessible(userID string, principals []string, includeOwned bool, q model.ListQuery) ([]model.Document, error) {
+	return m.ListAccessibleFunc(userID, principals, includeOwned, q)
+}
+func (m *DocsServiceMock) Search(userID string, principals []string, query string, limit int) ([]model.SearchResult, error) {
+	return m.SearchFunc(userID, principals, query, limit)
 }
 func (m *DocsServiceMock) GetByID(id string) (*model.Document, error) { return m.GetByIDFunc(id) }
+func (m *DocsServiceMock) Update(doc *model.Document) error           { return m.UpdateFunc(doc) }
 func (m *DocsServiceMock) Delete(id string) error                     { return m.DeleteFunc(id) }
 
 type SessionServiceMock struct {
-	CreateFunc   func(userID, login string) string
-	GetFunc      func(token string) (*model.Session, bool)
-	ValidateFunc func(token string) (model.Session, bool)
-	DeleteFunc   func(token string) bool
+	CreateFunc              func(userID, login string) string
+	CreateImpersonationFunc func(actor model.Session, target *model.User, ttl time.Duration) string
+	GetFunc                 func(token string) (*model.Session, bool)
+	ValidateFunc            func(token string) (model.Session, bool)
+	DeleteFunc              func(token string) bool
+	DeleteByUserFunc        func(userID string)
 }
 
-func (m *SessionServiceMock) Create(userID, login string) string      { return m.CreateFunc(userID, login) }
+func (m *SessionServiceMock) Create(userID, login string) string { return m.CreateFunc(userID, login) }
+func (m *SessionServiceMock) CreateImpersonation(actor model.Session, target *model.User, ttl time.Duration) string {
+	return m.CreateImpersonationFunc(actor, target, ttl)
+}
 func (m *SessionServiceMock) Get(token string) (*model.Session, bool) { return m.GetFunc(token) }
 func (m *SessionServiceMock) Validate(token string) (model.Session, bool) {
 	return m.ValidateFunc(token)
 }
-func (m *SessionServiceMock) Delete(token string) bool { return m.DeleteFunc(token) }
+func (m *SessionServiceMock) Delete(token string) bool   { return m.DeleteFunc(token) }
+func (m *SessionServiceMock) DeleteByUser(userID string) { m.DeleteByUserFunc(userID) }
+
+type TokenServiceMock struct {
+	SessionServiceMock
+	CreatePairFunc func(userID, login string) (*model.TokenPair, error)
+	RefreshFunc    func(refreshToken string) (*model.TokenPair, error)
+}
+
+func (m *TokenServiceMock) CreatePair(userID, login string) (*model.TokenPair, error) {
+	return m.CreatePairFunc(userID, login)
+}
+func (m *TokenServiceMock) Refresh(refreshToken string) (*model.TokenPair, error) {
+	return m.RefreshFunc(refreshToken)
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	// AuditImpersonationStart — администратор начал сессию от имени пользователя
+	AuditImpersonationStart = "impersonation.start"
+	// AuditImpersonatedRequest — запрос, выполненный в рамках такой сессии
+	AuditImpersonatedRequest = "impersonation.request"
+)
+
+// AuditEntry запись журнала аудита: кто (ActorID) что сделал от чьего имени (UserID)
+type AuditEntry struct {
+	ID        string    `db:"id" json:"id"`
+	ActorID   string    `db:"actor_id" json:"actor_id"`
+	UserID    string    `db:"user_id" json:"user_id"`
+	Action    string    `db:"action" json:"action"`
+	Method    string    `db:"method" json:"method,omitempty"`
+	Path      string    `db:"path" json:"path,omitempty"`
+	Status    int       `db:"status" json:"status,omitempty"`
+	Details   string    `db:"details" json:"details,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" example:"ticket #123: document is missing"`
+}
@@ -1,19 +1,195 @@
 package model
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
 )
 
 type Document struct {
-	ID        string         `db:"id" json:"id"`
-	Name      string         `db:"name" json:"name"`
-	Mime      string         `db:"mime" json:"mime"`
-	File      bool           `db:"file" json:"file"`
-	Public    bool           `db:"public" json:"public"`
-	Owner     string         `db:"owner" json:"owner"`
-	CreatedAt time.Time      `db:"created_at" json:"created"`
-	Grants    pq.StringArray `db:"grants" json:"grants"`
-	JsonData  []byte         `db:"json_data" json:"json,omitempty"`
+	ID        string    `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Mime      string    `db:"mime" json:"mime"`
+	File      bool      `db:"file" json:"file"`
+	Public    bool      `db:"public" json:"public"`
+	Owner     string    `db:"owner" json:"owner"`
+	CreatedAt time.Time `db:"created_at" json:"created"`
+	// ModifiedAt — когда содержимое последний раз заменялось; nil — не заменялось
+	ModifiedAt *time.Time `db:"modified_at" json:"-"`
+	// SchemaID — привязанная JSON Schema, по которой проверяется json
+	SchemaID *string `db:"schema_id" json:"schema_id,omitempty"`
+	// FolderID — папка документа; nil — корень
+	FolderID *string `db:"folder_id" json:"folder_id,omitempty"`
+	// Tags — теги в нижнем регистре без повторов (см. NormalizeTags)
+	Tags     pq.StringArray `db:"tags" json:"tags,omitempty" swaggertype:"array,string"`
+	Metadata Metadata       `db:"metadata" json:"metadata,omitempty"`
+	// ExpiresAt — после этого момента документ не виден и удаляется очисткой; nil — бессрочный
+	ExpiresAt *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	// Size — байты файла или JSON, учитываются в квоте владельца; nil — размер файла ещё не посчитан
+	Size *int64 `db:"size" json:"size,omitempty"`
+	// DeclaredMime — тип, заявленный при загрузке, если он не совпал с содержимым;
+	// Mime тогда — тип, найденный по содержимому
+	DeclaredMime *string `db:"declared_mime" json:"declared_mime,omitempty"`
+	// Encoding — как файл записан в хранилище: пусто — как есть, "gzip" — сжатым
+	Encoding string `db:"encoding" json:"encoding,omitempty"`
+	// StoredSize — сколько файл занимает в хранилище; Size — исходный размер, он и учитывается в квоте
+	StoredSize *int64 `db:"stored_size" json:"stored_size,omitempty"`
+	// ScanStatus — проверка файла антивирусом (ScanPending, ScanClean, ScanInfected, ScanFailed);
+	// пусто у JSON-документов. Файл отдаётся только после успешной проверки.
+	ScanStatus string `db:"scan_status" json:"scan_status,omitempty"`
+	// ScanSignature — найденная угроза или причина, по которой файл не удалось проверить
+	ScanSignature *string    `db:"scan_signature" json:"scan_signature,omitempty"`
+	ScannedAt     *time.Time `db:"scanned_at" json:"scanned_at,omitempty"`
+	// ScanQueuedAt — когда файл поставлен на проверку; результат проверки заменённого
+	// с тех пор содержимого не записывается
+	ScanQueuedAt *time.Time `db:"scan_queued_at" json:"-"`
+	// DeletedAt — когда документ попал в корзину; nil — документ не удалён
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	// UploadPending — документ создан ссылкой на загрузку и ждёт файла; до загрузки он не виден
+	UploadPending bool `db:"upload_pending" json:"-"`
+	// BlobKey — ключ файла в хранилище, если он не совпадает с ID (файл перезаписывался)
+	BlobKey  *string `db:"blob_key" json:"-"`
+	Grants   []Grant `db:"-" json:"grants,omitempty"`
+	JsonData []byte  `db:"json_data" json:"json,omitempty"`
+}
+
+// Состояния проверки файла антивирусом
+const (
+	ScanPending  = "pending_scan"
+	ScanClean    = "clean"
+	ScanInfected = "infected"
+	// ScanFailed — сканер не смог проверить файл (например, файл больше его лимита)
+	ScanFailed = "scan_failed"
+)
+
+// StorageKey возвращает ключ файла документа в хранилище
+func (d *Document) StorageKey() string {
+	if d.BlobKey != nil {
+		return *d.BlobKey
+	}
+	return d.ID
+}
+
+// LastModified возвращает время последнего изменения содержимого: замены или создания
+func (d *Document) LastModified() time.Time {
+	if d.ModifiedAt != nil {
+		return *d.ModifiedAt
+	}
+	return d.CreatedAt
+}
+
+// Downloadable сообщает, что содержимое можно отдавать: файл проверен и чист.
+// Заражённые и непроверенные файлы остаются в карантине — хранятся, но не отдаются.
+func (d *Document) Downloadable() bool {
+	return d.ScanStatus == "" || d.ScanStatus == ScanClean
+}
+
+// UpdateDocumentRequest — частичное изменение документа; отсутствующие поля не меняются
+type UpdateDocumentRequest struct {
+	Name   *string         `json:"name,omitempty" example:"report.json"`
+	Public *bool           `json:"public,omitempty"`
+	Json   json.RawMessage `json:"json,omitempty" swaggertype:"object"`
+	// Schema — имя схемы владельца документа для привязки, "" — отвязать
+	Schema *string `json:"schema,omitempty" example:"service-config"`
+	// Tags — новый список тегов целиком, [] — снять все
+	Tags *[]string `json:"tags,omitempty" example:"invoice,2026"`
+	// Metadata — merge patch метаданных: значение задаёт ключ, null удаляет
+	Metadata map[string]*string `json:"metadata,omitempty"`
+	// ExpiresAt — срок жизни: время RFC 3339 или длительность от текущего момента ("24h", "7d"), "" — бессрочно
+	ExpiresAt *string `json:"expires_at,omitempty" example:"7d"`
+}
+
+// SearchResult — найденный документ с релевантностью и фрагментом текста: фрагмент —
+// HTML, текст в нём экранирован, совпадения обрамлены <mark>...</mark>
+type SearchResult struct {
+	Document
+	Rank    float64 `db:"rank" json:"rank"`
+	Snippet string  `db:"snippet" json:"snippet"`
+}
+
+var ErrUnknownField = errors.New("unknown document field")
+
+// DocumentFields — поля документа, которые можно запросить через ?fields=, в порядке колонок.
+// json отвечает за json_data: без него содержимое документа из БД не читается.
+var DocumentFields = []string{"id", "name", "mime", "file", "public", "owner", "created", "folder_id", "schema_id", "tags", "metadata", "expires_at", "size", "declared_mime", "encoding", "stored_size", "scan_status", "scan_signature", "scanned_at", "json"}
+
+// ParseDocumentFields разбирает список полей через запятую; id добавляется всегда
+func ParseDocumentFields(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	want := map[string]bool{"id": true}
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if !containsString(DocumentFields, f) {
+			return nil, fmt.Errorf("%w %q", ErrUnknownField, f)
+		}
+		want[f] = true
+	}
+	fields := make([]string, 0, len(want))
+	for _, f := range DocumentFields {
+		if want[f] {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// Project возвращает только перечисленные поля документа под их JSON-именами
+func (d *Document) Project(fields []string) map[string]interface{} {
+	out := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		switch f {
+		case "id":
+			out[f] = d.ID
+		case "name":
+			out[f] = d.Name
+		case "mime":
+			out[f] = d.Mime
+		case "file":
+			out[f] = d.File
+		case "public":
+			out[f] = d.Public
+		case "owner":
+			out[f] = d.Owner
+		case "created":
+			out[f] = d.CreatedAt
+		case "folder_id":
+			out[f] = d.FolderID
+		case "schema_id":
+			out[f] = d.SchemaID
+		case "tags":
+			out[f] = d.Tags
+		case "metadata":
+			out[f] = d.Metadata
+		case "expires_at":
+			out[f] = d.ExpiresAt
+		case "json":
+			if len(d.JsonData) > 0 {
+				out[f] = json.RawMessage(d.JsonData)
+			} else {
+				out[f] = nil
+			}
+		}
+	}
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// TrashEntry — документ в корзине и время, когда он будет удалён окончательно
+type TrashEntry struct {
+	Document
+	PurgeAt time.Time `json:"purge_at"`
 }
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidExpiry = errors.New("expires_at must be a future RFC 3339 time or a positive duration like 90m, 24h or 7d")
+
+// ParseExpiry разбирает срок жизни документа: абсолютное время RFC 3339 или
+// длительность от now (time.ParseDuration, а также дни: "7d"). Пустая строка — без срока.
+func ParseExpiry(s string, now time.Time) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if at, err := time.Parse(time.RFC3339, s); err == nil {
+		if !at.After(now) {
+			return nil, ErrInvalidExpiry
+		}
+		return &at, nil
+	}
+	d, err := parseDuration(s)
+	if err != nil || d <= 0 {
+		return nil, ErrInvalidExpiry
+	}
+	at := now.Add(d)
+	return &at, nil
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// Expired сообщает, истёк ли срок жизни документа к моменту now
+func (d *Document) Expired(now time.Time) bool {
+	return d.ExpiresAt != nil && !d.ExpiresAt.After(now)
+}
+
+// ReapResult — итог одного прохода очистки истёкших документов
+type ReapResult struct {
+	Documents int `json:"documents"`
+	Files     int `json:"files"`
+	Batches   int `json:"batches"`
+}
+
+// ReaperStats — накопленная с запуска сервера статистика очистки истёкших документов
+type ReaperStats struct {
+	Runs      int64      `json:"runs"`
+	Failures  int64      `json:"failures"`
+	Documents int64      `json:"documents"`
+	Files     int64      `json:"files"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	// LastDuration — длительность последнего прохода в миллисекундах
+	LastDuration int64      `json:"last_duration_ms"`
+	LastResult   ReapResult `json:"last_result"`
+	LastError    string     `json:"last_error,omitempty"`
+}
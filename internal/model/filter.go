@@ -0,0 +1,137 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// JSONOp — оператор условия на содержимое JSON-документа
+type JSONOp string
+
+const (
+	JSONEq       JSONOp = "=="
+	JSONNe       JSONOp = "!="
+	JSONGt       JSONOp = ">"
+	JSONGe       JSONOp = ">="
+	JSONLt       JSONOp = "<"
+	JSONLe       JSONOp = "<="
+	JSONContains JSONOp = "@>"
+	// JSONExists — путь есть в документе (условие без оператора: "meta.tag")
+	JSONExists JSONOp = "exists"
+	// JSONNotExists — пути нет в документе ("!meta.tag")
+	JSONNotExists JSONOp = "!exists"
+)
+
+// операторы в порядке разбора: двухсимвольные раньше односимвольных
+var jsonOps = []JSONOp{JSONEq, JSONNe, JSONGe, JSONLe, JSONContains, JSONGt, JSONLt}
+
+const maxFilterDepth = 16
+
+// JSONCondition — условие вида path op value. Path — ключи объектов и индексы массивов.
+type JSONCondition struct {
+	Path  []string
+	Op    JSONOp
+	Value json.RawMessage
+}
+
+// ParseJSONCondition разбирает условие из query-параметра where, например
+// `status=="active"`, `meta.priority>3`, `tags@>["urgent"]`, `meta.owner` или `!meta.owner`.
+// Значение — JSON-литерал; если это не JSON, оно считается строкой (status==active).
+func ParseJSONCondition(s string) (JSONCondition, error) {
+	i := strings.IndexAny(s, "=!<>@")
+	if i < 0 {
+		path, err := parseJSONPath(s)
+		return JSONCondition{Path: path, Op: JSONExists}, err
+	}
+	if i == 0 && s[0] == '!' && !strings.ContainsAny(s[1:], "=!<>@") {
+		path, err := parseJSONPath(s[1:])
+		return JSONCondition{Path: path, Op: JSONNotExists}, err
+	}
+	var op JSONOp
+	for _, candidate := range jsonOps {
+		if strings.HasPrefix(s[i:], string(candidate)) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return JSONCondition{}, fmt.Errorf("%w: unknown operator in %q", ErrInvalidFilter, s)
+	}
+	path, err := parseJSONPath(s[:i])
+	if err != nil {
+		return JSONCondition{}, err
+	}
+	value := json.RawMessage(strings.TrimSpace(s[i+len(op):]))
+	if !json.Valid(value) {
+		value, _ = json.Marshal(string(value))
+	}
+	cond := JSONCondition{Path: path, Op: op, Value: value}
+	if cond.Comparison() {
+		switch value[0] {
+		case '"', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		default:
+			return JSONCondition{}, fmt.Errorf("%w: %s compares only numbers and strings", ErrInvalidFilter, op)
+		}
+	}
+	return cond, nil
+}
+
+// Comparison сообщает, упорядочивающий ли это оператор (>, >=, <, <=)
+func (c JSONCondition) Comparison() bool {
+	return c.Op == JSONGt || c.Op == JSONGe || c.Op == JSONLt || c.Op == JSONLe
+}
+
+// KeysOnly сообщает, что путь проходит только по ключам объектов: такое условие
+// можно выразить через @> от корня документа и использовать GIN-индекс
+func (c JSONCondition) KeysOnly() bool {
+	for _, key := range c.Path {
+		if strings.Trim(key, "0123456789") == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Containment строит документ {"a":{"b":value}} для проверки json_data @> ...
+func (c JSONCondition) Containment() json.RawMessage {
+	doc := c.Value
+	for i := len(c.Path) - 1; i >= 0; i-- {
+		key, _ := json.Marshal(c.Path[i])
+		doc = json.RawMessage("{" + string(key) + ":" + string(doc) + "}")
+	}
+	return doc
+}
+
+func parseJSONPath(s string) ([]string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty path", ErrInvalidFilter)
+	}
+	path := strings.Split(s, ".")
+	if len(path) > maxFilterDepth {
+		return nil, fmt.Errorf("%w: path %q is too deep", ErrInvalidFilter, s)
+	}
+	for _, key := range path {
+		if key == "" {
+			return nil, fmt.Errorf("%w: empty key in path %q", ErrInvalidFilter, s)
+		}
+	}
+	return path, nil
+}
+
+// ListQuery — параметры выборки списка документов
+type ListQuery struct {
+	Limit int
+	// Where — условия на json_data, объединяются через AND
+	Where []JSONCondition
+	// Fields — читаемые поля (см. DocumentFields); пусто — все
+	Fields []string
+	// Tags — теги, которые должны быть у документа все сразу
+	Tags []string
+	// Metadata — пары метаданных, которые должны совпасть
+	Metadata Metadata
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+)
+
+// Folder — папка документов. Папки образуют дерево одного владельца: подпапки и
+// документы внутри принадлежат владельцу папки, гранты на папку действуют на всё содержимое.
+type Folder struct {
+	ID       string  `db:"id" json:"id"`
+	Owner    string  `db:"owner" json:"owner"`
+	ParentID *string `db:"parent_id" json:"parent_id,omitempty"`
+	Name     string  `db:"name" json:"name"`
+	// Path — id предков от корня и самой папки
+	Path pq.StringArray `db:"path" json:"-"`
+	// SchemaID — JSON Schema для документов, лежащих прямо в папке
+	SchemaID  *string   `db:"schema_id" json:"schema_id,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Contains сообщает, лежит ли other внутри f или совпадает с ней
+func (f *Folder) Contains(other *Folder) bool {
+	for _, id := range other.Path {
+		if id == f.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidName проверяет имя папки или документа: это один элемент пути /api/fs,
+// поэтому '/' недопустим, а пустые и состоящие из точек имена зарезервированы
+func ValidName(name string) bool {
+	n := utf8.RuneCountInString(name)
+	return n > 0 && n <= 255 && strings.TrimSpace(name) != "" && strings.Trim(name, ".") != "" && !strings.Contains(name, "/")
+}
+
+type CreateFolderRequest struct {
+	Name string `json:"name" example:"reports"`
+	// Parent — id родительской папки; без него папка создаётся в корне
+	Parent *string `json:"parent,omitempty"`
+}
+
+// UpdateFolderRequest — переименование, перенос и привязка схемы; отсутствующие поля не меняются
+type UpdateFolderRequest struct {
+	Name *string `json:"name,omitempty" example:"reports-2026"`
+	// Parent — id новой родительской папки, "" — перенести в корень
+	Parent *string `json:"parent,omitempty"`
+	// Schema — имя JSON Schema владельца для документов в папке, "" — отвязать
+	Schema *string `json:"schema,omitempty"`
+}
+
+// MoveDocumentsRequest переносит документы в папку ("" — в корень) одной транзакцией
+type MoveDocumentsRequest struct {
+	Documents []string `json:"documents"`
+	Folder    string   `json:"folder" example:"5f0c7a1e-2d3b-4c5d-8e9f-0a1b2c3d4e5f"`
+}
+
+// FolderContents — папка, уровень доступа к ней, подпапки и документы без json
+type FolderContents struct {
+	Folder     *Folder    `json:"folder"`
+	Permission Permission `json:"permission"`
+	Folders    []Folder   `json:"folders"`
+	Documents  []Document `json:"documents"`
+}
@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+const (
+	FSEntryFolder   = "folder"
+	FSEntryDocument = "document"
+)
+
+// FSNode — результат разрешения пути /api/fs/{login}/{path}: папка, документ или
+// корень владельца (Folder и Document пусты)
+type FSNode struct {
+	Owner string
+	Path  string
+	// Parent — папка, в которой лежит элемент; nil — корень
+	Parent   *Folder
+	Folder   *Folder
+	Document *Document
+}
+
+// FSEntry — элемент листинга каталога
+type FSEntry struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Type      string    `json:"type" example:"document"`
+	ID        string    `json:"id"`
+	Mime      string    `json:"mime,omitempty"`
+	File      bool      `json:"file,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FSListing — содержимое каталога: корня пользователя или папки
+type FSListing struct {
+	Path       string     `json:"path" example:"/reports/2026"`
+	Folder     *Folder    `json:"folder,omitempty"`
+	Permission Permission `json:"permission"`
+	Entries    []FSEntry  `json:"entries"`
+}
+
+// FSContent — новое содержимое документа для PUT /api/fs: JSON или файл
+type FSContent struct {
+	File bool
+	Mime string
+	JSON []byte
+}
+
+// FSMoveRequest переименовывает или переносит элемент в пределах пространства владельца
+type FSMoveRequest struct {
+	Path string `json:"path" example:"/reports/2026/q1-final.json"`
+}
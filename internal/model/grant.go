@@ -0,0 +1,78 @@
+package model
+
+import "time"
+
+// Permission — уровень доступа к документу. Каждый следующий уровень включает предыдущие.
+type Permission string
+
+const (
+	PermissionRead    Permission = "read"
+	PermissionComment Permission = "comment"
+	// PermissionWrite позволяет менять содержимое документа
+	PermissionWrite Permission = "write"
+	// PermissionShare позволяет выдавать доступ не выше собственного
+	PermissionShare Permission = "share"
+	// PermissionManage — права владельца: удаление документа и любые гранты
+	PermissionManage Permission = "manage"
+)
+
+var permissionRank = map[Permission]int{
+	PermissionRead:    1,
+	PermissionComment: 2,
+	PermissionWrite:   3,
+	PermissionShare:   4,
+	PermissionManage:  5,
+}
+
+func (p Permission) Valid() bool {
+	return permissionRank[p] > 0
+}
+
+// Includes сообщает, достаточно ли уровня p для действия, требующего other
+func (p Permission) Includes(other Permission) bool {
+	return other.Valid() && permissionRank[p] >= permissionRank[other]
+}
+
+// Grant — доступ субъекта ("user:<id>" или "group:<id>") к документу или папке
+type Grant struct {
+	DocumentID string `db:"document_id" json:"-"`
+	// FolderID — папка гранта; у унаследованного документом гранта — папка, от которой он пришёл
+	FolderID   string     `db:"folder_id" json:"folder_id,omitempty"`
+	Principal  string     `db:"principal" json:"principal"`
+	Permission Permission `db:"permission" json:"permission"`
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	GrantedBy  *string    `db:"granted_by" json:"granted_by,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+func (g Grant) Active(now time.Time) bool {
+	return g.ExpiresAt == nil || now.Before(*g.ExpiresAt)
+}
+
+type GrantRequest struct {
+	// Логин, "user:<login>" или "group:<name>"
+	Principal  string     `json:"principal" example:"group:accounting"`
+	Permission Permission `json:"permission" example:"read"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// Источники доступа в AccessEntry.Via
+const (
+	AccessViaOwner  = "owner"
+	AccessViaGrant  = "grant"
+	AccessViaGroup  = "group"
+	AccessViaPublic = "public"
+)
+
+// AccessEntry объясняет, почему у пользователя есть доступ к документу.
+// Для Via=public пользователь не указывается: доступ есть у всех.
+type AccessEntry struct {
+	UserID     string     `json:"user_id,omitempty"`
+	Login      string     `json:"login,omitempty"`
+	Permission Permission `json:"permission"`
+	Via        string     `json:"via"`
+	Group      string     `json:"group,omitempty"`
+	// Folder — папка, грант на которую унаследован документом
+	Folder    string     `json:"folder,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
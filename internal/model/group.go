@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+const (
+	GroupRoleMember = "member"
+	// GroupRoleOwner может менять состав группы и удалить её
+	GroupRoleOwner = "owner"
+)
+
+// Префиксы субъектов в грантах документа: "user:<id>" или "group:<id>"
+const (
+	PrincipalUser  = "user:"
+	PrincipalGroup = "group:"
+)
+
+type Group struct {
+	ID        string    `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	CreatedBy *string   `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type GroupMember struct {
+	GroupID string    `db:"group_id" json:"-"`
+	UserID  string    `db:"user_id" json:"user_id"`
+	Login   string    `db:"login" json:"login"`
+	Role    string    `db:"role" json:"role"`
+	AddedAt time.Time `db:"added_at" json:"added_at"`
+}
+
+type CreateGroupRequest struct {
+	Name string `json:"name" example:"accounting"`
+}
+
+type GroupMemberRequest struct {
+	Role string `json:"role" example:"member"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// ShareLink — ссылка на документ для скачивания без учётной записи
+type ShareLink struct {
+	ID           string     `db:"id" json:"id"`
+	DocumentID   string     `db:"document_id" json:"document_id"`
+	CreatedBy    *string    `db:"created_by" json:"created_by,omitempty"`
+	Permission   Permission `db:"permission" json:"permission"`
+	PasswordHash string     `db:"password_hash" json:"-"`
+	MaxDownloads *int       `db:"max_downloads" json:"max_downloads,omitempty"`
+	Downloads    int        `db:"downloads" json:"downloads"`
+	ExpiresAt    time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt    *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+func (l ShareLink) Protected() bool {
+	return l.PasswordHash != ""
+}
+
+type CreateLinkRequest struct {
+	Permission   Permission `json:"permission,omitempty" example:"read"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Password     string     `json:"password,omitempty"`
+	MaxDownloads *int       `json:"max_downloads,omitempty" example:"10"`
+}
+
+// ShareLinkView — ссылка в ответах API; токен возвращается только при создании
+type ShareLinkView struct {
+	ShareLink
+	PasswordProtected bool   `json:"password_protected"`
+	Token             string `json:"token,omitempty"`
+	URL               string `json:"url,omitempty"`
+}
@@ -1,10 +1,10 @@
 package model
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
-
-	"github.com/lib/pq"
 )
 
 func TestAPIError(t *testing.T) {
@@ -91,7 +91,10 @@ func TestSession(t *testing.T) {
 }
 
 func TestDocument(t *testing.T) {
-	grants := pq.StringArray{"read", "write"}
+	grants := []Grant{
+		{Principal: "user:u1", Permission: PermissionRead},
+		{Principal: "group:g1", Permission: PermissionWrite},
+	}
 	jsonData := []byte(`{"test": "data"}`)
 
 	doc := Document{
@@ -130,11 +133,11 @@ func TestDocument(t *testing.T) {
 	if len(doc.Grants) != 2 {
 		t.Fatalf("expected 2 grants, got %d", len(doc.Grants))
 	}
-	if doc.Grants[0] != "read" {
-		t.Fatalf("expected first grant 'read', got %s", doc.Grants[0])
+	if doc.Grants[0].Permission != PermissionRead {
+		t.Fatalf("expected first grant 'read', got %s", doc.Grants[0].Permission)
 	}
-	if doc.Grants[1] != "write" {
-		t.Fatalf("expected second grant 'write', got %s", doc.Grants[1])
+	if doc.Grants[1].Permission != PermissionWrite {
+		t.Fatalf("expected second grant 'write', got %s", doc.Grants[1].Permission)
 	}
 	if len(doc.JsonData) == 0 {
 		t.Fatal("expected JsonData to be set")
@@ -145,7 +148,7 @@ func TestDocument_EmptyGrants(t *testing.T) {
 	doc := Document{
 		ID:     "doc123",
 		Name:   "test.json",
-		Grants: pq.StringArray{},
+		Grants: []Grant{},
 	}
 
 	if len(doc.Grants) != 0 {
@@ -192,3 +195,177 @@ func TestDocument_Public(t *testing.T) {
 		t.Fatal("expected Public to be true")
 	}
 }
+
+func TestPermission_Includes(t *testing.T) {
+	if !PermissionManage.Includes(PermissionShare) || !PermissionWrite.Includes(PermissionRead) {
+		t.Fatal("expected higher level to include lower")
+	}
+	if PermissionRead.Includes(PermissionWrite) {
+		t.Fatal("expected read not to include write")
+	}
+	if Permission("").Includes(PermissionRead) || PermissionManage.Includes(Permission("owner")) {
+		t.Fatal("expected unknown levels to grant nothing")
+	}
+}
+
+func TestGrant_Active(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+	if !(Grant{}).Active(now) || !(Grant{ExpiresAt: &future}).Active(now) {
+		t.Fatal("expected grant without expiry or with future expiry to be active")
+	}
+	if (Grant{ExpiresAt: &past}).Active(now) {
+		t.Fatal("expected expired grant to be inactive")
+	}
+}
+
+func TestParseJSONCondition(t *testing.T) {
+	cases := []struct {
+		in    string
+		path  string
+		op    JSONOp
+		value string
+	}{
+		{`status=="active"`, "status", JSONEq, `"active"`},
+		{`status==active`, "status", JSONEq, `"active"`},
+		{`meta.priority>3`, "meta.priority", JSONGt, `3`},
+		{`meta.priority>=-1.5`, "meta.priority", JSONGe, `-1.5`},
+		{`tags@>["urgent"]`, "tags", JSONContains, `["urgent"]`},
+		{`archived!=true`, "archived", JSONNe, `true`},
+		{`meta.owner`, "meta.owner", JSONExists, ``},
+		{`!meta.owner`, "meta.owner", JSONNotExists, ``},
+	}
+	for _, tc := range cases {
+		cond, err := ParseJSONCondition(tc.in)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tc.in, err)
+		}
+		if strings.Join(cond.Path, ".") != tc.path || cond.Op != tc.op || string(cond.Value) != tc.value {
+			t.Fatalf("%s: unexpected condition %+v (value %s)", tc.in, cond, cond.Value)
+		}
+	}
+
+	for _, in := range []string{"", "a..b", "==1", "a=1", "a>true", "a<{}"} {
+		if _, err := ParseJSONCondition(in); !errors.Is(err, ErrInvalidFilter) {
+			t.Fatalf("%q: expected ErrInvalidFilter, got %v", in, err)
+		}
+	}
+}
+
+func TestJSONCondition_Containment(t *testing.T) {
+	cond, _ := ParseJSONCondition(`meta.status=="a\"b"`)
+	if !cond.KeysOnly() || string(cond.Containment()) != `{"meta":{"status":"a\"b"}}` {
+		t.Fatalf("unexpected containment %s", cond.Containment())
+	}
+	cond, _ = ParseJSONCondition(`items.0.name==x`)
+	if cond.KeysOnly() {
+		t.Fatal("expected numeric segment to disable containment")
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{" Invoice ", "2026", "invoice"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(tags, ",") != "invoice,2026" {
+		t.Fatalf("unexpected tags %v", tags)
+	}
+	for _, bad := range [][]string{{""}, {"  "}, {strings.Repeat("x", MaxTagLength+1)}, {"a\nb"}} {
+		if _, err := NormalizeTags(bad); !errors.Is(err, ErrInvalidTag) {
+			t.Fatalf("expected ErrInvalidTag for %q, got %v", bad, err)
+		}
+	}
+	many := make([]string, MaxTags+1)
+	for i := range many {
+		many[i] = strings.Repeat("t", i+1)
+	}
+	if _, err := NormalizeTags(many); !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("expected ErrInvalidTag for too many tags, got %v", err)
+	}
+}
+
+func TestMetadata_PatchAndValidate(t *testing.T) {
+	year := "2026"
+	m := Metadata{"customer": "acme", "stage": "draft"}.Patch(map[string]*string{"stage": nil, "year": &year})
+	if len(m) != 2 || m["customer"] != "acme" || m["year"] != "2026" {
+		t.Fatalf("unexpected metadata %v", m)
+	}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m := (Metadata{"a": "b"}).Patch(map[string]*string{"a": nil}); m != nil {
+		t.Fatalf("expected nil metadata, got %v", m)
+	}
+	if err := (Metadata{"bad key": "x"}).Validate(); !errors.Is(err, ErrInvalidMetadata) {
+		t.Fatalf("expected ErrInvalidMetadata, got %v", err)
+	}
+	if err := (Metadata{"k": strings.Repeat("x", MaxMetadataValue+1)}).Validate(); !errors.Is(err, ErrInvalidMetadata) {
+		t.Fatalf("expected ErrInvalidMetadata for long value, got %v", err)
+	}
+}
+
+func TestMetadata_Scan(t *testing.T) {
+	var m Metadata
+	if err := m.Scan([]byte(`{"customer":"acme"}`)); err != nil || m["customer"] != "acme" {
+		t.Fatalf("unexpected scan result %v, %v", m, err)
+	}
+	if err := m.Scan([]byte(`{}`)); err != nil || m != nil {
+		t.Fatalf("expected nil metadata for {}, got %v, %v", m, err)
+	}
+	v, err := Metadata(nil).Value()
+	if err != nil || v != "{}" {
+		t.Fatalf("expected {} for nil metadata, got %v, %v", v, err)
+	}
+}
+
+func TestParseMetadataFilter(t *testing.T) {
+	m, err := ParseMetadataFilter(map[string][]string{"meta.customer": {"acme"}, "tag": {"x"}, "limit": {"5"}})
+	if err != nil || len(m) != 1 || m["customer"] != "acme" {
+		t.Fatalf("unexpected filter %v, %v", m, err)
+	}
+	if m, err := ParseMetadataFilter(map[string][]string{"limit": {"5"}}); err != nil || m != nil {
+		t.Fatalf("expected no filter, got %v, %v", m, err)
+	}
+	if _, err := ParseMetadataFilter(map[string][]string{"meta.k": {"a", "b"}}); !errors.Is(err, ErrInvalidMetadata) {
+		t.Fatalf("expected ErrInvalidMetadata for repeated key, got %v", err)
+	}
+}
+
+func TestParseExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		in   string
+		want time.Time
+	}{
+		{"24h", now.Add(24 * time.Hour)},
+		{"90m", now.Add(90 * time.Minute)},
+		{"7d", now.Add(7 * 24 * time.Hour)},
+		{"2026-10-20T00:00:00Z", time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		at, err := ParseExpiry(c.in, now)
+		if err != nil || at == nil || !at.Equal(c.want) {
+			t.Fatalf("ParseExpiry(%q) = %v, %v; want %v", c.in, at, err, c.want)
+		}
+	}
+	if at, err := ParseExpiry("", now); err != nil || at != nil {
+		t.Fatalf("expected no expiry for empty string, got %v, %v", at, err)
+	}
+	for _, bad := range []string{"-1h", "0s", "soon", "2026-10-19T11:00:00Z", "xd"} {
+		if _, err := ParseExpiry(bad, now); !errors.Is(err, ErrInvalidExpiry) {
+			t.Fatalf("expected ErrInvalidExpiry for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestDocument_Expired(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Second), now.Add(time.Hour)
+	if (&Document{}).Expired(now) || (&Document{ExpiresAt: &future}).Expired(now) {
+		t.Fatal("expected document to be alive")
+	}
+	if !(&Document{ExpiresAt: &past}).Expired(now) {
+		t.Fatal("expected document to be expired")
+	}
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidQuota = errors.New("quota limits must not be negative")
+
+// QuotaLimits — действующие лимиты пользователя; 0 — без ограничения
+type QuotaLimits struct {
+	// MaxBytes — всего байт в файлах и JSON, включая корзину
+	MaxBytes int64 `json:"max_bytes" example:"10737418240"`
+	// MaxDocuments — число документов, включая корзину
+	MaxDocuments int64 `json:"max_documents" example:"100000"`
+	// MaxFileSize — размер одного файла или JSON
+	MaxFileSize int64 `json:"max_file_size" example:"1073741824"`
+}
+
+// QuotaOverride — личные лимиты пользователя, заданные администратором.
+// nil поле — берётся лимит по умолчанию, 0 — без ограничения.
+type QuotaOverride struct {
+	UserID       string    `db:"user_id" json:"-"`
+	MaxBytes     *int64    `db:"max_bytes" json:"max_bytes"`
+	MaxDocuments *int64    `db:"max_documents" json:"max_documents"`
+	MaxFileSize  *int64    `db:"max_file_size" json:"max_file_size"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Validate проверяет, что заданные лимиты не отрицательны
+func (o *QuotaOverride) Validate() error {
+	for _, v := range []*int64{o.MaxBytes, o.MaxDocuments, o.MaxFileSize} {
+		if v != nil && *v < 0 {
+			return ErrInvalidQuota
+		}
+	}
+	return nil
+}
+
+// Empty сообщает, что все лимиты берутся по умолчанию
+func (o *QuotaOverride) Empty() bool {
+	return o.MaxBytes == nil && o.MaxDocuments == nil && o.MaxFileSize == nil
+}
+
+// Apply накладывает личные лимиты на лимиты по умолчанию; nil override их не меняет
+func (o *QuotaOverride) Apply(def QuotaLimits) QuotaLimits {
+	if o == nil {
+		return def
+	}
+	if o.MaxBytes != nil {
+		def.MaxBytes = *o.MaxBytes
+	}
+	if o.MaxDocuments != nil {
+		def.MaxDocuments = *o.MaxDocuments
+	}
+	if o.MaxFileSize != nil {
+		def.MaxFileSize = *o.MaxFileSize
+	}
+	return def
+}
+
+// Usage — занятое пользователем место. Документы в корзине и истёкшие, но ещё
+// не удалённые очисткой, учитываются, пока их не удалят окончательно.
+type Usage struct {
+	Bytes     int64 `db:"bytes" json:"bytes"`
+	Documents int64 `db:"documents" json:"documents"`
+}
+
+// UsageReport — использование и действующие лимиты пользователя
+type UsageReport struct {
+	Usage  Usage       `json:"usage"`
+	Limits QuotaLimits `json:"limits"`
+	// Override — личные лимиты, если администратор их задал
+	Override *QuotaOverride `json:"override,omitempty"`
+}
@@ -3,6 +3,8 @@ package model
 type APIError struct {
 	Code int    `json:"code"`
 	Text string `json:"text"`
+	// Details — подробности ошибки, например нарушения JSON Schema
+	Details interface{} `json:"details,omitempty"`
 }
 
 type APIResponse struct {
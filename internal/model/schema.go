@@ -0,0 +1,34 @@
+package model
+
+import (
+	"astra-api/internal/jsonschema"
+	"encoding/json"
+	"time"
+)
+
+// JSONSchema — именованная JSON Schema (draft 2020-12) пользователя.
+// Документ с привязанной схемой проверяется при создании и каждом изменении json;
+// схема, привязанная к папке или тегу, проверяет JSON-документы в папке или с тегом.
+type JSONSchema struct {
+	ID        string          `db:"id" json:"id"`
+	Owner     string          `db:"owner" json:"-"`
+	Name      string          `db:"name" json:"name"`
+	Schema    json.RawMessage `db:"schema" json:"schema,omitempty" swaggertype:"object"`
+	Documents int             `db:"documents" json:"documents"`
+	Folders   int             `db:"folders" json:"folders"`
+	Tags      int             `db:"tags" json:"tags"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// SchemaConflict — документ, который перестанет соответствовать изменённой схеме
+type SchemaConflict struct {
+	DocumentID string                 `json:"document_id"`
+	Name       string                 `json:"name"`
+	Violations []jsonschema.Violation `json:"violations"`
+}
+
+// TagSchemaRequest — схема, привязываемая к тегу
+type TagSchemaRequest struct {
+	Schema string `json:"schema" example:"invoice"`
+}
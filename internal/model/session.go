@@ -4,8 +4,63 @@ import "time"
 
 // Session represents a user session
 type Session struct {
-	Token   string    `json:"token"`
-	UserID  string    `json:"user_id"`
-	Login   string    `json:"login"`
-	Created time.Time `json:"created"`
+	Token     string    `json:"token"`
+	UserID    string    `json:"user_id"`
+	Login     string    `json:"login"`
+	Roles     []string  `json:"roles,omitempty"`
+	Created   time.Time `json:"created"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	// ImpersonatorID and ImpersonatorLogin identify the admin acting as
+	// UserID. They are empty for regular sessions.
+	ImpersonatorID    string `json:"impersonator_id,omitempty"`
+	ImpersonatorLogin string `json:"impersonator_login,omitempty"`
+	// Stateless is set when the session was decoded from a signed token
+	// rather than looked up in server-side storage.
+	Stateless bool `json:"-"`
+}
+
+// Impersonated reports whether the session acts on behalf of another user
+func (s *Session) Impersonated() bool {
+	return s.ImpersonatorID != ""
+}
+
+// Expired reports whether a session with a limited lifetime is over
+func (s *Session) Expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
+}
+
+// TokenPair is issued on login when signed tokens are enabled
+type TokenPair struct {
+	AccessToken  string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// RefreshToken is the server-side record of an issued refresh token.
+// Tokens issued by rotation share the FamilyID of the original login.
+type RefreshToken struct {
+	ID        string     `db:"id"`
+	FamilyID  string     `db:"family_id"`
+	UserID    string     `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+// RevokedAccessToken — access-токен, отозванный до истечения его срока
+type RevokedAccessToken struct {
+	TokenID   string    `db:"token_id"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// AccessCutoff — отсечка: access-токены пользователя, выданные раньше NotBefore, недействительны
+type AccessCutoff struct {
+	UserID    string    `db:"user_id"`
+	NotBefore time.Time `db:"not_before"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" example:"3q2-7wAAAAA"`
 }
@@ -0,0 +1,150 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	MaxTags           = 32
+	MaxTagLength      = 64
+	MaxMetadataKeys   = 64
+	MaxMetadataValue  = 1024
+	metadataKeyPrefix = "meta."
+)
+
+var (
+	ErrInvalidTag      = errors.New("invalid tag")
+	ErrInvalidMetadata = errors.New("invalid metadata")
+)
+
+var metadataKeyRe = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// Metadata — пользовательские пары ключ/значение документа, хранятся в jsonb
+type Metadata map[string]string
+
+func (m Metadata) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(map[string]string(m))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (m *Metadata) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Metadata", src)
+	}
+	var out map[string]string
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	if len(out) == 0 {
+		out = nil
+	}
+	*m = out
+	return nil
+}
+
+// Patch применяет merge patch: значение заменяет ключ, null удаляет его
+func (m Metadata) Patch(patch map[string]*string) Metadata {
+	out := make(Metadata, len(m)+len(patch))
+	for k, v := range m {
+		out[k] = v
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(out, k)
+		} else {
+			out[k] = *v
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// Validate проверяет ключи (латиница, цифры, _ . -, до 64 символов), длину значений и число ключей
+func (m Metadata) Validate() error {
+	if len(m) > MaxMetadataKeys {
+		return fmt.Errorf("%w: at most %d keys", ErrInvalidMetadata, MaxMetadataKeys)
+	}
+	for k, v := range m {
+		if !metadataKeyRe.MatchString(k) {
+			return fmt.Errorf("%w: key %q must be 1-64 characters of A-Z, a-z, 0-9, _ . -", ErrInvalidMetadata, k)
+		}
+		if utf8.RuneCountInString(v) > MaxMetadataValue {
+			return fmt.Errorf("%w: value of %q is longer than %d characters", ErrInvalidMetadata, k, MaxMetadataValue)
+		}
+	}
+	return nil
+}
+
+// NormalizeTags приводит теги к нижнему регистру, обрезает пробелы по краям и убирает
+// повторы, сохраняя порядок. Тег — 1-64 символа без управляющих, не больше MaxTags на документ.
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || utf8.RuneCountInString(t) > MaxTagLength || strings.IndexFunc(t, unicode.IsControl) >= 0 {
+			return nil, fmt.Errorf("%w %q: must be 1-%d characters", ErrInvalidTag, t, MaxTagLength)
+		}
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	if len(out) > MaxTags {
+		return nil, fmt.Errorf("%w: at most %d tags per document", ErrInvalidTag, MaxTags)
+	}
+	return out, nil
+}
+
+// ParseMetadataFilter собирает условия meta.<key>=value из параметров запроса
+func ParseMetadataFilter(query map[string][]string) (Metadata, error) {
+	var filter Metadata
+	for param, values := range query {
+		key, ok := strings.CutPrefix(param, metadataKeyPrefix)
+		if !ok {
+			continue
+		}
+		if !metadataKeyRe.MatchString(key) || len(values) != 1 {
+			return nil, fmt.Errorf("%w filter %q", ErrInvalidMetadata, param)
+		}
+		if filter == nil {
+			filter = Metadata{}
+		}
+		filter[key] = values[0]
+	}
+	return filter, nil
+}
+
+// TagCount — тег и число документов с ним
+type TagCount struct {
+	Tag   string `db:"tag" json:"tag"`
+	Count int    `db:"count" json:"count"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// UploadReservation — одноразовое право загрузить файл в документ, созданный вместе
+// с резервом и скрытый до загрузки. Неиспользованные резервы удаляются по сроку.
+type UploadReservation struct {
+	ID    string `db:"id" json:"id"`
+	Owner string `db:"owner_id" json:"owner"`
+	Name  string `db:"name" json:"name"`
+	// Mime — заявленный тип; пустой — тип берётся из Content-Type загрузки
+	Mime string `db:"mime" json:"mime"`
+	Size *int64 `db:"size" json:"size,omitempty"`
+	// DocumentExpiresAt — срок жизни документа после загрузки (expires_at из запроса)
+	DocumentExpiresAt *time.Time `db:"document_expires_at" json:"-"`
+	ExpiresAt         time.Time  `db:"expires_at" json:"expires_at"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+}
+
+// DocumentMeta — метаданные нового документа, общие для meta у POST /api/docs
+// и ссылки на загрузку
+type DocumentMeta struct {
+	Name   string   `json:"name" example:"report.pdf"`
+	Mime   string   `json:"mime,omitempty" example:"application/pdf"`
+	Public bool     `json:"public,omitempty"`
+	Grants []string `json:"grants,omitempty"`
+	// Schema — имя JSON Schema загружающего, которой должен соответствовать json
+	Schema string `json:"schema,omitempty"`
+	// Folder — id папки, в которую кладётся документ
+	Folder   string   `json:"folder,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Metadata Metadata `json:"metadata,omitempty"`
+	// ExpiresAt — время RFC 3339 или длительность от момента загрузки
+	ExpiresAt string `json:"expires_at,omitempty" example:"24h"`
+}
+
+// UploadURLRequest — метаданные будущего документа и заявленный размер файла
+type UploadURLRequest struct {
+	DocumentMeta
+	Size *int64 `json:"size,omitempty" example:"1048576"`
+}
+
+type UploadURLResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Method    string    `json:"method" example:"PUT"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
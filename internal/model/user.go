@@ -1,6 +1,13 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RoleAdmin даёт доступ к административным операциям
+const RoleAdmin = "admin"
 
 // User пользователь системы
 // @Description Модель пользователя
@@ -13,10 +20,23 @@ import "time"
 // @Description password — строка (hash)
 // @Description created_at — строка (timestamp)
 type User struct {
-	ID        string    `db:"id" json:"id" example:"b1a7c8e2-1c2d-4e5f-8a7b-2c3d4e5f6a7b"`
-	Login     string    `db:"login" json:"login" example:"TestUser01"`
-	Password  string    `db:"password" json:"-"`
-	CreatedAt time.Time `db:"created_at" json:"created_at" example:"2024-08-26T10:30:56Z"`
+	ID        string         `db:"id" json:"id" example:"b1a7c8e2-1c2d-4e5f-8a7b-2c3d4e5f6a7b"`
+	Login     string         `db:"login" json:"login" example:"TestUser01"`
+	Password  string         `db:"password" json:"-"`
+	Roles     pq.StringArray `db:"roles" json:"roles"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at" example:"2024-08-26T10:30:56Z"`
+	// DeleteAfter задан, если пользователь запросил удаление аккаунта
+	DeleteAfter *time.Time `db:"delete_after" json:"delete_after,omitempty"`
+}
+
+// HasRole сообщает, назначена ли пользователю роль
+func (u *User) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
 }
 
 type RegisterRequest struct {
@@ -30,6 +50,10 @@ type AuthRequest struct {
 	Pswd  string `json:"pswd" example:"Qwerty123!"`
 }
 
+type DeleteAccountRequest struct {
+	Pswd string `json:"pswd" example:"Qwerty123!"`
+}
+
 type UserNotFoundError struct{}
 
 func (e *UserNotFoundError) Error() string {
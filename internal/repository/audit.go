@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"astra-api/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) Create(entry *model.AuditEntry) error {
+	_, err := r.db.Exec(`INSERT INTO audit_log (id, actor_id, user_id, action, method, path, status, details, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`, entry.ID, entry.ActorID, entry.UserID, entry.Action, entry.Method, entry.Path, entry.Status, entry.Details, entry.CreatedAt)
+	return err
+}
+
+// List возвращает последние записи; пустой actorID означает записи всех администраторов
+func (r *AuditRepository) List(actorID string, limit int) ([]model.AuditEntry, error) {
+	entries := []model.AuditEntry{}
+	err := r.db.Select(&entries, `SELECT id, actor_id, user_id, action, method, path, status, details, created_at FROM audit_log WHERE ($1 = '' OR actor_id::text = $1) ORDER BY created_at DESC LIMIT $2`, actorID, limit)
+	return entries, err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"astra-api/internal/storage"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// BlobKeyRepository хранит ключи данных зашифрованных файлов (storage.KeyStore)
+type BlobKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewBlobKeyRepository(db *sqlx.DB) *BlobKeyRepository {
+	return &BlobKeyRepository{db: db}
+}
+
+func (r *BlobKeyRepository) PutKey(k *storage.WrappedKey) error {
+	_, err := r.db.Exec(`INSERT INTO blob_keys (blob_key, version, master_key_id, wrapped_key) VALUES ($1, $2, $3, $4)`,
+		k.BlobKey, k.Version, k.MasterKeyID, k.Key)
+	return err
+}
+
+// GetKey возвращает ключ версии файла или storage.ErrNotFound
+func (r *BlobKeyRepository) GetKey(blobKey, version string) (*storage.WrappedKey, error) {
+	var k storage.WrappedKey
+	err := r.db.Get(&k, `SELECT blob_key, version, master_key_id, wrapped_key FROM blob_keys WHERE blob_key = $1 AND version = $2`,
+		blobKey, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// DeleteKey удаляет одну версию ключа файла
+func (r *BlobKeyRepository) DeleteKey(blobKey, version string) error {
+	_, err := r.db.Exec(`DELETE FROM blob_keys WHERE blob_key = $1 AND version = $2`, blobKey, version)
+	return err
+}
+
+// DeleteKeys удаляет все версии ключа файла, кроме keep
+func (r *BlobKeyRepository) DeleteKeys(blobKey, keep string) error {
+	_, err := r.db.Exec(`DELETE FROM blob_keys WHERE blob_key = $1 AND version <> $2`, blobKey, keep)
+	return err
+}
+
+// ListStale возвращает ключи, зашифрованные не мастер-ключом masterKeyID
+func (r *BlobKeyRepository) ListStale(masterKeyID string, limit int) ([]storage.WrappedKey, error) {
+	keys := []storage.WrappedKey{}
+	err := r.db.Select(&keys, `SELECT blob_key, version, master_key_id, wrapped_key FROM blob_keys
+		WHERE master_key_id <> $1 ORDER BY blob_key, version LIMIT $2`, masterKeyID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// UpdateKey сохраняет перешифрованный ключ, если с момента чтения его не перешифровали и не удалили
+func (r *BlobKeyRepository) UpdateKey(k *storage.WrappedKey, oldMasterKeyID string) error {
+	_, err := r.db.Exec(`UPDATE blob_keys SET master_key_id = $1, wrapped_key = $2
+		WHERE blob_key = $3 AND version = $4 AND master_key_id = $5`,
+		k.MasterKeyID, k.Key, k.BlobKey, k.Version, oldMasterKeyID)
+	return err
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"astra-api/internal/model"
 	"database/sql"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -16,48 +18,464 @@ func NewDocumentRepository(db *sqlx.DB) *DocumentRepository {
 	return &DocumentRepository{db: db}
 }
 
+// execer — общее у *sqlx.DB и *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// Create сохраняет документ вместе с его грантами в одной транзакции
 func (r *DocumentRepository) Create(doc *model.Document) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := insertDocument(tx, doc); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *DocumentRepository) CreateTx(tx *sql.Tx, doc *model.Document) error {
+	return insertDocument(tx, doc)
+}
+
+func insertDocument(ex execer, doc *model.Document) error {
 	var jsonArg interface{}
 	if len(doc.JsonData) > 0 {
 		jsonArg = string(doc.JsonData)
 	} else {
 		jsonArg = nil
 	}
-	_, err := r.db.Exec(`INSERT INTO documents (id, name, mime, file, public, owner, created_at, grants, json_data) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9::jsonb)`, doc.ID, doc.Name, doc.Mime, doc.File, doc.Public, doc.Owner, doc.CreatedAt, pq.Array(doc.Grants), jsonArg)
+	if err := releaseExpiredPath(ex, doc.Owner, doc.FolderID, doc.Name); err != nil {
+		return err
+	}
+	_, err := ex.Exec(`INSERT INTO documents (id, name, mime, file, public, owner, created_at, json_data, schema_id, folder_id, tags, metadata, expires_at, size, declared_mime, encoding, stored_size, scan_status, scan_queued_at, upload_pending) VALUES ($1,$2,$3,$4,$5,$6,$7,$8::jsonb,$9,$10,$11,$12::jsonb,$13,$14,$15,$16,$17,$18,$19,$20)`, doc.ID, doc.Name, doc.Mime, doc.File, doc.Public, doc.Owner, doc.CreatedAt, jsonArg, doc.SchemaID, doc.FolderID, tagsArg(doc.Tags), doc.Metadata, doc.ExpiresAt, sizeArg(doc), doc.DeclaredMime, doc.Encoding, doc.StoredSize, doc.ScanStatus, doc.ScanQueuedAt, doc.UploadPending)
+	if err != nil {
+		return documentWriteError(err)
+	}
+	for i := range doc.Grants {
+		g := &doc.Grants[i]
+		g.DocumentID = doc.ID
+		if err := upsertGrant(ex, g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseExpiredPath освобождает путь от документа с истёкшим сроком жизни, которого
+// ещё не удалила фоновая очистка: он не виден, но держал бы имя в documents_path_key.
+// Документ уходит в корзину с deleted_at = expires_at — очистка всё равно удалит его
+// вместе с файлом, ListExpired видит и документы в корзине.
+func releaseExpiredPath(ex execer, owner string, folderID *string, name string) error {
+	_, err := ex.Exec(`UPDATE documents SET deleted_at = expires_at
+		WHERE owner = $1 AND folder_id IS NOT DISTINCT FROM $2 AND name = $3 AND deleted_at IS NULL AND expires_at <= NOW()`, owner, folderID, name)
 	return err
 }
 
-func (r *DocumentRepository) CreateTx(tx *sql.Tx, doc *model.Document) error {
+// Update сохраняет изменяемые поля документа: имя, публичность, JSON, схему, mime, теги,
+// метаданные, срок жизни, размер и время замены содержимого. Размер файла без Size не меняется.
+func (r *DocumentRepository) Update(doc *model.Document) error {
 	var jsonArg interface{}
 	if len(doc.JsonData) > 0 {
 		jsonArg = string(doc.JsonData)
-	} else {
-		jsonArg = nil
 	}
-	_, err := tx.Exec(`INSERT INTO documents (id, name, mime, file, public, owner, created_at, grants, json_data) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9::jsonb)`, doc.ID, doc.Name, doc.Mime, doc.File, doc.Public, doc.Owner, doc.CreatedAt, pq.Array(doc.Grants), jsonArg)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := releaseExpiredPath(tx, doc.Owner, doc.FolderID, doc.Name); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE documents SET name = $2, public = $3, json_data = $4::jsonb, schema_id = $5, mime = $6, tags = $7, metadata = $8::jsonb, expires_at = $9, size = COALESCE($10, size), declared_mime = $11, encoding = $12, stored_size = $13, modified_at = COALESCE($14, modified_at) WHERE id = $1`, doc.ID, doc.Name, doc.Public, jsonArg, doc.SchemaID, doc.Mime, tagsArg(doc.Tags), doc.Metadata, doc.ExpiresAt, sizeArg(doc), doc.DeclaredMime, doc.Encoding, doc.StoredSize, doc.ModifiedAt)
+	if err != nil {
+		return documentWriteError(err)
+	}
+	return tx.Commit()
+}
+
+// UpdateFile сохраняет документ, как Update, вместе с новым ключом файла doc.BlobKey и
+// состоянием проверки антивирусом, и возвращает ключ прежнего файла: его можно удалять,
+// документ на него больше не ссылается. Новое содержимое и его проверка видны вместе:
+// до записи документ отдаёт старый файл с результатом старой проверки
+func (r *DocumentRepository) UpdateFile(doc *model.Document) (string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	if err := releaseExpiredPath(tx, doc.Owner, doc.FolderID, doc.Name); err != nil {
+		return "", err
+	}
+	var previous string
+	err = tx.QueryRow(`WITH old AS (
+			SELECT id, COALESCE(blob_key, id::text) AS blob_key FROM documents WHERE id = $1 FOR UPDATE
+		)
+		UPDATE documents d SET name = $2, public = $3, schema_id = $4, mime = $5, tags = $6, metadata = $7::jsonb, expires_at = $8, size = $9, declared_mime = $10, encoding = $11, stored_size = $12, blob_key = $13, modified_at = $14,
+			scan_status = $15, scan_signature = $16, scanned_at = $17, scan_queued_at = $18
+		FROM old WHERE d.id = old.id RETURNING old.blob_key`, doc.ID, doc.Name, doc.Public, doc.SchemaID, doc.Mime, tagsArg(doc.Tags), doc.Metadata, doc.ExpiresAt, doc.Size, doc.DeclaredMime, doc.Encoding, doc.StoredSize, doc.BlobKey, doc.ModifiedAt,
+		doc.ScanStatus, doc.ScanSignature, doc.ScannedAt, doc.ScanQueuedAt).Scan(&previous)
+	if err != nil {
+		return "", documentWriteError(err)
+	}
+	return previous, tx.Commit()
+}
+
+// sizeArg возвращает размер для колонки size: у JSON-документа он считается по
+// содержимому и записывается в doc.Size, у файла берётся из doc.Size
+func sizeArg(doc *model.Document) *int64 {
+	if !doc.File {
+		size := int64(len(doc.JsonData))
+		doc.Size = &size
+	}
+	return doc.Size
+}
+
+// tagsArg передаёт пустой список вместо NULL: колонка tags NOT NULL
+func tagsArg(tags pq.StringArray) pq.StringArray {
+	if tags == nil {
+		return pq.StringArray{}
+	}
+	return tags
+}
+
+func (r *DocumentRepository) List(owner string, q model.ListQuery) ([]model.Document, error) {
+	docs := []model.Document{}
+	where, args := listConditions(q, []interface{}{owner, q.Limit})
+	err := r.db.Select(&docs, `SELECT `+documentColumns("", q.Fields)+` FROM documents WHERE owner = $1 AND `+liveSQL("")+where+` ORDER BY name, created_at DESC LIMIT $2`, args...)
+	return docs, err
 }
 
-func (r *DocumentRepository) List(owner string, limit int) ([]model.Document, error) {
+// liveSQL — условие «документ не в корзине, его срок жизни не истёк и файл уже загружен»;
+// prefix — алиас таблицы с точкой
+func liveSQL(prefix string) string {
+	return prefix + `deleted_at IS NULL AND NOT ` + prefix + `upload_pending AND (` + prefix + `expires_at IS NULL OR ` + prefix + `expires_at > NOW())`
+}
+
+// grantedToSQL — условие «документу d выдан действующий грант одному из субъектов
+// principals»: напрямую или на папку, в которой он лежит, на любом уровне вложенности
+func grantedToSQL(principals string) string {
+	return `(EXISTS (
+			SELECT 1 FROM document_grants g
+			WHERE g.document_id = d.id AND g.principal = ANY(` + principals + `::text[]) AND (g.expires_at IS NULL OR g.expires_at > NOW())
+		) OR EXISTS (
+			SELECT 1 FROM folders f JOIN folder_grants fg ON fg.folder_id = ANY(f.path)
+			WHERE f.id = d.folder_id AND fg.principal = ANY(` + principals + `::text[]) AND (fg.expires_at IS NULL OR fg.expires_at > NOW())
+		))`
+}
+
+// ListVisible возвращает документы владельца, доступные смотрящему: публичные
+// и выданные одному из его субъектов (user:<id>, group:<id>) грантом, срок которого не истёк
+func (r *DocumentRepository) ListVisible(owner string, principals []string, q model.ListQuery) ([]model.Document, error) {
 	docs := []model.Document{}
-	err := r.db.Select(&docs, `SELECT id, name, mime, file, public, owner, created_at, grants::text[] as grants, json_data FROM documents WHERE owner = $1 ORDER BY name, created_at DESC LIMIT $2`, owner, limit)
+	where, args := listConditions(q, []interface{}{owner, pq.Array(principals), q.Limit})
+	err := r.db.Select(&docs, `SELECT `+documentColumns("d.", q.Fields)+` FROM documents d
+		WHERE d.owner = $1 AND `+liveSQL("d.")+` AND (d.public OR `+grantedToSQL("$2")+`)`+where+`
+		ORDER BY d.name, d.created_at DESC LIMIT $3`, args...)
 	return docs, err
 }
 
+// ListAccessible возвращает чужие документы, доступные пользователю: публичные и
+// выданные одному из его субъектов действующим грантом. С includeOwned добавляются и свои.
+func (r *DocumentRepository) ListAccessible(userID string, principals []string, includeOwned bool, q model.ListQuery) ([]model.Document, error) {
+	docs := []model.Document{}
+	where, args := listConditions(q, []interface{}{userID, pq.Array(principals), includeOwned, q.Limit})
+	err := r.db.Select(&docs, `SELECT `+documentColumns("d.", q.Fields)+` FROM documents d
+		WHERE `+liveSQL("d.")+` AND CASE WHEN d.owner = $1 THEN $3 ELSE (d.public OR `+grantedToSQL("$2")+`) END`+where+`
+		ORDER BY d.name, d.created_at DESC LIMIT $4`, args...)
+	return docs, err
+}
+
+// documentColumnNames сопоставляет поля model.DocumentFields колонкам таблицы documents
+var documentColumnNames = map[string]string{
+	"id": "id", "name": "name", "mime": "mime", "file": "file", "public": "public",
+	"owner": "owner", "created": "created_at", "folder_id": "folder_id", "schema_id": "schema_id",
+	"tags": "tags", "metadata": "metadata", "expires_at": "expires_at", "size": "size", "declared_mime": "declared_mime", "encoding": "encoding", "stored_size": "stored_size", "scan_status": "scan_status", "scan_signature": "scan_signature", "scanned_at": "scanned_at", "json": "json_data",
+}
+
+// documentColumns строит список колонок для SELECT. Поля берутся только из
+// model.DocumentFields, поэтому в запрос не попадает пользовательский текст.
+// expires_at читается всегда: по нему кэш списка истекает вместе с первым истекающим документом.
+func documentColumns(prefix string, fields []string) string {
+	if len(fields) == 0 {
+		fields = model.DocumentFields
+	} else if !containsField(fields, "expires_at") {
+		fields = append(fields[:len(fields):len(fields)], "expires_at")
+	}
+	cols := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if col, ok := documentColumnNames[f]; ok {
+			cols = append(cols, prefix+col)
+		}
+	}
+	return strings.Join(cols, ", ")
+}
+
+// CountTags считает документы по тегам. owned — свои документы пользователя, shared — чужие
+// публичные и выданные одному из его субъектов действующим грантом. Сортировка — по убыванию числа.
+func (r *DocumentRepository) CountTags(userID string, principals []string, owned, shared bool) ([]model.TagCount, error) {
+	counts := []model.TagCount{}
+	err := r.db.Select(&counts, `SELECT t.tag, COUNT(*) AS count
+		FROM documents d, unnest(d.tags) AS t(tag)
+		WHERE `+liveSQL("d.")+` AND CASE WHEN d.owner = $1 THEN $3 ELSE $4 AND (d.public OR `+grantedToSQL("$2")+`) END
+		GROUP BY t.tag ORDER BY count DESC, t.tag`, userID, pq.Array(principals), owned, shared)
+	return counts, err
+}
+
+func containsField(fields []string, f string) bool {
+	for _, v := range fields {
+		if v == f {
+			return true
+		}
+	}
+	return false
+}
+
+// Search ищет по имени и строковым значениям JSON среди документов, которые пользователь
+// может читать: своих, публичных и выданных одному из его субъектов действующим грантом.
+// Запрос разбирается как в поисковиках (websearch_to_tsquery) в русской и английской конфигурациях.
+// Фрагмент строится только для отобранных limit документов; текст в нём экранирован как HTML,
+// разметка — только <mark>.
+func (r *DocumentRepository) Search(userID string, principals []string, query string, limit int) ([]model.SearchResult, error) {
+	results := []model.SearchResult{}
+	err := r.db.Select(&results, `WITH q AS (
+			SELECT websearch_to_tsquery('russian', $3) || websearch_to_tsquery('english', $3) AS query
+		), hits AS (
+			SELECT d.id, d.name, d.mime, d.file, d.public, d.owner, d.created_at, d.json_data,
+				ts_rank_cd(d.search_vector, q.query) AS rank
+			FROM documents d, q
+			WHERE d.search_vector @@ q.query AND `+liveSQL("d.")+` AND (d.owner = $1 OR d.public OR `+grantedToSQL("$2")+`)
+			ORDER BY rank DESC, d.created_at DESC LIMIT $4
+		)
+		SELECT h.id, h.name, h.mime, h.file, h.public, h.owner, h.created_at, h.rank,
+			ts_headline('russian',
+				`+htmlEscapeSQL(`h.name || ' ' || coalesce((
+					SELECT string_agg(v #>> '{}', ' ')
+					FROM jsonb_path_query(h.json_data, 'strict $.** ? (@.type() == "string")') AS v
+				), '')`)+`,
+				q.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') AS snippet
+		FROM hits h, q
+		ORDER BY h.rank DESC, h.created_at DESC`, userID, pq.Array(principals), query, limit)
+	return results, err
+}
+
+// htmlEscapeSQL экранирует спецсимволы HTML в текстовом выражении SQL: ts_headline
+// возвращает исходный текст как есть, а фрагмент поиска отдаётся клиенту как HTML
+func htmlEscapeSQL(expr string) string {
+	return `replace(replace(replace(replace(replace(` + expr + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+}
+
+// ForEachByOwner построчно обходит все документы владельца, включая корзину, не загружая их в память разом
+func (r *DocumentRepository) ForEachByOwner(owner string, fn func(doc *model.Document) error) error {
+	rows, err := r.db.Queryx(`SELECT id, name, mime, file, public, owner, created_at, modified_at, folder_id, schema_id, tags, metadata, expires_at, size, declared_mime, encoding, blob_key, stored_size, scan_status, scan_signature, scanned_at, deleted_at, json_data FROM documents WHERE owner = $1 AND NOT upload_pending ORDER BY created_at`, owner)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc model.Document
+		if err := rows.StructScan(&doc); err != nil {
+			return err
+		}
+		if err := fn(&doc); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (r *DocumentRepository) GetByID(id string) (*model.Document, error) {
 	var doc model.Document
-	err := r.db.Get(&doc, `SELECT id, name, mime, file, public, owner, created_at, grants::text[] as grants, json_data FROM documents WHERE id = $1`, id)
+	err := r.db.Get(&doc, `SELECT id, name, mime, file, public, owner, created_at, modified_at, folder_id, schema_id, tags, metadata, expires_at, size, declared_mime, encoding, blob_key, stored_size, scan_status, scan_signature, scanned_at, json_data FROM documents WHERE id = $1 AND `+liveSQL(""), id)
 	if err != nil {
 		return nil, err
 	}
 	return &doc, nil
 }
 
+// GetByIDTx читает документ в транзакции и блокирует его строку до конца транзакции
+func (r *DocumentRepository) GetByIDTx(tx *sql.Tx, id string) (*model.Document, error) {
+	var doc model.Document
+	err := scanTx(r.db, tx).Get(&doc, `SELECT id, name, mime, file, public, owner, created_at, modified_at, folder_id, schema_id, tags, metadata, expires_at, size, declared_mime, encoding, blob_key, stored_size, scan_status, scan_signature, scanned_at, json_data FROM documents WHERE id = $1 AND `+liveSQL("")+` FOR UPDATE`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// GetByPath ищет документ владельца по имени в папке (nil — в корне)
+func (r *DocumentRepository) GetByPath(owner string, folderID *string, name string) (*model.Document, error) {
+	var doc model.Document
+	err := r.db.Get(&doc, `SELECT id, name, mime, file, public, owner, created_at, modified_at, folder_id, schema_id, tags, metadata, expires_at, size, declared_mime, encoding, blob_key, stored_size, scan_status, scan_signature, scanned_at, json_data FROM documents WHERE owner = $1 AND folder_id IS NOT DISTINCT FROM $2 AND name = $3 AND `+liveSQL(""), owner, folderID, name)
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// Trash переносит документ в корзину; false — документа нет или он уже в корзине
+func (r *DocumentRepository) Trash(id string, at time.Time) (bool, error) {
+	res, err := r.db.Exec(`UPDATE documents SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`, id, at)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// Restore возвращает документ из корзины; false — его там нет или его срок жизни истёк.
+// Путь к этому времени может быть занят другим документом — тогда ErrDuplicate.
+func (r *DocumentRepository) Restore(id string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec(`UPDATE documents e SET deleted_at = e.expires_at FROM documents t
+		WHERE t.id = $1 AND e.owner = t.owner AND e.folder_id IS NOT DISTINCT FROM t.folder_id AND e.name = t.name
+			AND e.deleted_at IS NULL AND e.expires_at <= NOW()`, id)
+	if err != nil {
+		return false, err
+	}
+	res, err := tx.Exec(`UPDATE documents SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL AND (expires_at IS NULL OR expires_at > NOW())`, id)
+	if err != nil {
+		return false, uniqueViolation(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n != 1 {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// GetTrashed возвращает документ из корзины, срок жизни которого не истёк
+func (r *DocumentRepository) GetTrashed(id string) (*model.Document, error) {
+	var doc model.Document
+	err := r.db.Get(&doc, `SELECT id, name, mime, file, public, owner, created_at, modified_at, folder_id, schema_id, tags, metadata, expires_at, size, declared_mime, encoding, blob_key, stored_size, scan_status, scan_signature, scanned_at, deleted_at, json_data FROM documents WHERE id = $1 AND deleted_at IS NOT NULL AND (expires_at IS NULL OR expires_at > NOW())`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// ListTrash возвращает документы владельца в корзине без содержимого и с неистёкшим сроком
+// жизни, недавно удалённые первыми
+func (r *DocumentRepository) ListTrash(owner string, limit int) ([]model.Document, error) {
+	docs := []model.Document{}
+	err := r.db.Select(&docs, `SELECT id, name, mime, file, public, owner, created_at, modified_at, folder_id, schema_id, tags, metadata, expires_at, size, declared_mime, encoding, blob_key, stored_size, scan_status, scan_signature, scanned_at, deleted_at FROM documents
+		WHERE owner = $1 AND deleted_at IS NOT NULL AND (expires_at IS NULL OR expires_at > NOW()) ORDER BY deleted_at DESC, id LIMIT $2`, owner, limit)
+	return docs, err
+}
+
+// ListTrashedBefore возвращает до limit документов, попавших в корзину раньше before
+func (r *DocumentRepository) ListTrashedBefore(before time.Time, limit int) ([]model.Document, error) {
+	docs := []model.Document{}
+	err := r.db.Select(&docs, `SELECT id, name, mime, file, public, owner, created_at, folder_id, deleted_at, blob_key FROM documents
+		WHERE deleted_at < $1 ORDER BY deleted_at LIMIT $2`, before, limit)
+	return docs, err
+}
+
+// ListExpired возвращает до limit документов, срок жизни которых истёк к now, включая лежащие в корзине
+func (r *DocumentRepository) ListExpired(now time.Time, limit int) ([]model.Document, error) {
+	docs := []model.Document{}
+	err := r.db.Select(&docs, `SELECT id, name, mime, file, public, owner, created_at, folder_id, expires_at, blob_key FROM documents
+		WHERE expires_at <= $1 ORDER BY expires_at LIMIT $2`, now, limit)
+	return docs, err
+}
+
+// ListUnsized возвращает до limit файлов, размер которых ещё не посчитан
+func (r *DocumentRepository) ListUnsized(limit int) ([]model.Document, error) {
+	docs := []model.Document{}
+	err := r.db.Select(&docs, `SELECT id, name, mime, file, public, owner, created_at FROM documents
+		WHERE size IS NULL AND NOT upload_pending ORDER BY id LIMIT $1`, limit)
+	return docs, err
+}
+
+// ListLegacyFiles возвращает файлы без посчитанного или с нулевым размером — среди них
+// загруженные, когда файлы хранились под именем документа, — от новых к старым,
+// начиная после документа after (nil — с самого нового)
+func (r *DocumentRepository) ListLegacyFiles(after *model.Document, limit int) ([]model.Document, error) {
+	docs := []model.Document{}
+	const columns = `SELECT id, name, mime, file, public, owner, created_at, size FROM documents WHERE file AND NOT upload_pending AND blob_key IS NULL AND (size IS NULL OR size = 0)`
+	if after == nil {
+		err := r.db.Select(&docs, columns+` ORDER BY created_at DESC, id DESC LIMIT $1`, limit)
+		return docs, err
+	}
+	err := r.db.Select(&docs, columns+` AND (created_at, id) < ($1, $2) ORDER BY created_at DESC, id DESC LIMIT $3`, after.CreatedAt, after.ID, limit)
+	return docs, err
+}
+
+// CompleteUpload записывает загруженный по ссылке файл в ожидающий его документ и
+// делает документ видимым; false — документа уже нет (резерв истёк и удалён очисткой)
+func (r *DocumentRepository) CompleteUpload(doc *model.Document) (bool, error) {
+	res, err := r.db.Exec(`UPDATE documents SET upload_pending = FALSE, created_at = $2, mime = $3, declared_mime = $4, size = $5, encoding = $6, stored_size = $7, scan_status = $8, scan_queued_at = $9, expires_at = $10
+		WHERE id = $1 AND upload_pending AND deleted_at IS NULL`, doc.ID, doc.CreatedAt, doc.Mime, doc.DeclaredMime, doc.Size, doc.Encoding, doc.StoredSize, doc.ScanStatus, doc.ScanQueuedAt, doc.ExpiresAt)
+	if err != nil {
+		return false, documentWriteError(err)
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// SetSize записывает размер файла; использование владельца пересчитывает триггер.
+// Размер уже сохранённого файла лимитами не проверяется: запись не новая, её только
+// начинают учитывать.
+func (r *DocumentRepository) SetSize(id string, size int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`SET LOCAL astra.quota_exempt = 'on'`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE documents SET size = $2 WHERE id = $1`, id, size); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListPendingScan возвращает файлы, поставленные на проверку антивирусом раньше before
+func (r *DocumentRepository) ListPendingScan(before time.Time, limit int) ([]model.Document, error) {
+	docs := []model.Document{}
+	err := r.db.Select(&docs, `SELECT id, name, mime, file, public, owner, created_at, size, encoding, blob_key, scan_status, scan_queued_at FROM documents
+		WHERE scan_status = 'pending_scan' AND scan_queued_at < $1 ORDER BY scan_queued_at LIMIT $2`, before, limit)
+	return docs, err
+}
+
+// SetScanResult записывает результат проверки, если файл с тех пор не ставили на
+// проверку заново; false — результат устарел
+func (r *DocumentRepository) SetScanResult(id string, queuedAt time.Time, status string, signature *string, scannedAt time.Time) (bool, error) {
+	res, err := r.db.Exec(`UPDATE documents SET scan_status = $3, scan_signature = $4, scanned_at = $5
+		WHERE id = $1 AND scan_status = 'pending_scan' AND scan_queued_at = $2`, id, queuedAt, status, signature, scannedAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Delete удаляет документ окончательно, вместе с грантами и ссылками
 func (r *DocumentRepository) Delete(id string) error {
 	_, err := r.db.Exec(`DELETE FROM documents WHERE id = $1`, id)
 	return err
 }
 
+// MoveTx переносит документ в папку (nil — в корень) под именем name в рамках транзакции
+func (r *DocumentRepository) MoveTx(tx *sql.Tx, id string, folderID *string, name string) error {
+	// Истёкший, но ещё не удалённый документ не занимает путь (см. releaseExpiredPath)
+	_, err := tx.Exec(`UPDATE documents e SET deleted_at = e.expires_at FROM documents d
+		WHERE d.id = $1 AND e.owner = d.owner AND e.folder_id IS NOT DISTINCT FROM $2 AND e.name = $3
+			AND e.deleted_at IS NULL AND e.expires_at <= NOW()`, id, folderID, name)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE documents SET folder_id = $2, name = $3 WHERE id = $1`, id, folderID, name)
+	return uniqueViolation(err)
+}
+
 func (r *DocumentRepository) DeleteTx(tx *sql.Tx, id string) error {
 	_, err := tx.Exec(`DELETE FROM documents WHERE id = $1`, id)
 	return err
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ErrDuplicate — запись нарушает ограничение уникальности
+var ErrDuplicate = errors.New("duplicate key")
+
+// Ошибки лимитов, которые проверяет триггер documents_track_usage в транзакции записи документа
+var (
+	ErrFileTooLarge  = errors.New("file exceeds the maximum file size")
+	ErrStorageQuota  = errors.New("storage quota exceeded")
+	ErrDocumentQuota = errors.New("document count quota exceeded")
+)
+
+// quotaErrors — коды ошибок, которыми триггер documents_track_usage отклоняет запись
+var quotaErrors = map[pq.ErrorCode]error{
+	"AQ001": ErrStorageQuota,
+	"AQ002": ErrDocumentQuota,
+	"AQ003": ErrFileTooLarge,
+}
+
+// uniqueViolation заменяет ошибку нарушения уникальности Postgres на ErrDuplicate
+func uniqueViolation(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// documentWriteError переводит нарушение уникальности пути и лимитов квоты при записи
+// документа в ErrDuplicate и ошибки квоты
+func documentWriteError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if quotaErr, ok := quotaErrors[pqErr.Code]; ok {
+			return quotaErr
+		}
+	}
+	return uniqueViolation(err)
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"astra-api/internal/model"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type FolderRepository struct {
+	db *sqlx.DB
+}
+
+func NewFolderRepository(db *sqlx.DB) *FolderRepository {
+	return &FolderRepository{db: db}
+}
+
+const folderColumns = `id, owner, parent_id, name, path, schema_id, created_at`
+
+func (r *FolderRepository) Create(folder *model.Folder) error {
+	_, err := r.db.Exec(`INSERT INTO folders (id, owner, parent_id, name, path, schema_id, created_at) VALUES ($1, $2, $3, $4, $5::uuid[], $6, $7)`,
+		folder.ID, folder.Owner, folder.ParentID, folder.Name, folder.Path, folder.SchemaID, folder.CreatedAt)
+	return uniqueViolation(err)
+}
+
+func (r *FolderRepository) GetByID(id string) (*model.Folder, error) {
+	var folder model.Folder
+	err := r.db.Get(&folder, `SELECT `+folderColumns+` FROM folders WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// GetByName ищет папку владельца по имени среди детей parentID (nil — корень)
+func (r *FolderRepository) GetByName(owner string, parentID *string, name string) (*model.Folder, error) {
+	var folder model.Folder
+	err := r.db.Get(&folder, `SELECT `+folderColumns+` FROM folders WHERE owner = $1 AND parent_id IS NOT DISTINCT FROM $2 AND name = $3`, owner, parentID, name)
+	if err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// ListChildren возвращает подпапки; с пустым parentID — корневые папки владельца
+func (r *FolderRepository) ListChildren(owner string, parentID *string) ([]model.Folder, error) {
+	folders := []model.Folder{}
+	err := r.db.Select(&folders, `SELECT `+folderColumns+` FROM folders WHERE owner = $1 AND parent_id IS NOT DISTINCT FROM $2 ORDER BY name`, owner, parentID)
+	return folders, err
+}
+
+// ListShared возвращает чужие папки, выданные одному из субъектов действующим грантом
+func (r *FolderRepository) ListShared(userID string, principals []string) ([]model.Folder, error) {
+	folders := []model.Folder{}
+	err := r.db.Select(&folders, `SELECT `+folderColumns+` FROM folders f
+		WHERE f.owner <> $1 AND EXISTS (
+			SELECT 1 FROM folder_grants g
+			WHERE g.folder_id = f.id AND g.principal = ANY($2::text[]) AND (g.expires_at IS NULL OR g.expires_at > NOW())
+		)
+		ORDER BY f.name`, userID, pq.Array(principals))
+	return folders, err
+}
+
+// ListDocuments возвращает документы папки без содержимого json; с пустым folderID —
+// документы в корне владельца
+func (r *FolderRepository) ListDocuments(owner string, folderID *string) ([]model.Document, error) {
+	docs := []model.Document{}
+	err := r.db.Select(&docs, `SELECT id, name, mime, file, public, owner, created_at, folder_id, schema_id, tags, metadata, expires_at, size, declared_mime, encoding, stored_size, scan_status, scan_signature, scanned_at FROM documents WHERE owner = $1 AND folder_id IS NOT DISTINCT FROM $2 AND `+liveSQL("")+` ORDER BY name`, owner, folderID)
+	return docs, err
+}
+
+// GetByIDTx читает папку в транзакции и блокирует её строку до конца транзакции
+func (r *FolderRepository) GetByIDTx(tx *sql.Tx, id string) (*model.Folder, error) {
+	var folder model.Folder
+	err := scanTx(r.db, tx).Get(&folder, `SELECT `+folderColumns+` FROM folders WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// UpdateTx сохраняет имя и схему папки
+func (r *FolderRepository) UpdateTx(tx *sql.Tx, folder *model.Folder) error {
+	_, err := tx.Exec(`UPDATE folders SET name = $2, schema_id = $3 WHERE id = $1`, folder.ID, folder.Name, folder.SchemaID)
+	return uniqueViolation(err)
+}
+
+// MoveTx переносит папку вместе с поддеревом: у всех потомков заменяется начало path
+func (r *FolderRepository) MoveTx(tx *sql.Tx, folder *model.Folder, parentID *string, path []string) error {
+	if _, err := tx.Exec(`UPDATE folders SET parent_id = $2 WHERE id = $1`, folder.ID, parentID); err != nil {
+		return uniqueViolation(err)
+	}
+	_, err := tx.Exec(`UPDATE folders SET path = $2::uuid[] || path[$3 + 1:] WHERE path @> ARRAY[$1::uuid]`, folder.ID, pq.Array(path), len(folder.Path))
+	return err
+}
+
+// IsEmpty сообщает, что в папке нет ни подпапок, ни документов
+func (r *FolderRepository) IsEmpty(id string) (bool, error) {
+	var busy bool
+	err := r.db.Get(&busy, `SELECT EXISTS (SELECT 1 FROM folders WHERE parent_id = $1) OR EXISTS (SELECT 1 FROM documents WHERE folder_id = $1 AND `+liveSQL("")+`)`, id)
+	return !busy, err
+}
+
+func (r *FolderRepository) Delete(id string) error {
+	_, err := r.db.Exec(`DELETE FROM folders WHERE id = $1`, id)
+	return err
+}
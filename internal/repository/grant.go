@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"astra-api/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type GrantRepository struct {
+	db *sqlx.DB
+}
+
+func NewGrantRepository(db *sqlx.DB) *GrantRepository {
+	return &GrantRepository{db: db}
+}
+
+// List возвращает все гранты документа, включая истёкшие
+func (r *GrantRepository) List(documentID string) ([]model.Grant, error) {
+	grants := []model.Grant{}
+	err := r.db.Select(&grants, `SELECT document_id, principal, permission, expires_at, granted_by, created_at FROM document_grants WHERE document_id = $1 ORDER BY created_at`, documentID)
+	return grants, err
+}
+
+// Set выдаёт грант или заменяет уровень и срок уже выданного
+func (r *GrantRepository) Set(grant *model.Grant) error {
+	return upsertGrant(r.db, grant)
+}
+
+func (r *GrantRepository) Delete(documentID, principal string) error {
+	_, err := r.db.Exec(`DELETE FROM document_grants WHERE document_id = $1 AND principal = $2`, documentID, principal)
+	return err
+}
+
+func upsertGrant(ex execer, g *model.Grant) error {
+	_, err := ex.Exec(`INSERT INTO document_grants (document_id, principal, permission, expires_at, granted_by, created_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (document_id, principal) DO UPDATE SET permission = EXCLUDED.permission, expires_at = EXCLUDED.expires_at, granted_by = EXCLUDED.granted_by`,
+		g.DocumentID, g.Principal, g.Permission, g.ExpiresAt, g.GrantedBy, g.CreatedAt)
+	return err
+}
+
+// ListFolder возвращает все гранты папки, включая истёкшие
+func (r *GrantRepository) ListFolder(folderID string) ([]model.Grant, error) {
+	grants := []model.Grant{}
+	err := r.db.Select(&grants, `SELECT folder_id, principal, permission, expires_at, granted_by, created_at FROM folder_grants WHERE folder_id = $1 ORDER BY created_at`, folderID)
+	return grants, err
+}
+
+// ListInherited возвращает гранты папки и всех её предков — то, что наследует её содержимое
+func (r *GrantRepository) ListInherited(folderID string) ([]model.Grant, error) {
+	grants := []model.Grant{}
+	err := r.db.Select(&grants, `SELECT g.folder_id, g.principal, g.permission, g.expires_at, g.granted_by, g.created_at
+		FROM folders f JOIN folder_grants g ON g.folder_id = ANY(f.path)
+		WHERE f.id = $1 ORDER BY array_position(f.path, g.folder_id), g.created_at`, folderID)
+	return grants, err
+}
+
+func (r *GrantRepository) SetFolder(grant *model.Grant) error {
+	_, err := r.db.Exec(`INSERT INTO folder_grants (folder_id, principal, permission, expires_at, granted_by, created_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (folder_id, principal) DO UPDATE SET permission = EXCLUDED.permission, expires_at = EXCLUDED.expires_at, granted_by = EXCLUDED.granted_by`,
+		grant.FolderID, grant.Principal, grant.Permission, grant.ExpiresAt, grant.GrantedBy, grant.CreatedAt)
+	return err
+}
+
+func (r *GrantRepository) DeleteFolder(folderID, principal string) error {
+	_, err := r.db.Exec(`DELETE FROM folder_grants WHERE folder_id = $1 AND principal = $2`, folderID, principal)
+	return err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"astra-api/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type GroupRepository struct {
+	db *sqlx.DB
+}
+
+func NewGroupRepository(db *sqlx.DB) *GroupRepository {
+	return &GroupRepository{db: db}
+}
+
+// Create создаёт группу и делает ownerID её владельцем в одной транзакции
+func (r *GroupRepository) Create(group *model.Group, ownerID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`INSERT INTO groups (id, name, created_by, created_at) VALUES ($1, $2, $3, $4)`, group.ID, group.Name, ownerID, group.CreatedAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO group_members (group_id, user_id, role, added_at) VALUES ($1, $2, $3, $4)`, group.ID, ownerID, model.GroupRoleOwner, group.CreatedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *GroupRepository) GetByID(id string) (*model.Group, error) {
+	var group model.Group
+	err := r.db.Get(&group, `SELECT id, name, created_by, created_at FROM groups WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *GroupRepository) GetByName(name string) (*model.Group, error) {
+	var group model.Group
+	err := r.db.Get(&group, `SELECT id, name, created_by, created_at FROM groups WHERE name = $1`, name)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *GroupRepository) Delete(id string) error {
+	_, err := r.db.Exec(`DELETE FROM groups WHERE id = $1`, id)
+	return err
+}
+
+// ListByUser возвращает группы, в которых состоит пользователь
+func (r *GroupRepository) ListByUser(userID string) ([]model.Group, error) {
+	groups := []model.Group{}
+	err := r.db.Select(&groups, `SELECT g.id, g.name, g.created_by, g.created_at FROM groups g JOIN group_members m ON m.group_id = g.id WHERE m.user_id = $1 ORDER BY g.name`, userID)
+	return groups, err
+}
+
+// SetMember добавляет участника или меняет его роль
+func (r *GroupRepository) SetMember(groupID, userID, role string) error {
+	_, err := r.db.Exec(`INSERT INTO group_members (group_id, user_id, role) VALUES ($1, $2, $3) ON CONFLICT (group_id, user_id) DO UPDATE SET role = EXCLUDED.role`, groupID, userID, role)
+	return err
+}
+
+func (r *GroupRepository) RemoveMember(groupID, userID string) error {
+	_, err := r.db.Exec(`DELETE FROM group_members WHERE group_id = $1 AND user_id = $2`, groupID, userID)
+	return err
+}
+
+func (r *GroupRepository) ListMembers(groupID string) ([]model.GroupMember, error) {
+	members := []model.GroupMember{}
+	err := r.db.Select(&members, `SELECT m.group_id, m.user_id, u.login, m.role, m.added_at FROM group_members m JOIN users u ON u.id = m.user_id WHERE m.group_id = $1 ORDER BY u.login`, groupID)
+	return members, err
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"astra-api/internal/model"
+	"astra-api/internal/storage"
 	"database/sql"
+	"time"
 )
 
 // UserRepositoryInterface описывает контракт репозитория пользователей
@@ -11,14 +13,153 @@ type UserRepositoryInterface interface {
 	CreateTx(tx *sql.Tx, user *model.User) error
 	GetByLogin(login string) (*model.User, error)
 	GetByID(id string) (*model.User, error)
+	ScheduleDeletion(id string, at *time.Time) error
+	ListDeletionDue(now time.Time) ([]model.User, error)
+	LockDeletionDueTx(tx *sql.Tx, id string, now time.Time) (bool, error)
+	DeleteDueTx(tx *sql.Tx, id string, now time.Time) (bool, error)
 }
 
 // DocumentRepositoryInterface описывает контракт репозитория документов
 type DocumentRepositoryInterface interface {
 	Create(doc *model.Document) error
 	CreateTx(tx *sql.Tx, doc *model.Document) error
-	List(owner string, limit int) ([]model.Document, error)
+	List(owner string, q model.ListQuery) ([]model.Document, error)
+	ListVisible(owner string, principals []string, q model.ListQuery) ([]model.Document, error)
+	ListAccessible(userID string, principals []string, includeOwned bool, q model.ListQuery) ([]model.Document, error)
+	Search(userID string, principals []string, query string, limit int) ([]model.SearchResult, error)
+	CountTags(userID string, principals []string, owned, shared bool) ([]model.TagCount, error)
+	Update(doc *model.Document) error
+	UpdateFile(doc *model.Document) (string, error)
+	ForEachByOwner(owner string, fn func(doc *model.Document) error) error
 	GetByID(id string) (*model.Document, error)
+	GetByIDTx(tx *sql.Tx, id string) (*model.Document, error)
 	Delete(id string) error
+	ListLegacyFiles(after *model.Document, limit int) ([]model.Document, error)
+	CompleteUpload(doc *model.Document) (bool, error)
+	Trash(id string, at time.Time) (bool, error)
+	Restore(id string) (bool, error)
+	GetTrashed(id string) (*model.Document, error)
+	ListTrash(owner string, limit int) ([]model.Document, error)
+	ListTrashedBefore(before time.Time, limit int) ([]model.Document, error)
+	ListExpired(now time.Time, limit int) ([]model.Document, error)
+	ListUnsized(limit int) ([]model.Document, error)
+	SetSize(id string, size int64) error
+	ListPendingScan(before time.Time, limit int) ([]model.Document, error)
+	SetScanResult(id string, queuedAt time.Time, status string, signature *string, scannedAt time.Time) (bool, error)
+	GetByPath(owner string, folderID *string, name string) (*model.Document, error)
+	MoveTx(tx *sql.Tx, id string, folderID *string, name string) error
 	DeleteTx(tx *sql.Tx, id string) error
 }
+
+// RefreshTokenRepositoryInterface описывает контракт хранилища refresh-токенов
+type RefreshTokenRepositoryInterface interface {
+	Create(token *model.RefreshToken) error
+	GetByHash(hash string) (*model.RefreshToken, error)
+	MarkUsed(id string, at time.Time) (bool, error)
+	RevokeFamily(familyID string, at time.Time) error
+	RevokeByUser(userID string, at time.Time) error
+	RevokeAccess(tokenID string, expiresAt, now time.Time) (bool, error)
+	RevokeAccessBefore(userID string, notBefore time.Time) error
+	ListAccessRevocations(now, since time.Time) ([]model.RevokedAccessToken, []model.AccessCutoff, error)
+}
+
+// AuditRepositoryInterface описывает контракт журнала аудита
+type AuditRepositoryInterface interface {
+	Create(entry *model.AuditEntry) error
+	List(actorID string, limit int) ([]model.AuditEntry, error)
+}
+
+// GroupRepositoryInterface описывает контракт репозитория групп пользователей
+type GroupRepositoryInterface interface {
+	Create(group *model.Group, ownerID string) error
+	GetByID(id string) (*model.Group, error)
+	GetByName(name string) (*model.Group, error)
+	Delete(id string) error
+	ListByUser(userID string) ([]model.Group, error)
+	SetMember(groupID, userID, role string) error
+	RemoveMember(groupID, userID string) error
+	ListMembers(groupID string) ([]model.GroupMember, error)
+}
+
+// GrantRepositoryInterface описывает контракт репозитория грантов на документы и папки
+type GrantRepositoryInterface interface {
+	List(documentID string) ([]model.Grant, error)
+	Set(grant *model.Grant) error
+	Delete(documentID, principal string) error
+	ListFolder(folderID string) ([]model.Grant, error)
+	ListInherited(folderID string) ([]model.Grant, error)
+	SetFolder(grant *model.Grant) error
+	DeleteFolder(folderID, principal string) error
+}
+
+// ShareLinkRepositoryInterface описывает контракт репозитория ссылок для внешнего доступа
+type ShareLinkRepositoryInterface interface {
+	Create(link *model.ShareLink) error
+	GetByID(id string) (*model.ShareLink, error)
+	ListByDocument(documentID string) ([]model.ShareLink, error)
+	Revoke(id, documentID string, at time.Time) (bool, error)
+	CountDownload(id string) (bool, error)
+}
+
+// UploadReservationRepositoryInterface описывает резервы под загрузку по подписанной ссылке
+type UploadReservationRepositoryInterface interface {
+	CreateTx(tx *sql.Tx, res *model.UploadReservation) error
+	Take(id string, now time.Time) (*model.UploadReservation, error)
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+// SchemaRepositoryInterface описывает хранилище JSON Schema пользователей
+type SchemaRepositoryInterface interface {
+	Create(schema *model.JSONSchema) error
+	Update(schema *model.JSONSchema) error
+	GetByID(id string) (*model.JSONSchema, error)
+	GetByName(owner, name string) (*model.JSONSchema, error)
+	ListByOwner(owner string) ([]model.JSONSchema, error)
+	Delete(id string) error
+	GetByFolder(folderID string) (*model.JSONSchema, error)
+	AttachTag(owner, tag, schemaID string) error
+	DetachTag(owner, tag string) error
+	ListByTags(owner string, tags []string) ([]model.JSONSchema, error)
+	ForEachDocument(schemaID string, fn func(doc *model.Document) error) error
+	ForEachInFolder(folderID string, fn func(doc *model.Document) error) error
+	ForEachTagged(owner, tag string, fn func(doc *model.Document) error) error
+}
+
+// FolderRepositoryInterface описывает хранилище папок документов
+type FolderRepositoryInterface interface {
+	Create(folder *model.Folder) error
+	GetByID(id string) (*model.Folder, error)
+	GetByName(owner string, parentID *string, name string) (*model.Folder, error)
+	ListChildren(owner string, parentID *string) ([]model.Folder, error)
+	ListShared(userID string, principals []string) ([]model.Folder, error)
+	ListDocuments(owner string, folderID *string) ([]model.Document, error)
+	GetByIDTx(tx *sql.Tx, id string) (*model.Folder, error)
+	UpdateTx(tx *sql.Tx, folder *model.Folder) error
+	MoveTx(tx *sql.Tx, folder *model.Folder, parentID *string, path []string) error
+	IsEmpty(id string) (bool, error)
+	Delete(id string) error
+}
+
+// QuotaRepositoryInterface описывает хранилище использования места и личных лимитов пользователей
+type QuotaRepositoryInterface interface {
+	GetUsage(userID string) (*model.Usage, error)
+	SetDefaults(limits model.QuotaLimits) error
+	GetOverride(userID string) (*model.QuotaOverride, error)
+	SetOverride(o *model.QuotaOverride) error
+	DeleteOverride(userID string) error
+}
+
+// BlobKeyRepositoryInterface описывает хранилище ключей данных зашифрованных файлов
+type BlobKeyRepositoryInterface interface {
+	PutKey(k *storage.WrappedKey) error
+	GetKey(blobKey, version string) (*storage.WrappedKey, error)
+	DeleteKey(blobKey, version string) error
+	DeleteKeys(blobKey, keep string) error
+	ListStale(masterKeyID string, limit int) ([]storage.WrappedKey, error)
+	UpdateKey(k *storage.WrappedKey, oldMasterKeyID string) error
+}
+
+// TxManagerInterface выполняет функцию в транзакции, в которой вызываются *Tx-методы репозиториев
+type TxManagerInterface interface {
+	InTx(fn func(tx *sql.Tx) error) error
+}
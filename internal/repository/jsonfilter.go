@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"astra-api/internal/model"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// listConditions добавляет к jsonConditions фильтры по тегам и метаданным.
+// Оба выражаются через @>, чтобы работали GIN-индексы.
+func listConditions(q model.ListQuery, args []interface{}) (string, []interface{}) {
+	where, args := jsonConditions(q.Where, args)
+	if len(q.Tags) > 0 {
+		args = append(args, pq.Array(q.Tags))
+		where += fmt.Sprintf(" AND tags @> $%d::text[]", len(args))
+	}
+	if len(q.Metadata) > 0 {
+		args = append(args, q.Metadata)
+		where += fmt.Sprintf(" AND metadata @> $%d::jsonb", len(args))
+	}
+	return where, args
+}
+
+// jsonConditions переводит условия на json_data в SQL. Пути и значения передаются только
+// параметрами ($n), в текст запроса попадают лишь операторы из фиксированного набора.
+// Возвращает фрагмент " AND ..." (или пустую строку) и дополненный список аргументов.
+func jsonConditions(conds []model.JSONCondition, args []interface{}) (string, []interface{}) {
+	var sb strings.Builder
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	for _, c := range conds {
+		sb.WriteString(" AND ")
+		switch c.Op {
+		case model.JSONExists:
+			fmt.Fprintf(&sb, "json_data #> %s::text[] IS NOT NULL", arg(pq.Array(c.Path)))
+		case model.JSONNotExists:
+			fmt.Fprintf(&sb, "json_data #> %s::text[] IS NULL", arg(pq.Array(c.Path)))
+		case model.JSONEq:
+			if c.KeysOnly() {
+				// @> отбирает кандидатов по индексу, = отсекает объекты и массивы с лишними элементами
+				fmt.Fprintf(&sb, "json_data @> %s::jsonb AND ", arg(string(c.Containment())))
+			}
+			fmt.Fprintf(&sb, "json_data #> %s::text[] = %s::jsonb", arg(pq.Array(c.Path)), arg(string(c.Value)))
+		case model.JSONNe:
+			fmt.Fprintf(&sb, "json_data #> %s::text[] IS DISTINCT FROM %s::jsonb", arg(pq.Array(c.Path)), arg(string(c.Value)))
+		case model.JSONContains:
+			if c.KeysOnly() {
+				fmt.Fprintf(&sb, "json_data @> %s::jsonb", arg(string(c.Containment())))
+			} else {
+				fmt.Fprintf(&sb, "json_data #> %s::text[] @> %s::jsonb", arg(pq.Array(c.Path)), arg(string(c.Value)))
+			}
+		case model.JSONGt, model.JSONGe, model.JSONLt, model.JSONLe:
+			// jsonb упорядочивает и значения разных типов, поэтому сравниваем только однотипные
+			path, value := arg(pq.Array(c.Path)), arg(string(c.Value))
+			fmt.Fprintf(&sb, "jsonb_typeof(json_data #> %[1]s::text[]) = jsonb_typeof(%[2]s::jsonb) AND json_data #> %[1]s::text[] %[3]s %[2]s::jsonb", path, value, c.Op)
+		default:
+			// ParseJSONCondition других операторов не выдаёт
+			sb.WriteString("FALSE")
+		}
+	}
+	return sb.String(), args
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"astra-api/internal/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ShareLinkRepository struct {
+	db *sqlx.DB
+}
+
+func NewShareLinkRepository(db *sqlx.DB) *ShareLinkRepository {
+	return &ShareLinkRepository{db: db}
+}
+
+func (r *ShareLinkRepository) Create(link *model.ShareLink) error {
+	_, err := r.db.Exec(`INSERT INTO share_links (id, document_id, created_by, permission, password_hash, max_downloads, downloads, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, 0, $7, $8)`,
+		link.ID, link.DocumentID, link.CreatedBy, link.Permission, link.PasswordHash, link.MaxDownloads, link.ExpiresAt, link.CreatedAt)
+	return err
+}
+
+func (r *ShareLinkRepository) GetByID(id string) (*model.ShareLink, error) {
+	var link model.ShareLink
+	err := r.db.Get(&link, `SELECT id, document_id, created_by, permission, password_hash, max_downloads, downloads, expires_at, revoked_at, created_at FROM share_links WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *ShareLinkRepository) ListByDocument(documentID string) ([]model.ShareLink, error) {
+	links := []model.ShareLink{}
+	err := r.db.Select(&links, `SELECT id, document_id, created_by, permission, password_hash, max_downloads, downloads, expires_at, revoked_at, created_at FROM share_links WHERE document_id = $1 ORDER BY created_at DESC`, documentID)
+	return links, err
+}
+
+// Revoke отзывает ссылку документа; false — ссылки нет или она уже отозвана
+func (r *ShareLinkRepository) Revoke(id, documentID string, at time.Time) (bool, error) {
+	res, err := r.db.Exec(`UPDATE share_links SET revoked_at = $3 WHERE id = $1 AND document_id = $2 AND revoked_at IS NULL`, id, documentID, at)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// CountDownload атомарно учитывает скачивание; false — лимит исчерпан или ссылка отозвана
+func (r *ShareLinkRepository) CountDownload(id string) (bool, error) {
+	res, err := r.db.Exec(`UPDATE share_links SET downloads = downloads + 1 WHERE id = $1 AND revoked_at IS NULL AND (max_downloads IS NULL OR downloads < max_downloads)`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"astra-api/internal/model"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type QuotaRepository struct {
+	db *sqlx.DB
+}
+
+func NewQuotaRepository(db *sqlx.DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+// GetUsage возвращает занятое пользователем место; без документов — нули
+func (r *QuotaRepository) GetUsage(userID string) (*model.Usage, error) {
+	var usage model.Usage
+	err := r.db.Get(&usage, `SELECT bytes, documents FROM user_usage WHERE user_id = $1`, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &usage, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// SetDefaults записывает лимиты по умолчанию, по которым триггер documents_track_usage
+// проверяет запись документов пользователей без личных лимитов
+func (r *QuotaRepository) SetDefaults(limits model.QuotaLimits) error {
+	_, err := r.db.Exec(`UPDATE quota_defaults SET max_bytes = $1, max_documents = $2, max_file_size = $3`,
+		limits.MaxBytes, limits.MaxDocuments, limits.MaxFileSize)
+	return err
+}
+
+// GetOverride возвращает личные лимиты пользователя или sql.ErrNoRows
+func (r *QuotaRepository) GetOverride(userID string) (*model.QuotaOverride, error) {
+	var o model.QuotaOverride
+	err := r.db.Get(&o, `SELECT user_id, max_bytes, max_documents, max_file_size, updated_at FROM user_quotas WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// SetOverride задаёт личные лимиты пользователя целиком
+func (r *QuotaRepository) SetOverride(o *model.QuotaOverride) error {
+	_, err := r.db.Exec(`INSERT INTO user_quotas (user_id, max_bytes, max_documents, max_file_size, updated_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET max_bytes = EXCLUDED.max_bytes, max_documents = EXCLUDED.max_documents,
+			max_file_size = EXCLUDED.max_file_size, updated_at = EXCLUDED.updated_at`,
+		o.UserID, o.MaxBytes, o.MaxDocuments, o.MaxFileSize, o.UpdatedAt)
+	return err
+}
+
+// DeleteOverride возвращает пользователю лимиты по умолчанию
+func (r *QuotaRepository) DeleteOverride(userID string) error {
+	_, err := r.db.Exec(`DELETE FROM user_quotas WHERE user_id = $1`, userID)
+	return err
+}
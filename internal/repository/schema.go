@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"astra-api/internal/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type SchemaRepository struct {
+	db *sqlx.DB
+}
+
+func NewSchemaRepository(db *sqlx.DB) *SchemaRepository {
+	return &SchemaRepository{db: db}
+}
+
+func (r *SchemaRepository) Create(schema *model.JSONSchema) error {
+	_, err := r.db.Exec(`INSERT INTO json_schemas (id, owner, name, schema, created_at, updated_at) VALUES ($1, $2, $3, $4::jsonb, $5, $6)`,
+		schema.ID, schema.Owner, schema.Name, string(schema.Schema), schema.CreatedAt, schema.UpdatedAt)
+	return err
+}
+
+// Update заменяет текст схемы
+func (r *SchemaRepository) Update(schema *model.JSONSchema) error {
+	_, err := r.db.Exec(`UPDATE json_schemas SET schema = $2::jsonb, updated_at = $3 WHERE id = $1`, schema.ID, string(schema.Schema), schema.UpdatedAt)
+	return err
+}
+
+func (r *SchemaRepository) GetByID(id string) (*model.JSONSchema, error) {
+	var schema model.JSONSchema
+	err := r.db.Get(&schema, `SELECT id, owner, name, schema, created_at, updated_at FROM json_schemas WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (r *SchemaRepository) GetByName(owner, name string) (*model.JSONSchema, error) {
+	var schema model.JSONSchema
+	err := r.db.Get(&schema, `SELECT s.id, s.owner, s.name, s.schema, s.created_at, s.updated_at,
+			(SELECT COUNT(*) FROM documents d WHERE d.schema_id = s.id AND `+liveSQL("d.")+`) AS documents,
+			(SELECT COUNT(*) FROM folders f WHERE f.schema_id = s.id) AS folders,
+			(SELECT COUNT(*) FROM tag_schemas t WHERE t.schema_id = s.id) AS tags
+		FROM json_schemas s WHERE s.owner = $1 AND s.name = $2`, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// ListByOwner возвращает схемы пользователя без текста, с числом привязанных документов, папок и тегов
+func (r *SchemaRepository) ListByOwner(owner string) ([]model.JSONSchema, error) {
+	schemas := []model.JSONSchema{}
+	err := r.db.Select(&schemas, `SELECT s.id, s.owner, s.name, s.created_at, s.updated_at,
+			(SELECT COUNT(*) FROM documents d WHERE d.schema_id = s.id AND `+liveSQL("d.")+`) AS documents,
+			(SELECT COUNT(*) FROM folders f WHERE f.schema_id = s.id) AS folders,
+			(SELECT COUNT(*) FROM tag_schemas t WHERE t.schema_id = s.id) AS tags
+		FROM json_schemas s WHERE s.owner = $1 ORDER BY s.name`, owner)
+	return schemas, err
+}
+
+func (r *SchemaRepository) Delete(id string) error {
+	_, err := r.db.Exec(`DELETE FROM json_schemas WHERE id = $1`, id)
+	return err
+}
+
+// GetByFolder возвращает схему, привязанную к папке
+func (r *SchemaRepository) GetByFolder(folderID string) (*model.JSONSchema, error) {
+	var schema model.JSONSchema
+	err := r.db.Get(&schema, `SELECT s.id, s.owner, s.name, s.schema, s.created_at, s.updated_at FROM json_schemas s JOIN folders f ON f.schema_id = s.id WHERE f.id = $1`, folderID)
+	if err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// AttachTag привязывает схему к тегу владельца, заменяя прежнюю
+func (r *SchemaRepository) AttachTag(owner, tag, schemaID string) error {
+	_, err := r.db.Exec(`INSERT INTO tag_schemas (owner, tag, schema_id) VALUES ($1, $2, $3)
+		ON CONFLICT (owner, tag) DO UPDATE SET schema_id = EXCLUDED.schema_id`, owner, tag, schemaID)
+	return err
+}
+
+// DetachTag отвязывает схему от тега владельца
+func (r *SchemaRepository) DetachTag(owner, tag string) error {
+	_, err := r.db.Exec(`DELETE FROM tag_schemas WHERE owner = $1 AND tag = $2`, owner, tag)
+	return err
+}
+
+// ListByTags возвращает схемы, привязанные к любому из тегов владельца
+func (r *SchemaRepository) ListByTags(owner string, tags []string) ([]model.JSONSchema, error) {
+	schemas := []model.JSONSchema{}
+	err := r.db.Select(&schemas, `SELECT DISTINCT s.id, s.owner, s.name, s.schema, s.created_at, s.updated_at
+		FROM json_schemas s JOIN tag_schemas t ON t.schema_id = s.id WHERE t.owner = $1 AND t.tag = ANY($2)`, owner, pq.Array(tags))
+	return schemas, err
+}
+
+// ForEachDocument построчно обходит документы, которые проверяются схемой: привязанные
+// к ней напрямую и JSON-документы из папок и с тегами, к которым она привязана
+func (r *SchemaRepository) ForEachDocument(schemaID string, fn func(doc *model.Document) error) error {
+	return r.forEach(`SELECT id, name, mime, file, public, owner, created_at, folder_id, schema_id, tags, metadata, expires_at, json_data FROM documents d
+		WHERE `+liveSQL("d.")+` AND (d.schema_id = $1 OR (NOT d.file AND (
+			d.folder_id IN (SELECT id FROM folders WHERE schema_id = $1) OR
+			EXISTS (SELECT 1 FROM tag_schemas t WHERE t.schema_id = $1 AND t.owner = d.owner AND t.tag = ANY(d.tags))
+		))) ORDER BY d.created_at`, fn, schemaID)
+}
+
+// ForEachInFolder построчно обходит JSON-документы, лежащие прямо в папке
+func (r *SchemaRepository) ForEachInFolder(folderID string, fn func(doc *model.Document) error) error {
+	return r.forEach(`SELECT id, name, mime, file, public, owner, created_at, folder_id, schema_id, tags, metadata, expires_at, json_data FROM documents
+		WHERE folder_id = $1 AND NOT file AND `+liveSQL("")+` ORDER BY created_at`, fn, folderID)
+}
+
+// ForEachTagged построчно обходит JSON-документы владельца с тегом
+func (r *SchemaRepository) ForEachTagged(owner, tag string, fn func(doc *model.Document) error) error {
+	return r.forEach(`SELECT id, name, mime, file, public, owner, created_at, folder_id, schema_id, tags, metadata, expires_at, json_data FROM documents
+		WHERE owner = $1 AND $2 = ANY(tags) AND NOT file AND `+liveSQL("")+` ORDER BY created_at`, fn, owner, tag)
+}
+
+func (r *SchemaRepository) forEach(query string, fn func(doc *model.Document) error, args ...interface{}) error {
+	rows, err := r.db.Queryx(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var doc model.Document
+		if err := rows.StructScan(&doc); err != nil {
+			return err
+		}
+		if err := fn(&doc); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
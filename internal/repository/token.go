@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"astra-api/internal/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type RefreshTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewRefreshTokenRepository(db *sqlx.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Create(token *model.RefreshToken) error {
+	_, err := r.db.Exec(`INSERT INTO refresh_tokens (id, family_id, user_id, token_hash, created_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6)`, token.ID, token.FamilyID, token.UserID, token.TokenHash, token.CreatedAt, token.ExpiresAt)
+	return err
+}
+
+func (r *RefreshTokenRepository) GetByHash(hash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.Get(&token, `SELECT id, family_id, user_id, token_hash, created_at, expires_at, used_at, revoked_at FROM refresh_tokens WHERE token_hash = $1`, hash)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed помечает токен использованным; false означает, что его уже успели использовать
+func (r *RefreshTokenRepository) MarkUsed(id string, at time.Time) (bool, error) {
+	res, err := r.db.Exec(`UPDATE refresh_tokens SET used_at = $2 WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`, id, at)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(familyID string, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE refresh_tokens SET revoked_at = $2 WHERE family_id = $1 AND revoked_at IS NULL`, familyID, at)
+	return err
+}
+
+func (r *RefreshTokenRepository) RevokeByUser(userID string, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE refresh_tokens SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`, userID, at)
+	return err
+}
+
+// RevokeAccess заносит access-токен в список отозванных до expiresAt; false означает,
+// что он уже там. Заодно удаляются записи о токенах, срок которых уже истёк.
+func (r *RefreshTokenRepository) RevokeAccess(tokenID string, expiresAt, now time.Time) (bool, error) {
+	if _, err := r.db.Exec(`DELETE FROM revoked_access_tokens WHERE expires_at < $1`, now); err != nil {
+		return false, err
+	}
+	res, err := r.db.Exec(`INSERT INTO revoked_access_tokens (token_id, expires_at) VALUES ($1, $2) ON CONFLICT (token_id) DO NOTHING`, tokenID, expiresAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// RevokeAccessBefore делает недействительными access-токены пользователя, выданные раньше notBefore
+func (r *RefreshTokenRepository) RevokeAccessBefore(userID string, notBefore time.Time) error {
+	_, err := r.db.Exec(`INSERT INTO access_token_cutoffs (user_id, not_before) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET not_before = GREATEST(access_token_cutoffs.not_before, EXCLUDED.not_before)`, userID, notBefore)
+	return err
+}
+
+// ListAccessRevocations возвращает отозванные access-токены, срок которых не истёк к now,
+// и отсечки, поставленные позже since: более ранние отсекают только истёкшие токены
+func (r *RefreshTokenRepository) ListAccessRevocations(now, since time.Time) ([]model.RevokedAccessToken, []model.AccessCutoff, error) {
+	tokens := []model.RevokedAccessToken{}
+	if err := r.db.Select(&tokens, `SELECT token_id, expires_at FROM revoked_access_tokens WHERE expires_at > $1`, now); err != nil {
+		return nil, nil, err
+	}
+	cutoffs := []model.AccessCutoff{}
+	if err := r.db.Select(&cutoffs, `SELECT user_id, not_before FROM access_token_cutoffs WHERE not_before > $1`, since); err != nil {
+		return nil, nil, err
+	}
+	return tokens, cutoffs, nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TxManager выполняет вызовы *Tx-методов разных репозиториев в одной транзакции
+type TxManager struct {
+	db *sqlx.DB
+}
+
+func NewTxManager(db *sqlx.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// InTx фиксирует транзакцию, если fn завершилась без ошибки, иначе откатывает её
+func (m *TxManager) InTx(fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// scanTx даёт читать строки в структуры внутри транзакции, открытой InTx
+func scanTx(db *sqlx.DB, tx *sql.Tx) *sqlx.Tx {
+	return &sqlx.Tx{Tx: tx, Mapper: db.Mapper}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"astra-api/internal/model"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type UploadReservationRepository struct {
+	db *sqlx.DB
+}
+
+func NewUploadReservationRepository(db *sqlx.DB) *UploadReservationRepository {
+	return &UploadReservationRepository{db: db}
+}
+
+// CreateTx сохраняет резерв в транзакции, в которой создаётся ожидающий загрузки документ
+func (r *UploadReservationRepository) CreateTx(tx *sql.Tx, res *model.UploadReservation) error {
+	_, err := tx.Exec(`INSERT INTO upload_reservations (id, owner_id, name, mime, size, document_expires_at, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		res.ID, res.Owner, res.Name, res.Mime, res.Size, res.DocumentExpiresAt, res.ExpiresAt, res.CreatedAt)
+	return err
+}
+
+// Take атомарно забирает действующий резерв: из двух одновременных загрузок пройдёт одна
+func (r *UploadReservationRepository) Take(id string, now time.Time) (*model.UploadReservation, error) {
+	var res model.UploadReservation
+	err := r.db.Get(&res, `DELETE FROM upload_reservations WHERE id = $1 AND expires_at > $2 RETURNING id, owner_id, name, mime, size, document_expires_at, expires_at, created_at`, id, now)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *UploadReservationRepository) DeleteExpired(now time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM upload_reservations WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"astra-api/internal/model"
 	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -42,3 +44,39 @@ func (r *UserRepository) GetByID(id string) (*model.User, error) {
 	}
 	return &user, nil
 }
+
+// ScheduleDeletion назначает удаление аккаунта; nil отменяет его
+func (r *UserRepository) ScheduleDeletion(id string, at *time.Time) error {
+	_, err := r.db.Exec(`UPDATE users SET delete_after = $2 WHERE id = $1`, id, at)
+	return err
+}
+
+func (r *UserRepository) ListDeletionDue(now time.Time) ([]model.User, error) {
+	users := []model.User{}
+	err := r.db.Select(&users, `SELECT * FROM users WHERE delete_after IS NOT NULL AND delete_after <= $1`, now)
+	return users, err
+}
+
+// LockDeletionDueTx блокирует строку пользователя до конца транзакции, если его удаление
+// назначено и срок наступил к now; false — удаление отменено или ещё не наступило.
+// Пока строка заблокирована, вход не отменит удаление, а документы с этим владельцем
+// не появятся: их вставка ждёт блокировки из-за внешнего ключа.
+func (r *UserRepository) LockDeletionDueTx(tx *sql.Tx, id string, now time.Time) (bool, error) {
+	var locked string
+	err := tx.QueryRow(`SELECT id FROM users WHERE id = $1 AND delete_after IS NOT NULL AND delete_after <= $2 FOR UPDATE`, id, now).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DeleteDueTx удаляет пользователя, если его удаление назначено и срок наступил к now;
+// документы и refresh-токены удаляются каскадно
+func (r *UserRepository) DeleteDueTx(tx *sql.Tx, id string, now time.Time) (bool, error) {
+	res, err := tx.Exec(`DELETE FROM users WHERE id = $1 AND delete_after IS NOT NULL AND delete_after <= $2`, id, now)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
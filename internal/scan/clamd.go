@@ -0,0 +1,118 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// chunkSize — размер порции INSTREAM; clamd принимает порции до StreamMaxLength
+const chunkSize = 64 << 10
+
+// Clamd проверяет файлы демоном ClamAV по протоколу INSTREAM: команда, затем
+// порции вида <длина uint32 big-endian><данные> и пустая порция в конце.
+type Clamd struct {
+	network string
+	address string
+	timeout time.Duration
+	chunk   int
+}
+
+// NewClamd создаёт клиент clamd. addr — tcp://host:port, unix:///path/clamd.sock,
+// host:port или путь к сокету. timeout ограничивает подключение и каждую операцию
+// обмена, а не проверку целиком: большой файл может проверяться дольше.
+func NewClamd(addr string, timeout time.Duration) (*Clamd, error) {
+	c := &Clamd{timeout: timeout, chunk: chunkSize}
+	switch {
+	case strings.HasPrefix(addr, "tcp://"):
+		c.network, c.address = "tcp", strings.TrimPrefix(addr, "tcp://")
+	case strings.HasPrefix(addr, "unix://"):
+		c.network, c.address = "unix", strings.TrimPrefix(addr, "unix://")
+	case strings.HasPrefix(addr, "/"):
+		c.network, c.address = "unix", addr
+	default:
+		c.network, c.address = "tcp", addr
+	}
+	if c.address == "" {
+		return nil, fmt.Errorf("invalid clamd address %q", addr)
+	}
+	return c, nil
+}
+
+func (c *Clamd) Scan(ctx context.Context, r io.Reader) (Verdict, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return Verdict{}, err
+	}
+	defer conn.Close()
+	// Отмена ctx прерывает обмен
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	werr := c.send(ctx, conn, r)
+	// Превысив лимит, clamd отвечает ошибкой и закрывает соединение, не дочитав поток:
+	// ответ важнее ошибки записи
+	c.extend(ctx, conn)
+	reply, rerr := bufio.NewReader(conn).ReadString(0)
+	if rerr != nil && reply == "" {
+		if werr != nil {
+			return Verdict{}, werr
+		}
+		return Verdict{}, rerr
+	}
+	return parseReply(reply)
+}
+
+func (c *Clamd) send(ctx context.Context, conn net.Conn, r io.Reader) error {
+	c.extend(ctx, conn)
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+	buf := make([]byte, 4+c.chunk)
+	for {
+		n, err := io.ReadFull(r, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			c.extend(ctx, conn)
+			if _, werr := conn.Write(buf[:4+n]); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := conn.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+// extend продлевает срок текущей операции обмена, но не после отмены ctx
+func (c *Clamd) extend(ctx context.Context, conn net.Conn) {
+	if c.timeout > 0 && ctx.Err() == nil {
+		conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+}
+
+// parseReply разбирает ответ clamd: "stream: OK", "stream: <угроза> FOUND" или "<причина> ERROR"
+func parseReply(reply string) (Verdict, error) {
+	reply = strings.TrimRight(reply, "\x00\n")
+	switch {
+	case strings.HasSuffix(reply, " FOUND"):
+		return Verdict{Infected: true, Signature: strings.TrimSuffix(strings.TrimPrefix(reply, "stream: "), " FOUND")}, nil
+	case reply == "stream: OK":
+		return Verdict{}, nil
+	case strings.HasSuffix(reply, " ERROR"):
+		return Verdict{}, fmt.Errorf("%w: %s", ErrUnscannable, strings.TrimSuffix(reply, " ERROR"))
+	}
+	return Verdict{}, errors.New("unexpected clamd reply: " + reply)
+}
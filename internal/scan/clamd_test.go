@@ -0,0 +1,164 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd — демон, понимающий INSTREAM: находит EICAR и отвечает ошибкой на поток
+// больше limit байт
+type fakeClamd struct {
+	ln     net.Listener
+	limit  int
+	chunks int
+}
+
+func newFakeClamd(t *testing.T, network, address string) *fakeClamd {
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatalf("cannot listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	d := &fakeClamd{ln: ln, limit: 1 << 20}
+	go d.serve()
+	return d
+}
+
+func (d *fakeClamd) serve() {
+	for {
+		conn, err := d.ln.Accept()
+		if err != nil {
+			return
+		}
+		d.handle(conn)
+	}
+}
+
+func (d *fakeClamd) handle(conn net.Conn) {
+	defer conn.Close()
+	cmd := make([]byte, len("zINSTREAM\x00"))
+	if _, err := io.ReadFull(conn, cmd); err != nil || string(cmd) != "zINSTREAM\x00" {
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+		return
+	}
+	var data bytes.Buffer
+	for {
+		var size uint32
+		if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+			return
+		}
+		if size == 0 {
+			break
+		}
+		d.chunks++
+		if _, err := io.CopyN(&data, conn, int64(size)); err != nil {
+			return
+		}
+		if data.Len() > d.limit {
+			conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+			// Дочитываем поток, чтобы закрытие не сбросило соединение до чтения ответа
+			io.Copy(io.Discard, conn)
+			return
+		}
+	}
+	if bytes.Contains(data.Bytes(), []byte("EICAR-STANDARD-ANTIVIRUS-TEST-FILE")) {
+		conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+		return
+	}
+	conn.Write([]byte("stream: OK\x00"))
+}
+
+func TestClamd_Scan(t *testing.T) {
+	d := newFakeClamd(t, "tcp", "127.0.0.1:0")
+	c, err := NewClamd("tcp://"+d.ln.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	v, err := c.Scan(context.Background(), strings.NewReader("hello"))
+	if err != nil || v.Infected {
+		t.Fatalf("expected clean verdict, got %+v, %v", v, err)
+	}
+	v, err = c.Scan(context.Background(), strings.NewReader("prefix "+eicar))
+	if err != nil || !v.Infected || v.Signature != "Eicar-Test-Signature" {
+		t.Fatalf("expected EICAR, got %+v, %v", v, err)
+	}
+}
+
+func TestClamd_Scan_Chunks(t *testing.T) {
+	d := newFakeClamd(t, "tcp", "127.0.0.1:0")
+	c, _ := NewClamd(d.ln.Addr().String(), time.Second)
+	c.chunk = 16
+
+	// Сигнатура разрезана границами порций и должна собраться на стороне демона
+	v, err := c.Scan(context.Background(), strings.NewReader(strings.Repeat("a", 10)+eicar))
+	if err != nil || !v.Infected {
+		t.Fatalf("expected EICAR across chunks, got %+v, %v", v, err)
+	}
+	if d.chunks < 5 {
+		t.Fatalf("expected the stream to be split into chunks, got %d", d.chunks)
+	}
+}
+
+func TestClamd_Scan_Unix(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "clamd.sock")
+	newFakeClamd(t, "unix", sock)
+	c, err := NewClamd("unix://"+sock, time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, err := c.Scan(context.Background(), strings.NewReader(eicar)); err != nil || !v.Infected {
+		t.Fatalf("expected EICAR, got %+v, %v", v, err)
+	}
+}
+
+func TestClamd_Scan_SizeLimit(t *testing.T) {
+	d := newFakeClamd(t, "tcp", "127.0.0.1:0")
+	d.limit = 100
+	c, _ := NewClamd(d.ln.Addr().String(), time.Second)
+	c.chunk = 64
+
+	_, err := c.Scan(context.Background(), bytes.NewReader(make([]byte, 1<<20)))
+	if !errors.Is(err, ErrUnscannable) {
+		t.Fatalf("expected ErrUnscannable, got %v", err)
+	}
+}
+
+func TestClamd_Scan_Unavailable(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+	c, _ := NewClamd(addr, time.Second)
+	_, err := c.Scan(context.Background(), strings.NewReader("hello"))
+	if err == nil || errors.Is(err, ErrUnscannable) {
+		t.Fatalf("expected a connection error, got %v", err)
+	}
+}
+
+func TestNewClamd(t *testing.T) {
+	cases := []struct{ addr, network, address string }{
+		{"tcp://clamav:3310", "tcp", "clamav:3310"},
+		{"clamav:3310", "tcp", "clamav:3310"},
+		{"unix:///run/clamd.sock", "unix", "/run/clamd.sock"},
+		{"/run/clamd.sock", "unix", "/run/clamd.sock"},
+	}
+	for _, c := range cases {
+		got, err := NewClamd(c.addr, 0)
+		if err != nil || got.network != c.network || got.address != c.address {
+			t.Errorf("%s: unexpected %+v, %v", c.addr, got, err)
+		}
+	}
+	if _, err := NewClamd("tcp://", 0); err == nil {
+		t.Error("expected an error for an empty address")
+	}
+}
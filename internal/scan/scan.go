@@ -0,0 +1,32 @@
+// Package scan проверяет содержимое загружаемых файлов антивирусом
+package scan
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrUnscannable — сканер ответил, но проверить файл не смог (например, файл больше
+// его лимита). Повторная проверка того же файла ничего не изменит.
+var ErrUnscannable = errors.New("scanner could not check the file")
+
+// Verdict — результат проверки
+type Verdict struct {
+	Infected bool
+	// Signature — имя найденной угрозы
+	Signature string
+}
+
+// ContentScanner проверяет содержимое файла. Ошибка, кроме ErrUnscannable, означает,
+// что сканер недоступен, и проверку стоит повторить позже.
+type ContentScanner interface {
+	Scan(ctx context.Context, r io.Reader) (Verdict, error)
+}
+
+// Nop считает чистым любой файл; используется, когда антивирус не настроен
+type Nop struct{}
+
+func (Nop) Scan(ctx context.Context, r io.Reader) (Verdict, error) {
+	return Verdict{}, nil
+}
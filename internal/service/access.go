@@ -0,0 +1,398 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownPrincipal = errors.New("unknown grant principal")
+	ErrInvalidGrant     = errors.New("invalid grant")
+	ErrForbidden        = errors.New("permission denied")
+)
+
+// AccessService решает, что пользователь может делать с документом или папкой. Владелец
+// имеет уровень manage, публичный документ доступен всем на чтение, остальное
+// определяют гранты субъектам "user:<id>" и "group:<id>" с необязательным сроком.
+// Гранты на папку действуют на всё её содержимое, включая вложенные папки.
+type AccessService struct {
+	userRepo  repository.UserRepositoryInterface
+	groupRepo repository.GroupRepositoryInterface
+	grantRepo repository.GrantRepositoryInterface
+	now       func() time.Time
+}
+
+func NewAccessService(userRepo repository.UserRepositoryInterface, groupRepo repository.GroupRepositoryInterface, grantRepo repository.GrantRepositoryInterface) *AccessService {
+	return &AccessService{userRepo: userRepo, groupRepo: groupRepo, grantRepo: grantRepo, now: time.Now}
+}
+
+// Principals возвращает субъектов, от имени которых действует пользователь
+func (s *AccessService) Principals(userID string) ([]string, error) {
+	groups, err := s.groupRepo.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	principals := make([]string, 0, len(groups)+1)
+	principals = append(principals, model.PrincipalUser+userID)
+	for _, g := range groups {
+		principals = append(principals, model.PrincipalGroup+g.ID)
+	}
+	return principals, nil
+}
+
+// Permission возвращает наибольший действующий уровень доступа; пустая строка — доступа нет
+func (s *AccessService) Permission(userID string, doc *model.Document) (model.Permission, error) {
+	if doc.Owner == userID {
+		return model.PermissionManage, nil
+	}
+	var level model.Permission
+	if doc.Public {
+		level = model.PermissionRead
+	}
+	grants, err := s.documentGrants(doc)
+	if err != nil {
+		return level, err
+	}
+	return s.strongest(userID, level, grants)
+}
+
+// FolderPermission возвращает уровень доступа к папке: владелец — manage,
+// остальным — наибольший из грантов на папку и её предков
+func (s *AccessService) FolderPermission(userID string, folder *model.Folder) (model.Permission, error) {
+	if folder.Owner == userID {
+		return model.PermissionManage, nil
+	}
+	grants, err := s.grantRepo.ListInherited(folder.ID)
+	if err != nil {
+		return "", err
+	}
+	return s.strongest(userID, "", grants)
+}
+
+// documentGrants возвращает гранты документа вместе с унаследованными от его папок
+func (s *AccessService) documentGrants(doc *model.Document) ([]model.Grant, error) {
+	grants, err := s.grantRepo.List(doc.ID)
+	if err != nil || doc.FolderID == nil {
+		return grants, err
+	}
+	inherited, err := s.grantRepo.ListInherited(*doc.FolderID)
+	if err != nil {
+		return nil, err
+	}
+	return append(grants, inherited...), nil
+}
+
+// strongest повышает level до наибольшего действующего гранта субъектам пользователя
+func (s *AccessService) strongest(userID string, level model.Permission, grants []model.Grant) (model.Permission, error) {
+	if len(grants) == 0 {
+		return level, nil
+	}
+	principals, err := s.Principals(userID)
+	if err != nil {
+		return "", err
+	}
+	now := s.now()
+	for _, g := range grants {
+		if !g.Active(now) || !g.Permission.Valid() || !contains(principals, g.Principal) {
+			continue
+		}
+		if !level.Includes(g.Permission) {
+			level = g.Permission
+		}
+	}
+	return level, nil
+}
+
+// PermissionExpiry возвращает, до какого момента у пользователя останется уровень perm:
+// nil — бессрочно (владелец, публичный документ для read, бессрочный грант).
+// Без этого уровня возвращает ErrForbidden.
+func (s *AccessService) PermissionExpiry(userID string, doc *model.Document, perm model.Permission) (*time.Time, error) {
+	if doc.Owner == userID || doc.Public && model.PermissionRead.Includes(perm) {
+		return nil, nil
+	}
+	grants, err := s.documentGrants(doc)
+	if err != nil {
+		return nil, err
+	}
+	principals, err := s.Principals(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	var until *time.Time
+	found := false
+	for _, g := range grants {
+		if !g.Active(now) || !g.Permission.Includes(perm) || !contains(principals, g.Principal) {
+			continue
+		}
+		if g.ExpiresAt == nil {
+			return nil, nil
+		}
+		if !found || g.ExpiresAt.After(*until) {
+			until = g.ExpiresAt
+		}
+		found = true
+	}
+	if !found {
+		return nil, ErrForbidden
+	}
+	return until, nil
+}
+
+// Explain перечисляет, кто и почему имеет доступ к документу: владелец, прямые
+// гранты, участники групп с грантом и все — для публичного документа. Полный
+// список видят те, кто может делиться документом, остальные — только записи о себе.
+func (s *AccessService) Explain(actorID string, doc *model.Document) ([]model.AccessEntry, error) {
+	level, err := s.Permission(actorID, doc)
+	if err != nil {
+		return nil, err
+	}
+	if level == "" {
+		return nil, ErrForbidden
+	}
+	entries := []model.AccessEntry{}
+	owner := model.AccessEntry{UserID: doc.Owner, Permission: model.PermissionManage, Via: model.AccessViaOwner}
+	if user, err := s.userRepo.GetByID(doc.Owner); err == nil {
+		owner.Login = user.Login
+	}
+	entries = append(entries, owner)
+	if doc.Public {
+		entries = append(entries, model.AccessEntry{Permission: model.PermissionRead, Via: model.AccessViaPublic})
+	}
+	grants, err := s.documentGrants(doc)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	for _, g := range grants {
+		if !g.Active(now) {
+			continue
+		}
+		if id, ok := strings.CutPrefix(g.Principal, model.PrincipalUser); ok {
+			entry := model.AccessEntry{UserID: id, Permission: g.Permission, Via: model.AccessViaGrant, Folder: g.FolderID, ExpiresAt: g.ExpiresAt}
+			if user, err := s.userRepo.GetByID(id); err == nil {
+				entry.Login = user.Login
+			}
+			entries = append(entries, entry)
+			continue
+		}
+		groupID := strings.TrimPrefix(g.Principal, model.PrincipalGroup)
+		group, err := s.groupRepo.GetByID(groupID)
+		if err != nil {
+			// Группа удалена, грант больше ни на кого не действует
+			continue
+		}
+		members, err := s.groupRepo.ListMembers(groupID)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			entries = append(entries, model.AccessEntry{UserID: m.UserID, Login: m.Login, Permission: g.Permission, Via: model.AccessViaGroup, Group: group.Name, Folder: g.FolderID, ExpiresAt: g.ExpiresAt})
+		}
+	}
+	if level.Includes(model.PermissionShare) {
+		return entries, nil
+	}
+	own := []model.AccessEntry{}
+	for _, e := range entries {
+		if e.UserID == actorID || e.Via == model.AccessViaPublic {
+			own = append(own, e)
+		}
+	}
+	return own, nil
+}
+
+// ListGrants показывает гранты документа тем, кто может ими делиться
+func (s *AccessService) ListGrants(actorID string, doc *model.Document) ([]model.Grant, error) {
+	if err := s.require(actorID, doc, model.PermissionShare); err != nil {
+		return nil, err
+	}
+	return s.grantRepo.List(doc.ID)
+}
+
+// SetGrant выдаёт доступ или меняет существующий. Без уровня manage можно выдавать
+// только уровни не выше собственного и нельзя трогать гранты выше собственного.
+func (s *AccessService) SetGrant(actorID string, doc *model.Document, req model.GrantRequest) (*model.Grant, error) {
+	if err := s.checkGrantRequest(req); err != nil {
+		return nil, err
+	}
+	level, err := s.Permission(actorID, doc)
+	if err != nil {
+		return nil, err
+	}
+	grant, err := s.newGrant(actorID, level, req, func() ([]model.Grant, error) { return s.grantRepo.List(doc.ID) })
+	if err != nil {
+		return nil, err
+	}
+	grant.DocumentID = doc.ID
+	if err := s.grantRepo.Set(grant); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+// ListFolderGrants показывает гранты папки тем, кто может ими делиться
+func (s *AccessService) ListFolderGrants(actorID string, folder *model.Folder) ([]model.Grant, error) {
+	if err := s.requireFolder(actorID, folder, model.PermissionShare); err != nil {
+		return nil, err
+	}
+	return s.grantRepo.ListFolder(folder.ID)
+}
+
+// SetFolderGrant выдаёт доступ к папке и всему её содержимому по тем же правилам, что SetGrant
+func (s *AccessService) SetFolderGrant(actorID string, folder *model.Folder, req model.GrantRequest) (*model.Grant, error) {
+	if err := s.checkGrantRequest(req); err != nil {
+		return nil, err
+	}
+	level, err := s.FolderPermission(actorID, folder)
+	if err != nil {
+		return nil, err
+	}
+	grant, err := s.newGrant(actorID, level, req, func() ([]model.Grant, error) { return s.grantRepo.ListFolder(folder.ID) })
+	if err != nil {
+		return nil, err
+	}
+	grant.FolderID = folder.ID
+	if err := s.grantRepo.SetFolder(grant); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+// RevokeFolderGrant отзывает грант на папку; без manage можно отказаться только от собственного
+func (s *AccessService) RevokeFolderGrant(actorID string, folder *model.Folder, principal string) error {
+	resolved, err := s.ResolvePrincipal(principal)
+	if err != nil {
+		return err
+	}
+	if resolved != model.PrincipalUser+actorID {
+		if err := s.requireFolder(actorID, folder, model.PermissionManage); err != nil {
+			return err
+		}
+	}
+	return s.grantRepo.DeleteFolder(folder.ID, resolved)
+}
+
+func (s *AccessService) checkGrantRequest(req model.GrantRequest) error {
+	if !req.Permission.Valid() {
+		return fmt.Errorf("%w: unknown permission %q", ErrInvalidGrant, req.Permission)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(s.now()) {
+		return fmt.Errorf("%w: expires_at is in the past", ErrInvalidGrant)
+	}
+	return nil
+}
+
+// newGrant проверяет, что уровнем level можно выдать запрошенный грант, и собирает его.
+// Без manage нельзя заменить действующий грант выше собственного уровня.
+func (s *AccessService) newGrant(actorID string, level model.Permission, req model.GrantRequest, existing func() ([]model.Grant, error)) (*model.Grant, error) {
+	if !level.Includes(model.PermissionShare) || !level.Includes(req.Permission) {
+		return nil, ErrForbidden
+	}
+	principal, err := s.ResolvePrincipal(req.Principal)
+	if err != nil {
+		return nil, err
+	}
+	if level != model.PermissionManage {
+		grants, err := existing()
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range grants {
+			if g.Principal == principal && g.Active(s.now()) && !level.Includes(g.Permission) {
+				return nil, ErrForbidden
+			}
+		}
+	}
+	return &model.Grant{
+		Principal:  principal,
+		Permission: req.Permission,
+		ExpiresAt:  req.ExpiresAt,
+		GrantedBy:  &actorID,
+		CreatedAt:  s.now(),
+	}, nil
+}
+
+// RevokeGrant отзывает грант; без manage можно отказаться только от собственного
+func (s *AccessService) RevokeGrant(actorID string, doc *model.Document, principal string) error {
+	resolved, err := s.ResolvePrincipal(principal)
+	if err != nil {
+		return err
+	}
+	if resolved != model.PrincipalUser+actorID {
+		if err := s.require(actorID, doc, model.PermissionManage); err != nil {
+			return err
+		}
+	}
+	return s.grantRepo.Delete(doc.ID, resolved)
+}
+
+// ResolveGrants переводит гранты из метаданных загрузки в гранты на чтение
+func (s *AccessService) ResolveGrants(principals []string) ([]model.Grant, error) {
+	grants := make([]model.Grant, 0, len(principals))
+	seen := make(map[string]bool, len(principals))
+	now := s.now()
+	for _, p := range principals {
+		principal, err := s.ResolvePrincipal(p)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[principal] {
+			seen[principal] = true
+			grants = append(grants, model.Grant{Principal: principal, Permission: model.PermissionRead, CreatedAt: now})
+		}
+	}
+	return grants, nil
+}
+
+// ResolvePrincipal переводит "login", "user:login" или "group:name" в хранимый вид
+// со стабильным идентификатором
+func (s *AccessService) ResolvePrincipal(p string) (string, error) {
+	if name, ok := strings.CutPrefix(p, model.PrincipalGroup); ok {
+		group, err := s.groupRepo.GetByName(name)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrUnknownPrincipal, p)
+		}
+		return model.PrincipalGroup + group.ID, nil
+	}
+	user, err := s.userRepo.GetByLogin(strings.TrimPrefix(p, model.PrincipalUser))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownPrincipal, p)
+	}
+	return model.PrincipalUser + user.ID, nil
+}
+
+func (s *AccessService) require(userID string, doc *model.Document, need model.Permission) error {
+	level, err := s.Permission(userID, doc)
+	if err != nil {
+		return err
+	}
+	if !level.Includes(need) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *AccessService) requireFolder(userID string, folder *model.Folder, need model.Permission) error {
+	level, err := s.FolderPermission(userID, folder)
+	if err != nil {
+		return err
+	}
+	if !level.Includes(need) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestAccessService_Permission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	groupRepo := mocksgen.NewMockGroupRepositoryInterface(ctrl)
+	grantRepo := mocksgen.NewMockGrantRepositoryInterface(ctrl)
+	access := NewAccessService(mocksgen.NewMockUserRepositoryInterface(ctrl), groupRepo, grantRepo)
+
+	expired := time.Now().Add(-time.Minute)
+	groupRepo.EXPECT().ListByUser("u2").Return([]model.Group{{ID: "g1"}}, nil).AnyTimes()
+	grantRepo.EXPECT().List("direct").Return([]model.Grant{{Principal: "user:u2", Permission: model.PermissionWrite}}, nil)
+	grantRepo.EXPECT().List("group").Return([]model.Grant{
+		{Principal: "group:g1", Permission: model.PermissionComment},
+		{Principal: "user:u2", Permission: model.PermissionRead},
+	}, nil)
+	grantRepo.EXPECT().List("expired").Return([]model.Grant{{Principal: "user:u2", Permission: model.PermissionShare, ExpiresAt: &expired}}, nil)
+	grantRepo.EXPECT().List("other").Return([]model.Grant{{Principal: "user:u3", Permission: model.PermissionRead}}, nil)
+	grantRepo.EXPECT().List("public").Return(nil, nil)
+
+	cases := []struct {
+		name string
+		doc  model.Document
+		want model.Permission
+	}{
+		{"owner", model.Document{ID: "own", Owner: "u2"}, model.PermissionManage},
+		{"direct grant", model.Document{ID: "direct", Owner: "u1"}, model.PermissionWrite},
+		{"highest of group and user", model.Document{ID: "group", Owner: "u1"}, model.PermissionComment},
+		{"expired grant", model.Document{ID: "expired", Owner: "u1"}, ""},
+		{"other grant", model.Document{ID: "other", Owner: "u1"}, ""},
+		{"public", model.Document{ID: "public", Owner: "u1", Public: true}, model.PermissionRead},
+	}
+	for _, tc := range cases {
+		level, err := access.Permission("u2", &tc.doc)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tc.name, err)
+		}
+		if level != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.name, tc.want, level)
+		}
+	}
+}
+
+func TestAccessService_SetGrant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	groupRepo := mocksgen.NewMockGroupRepositoryInterface(ctrl)
+	grantRepo := mocksgen.NewMockGrantRepositoryInterface(ctrl)
+	access := NewAccessService(userRepo, groupRepo, grantRepo)
+
+	doc := &model.Document{ID: "d1", Owner: "u1"}
+	groupRepo.EXPECT().ListByUser("u2").Return(nil, nil).AnyTimes()
+	grantRepo.EXPECT().List("d1").Return([]model.Grant{{Principal: "user:u2", Permission: model.PermissionShare}}, nil).AnyTimes()
+	userRepo.EXPECT().GetByLogin("contractor").Return(&model.User{ID: "u3"}, nil).AnyTimes()
+
+	// share не позволяет выдать уровень выше собственного
+	if _, err := access.SetGrant("u2", doc, model.GrantRequest{Principal: "contractor", Permission: model.PermissionManage}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if _, err := access.SetGrant("u2", doc, model.GrantRequest{Principal: "contractor", Permission: "owner"}); !errors.Is(err, ErrInvalidGrant) {
+		t.Fatalf("expected ErrInvalidGrant, got %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if _, err := access.SetGrant("u2", doc, model.GrantRequest{Principal: "contractor", Permission: model.PermissionRead, ExpiresAt: &past}); !errors.Is(err, ErrInvalidGrant) {
+		t.Fatalf("expected ErrInvalidGrant for past expiry, got %v", err)
+	}
+
+	until := time.Now().Add(24 * time.Hour)
+	grantRepo.EXPECT().Set(gomock.Any()).DoAndReturn(func(g *model.Grant) error {
+		if g.Principal != "user:u3" || g.Permission != model.PermissionWrite || g.ExpiresAt == nil || *g.GrantedBy != "u2" {
+			t.Fatalf("unexpected grant: %+v", g)
+		}
+		return nil
+	})
+	if _, err := access.SetGrant("u2", doc, model.GrantRequest{Principal: "contractor", Permission: model.PermissionWrite, ExpiresAt: &until}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestAccessService_RevokeGrant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	groupRepo := mocksgen.NewMockGroupRepositoryInterface(ctrl)
+	grantRepo := mocksgen.NewMockGrantRepositoryInterface(ctrl)
+	access := NewAccessService(userRepo, groupRepo, grantRepo)
+
+	doc := &model.Document{ID: "d1", Owner: "u1"}
+	groupRepo.EXPECT().ListByUser("u2").Return(nil, nil).AnyTimes()
+	grantRepo.EXPECT().List("d1").Return([]model.Grant{{Principal: "user:u2", Permission: model.PermissionShare}}, nil).AnyTimes()
+	userRepo.EXPECT().GetByLogin("other").Return(&model.User{ID: "u3"}, nil)
+	userRepo.EXPECT().GetByLogin("me").Return(&model.User{ID: "u2"}, nil)
+
+	if err := access.RevokeGrant("u2", doc, "other"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	grantRepo.EXPECT().Delete("d1", "user:u2").Return(nil)
+	if err := access.RevokeGrant("u2", doc, "me"); err != nil {
+		t.Fatalf("expected own grant to be dropped, got %v", err)
+	}
+}
+
+func TestAccessService_ResolveGrants(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	groupRepo := mocksgen.NewMockGroupRepositoryInterface(ctrl)
+	access := NewAccessService(userRepo, groupRepo, mocksgen.NewMockGrantRepositoryInterface(ctrl))
+
+	userRepo.EXPECT().GetByLogin("testuser").Return(&model.User{ID: "u1"}, nil).Times(2)
+	groupRepo.EXPECT().GetByName("accounting").Return(&model.Group{ID: "g1"}, nil)
+
+	grants, err := access.ResolveGrants([]string{"testuser", "user:testuser", "group:accounting"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(grants) != 2 || grants[0].Principal != "user:u1" || grants[1].Principal != "group:g1" {
+		t.Fatalf("unexpected grants: %v", grants)
+	}
+	if grants[0].Permission != model.PermissionRead {
+		t.Fatalf("expected read grants, got %s", grants[0].Permission)
+	}
+
+	groupRepo.EXPECT().GetByName("nobody").Return(nil, errors.New("not found"))
+	if _, err := access.ResolveGrants([]string{"group:nobody"}); !errors.Is(err, ErrUnknownPrincipal) {
+		t.Fatalf("expected ErrUnknownPrincipal, got %v", err)
+	}
+}
+
+func TestAccessService_Explain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	groupRepo := mocksgen.NewMockGroupRepositoryInterface(ctrl)
+	grantRepo := mocksgen.NewMockGrantRepositoryInterface(ctrl)
+	access := NewAccessService(userRepo, groupRepo, grantRepo)
+
+	doc := &model.Document{ID: "d1", Owner: "u1", Public: true}
+	expired := time.Now().Add(-time.Minute)
+	grantRepo.EXPECT().List("d1").Return([]model.Grant{
+		{Principal: "user:u2", Permission: model.PermissionWrite},
+		{Principal: "group:g1", Permission: model.PermissionRead},
+		{Principal: "user:u4", Permission: model.PermissionShare, ExpiresAt: &expired},
+	}, nil).AnyTimes()
+	groupRepo.EXPECT().ListByUser("u3").Return([]model.Group{{ID: "g1"}}, nil)
+	userRepo.EXPECT().GetByID("u1").Return(&model.User{ID: "u1", Login: "owner"}, nil).AnyTimes()
+	userRepo.EXPECT().GetByID("u2").Return(&model.User{ID: "u2", Login: "writer"}, nil).AnyTimes()
+	groupRepo.EXPECT().GetByID("g1").Return(&model.Group{ID: "g1", Name: "accounting"}, nil).AnyTimes()
+	groupRepo.EXPECT().ListMembers("g1").Return([]model.GroupMember{{UserID: "u3", Login: "member"}}, nil).AnyTimes()
+
+	entries, err := access.Explain("u1", doc)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	vias := []string{}
+	for _, e := range entries {
+		vias = append(vias, e.Via)
+	}
+	want := []string{model.AccessViaOwner, model.AccessViaPublic, model.AccessViaGrant, model.AccessViaGroup}
+	if len(vias) != len(want) {
+		t.Fatalf("expected %v, got %v", want, vias)
+	}
+	for i := range want {
+		if vias[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, vias)
+		}
+	}
+
+	// Без уровня share виден только свой путь доступа и публичность
+	entries, err = access.Explain("u3", doc)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(entries) != 2 || entries[0].Via != model.AccessViaPublic || entries[1].Group != "accounting" {
+		t.Fatalf("unexpected entries for member: %+v", entries)
+	}
+}
+
+func TestAccessService_Permission_Inherited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	groupRepo := mocksgen.NewMockGroupRepositoryInterface(ctrl)
+	grantRepo := mocksgen.NewMockGrantRepositoryInterface(ctrl)
+	access := NewAccessService(mocksgen.NewMockUserRepositoryInterface(ctrl), groupRepo, grantRepo)
+
+	groupRepo.EXPECT().ListByUser("u2").Return([]model.Group{{ID: "g1"}}, nil).AnyTimes()
+	grantRepo.EXPECT().List("d1").Return([]model.Grant{{DocumentID: "d1", Principal: "user:u2", Permission: model.PermissionRead}}, nil)
+	grantRepo.EXPECT().ListInherited("child").Return([]model.Grant{{FolderID: "root", Principal: "group:g1", Permission: model.PermissionWrite}}, nil).Times(2)
+
+	folderID := "child"
+	level, err := access.Permission("u2", &model.Document{ID: "d1", Owner: "u1", FolderID: &folderID})
+	if err != nil || level != model.PermissionWrite {
+		t.Fatalf("expected inherited write, got %q, %v", level, err)
+	}
+	folder := &model.Folder{ID: "child", Owner: "u1", Path: []string{"root", "child"}}
+	if level, err := access.FolderPermission("u2", folder); err != nil || level != model.PermissionWrite {
+		t.Fatalf("expected inherited write on folder, got %q, %v", level, err)
+	}
+	if level, err := access.FolderPermission("u1", folder); err != nil || level != model.PermissionManage {
+		t.Fatalf("expected manage for owner, got %q, %v", level, err)
+	}
+}
+
+func TestAccessService_PermissionExpiry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	groupRepo := mocksgen.NewMockGroupRepositoryInterface(ctrl)
+	grantRepo := mocksgen.NewMockGrantRepositoryInterface(ctrl)
+	access := NewAccessService(mocksgen.NewMockUserRepositoryInterface(ctrl), groupRepo, grantRepo)
+
+	soon, later := time.Now().Add(time.Hour), time.Now().Add(2*time.Hour)
+	groupRepo.EXPECT().ListByUser("u2").Return([]model.Group{{ID: "g1"}}, nil).AnyTimes()
+	grantRepo.EXPECT().List("d1").Return([]model.Grant{
+		{Principal: "user:u2", Permission: model.PermissionShare, ExpiresAt: &soon},
+		{Principal: "group:g1", Permission: model.PermissionManage, ExpiresAt: &later},
+		{Principal: "user:u2", Permission: model.PermissionRead},
+	}, nil).Times(3)
+	doc := &model.Document{ID: "d1", Owner: "u1"}
+
+	until, err := access.PermissionExpiry("u2", doc, model.PermissionShare)
+	if err != nil || until == nil || !until.Equal(later) {
+		t.Fatalf("expected the latest expiry among share grants, got %v, %v", until, err)
+	}
+	if until, err := access.PermissionExpiry("u2", doc, model.PermissionRead); err != nil || until != nil {
+		t.Fatalf("expected read without expiry, got %v, %v", until, err)
+	}
+	groupRepo.EXPECT().ListByUser("u3").Return(nil, nil)
+	if _, err := access.PermissionExpiry("u3", doc, model.PermissionRead); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if until, err := access.PermissionExpiry("u1", doc, model.PermissionManage); err != nil || until != nil {
+		t.Fatalf("expected owner without expiry, got %v, %v", until, err)
+	}
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"archive/zip"
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"astra-api/internal/storage"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrInvalidPassword = errors.New("invalid password")
+
+// AccountService обслуживает самостоятельное удаление аккаунта и выгрузку данных пользователя
+type AccountService struct {
+	userRepo  repository.UserRepositoryInterface
+	docRepo   repository.DocumentRepositoryInterface
+	grantRepo repository.GrantRepositoryInterface
+	blobs     storage.BlobStore
+	sessions  SessionServiceInterface
+	tx        repository.TxManagerInterface
+	grace     time.Duration
+}
+
+func NewAccountService(userRepo repository.UserRepositoryInterface, docRepo repository.DocumentRepositoryInterface, grantRepo repository.GrantRepositoryInterface, blobs storage.BlobStore, sessions SessionServiceInterface, tx repository.TxManagerInterface, grace time.Duration) *AccountService {
+	return &AccountService{userRepo: userRepo, docRepo: docRepo, grantRepo: grantRepo, blobs: blobs, sessions: sessions, tx: tx, grace: grace}
+}
+
+// RequestDeletion после проверки пароля назначает удаление через льготный период
+// и завершает все сессии. Повторный вход до этого момента отменяет удаление.
+func (s *AccountService) RequestDeletion(userID, password string) (time.Time, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return time.Time{}, ErrInvalidPassword
+	}
+	at := time.Now().Add(s.grace)
+	if err := s.userRepo.ScheduleDeletion(userID, &at); err != nil {
+		return time.Time{}, err
+	}
+	s.sessions.DeleteByUser(userID)
+	return at, nil
+}
+
+// PurgeDue окончательно удаляет аккаунты, у которых истёк льготный период
+func (s *AccountService) PurgeDue(now time.Time) (int, error) {
+	users, err := s.userRepo.ListDeletionDue(now)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, user := range users {
+		ok, err := s.Purge(user.ID, now)
+		if err != nil {
+			return purged, err
+		}
+		if ok {
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// Purge удаляет файлы документов, сессии и самого пользователя, если его удаление всё
+// ещё назначено и срок наступил к now; false — удаление отменили входом. Строка
+// пользователя заблокирована до конца транзакции, так что отменить удаление или добавить
+// ему документ, пока удаляются файлы, нельзя. Файлы удаляются первыми: если удаление
+// строки не удастся, следующий запуск повторит всё заново.
+func (s *AccountService) Purge(userID string, now time.Time) (bool, error) {
+	purged := false
+	err := s.tx.InTx(func(tx *sql.Tx) error {
+		due, err := s.userRepo.LockDeletionDueTx(tx, userID, now)
+		if err != nil || !due {
+			return err
+		}
+		// Ключи собираются после блокировки: новые документы владельца её ждут
+		var blobs []string
+		err = s.docRepo.ForEachByOwner(userID, func(doc *model.Document) error {
+			if doc.File {
+				blobs = append(blobs, doc.StorageKey())
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range blobs {
+			if err := s.blobs.Remove(key); err != nil {
+				return err
+			}
+		}
+		purged, err = s.userRepo.DeleteDueTx(tx, userID, now)
+		return err
+	})
+	if err != nil || !purged {
+		return false, err
+	}
+	s.sessions.DeleteByUser(userID)
+	return true, nil
+}
+
+type exportedDocument struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Mime      string        `json:"mime"`
+	File      bool          `json:"file"`
+	Public    bool          `json:"public"`
+	CreatedAt time.Time     `json:"created"`
+	Grants    []model.Grant `json:"grants"`
+	Path      string        `json:"path,omitempty"`
+	// DeletedAt — документ лежит в корзине с этого момента
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// ScanStatus — проверка файла антивирусом; файлы не в статусе clean в архив не попадают
+	ScanStatus string `json:"scan_status,omitempty"`
+}
+
+// Export пишет в w ZIP-архив: profile.json, documents.json, json/<id>.json и files/<id>/<name>.
+// Документы читаются и пишутся по одному, так что архив формируется потоково.
+func (s *AccountService) Export(userID string, w io.Writer) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+
+	profile := map[string]interface{}{
+		"id":         user.ID,
+		"login":      user.Login,
+		"roles":      user.Roles,
+		"created_at": user.CreatedAt,
+	}
+	if err := writeJSONEntry(zw, "profile.json", profile); err != nil {
+		return err
+	}
+
+	index := []exportedDocument{}
+	err = s.docRepo.ForEachByOwner(userID, func(doc *model.Document) error {
+		grants, err := s.grantRepo.List(doc.ID)
+		if err != nil {
+			return err
+		}
+		entry := exportedDocument{
+			ID:         doc.ID,
+			Name:       doc.Name,
+			Mime:       doc.Mime,
+			File:       doc.File,
+			Public:     doc.Public,
+			CreatedAt:  doc.CreatedAt,
+			Grants:     grants,
+			DeletedAt:  doc.DeletedAt,
+			ScanStatus: doc.ScanStatus,
+		}
+		if len(doc.JsonData) > 0 {
+			entry.Path = "json/" + doc.ID + ".json"
+			f, err := zw.Create(entry.Path)
+			if err != nil {
+				return err
+			}
+			if _, err := f.Write(doc.JsonData); err != nil {
+				return err
+			}
+		}
+		if doc.File && doc.Downloadable() {
+			entry.Path = "files/" + doc.ID + "/" + exportFileName(doc.Name)
+			if err := s.copyBlob(zw, entry.Path, doc); err != nil {
+				return err
+			}
+		}
+		index = append(index, entry)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := writeJSONEntry(zw, "documents.json", index); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (s *AccountService) copyBlob(zw *zip.Writer, name string, doc *model.Document) error {
+	blob, err := OpenContent(s.blobs, doc)
+	if errors.Is(err, storage.ErrNotFound) {
+		// Метаданные без файла всё равно попадут в documents.json
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, blob)
+	return err
+}
+
+func writeJSONEntry(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// exportFileName не даёт имени документа выйти за пределы своего каталога в архиве
+func exportFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" || name == ".." || name == "" {
+		return "file"
+	}
+	return name
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"archive/zip"
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/storage"
+	"bytes"
+	"database/sql"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestAccountService собирает AccountService поверх моков; гранты документов всегда пустые
+func newTestAccountService(t *testing.T, ctrl *gomock.Controller) (*AccountService, *mocksgen.MockUserRepositoryInterface, *mocksgen.MockDocumentRepositoryInterface, *mocksgen.MockSessionServiceInterface, storage.BlobStore) {
+	blobs, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create blob store: %v", err)
+	}
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	grantRepo := mocksgen.NewMockGrantRepositoryInterface(ctrl)
+	grantRepo.EXPECT().List(gomock.Any()).Return([]model.Grant{}, nil).AnyTimes()
+	sessions := mocksgen.NewMockSessionServiceInterface(ctrl)
+	return NewAccountService(userRepo, docRepo, grantRepo, blobs, sessions, newInlineTx(ctrl), time.Hour), userRepo, docRepo, sessions, blobs
+}
+
+// newInlineTx — менеджер транзакций, выполняющий функцию без транзакции
+func newInlineTx(ctrl *gomock.Controller) *mocksgen.MockTxManagerInterface {
+	tx := mocksgen.NewMockTxManagerInterface(ctrl)
+	tx.EXPECT().InTx(gomock.Any()).DoAndReturn(func(fn func(tx *sql.Tx) error) error { return fn(nil) }).AnyTimes()
+	return tx
+}
+
+func TestAccountService_RequestDeletion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accounts, userRepo, _, sessions, _ := newTestAccountService(t, ctrl)
+	hash, _ := bcrypt.GenerateFromPassword([]byte("Password123!"), bcrypt.MinCost)
+
+	userRepo.EXPECT().GetByID("u1").Return(&model.User{ID: "u1", Password: string(hash)}, nil).Times(2)
+	userRepo.EXPECT().ScheduleDeletion("u1", gomock.Not(gomock.Nil())).Return(nil)
+	sessions.EXPECT().DeleteByUser("u1")
+
+	if _, err := accounts.RequestDeletion("u1", "wrong"); !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("expected ErrInvalidPassword, got %v", err)
+	}
+
+	at, err := accounts.RequestDeletion("u1", "Password123!")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if d := time.Until(at); d < 59*time.Minute || d > time.Hour {
+		t.Fatalf("expected deletion in about an hour, got %v", d)
+	}
+}
+
+func TestAccountService_PurgeDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accounts, userRepo, docRepo, sessions, blobs := newTestAccountService(t, ctrl)
+	if _, err := blobs.Put("d1", strings.NewReader("data")); err != nil {
+		t.Fatalf("failed to put blob: %v", err)
+	}
+
+	userRepo.EXPECT().ListDeletionDue(gomock.Any()).Return([]model.User{{ID: "u1"}, {ID: "u2"}}, nil)
+	userRepo.EXPECT().LockDeletionDueTx(gomock.Any(), "u1", gomock.Any()).Return(true, nil)
+	// Вход отменил удаление u2 после выборки: его файлы и строка остаются
+	userRepo.EXPECT().LockDeletionDueTx(gomock.Any(), "u2", gomock.Any()).Return(false, nil)
+	docRepo.EXPECT().ForEachByOwner("u1", gomock.Any()).DoAndReturn(func(owner string, fn func(doc *model.Document) error) error {
+		if err := fn(&model.Document{ID: "d1", File: true}); err != nil {
+			return err
+		}
+		return fn(&model.Document{ID: "d2"})
+	})
+	sessions.EXPECT().DeleteByUser("u1")
+	userRepo.EXPECT().DeleteDueTx(gomock.Any(), "u1", gomock.Any()).Return(true, nil)
+
+	n, err := accounts.PurgeDue(time.Now())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 purged account, got %d", n)
+	}
+	if _, err := blobs.Open("d1"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected blob to be removed, got %v", err)
+	}
+}
+
+func TestAccountService_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accounts, userRepo, docRepo, _, blobs := newTestAccountService(t, ctrl)
+	if _, err := blobs.Put("d2", strings.NewReader("file body")); err != nil {
+		t.Fatalf("failed to put blob: %v", err)
+	}
+
+	userRepo.EXPECT().GetByID("u1").Return(&model.User{ID: "u1", Login: "testuser"}, nil)
+	docRepo.EXPECT().ForEachByOwner("u1", gomock.Any()).DoAndReturn(func(owner string, fn func(doc *model.Document) error) error {
+		if err := fn(&model.Document{ID: "d1", Name: "data", JsonData: []byte(`{"a":1}`)}); err != nil {
+			return err
+		}
+		return fn(&model.Document{ID: "d2", Name: "../report.txt", File: true})
+	})
+
+	var buf bytes.Buffer
+	if err := accounts.Export("u1", &buf); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("expected valid zip, got %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(body)
+	}
+	for _, name := range []string{"profile.json", "documents.json", "json/d1.json", "files/d2/report.txt"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("expected %s in archive, got %v", name, zr.File)
+		}
+	}
+	if files["json/d1.json"] != `{"a":1}` || files["files/d2/report.txt"] != "file body" {
+		t.Fatalf("unexpected archive contents: %v", files)
+	}
+	if !strings.Contains(files["profile.json"], `"login": "testuser"`) {
+		t.Fatalf("unexpected profile: %s", files["profile.json"])
+	}
+}
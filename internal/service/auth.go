@@ -55,6 +55,13 @@ func (s *AuthService) Authenticate(login, password string) (*model.User, error)
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		return nil, errors.New("invalid password")
 	}
+	if user.DeleteAfter != nil {
+		// Вход в течение льготного периода отменяет удаление аккаунта
+		if err := s.userRepo.ScheduleDeletion(user.ID, nil); err != nil {
+			return nil, err
+		}
+		user.DeleteAfter = nil
+	}
 	return user, nil
 }
 
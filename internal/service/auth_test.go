@@ -5,6 +5,7 @@ import (
 	"astra-api/internal/model"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/mock/gomock"
@@ -206,3 +207,26 @@ func TestAuthService_Authenticate_InvalidPassword(t *testing.T) {
 		t.Fatalf("expected 'invalid password', got %s", err.Error())
 	}
 }
+
+func TestAuthService_Authenticate_CancelsDeletion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	authService := NewAuthService(userRepo, "admin123")
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("Password123!"), bcrypt.MinCost)
+	at := time.Now().Add(time.Hour)
+	user := &model.User{ID: "u1", Login: "testuser123", Password: string(hash), DeleteAfter: &at}
+
+	userRepo.EXPECT().GetByLogin("testuser123").Return(user, nil)
+	userRepo.EXPECT().ScheduleDeletion("u1", nil).Return(nil)
+
+	result, err := authService.Authenticate("testuser123", "Password123!")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.DeleteAfter != nil {
+		t.Fatal("expected pending deletion to be cleared")
+	}
+}
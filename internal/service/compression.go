@@ -0,0 +1,40 @@
+package service
+
+import (
+	"astra-api/internal/compress"
+	"astra-api/internal/model"
+	"astra-api/internal/storage"
+	"io"
+)
+
+// CompressionService сжимает загружаемые файлы перед записью в хранилище, если это
+// окупается: решают тип файла и то, насколько сжимается его начало (см. compress.Policy)
+type CompressionService struct {
+	policy compress.Policy
+}
+
+func NewCompressionService(policy compress.Policy) *CompressionService {
+	return &CompressionService{policy: policy}
+}
+
+// Pack решает, сжимать ли файл, и записывает решение в doc.Encoding. Вызывается
+// после определения типа: решение зависит от doc.Mime. Packed.Size() после записи —
+// исходный размер файла, он и учитывается в квоте.
+func (s *CompressionService) Pack(doc *model.Document, body io.Reader) (*compress.Packed, error) {
+	packed, err := s.policy.Pack(doc.Mime, body)
+	if err != nil {
+		return nil, err
+	}
+	doc.Encoding = packed.Encoding
+	return packed, nil
+}
+
+// OpenContent открывает исходное содержимое файла документа: сжатый файл
+// распаковывается на лету, Seek и Range-запросы работают по исходным байтам
+func OpenContent(blobs storage.BlobStore, doc *model.Document) (io.ReadSeekCloser, error) {
+	var size int64
+	if doc.Size != nil {
+		size = *doc.Size
+	}
+	return compress.Open(blobs, doc.StorageKey(), doc.Encoding, size)
+}
@@ -3,11 +3,18 @@ package service
 import (
 	"astra-api/internal/model"
 	"astra-api/internal/repository"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrDocumentNotFound    = errors.New("document not found")
+	ErrDocumentExists      = errors.New("document with this name already exists in the folder")
+	ErrInvalidDocumentName = errors.New("document name must be 1-255 characters without '/'")
+)
+
 type DocsService struct {
 	docRepo repository.DocumentRepositoryInterface
 }
@@ -17,19 +24,57 @@ func NewDocsService(docRepo repository.DocumentRepositoryInterface) *DocsService
 }
 
 func (s *DocsService) Create(doc *model.Document) error {
-	doc.ID = uuid.New().String()
+	if doc.ID == "" {
+		doc.ID = uuid.New().String()
+	}
 	doc.CreatedAt = time.Now()
-	return s.docRepo.Create(doc)
+	return documentExists(s.docRepo.Create(doc))
+}
+
+func (s *DocsService) List(owner string, q model.ListQuery) ([]model.Document, error) {
+	return s.docRepo.List(owner, q)
+}
+
+func (s *DocsService) ListVisible(owner string, principals []string, q model.ListQuery) ([]model.Document, error) {
+	return s.docRepo.ListVisible(owner, principals, q)
+}
+
+func (s *DocsService) ListAccessible(userID string, principals []string, includeOwned bool, q model.ListQuery) ([]model.Document, error) {
+	return s.docRepo.ListAccessible(userID, principals, includeOwned, q)
+}
+
+func (s *DocsService) Search(userID string, principals []string, query string, limit int) ([]model.SearchResult, error) {
+	return s.docRepo.Search(userID, principals, query, limit)
 }
 
-func (s *DocsService) List(owner string, limit int) ([]model.Document, error) {
-	return s.docRepo.List(owner, limit)
+func (s *DocsService) CountTags(userID string, principals []string, owned, shared bool) ([]model.TagCount, error) {
+	return s.docRepo.CountTags(userID, principals, owned, shared)
 }
 
 func (s *DocsService) GetByID(id string) (*model.Document, error) {
 	return s.docRepo.GetByID(id)
 }
 
+func (s *DocsService) Update(doc *model.Document) error {
+	return documentExists(s.docRepo.Update(doc))
+}
+
+// documentExists переводит нарушение уникальности имени документа в ErrDocumentExists
+func documentExists(err error) error {
+	if errors.Is(err, repository.ErrDuplicate) {
+		return ErrDocumentExists
+	}
+	return err
+}
+
+// Delete переносит документ в корзину; окончательно его удаляет TrashService
 func (s *DocsService) Delete(id string) error {
-	return s.docRepo.Delete(id)
+	ok, err := s.docRepo.Trash(id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrDocumentNotFound
+	}
+	return nil
 }
@@ -24,7 +24,7 @@ func TestDocsService_Create(t *testing.T) {
 		File:     false,
 		Public:   false,
 		Owner:    "user123",
-		Grants:   []string{},
+		Grants:   []model.Grant{},
 		JsonData: []byte(`{"test": "data"}`),
 	}
 
@@ -57,7 +57,7 @@ func TestDocsService_Create_RepositoryError(t *testing.T) {
 		File:     false,
 		Public:   false,
 		Owner:    "user123",
-		Grants:   []string{},
+		Grants:   []model.Grant{},
 		JsonData: []byte(`{"test": "data"}`),
 	}
 
@@ -89,7 +89,7 @@ func TestDocsService_List(t *testing.T) {
 			Public:    false,
 			Owner:     "user123",
 			CreatedAt: time.Now(),
-			Grants:    []string{},
+			Grants:    []model.Grant{},
 			JsonData:  []byte(`{"test": "data1"}`),
 		},
 		{
@@ -100,14 +100,14 @@ func TestDocsService_List(t *testing.T) {
 			Public:    false,
 			Owner:     "user123",
 			CreatedAt: time.Now(),
-			Grants:    []string{},
+			Grants:    []model.Grant{},
 			JsonData:  []byte(`{"test": "data2"}`),
 		},
 	}
 
-	docRepo.EXPECT().List("user123", 10).Return(expectedDocs, nil)
+	docRepo.EXPECT().List("user123", model.ListQuery{Limit: 10}).Return(expectedDocs, nil)
 
-	docs, err := docsService.List("user123", 10)
+	docs, err := docsService.List("user123", model.ListQuery{Limit: 10})
 
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
@@ -127,9 +127,9 @@ func TestDocsService_List_RepositoryError(t *testing.T) {
 	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
 	docsService := NewDocsService(docRepo)
 
-	docRepo.EXPECT().List("user123", 10).Return(nil, errors.New("database error"))
+	docRepo.EXPECT().List("user123", model.ListQuery{Limit: 10}).Return(nil, errors.New("database error"))
 
-	docs, err := docsService.List("user123", 10)
+	docs, err := docsService.List("user123", model.ListQuery{Limit: 10})
 
 	if err == nil {
 		t.Fatal("expected error from repository")
@@ -157,7 +157,7 @@ func TestDocsService_GetByID(t *testing.T) {
 		Public:    false,
 		Owner:     "user123",
 		CreatedAt: time.Now(),
-		Grants:    []string{},
+		Grants:    []model.Grant{},
 		JsonData:  []byte(`{"test": "data"}`),
 	}
 
@@ -205,7 +205,7 @@ func TestDocsService_Delete(t *testing.T) {
 	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
 	docsService := NewDocsService(docRepo)
 
-	docRepo.EXPECT().Delete("doc123").Return(nil)
+	docRepo.EXPECT().Trash("doc123", gomock.Any()).Return(true, nil)
 
 	err := docsService.Delete("doc123")
 
@@ -214,6 +214,20 @@ func TestDocsService_Delete(t *testing.T) {
 	}
 }
 
+func TestDocsService_Delete_AlreadyTrashed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	docsService := NewDocsService(docRepo)
+
+	docRepo.EXPECT().Trash("doc123", gomock.Any()).Return(false, nil)
+
+	if err := docsService.Delete("doc123"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Fatalf("expected ErrDocumentNotFound, got %v", err)
+	}
+}
+
 func TestDocsService_Delete_RepositoryError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -221,7 +235,7 @@ func TestDocsService_Delete_RepositoryError(t *testing.T) {
 	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
 	docsService := NewDocsService(docRepo)
 
-	docRepo.EXPECT().Delete("nonexistent").Return(errors.New("not found"))
+	docRepo.EXPECT().Trash("nonexistent", gomock.Any()).Return(false, errors.New("not found"))
 
 	err := docsService.Delete("nonexistent")
 
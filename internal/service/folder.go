@@ -0,0 +1,309 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrFolderNotFound    = errors.New("folder not found")
+	ErrFolderExists      = errors.New("folder with this name already exists")
+	ErrInvalidFolderName = errors.New("folder name must be 1-255 characters without '/'")
+	ErrFolderNotEmpty    = errors.New("folder is not empty")
+	ErrFolderCycle       = errors.New("folder cannot be moved into itself")
+	ErrFolderOwner       = errors.New("folder belongs to another owner")
+)
+
+// FolderService управляет деревом папок. Права на папку действуют на всё содержимое:
+// read — видеть папку и её содержимое, write — переименовывать и загружать документы,
+// share — выдавать гранты, manage — создавать подпапки, переносить и удалять.
+// Всё содержимое папки принадлежит её владельцу.
+type FolderService struct {
+	folderRepo repository.FolderRepositoryInterface
+	docRepo    repository.DocumentRepositoryInterface
+	access     AccessServiceInterface
+	schemas    SchemaServiceInterface
+	tx         repository.TxManagerInterface
+}
+
+func NewFolderService(folderRepo repository.FolderRepositoryInterface, docRepo repository.DocumentRepositoryInterface, access AccessServiceInterface, schemas SchemaServiceInterface, tx repository.TxManagerInterface) *FolderService {
+	return &FolderService{folderRepo: folderRepo, docRepo: docRepo, access: access, schemas: schemas, tx: tx}
+}
+
+// Create создаёт папку в корне actorID или внутри папки, на которую у него есть manage
+func (s *FolderService) Create(actorID string, req model.CreateFolderRequest) (*model.Folder, error) {
+	if !model.ValidName(req.Name) {
+		return nil, ErrInvalidFolderName
+	}
+	folder := &model.Folder{ID: uuid.New().String(), Owner: actorID, Name: req.Name, CreatedAt: time.Now()}
+	if req.Parent != nil && *req.Parent != "" {
+		parent, _, err := s.load(actorID, *req.Parent, model.PermissionManage)
+		if err != nil {
+			return nil, err
+		}
+		folder.Owner = parent.Owner
+		folder.ParentID = &parent.ID
+		folder.Path = append(folder.Path, parent.Path...)
+	}
+	folder.Path = append(folder.Path, folder.ID)
+	if err := s.checkName(folder.Owner, folder.ParentID, folder.Name); err != nil {
+		return nil, err
+	}
+	if err := s.folderRepo.Create(folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// List возвращает корневые папки пользователя и чужие папки, выданные ему грантом
+func (s *FolderService) List(userID string) ([]model.Folder, error) {
+	folders, err := s.folderRepo.ListChildren(userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	principals, err := s.access.Principals(userID)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := s.folderRepo.ListShared(userID, principals)
+	if err != nil {
+		return nil, err
+	}
+	return append(folders, shared...), nil
+}
+
+// Get возвращает папку с подпапками и документами; нужен уровень read
+func (s *FolderService) Get(actorID, id string) (*model.FolderContents, error) {
+	folder, level, err := s.load(actorID, id, model.PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+	folders, err := s.folderRepo.ListChildren(folder.Owner, &folder.ID)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.folderRepo.ListDocuments(folder.Owner, &folder.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.FolderContents{Folder: folder, Permission: level, Folders: folders, Documents: docs}, nil
+}
+
+// Update переименовывает папку (write), переносит её вместе с содержимым и меняет схему (manage).
+// Все изменения — одна транзакция, в которой папка и новый родитель заблокированы.
+func (s *FolderService) Update(actorID, id string, req model.UpdateFolderRequest) (*model.Folder, error) {
+	var folder *model.Folder
+	err := s.tx.InTx(func(tx *sql.Tx) error {
+		var err error
+		folder, err = s.update(tx, actorID, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+func (s *FolderService) update(tx *sql.Tx, actorID, id string, req model.UpdateFolderRequest) (*model.Folder, error) {
+	need := model.PermissionWrite
+	if req.Parent != nil || req.Schema != nil {
+		need = model.PermissionManage
+	}
+	folder, _, err := s.loadTx(tx, actorID, id, need)
+	if err != nil {
+		return nil, err
+	}
+	name, parentID := folder.Name, folder.ParentID
+	if req.Name != nil {
+		if !model.ValidName(*req.Name) {
+			return nil, ErrInvalidFolderName
+		}
+		name = *req.Name
+	}
+	path := []string{folder.ID}
+	moved := false
+	if req.Parent != nil {
+		parentID = nil
+		if *req.Parent != "" {
+			parent, _, err := s.loadTx(tx, actorID, *req.Parent, model.PermissionManage)
+			if err != nil {
+				return nil, err
+			}
+			if parent.Owner != folder.Owner {
+				return nil, ErrFolderOwner
+			}
+			if folder.Contains(parent) {
+				return nil, ErrFolderCycle
+			}
+			parentID = &parent.ID
+			path = append(append([]string{}, parent.Path...), folder.ID)
+		}
+		moved = !sameFolder(parentID, folder.ParentID)
+	}
+	if moved || name != folder.Name {
+		if err := s.checkName(folder.Owner, parentID, name); err != nil {
+			return nil, err
+		}
+	}
+	if req.Schema != nil {
+		if err := s.schemas.AttachFolder(folder, *req.Schema); err != nil {
+			return nil, err
+		}
+	}
+	if moved {
+		if err := s.folderRepo.MoveTx(tx, folder, parentID, path); err != nil {
+			return nil, folderExists(err)
+		}
+		folder.ParentID = parentID
+		folder.Path = path
+	}
+	folder.Name = name
+	if err := s.folderRepo.UpdateTx(tx, folder); err != nil {
+		return nil, folderExists(err)
+	}
+	return folder, nil
+}
+
+// Delete удаляет пустую папку; нужен уровень manage
+func (s *FolderService) Delete(actorID, id string) error {
+	folder, _, err := s.load(actorID, id, model.PermissionManage)
+	if err != nil {
+		return err
+	}
+	empty, err := s.folderRepo.IsEmpty(folder.ID)
+	if err != nil {
+		return err
+	}
+	if !empty {
+		return ErrFolderNotEmpty
+	}
+	return s.folderRepo.Delete(folder.ID)
+}
+
+// Place кладёт новый документ в папку, на которую у actorID есть write: документ
+// переходит к владельцу папки и проверяется её схемой
+func (s *FolderService) Place(actorID string, doc *model.Document, folderID string) error {
+	folder, _, err := s.load(actorID, folderID, model.PermissionWrite)
+	if err != nil {
+		return err
+	}
+	doc.Owner = folder.Owner
+	doc.FolderID = &folder.ID
+	return s.schemas.Validate(doc)
+}
+
+// MoveDocuments переносит документы в папку (пустой folderID — в корень владельца) одной
+// транзакцией: если хотя бы один документ перенести нельзя, не переносится ни один.
+// Нужен manage на каждый документ и на папку назначения. Права и схемы проверяются в той же
+// транзакции по заблокированным строкам, так что проверенное состояние и записывается.
+func (s *FolderService) MoveDocuments(actorID string, ids []string, folderID string) error {
+	return s.tx.InTx(func(tx *sql.Tx) error {
+		var target *string
+		var owner string
+		if folderID != "" {
+			folder, _, err := s.loadTx(tx, actorID, folderID, model.PermissionManage)
+			if err != nil {
+				return err
+			}
+			target, owner = &folder.ID, folder.Owner
+		}
+		for _, id := range ids {
+			doc, err := s.docRepo.GetByIDTx(tx, id)
+			if err != nil {
+				return fmt.Errorf("document %s: %w", id, ErrDocumentNotFound)
+			}
+			level, err := s.access.Permission(actorID, doc)
+			if err != nil {
+				return err
+			}
+			if level == "" {
+				return fmt.Errorf("document %s: %w", id, ErrDocumentNotFound)
+			}
+			if !level.Includes(model.PermissionManage) {
+				return fmt.Errorf("document %s: %w", id, ErrForbidden)
+			}
+			if target != nil && doc.Owner != owner {
+				return fmt.Errorf("document %s: %w", id, ErrFolderOwner)
+			}
+			doc.FolderID = target
+			if err := s.schemas.Validate(doc); err != nil {
+				return fmt.Errorf("document %s: %w", id, err)
+			}
+			err = s.docRepo.MoveTx(tx, doc.ID, target, doc.Name)
+			if errors.Is(err, repository.ErrDuplicate) {
+				return fmt.Errorf("document %s: %w", doc.ID, ErrDocumentExists)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Lookup возвращает папку, которую actorID может читать
+func (s *FolderService) Lookup(actorID, id string) (*model.Folder, error) {
+	folder, _, err := s.load(actorID, id, model.PermissionRead)
+	return folder, err
+}
+
+// load читает папку и проверяет уровень доступа; тем, кто не может её читать,
+// папка не видна
+func (s *FolderService) load(actorID, id string, need model.Permission) (*model.Folder, model.Permission, error) {
+	folder, err := s.folderRepo.GetByID(id)
+	if err != nil {
+		return nil, "", ErrFolderNotFound
+	}
+	return s.authorize(actorID, folder, need)
+}
+
+// loadTx — как load, но папка читается в транзакции и блокируется до её конца
+func (s *FolderService) loadTx(tx *sql.Tx, actorID, id string, need model.Permission) (*model.Folder, model.Permission, error) {
+	folder, err := s.folderRepo.GetByIDTx(tx, id)
+	if err != nil {
+		return nil, "", ErrFolderNotFound
+	}
+	return s.authorize(actorID, folder, need)
+}
+
+func (s *FolderService) authorize(actorID string, folder *model.Folder, need model.Permission) (*model.Folder, model.Permission, error) {
+	level, err := s.access.FolderPermission(actorID, folder)
+	if err != nil {
+		return nil, "", err
+	}
+	if level == "" {
+		return nil, "", ErrFolderNotFound
+	}
+	if !level.Includes(need) {
+		return nil, "", ErrForbidden
+	}
+	return folder, level, nil
+}
+
+func (s *FolderService) checkName(owner string, parentID *string, name string) error {
+	if _, err := s.folderRepo.GetByName(owner, parentID, name); err == nil {
+		return ErrFolderExists
+	}
+	return nil
+}
+
+// folderExists переводит нарушение уникальности имени в ErrFolderExists
+func folderExists(err error) error {
+	if errors.Is(err, repository.ErrDuplicate) {
+		return ErrFolderExists
+	}
+	return err
+}
+
+func sameFolder(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestFolderService_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	folderRepo := mocksgen.NewMockFolderRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	folders := NewFolderService(folderRepo, mocksgen.NewMockDocumentRepositoryInterface(ctrl), access, mocksgen.NewMockSchemaServiceInterface(ctrl), mocksgen.NewMockTxManagerInterface(ctrl))
+
+	parent := &model.Folder{ID: "f1", Owner: "u1", Name: "reports", Path: []string{"f1"}}
+	folderRepo.EXPECT().GetByID("f1").Return(parent, nil).AnyTimes()
+	access.EXPECT().FolderPermission("u2", parent).Return(model.PermissionManage, nil)
+	folderRepo.EXPECT().GetByName("u1", &parent.ID, "2026").Return(nil, sql.ErrNoRows)
+	folderRepo.EXPECT().Create(gomock.Any()).Return(nil)
+
+	folder, err := folders.Create("u2", model.CreateFolderRequest{Name: "2026", Parent: &parent.ID})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if folder.Owner != "u1" || *folder.ParentID != "f1" || len(folder.Path) != 2 || folder.Path[0] != "f1" || folder.Path[1] != folder.ID {
+		t.Fatalf("unexpected folder %+v", folder)
+	}
+
+	access.EXPECT().FolderPermission("u3", parent).Return(model.PermissionWrite, nil)
+	if _, err := folders.Create("u3", model.CreateFolderRequest{Name: "2026", Parent: &parent.ID}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	access.EXPECT().FolderPermission("u4", parent).Return(model.Permission(""), nil)
+	if _, err := folders.Create("u4", model.CreateFolderRequest{Name: "2026", Parent: &parent.ID}); !errors.Is(err, ErrFolderNotFound) {
+		t.Fatalf("expected ErrFolderNotFound, got %v", err)
+	}
+	folderRepo.EXPECT().GetByName("u1", nil, "reports").Return(parent, nil)
+	if _, err := folders.Create("u1", model.CreateFolderRequest{Name: "reports"}); !errors.Is(err, ErrFolderExists) {
+		t.Fatalf("expected ErrFolderExists, got %v", err)
+	}
+	if _, err := folders.Create("u1", model.CreateFolderRequest{Name: "a/b"}); !errors.Is(err, ErrInvalidFolderName) {
+		t.Fatalf("expected ErrInvalidFolderName, got %v", err)
+	}
+}
+
+func TestFolderService_Update_Move(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	folderRepo := mocksgen.NewMockFolderRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	folders := NewFolderService(folderRepo, mocksgen.NewMockDocumentRepositoryInterface(ctrl), access, mocksgen.NewMockSchemaServiceInterface(ctrl), newInlineTx(ctrl))
+
+	root := &model.Folder{ID: "a", Owner: "u1", Name: "a", Path: []string{"a"}}
+	child := &model.Folder{ID: "b", Owner: "u1", Name: "b", ParentID: &root.ID, Path: []string{"a", "b"}}
+	other := &model.Folder{ID: "c", Owner: "u1", Name: "c", Path: []string{"c"}}
+	foreign := &model.Folder{ID: "x", Owner: "u9", Name: "x", Path: []string{"x"}}
+	for _, f := range []*model.Folder{root, child, other, foreign} {
+		folderRepo.EXPECT().GetByIDTx(gomock.Any(), f.ID).Return(f, nil).AnyTimes()
+	}
+	access.EXPECT().FolderPermission("u1", gomock.Any()).Return(model.PermissionManage, nil).AnyTimes()
+
+	if _, err := folders.Update("u1", "a", model.UpdateFolderRequest{Parent: &child.ID}); !errors.Is(err, ErrFolderCycle) {
+		t.Fatalf("expected ErrFolderCycle, got %v", err)
+	}
+	if _, err := folders.Update("u1", "a", model.UpdateFolderRequest{Parent: &foreign.ID}); !errors.Is(err, ErrFolderOwner) {
+		t.Fatalf("expected ErrFolderOwner, got %v", err)
+	}
+
+	folderRepo.EXPECT().GetByName("u1", &other.ID, "a").Return(nil, sql.ErrNoRows)
+	folderRepo.EXPECT().MoveTx(gomock.Any(), root, &other.ID, []string{"c", "a"}).Return(nil)
+	folderRepo.EXPECT().UpdateTx(gomock.Any(), root).Return(nil)
+	moved, err := folders.Update("u1", "a", model.UpdateFolderRequest{Parent: &other.ID})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *moved.ParentID != "c" || len(moved.Path) != 2 {
+		t.Fatalf("unexpected folder %+v", moved)
+	}
+
+	// Имя заняли между проверкой и записью: перенос откатывается вместе с переименованием
+	name := "taken"
+	folderRepo.EXPECT().GetByName("u1", &root.ID, "taken").Return(nil, sql.ErrNoRows)
+	folderRepo.EXPECT().MoveTx(gomock.Any(), child, &root.ID, gomock.Any()).Times(0)
+	folderRepo.EXPECT().UpdateTx(gomock.Any(), child).Return(repository.ErrDuplicate)
+	if _, err := folders.Update("u1", "b", model.UpdateFolderRequest{Name: &name}); !errors.Is(err, ErrFolderExists) {
+		t.Fatalf("expected ErrFolderExists, got %v", err)
+	}
+}
+
+func TestFolderService_MoveDocuments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	folderRepo := mocksgen.NewMockFolderRepositoryInterface(ctrl)
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	schemas := mocksgen.NewMockSchemaServiceInterface(ctrl)
+	folders := NewFolderService(folderRepo, docRepo, access, schemas, newInlineTx(ctrl))
+
+	folder := &model.Folder{ID: "f1", Owner: "u1", Path: []string{"f1"}}
+	folderRepo.EXPECT().GetByIDTx(gomock.Any(), "f1").Return(folder, nil).AnyTimes()
+	access.EXPECT().FolderPermission("u1", folder).Return(model.PermissionManage, nil).AnyTimes()
+	docs := map[string]*model.Document{
+		"d1": {ID: "d1", Owner: "u1"},
+		"d2": {ID: "d2", Owner: "u1"},
+		"d3": {ID: "d3", Owner: "u2"},
+	}
+	docRepo.EXPECT().GetByIDTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ *sql.Tx, id string) (*model.Document, error) {
+		doc, ok := docs[id]
+		if !ok {
+			return nil, sql.ErrNoRows
+		}
+		copied := *doc
+		return &copied, nil
+	}).AnyTimes()
+	access.EXPECT().Permission("u1", gomock.Any()).Return(model.PermissionManage, nil).AnyTimes()
+	schemas.EXPECT().Validate(gomock.Any()).Return(nil).AnyTimes()
+
+	// Проверки идут в транзакции: документ, перенесённый до ошибки, откатывается вместе с ней
+	docRepo.EXPECT().MoveTx(gomock.Any(), "d1", &folder.ID, "").Return(nil).Times(2)
+	if err := folders.MoveDocuments("u1", []string{"d1", "d3"}, "f1"); !errors.Is(err, ErrFolderOwner) {
+		t.Fatalf("expected ErrFolderOwner, got %v", err)
+	}
+	if err := folders.MoveDocuments("u1", []string{"d1", "missing"}, "f1"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Fatalf("expected ErrDocumentNotFound, got %v", err)
+	}
+
+	docRepo.EXPECT().MoveTx(gomock.Any(), "d1", &folder.ID, "").Return(nil)
+	docRepo.EXPECT().MoveTx(gomock.Any(), "d2", &folder.ID, "").Return(repository.ErrDuplicate)
+	if err := folders.MoveDocuments("u1", []string{"d1", "d2"}, "f1"); !errors.Is(err, ErrDocumentExists) {
+		t.Fatalf("expected ErrDocumentExists, got %v", err)
+	}
+}
+
+func TestFolderService_Delete_NotEmpty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	folderRepo := mocksgen.NewMockFolderRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	folders := NewFolderService(folderRepo, mocksgen.NewMockDocumentRepositoryInterface(ctrl), access, mocksgen.NewMockSchemaServiceInterface(ctrl), mocksgen.NewMockTxManagerInterface(ctrl))
+
+	folder := &model.Folder{ID: "f1", Owner: "u1", Path: []string{"f1"}}
+	folderRepo.EXPECT().GetByID("f1").Return(folder, nil).Times(2)
+	access.EXPECT().FolderPermission("u1", folder).Return(model.PermissionManage, nil).Times(2)
+	folderRepo.EXPECT().IsEmpty("f1").Return(false, nil)
+	if err := folders.Delete("u1", "f1"); !errors.Is(err, ErrFolderNotEmpty) {
+		t.Fatalf("expected ErrFolderNotEmpty, got %v", err)
+	}
+	folderRepo.EXPECT().IsEmpty("f1").Return(true, nil)
+	folderRepo.EXPECT().Delete("f1").Return(nil)
+	if err := folders.Delete("u1", "f1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,483 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"astra-api/internal/storage"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPathNotFound = errors.New("path not found")
+	ErrInvalidPath  = errors.New("invalid path")
+	ErrPathExists   = errors.New("path already exists")
+	ErrPathKind     = errors.New("document at path is of another kind")
+)
+
+// FSService адресует документы путями внутри пространства пользователя: путь — это
+// имена папок от корня владельца и имя документа. Если в каталоге есть и папка, и
+// документ с одним именем, путь указывает на папку. Корень виден только владельцу,
+// права на папки и документы — как в FolderService и AccessService.
+type FSService struct {
+	userRepo   repository.UserRepositoryInterface
+	folderRepo repository.FolderRepositoryInterface
+	docRepo    repository.DocumentRepositoryInterface
+	access     AccessServiceInterface
+	folders    FolderServiceInterface
+	schemas    SchemaServiceInterface
+	blobs      storage.BlobStore
+	quota      QuotaServiceInterface
+	mime       MimeServiceInterface
+	scans      ScanServiceInterface
+	compress   CompressionServiceInterface
+	tx         repository.TxManagerInterface
+}
+
+func NewFSService(userRepo repository.UserRepositoryInterface, folderRepo repository.FolderRepositoryInterface, docRepo repository.DocumentRepositoryInterface, access AccessServiceInterface, folders FolderServiceInterface, schemas SchemaServiceInterface, blobs storage.BlobStore, quota QuotaServiceInterface, mime MimeServiceInterface, scans ScanServiceInterface, compress CompressionServiceInterface, tx repository.TxManagerInterface) *FSService {
+	return &FSService{userRepo: userRepo, folderRepo: folderRepo, docRepo: docRepo, access: access, folders: folders, schemas: schemas, blobs: blobs, quota: quota, mime: mime, scans: scans, compress: compress, tx: tx}
+}
+
+// Get разрешает путь: для документа возвращает его в node.Document, для папки или
+// корня — листинг. Нужен уровень read.
+func (s *FSService) Get(actorID, login, p string) (*model.FSNode, *model.FSListing, error) {
+	owner, parts, err := s.parse(login, p)
+	if err != nil {
+		return nil, nil, err
+	}
+	node, err := s.resolve(owner, parts)
+	if err != nil {
+		return nil, nil, err
+	}
+	level, err := s.require(actorID, node, model.PermissionRead)
+	if err != nil {
+		return nil, nil, err
+	}
+	if node.Document != nil {
+		return node, nil, nil
+	}
+	listing, err := s.list(node, level)
+	if err != nil {
+		return nil, nil, err
+	}
+	return node, listing, nil
+}
+
+// Put создаёт документ по пути или заменяет содержимое существующего. Недостающие
+// папки создаются, как mkdir -p. Новый документ требует write на каталог (в корне —
+// быть владельцем), замена — write на документ; вид документа (JSON или файл) не меняется.
+func (s *FSService) Put(actorID, login, p string, content model.FSContent, body io.Reader) (*model.Document, bool, error) {
+	owner, parts, err := s.parse(login, p)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(parts) == 0 {
+		return nil, false, ErrInvalidPath
+	}
+	parent, err := s.mkdirAll(actorID, owner, parts[:len(parts)-1])
+	if err != nil {
+		return nil, false, err
+	}
+	name := parts[len(parts)-1]
+	if _, err := s.folderRepo.GetByName(owner, folderID(parent), name); err == nil {
+		return nil, false, ErrPathExists
+	}
+	doc, err := s.docRepo.GetByPath(owner, folderID(parent), name)
+	if err == nil {
+		return doc, false, s.overwrite(actorID, &model.FSNode{Owner: owner, Parent: parent, Document: doc}, content, body)
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, err
+	}
+	if _, err := s.require(actorID, &model.FSNode{Owner: owner, Folder: parent}, model.PermissionWrite); err != nil {
+		return nil, false, err
+	}
+	doc = &model.Document{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Mime:      content.Mime,
+		File:      content.File,
+		Owner:     owner,
+		FolderID:  folderID(parent),
+		JsonData:  content.JSON,
+		CreatedAt: time.Now(),
+	}
+	if err := s.schemas.Validate(doc); err != nil {
+		return nil, false, err
+	}
+	if err := s.store(doc, 1, 0, body); err != nil {
+		return nil, false, err
+	}
+	if err := s.docRepo.Create(doc); err != nil {
+		if doc.File {
+			_ = s.blobs.Remove(doc.ID)
+		}
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, false, ErrPathExists
+		}
+		return nil, false, err
+	}
+	_ = s.scans.Scan(doc)
+	return doc, true, nil
+}
+
+func (s *FSService) overwrite(actorID string, node *model.FSNode, content model.FSContent, body io.Reader) error {
+	if _, err := s.require(actorID, node, model.PermissionWrite); err != nil {
+		return err
+	}
+	doc := node.Document
+	if doc.File != content.File {
+		return ErrPathKind
+	}
+	// Тип файла определяется заново: старый мог не подойти новому содержимому
+	if content.Mime != "" || doc.File {
+		doc.Mime = content.Mime
+	}
+	var replaced int64
+	if doc.Size != nil {
+		replaced = *doc.Size
+	}
+	if !doc.File {
+		doc.JsonData = content.JSON
+		if err := s.schemas.Validate(doc); err != nil {
+			return err
+		}
+	}
+	if err := s.store(doc, 0, replaced, body); err != nil {
+		return err
+	}
+	// От времени замены зависят Last-Modified и ответы на условные запросы
+	modified := time.Now()
+	doc.ModifiedAt = &modified
+	if !doc.File {
+		return s.docRepo.Update(doc)
+	}
+	// Новый файл записан под новым ключом: документ переключается на него вместе
+	// с encoding, размером и постановкой на проверку, а до этого по-прежнему отдаёт
+	// старый с результатом его проверки
+	previous, err := s.docRepo.UpdateFile(doc)
+	if err != nil {
+		_ = s.blobs.Remove(doc.StorageKey())
+		return err
+	}
+	if err := s.blobs.Remove(previous); err != nil {
+		log.Printf("cannot remove replaced file %s of document %s: %v", previous, doc.ID, err)
+	}
+	// Недоступный сканер не мешает записи: файл проверит фоновая задача
+	_ = s.scans.Scan(doc)
+	return nil
+}
+
+// store проверяет квоту владельца: JSON — по размеру, файл — при записи, прерывая
+// её на превышении. Тип файла определяется по содержимому (см. MimeService),
+// файл сжимается, если это окупается (см. CompressionService); исходный размер
+// сохраняется в doc.Size, занятое в хранилище — в doc.StoredSize. Файл ставится на проверку
+// антивирусом. newDocs=0 — замена содержимого существующего документа.
+func (s *FSService) store(doc *model.Document, newDocs int, replaced int64, body io.Reader) error {
+	if !doc.File {
+		return s.quota.Check(doc.Owner, newDocs, int64(len(doc.JsonData)), replaced)
+	}
+	body, err := s.mime.Inspect(doc, body)
+	if err != nil {
+		return err
+	}
+	body, err = s.quota.Reader(doc.Owner, newDocs, replaced, body)
+	if err != nil {
+		return err
+	}
+	s.scans.Queue(doc)
+	packed, err := s.compress.Pack(doc, body)
+	if err != nil {
+		return err
+	}
+	// Новый документ хранит файл под своим ID, заменённое содержимое — под новым ключом
+	key := doc.ID
+	if newDocs == 0 {
+		key = uuid.New().String()
+	}
+	n, err := s.blobs.Put(key, packed)
+	if err != nil {
+		return err
+	}
+	if key != doc.ID {
+		doc.BlobKey = &key
+	}
+	size := packed.Size()
+	doc.Size, doc.StoredSize = &size, &n
+	return nil
+}
+
+// Delete переносит документ в корзину (manage) или удаляет пустую папку по пути
+func (s *FSService) Delete(actorID, login, p string) (*model.FSNode, error) {
+	owner, parts, err := s.parse(login, p)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, ErrInvalidPath
+	}
+	node, err := s.resolve(owner, parts)
+	if err != nil {
+		return nil, err
+	}
+	if node.Folder != nil {
+		if err := s.folders.Delete(actorID, node.Folder.ID); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+	if _, err := s.require(actorID, node, model.PermissionManage); err != nil {
+		return nil, err
+	}
+	ok, err := s.docRepo.Trash(node.Document.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrPathNotFound
+	}
+	return node, nil
+}
+
+// Move переименовывает или переносит папку либо документ в пределах пространства
+// владельца. Каталог назначения должен существовать, путь назначения — быть свободным.
+// Переименование требует write, перенос — manage на элемент и на каталог назначения.
+func (s *FSService) Move(actorID, login, from, to string) (*model.FSNode, error) {
+	owner, src, err := s.parse(login, from)
+	if err != nil {
+		return nil, err
+	}
+	_, dst, err := s.parse(login, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(src) == 0 || len(dst) == 0 {
+		return nil, ErrInvalidPath
+	}
+	node, err := s.resolve(owner, src)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.require(actorID, node, model.PermissionRead); err != nil {
+		return nil, err
+	}
+	parent, err := s.walk(owner, dst[:len(dst)-1])
+	if err != nil {
+		return nil, err
+	}
+	name := dst[len(dst)-1]
+	if target, err := s.resolve(owner, dst); err == nil {
+		if sameNode(node, target) {
+			return node, nil
+		}
+		return nil, ErrPathExists
+	} else if err != ErrPathNotFound {
+		return nil, err
+	}
+	if node.Folder != nil {
+		req := model.UpdateFolderRequest{Name: &name}
+		if !sameFolder(folderID(parent), node.Folder.ParentID) {
+			parentID := ""
+			if parent != nil {
+				parentID = parent.ID
+			}
+			req.Parent = &parentID
+		}
+		folder, err := s.folders.Update(actorID, node.Folder.ID, req)
+		if err != nil {
+			return nil, err
+		}
+		node.Folder = folder
+	} else if err := s.moveDocument(actorID, node, parent, name); err != nil {
+		return nil, err
+	}
+	node.Parent = parent
+	node.Path = joinPath(dst)
+	return node, nil
+}
+
+func (s *FSService) moveDocument(actorID string, node *model.FSNode, parent *model.Folder, name string) error {
+	doc := node.Document
+	moved := !sameFolder(folderID(parent), doc.FolderID)
+	need := model.PermissionWrite
+	if moved {
+		need = model.PermissionManage
+	}
+	if _, err := s.require(actorID, node, need); err != nil {
+		return err
+	}
+	if moved {
+		if _, err := s.require(actorID, &model.FSNode{Owner: node.Owner, Folder: parent}, model.PermissionManage); err != nil {
+			return err
+		}
+		doc.FolderID = folderID(parent)
+		if err := s.schemas.Validate(doc); err != nil {
+			return err
+		}
+	}
+	doc.Name = name
+	err := s.tx.InTx(func(tx *sql.Tx) error {
+		return s.docRepo.MoveTx(tx, doc.ID, doc.FolderID, doc.Name)
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		return ErrPathExists
+	}
+	return err
+}
+
+// parse находит владельца пространства и разбивает путь на имена
+func (s *FSService) parse(login, p string) (string, []string, error) {
+	var parts []string
+	for _, name := range strings.Split(p, "/") {
+		if name == "" {
+			continue
+		}
+		if !model.ValidName(name) {
+			return "", nil, ErrInvalidPath
+		}
+		parts = append(parts, name)
+	}
+	user, err := s.userRepo.GetByLogin(login)
+	if err != nil {
+		return "", nil, ErrPathNotFound
+	}
+	return user.ID, parts, nil
+}
+
+// resolve находит папку или документ по именам от корня владельца; пустой путь — корень
+func (s *FSService) resolve(owner string, parts []string) (*model.FSNode, error) {
+	node := &model.FSNode{Owner: owner, Path: joinPath(parts)}
+	if len(parts) == 0 {
+		return node, nil
+	}
+	parent, err := s.walk(owner, parts[:len(parts)-1])
+	if err != nil {
+		return nil, err
+	}
+	node.Parent = parent
+	name := parts[len(parts)-1]
+	folder, err := s.folderRepo.GetByName(owner, folderID(parent), name)
+	if err == nil {
+		node.Folder = folder
+		return node, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+	doc, err := s.docRepo.GetByPath(owner, folderID(parent), name)
+	if err == sql.ErrNoRows {
+		return nil, ErrPathNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	node.Document = doc
+	return node, nil
+}
+
+// walk находит цепочку папок по именам; nil — корень
+func (s *FSService) walk(owner string, names []string) (*model.Folder, error) {
+	var parent *model.Folder
+	for _, name := range names {
+		folder, err := s.folderRepo.GetByName(owner, folderID(parent), name)
+		if err == sql.ErrNoRows {
+			return nil, ErrPathNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent = folder
+	}
+	return parent, nil
+}
+
+// mkdirAll как walk, но создаёт недостающие папки; права на создание проверяет FolderService
+func (s *FSService) mkdirAll(actorID, owner string, names []string) (*model.Folder, error) {
+	var parent *model.Folder
+	for _, name := range names {
+		folder, err := s.folderRepo.GetByName(owner, folderID(parent), name)
+		if err == sql.ErrNoRows {
+			if parent == nil && actorID != owner {
+				return nil, ErrPathNotFound
+			}
+			if _, err := s.docRepo.GetByPath(owner, folderID(parent), name); err == nil {
+				return nil, ErrPathExists
+			}
+			folder, err = s.folders.Create(actorID, model.CreateFolderRequest{Name: name, Parent: folderID(parent)})
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent = folder
+	}
+	return parent, nil
+}
+
+func (s *FSService) list(node *model.FSNode, level model.Permission) (*model.FSListing, error) {
+	folders, err := s.folderRepo.ListChildren(node.Owner, folderID(node.Folder))
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.folderRepo.ListDocuments(node.Owner, folderID(node.Folder))
+	if err != nil {
+		return nil, err
+	}
+	listing := &model.FSListing{Path: node.Path, Folder: node.Folder, Permission: level, Entries: make([]model.FSEntry, 0, len(folders)+len(docs))}
+	for _, f := range folders {
+		listing.Entries = append(listing.Entries, model.FSEntry{Name: f.Name, Path: path.Join(node.Path, f.Name), Type: model.FSEntryFolder, ID: f.ID, CreatedAt: f.CreatedAt})
+	}
+	for _, d := range docs {
+		listing.Entries = append(listing.Entries, model.FSEntry{Name: d.Name, Path: path.Join(node.Path, d.Name), Type: model.FSEntryDocument, ID: d.ID, Mime: d.Mime, File: d.File, CreatedAt: d.CreatedAt})
+	}
+	return listing, nil
+}
+
+// require проверяет уровень доступа к элементу; корень доступен только владельцу,
+// а недоступное даже на чтение считается несуществующим
+func (s *FSService) require(actorID string, node *model.FSNode, need model.Permission) (model.Permission, error) {
+	var level model.Permission
+	var err error
+	switch {
+	case node.Document != nil:
+		level, err = s.access.Permission(actorID, node.Document)
+	case node.Folder != nil:
+		level, err = s.access.FolderPermission(actorID, node.Folder)
+	case actorID == node.Owner:
+		level = model.PermissionManage
+	}
+	if err != nil {
+		return "", err
+	}
+	if level == "" {
+		return "", ErrPathNotFound
+	}
+	if !level.Includes(need) {
+		return "", ErrForbidden
+	}
+	return level, nil
+}
+
+func joinPath(parts []string) string {
+	return "/" + strings.Join(parts, "/")
+}
+
+func folderID(f *model.Folder) *string {
+	if f == nil {
+		return nil
+	}
+	return &f.ID
+}
+
+func sameNode(a, b *model.FSNode) bool {
+	if a.Folder != nil && b.Folder != nil {
+		return a.Folder.ID == b.Folder.ID
+	}
+	return a.Document != nil && b.Document != nil && a.Document.ID == b.Document.ID
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/storage"
+	"database/sql"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+type fsMocks struct {
+	users   *mocksgen.MockUserRepositoryInterface
+	folders *mocksgen.MockFolderRepositoryInterface
+	docs    *mocksgen.MockDocumentRepositoryInterface
+	access  *mocksgen.MockAccessServiceInterface
+	fsvc    *mocksgen.MockFolderServiceInterface
+	schemas *mocksgen.MockSchemaServiceInterface
+	tx      *mocksgen.MockTxManagerInterface
+	blobs   *storage.FileStore
+}
+
+func newTestFSService(t *testing.T, ctrl *gomock.Controller) (*FSService, fsMocks) {
+	blobs, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create blob store: %v", err)
+	}
+	m := fsMocks{
+		users:   mocksgen.NewMockUserRepositoryInterface(ctrl),
+		folders: mocksgen.NewMockFolderRepositoryInterface(ctrl),
+		docs:    mocksgen.NewMockDocumentRepositoryInterface(ctrl),
+		access:  mocksgen.NewMockAccessServiceInterface(ctrl),
+		fsvc:    mocksgen.NewMockFolderServiceInterface(ctrl),
+		schemas: mocksgen.NewMockSchemaServiceInterface(ctrl),
+		tx:      mocksgen.NewMockTxManagerInterface(ctrl),
+		blobs:   blobs,
+	}
+	m.users.EXPECT().GetByLogin("alice").Return(&model.User{ID: "u1", Login: "alice"}, nil).AnyTimes()
+	return NewFSService(m.users, m.folders, m.docs, m.access, m.fsvc, m.schemas, blobs, newUnlimitedQuota(ctrl), NewMimeService(nil, nil, MimeMismatchReject), newNopScans(ctrl), newNopCompression(), m.tx), m
+}
+
+func TestFSService_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	fs, m := newTestFSService(t, ctrl)
+
+	reports := &model.Folder{ID: "f1", Owner: "u1", Name: "reports", Path: []string{"f1"}}
+	doc := &model.Document{ID: "d1", Owner: "u1", Name: "q1.json", FolderID: &reports.ID}
+	m.folders.EXPECT().GetByName("u1", nil, "reports").Return(reports, nil).AnyTimes()
+	m.folders.EXPECT().GetByName("u1", &reports.ID, "q1.json").Return(nil, sql.ErrNoRows).AnyTimes()
+	m.docs.EXPECT().GetByPath("u1", &reports.ID, "q1.json").Return(doc, nil).AnyTimes()
+	m.access.EXPECT().Permission("u2", doc).Return(model.PermissionRead, nil)
+	m.access.EXPECT().Permission("u3", doc).Return(model.Permission(""), nil)
+
+	node, listing, err := fs.Get("u2", "alice", "/reports/q1.json")
+	if err != nil || listing != nil || node.Document != doc || node.Path != "/reports/q1.json" {
+		t.Fatalf("unexpected result %+v, %+v, %v", node, listing, err)
+	}
+	if _, _, err := fs.Get("u3", "alice", "reports/q1.json"); !errors.Is(err, ErrPathNotFound) {
+		t.Fatalf("expected ErrPathNotFound for unreadable document, got %v", err)
+	}
+	if _, _, err := fs.Get("u2", "alice", "/"); !errors.Is(err, ErrPathNotFound) {
+		t.Fatalf("expected root to be hidden from other users, got %v", err)
+	}
+	if _, _, err := fs.Get("u1", "alice", "/reports/../etc"); !errors.Is(err, ErrInvalidPath) {
+		t.Fatalf("expected ErrInvalidPath, got %v", err)
+	}
+
+	m.folders.EXPECT().ListChildren("u1", &reports.ID).Return([]model.Folder{{ID: "f2", Name: "2026"}}, nil)
+	m.folders.EXPECT().ListDocuments("u1", &reports.ID).Return([]model.Document{{ID: "d1", Name: "q1.json"}}, nil)
+	m.access.EXPECT().FolderPermission("u1", reports).Return(model.PermissionManage, nil)
+	_, listing, err = fs.Get("u1", "alice", "/reports/")
+	if err != nil || len(listing.Entries) != 2 || listing.Entries[0].Path != "/reports/2026" || listing.Entries[1].Type != model.FSEntryDocument {
+		t.Fatalf("unexpected listing %+v, %v", listing, err)
+	}
+}
+
+func TestFSService_Put(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	fs, m := newTestFSService(t, ctrl)
+
+	created := &model.Folder{ID: "f1", Owner: "u1", Name: "reports", Path: []string{"f1"}}
+	m.folders.EXPECT().GetByName("u1", nil, "reports").Return(nil, sql.ErrNoRows)
+	m.docs.EXPECT().GetByPath("u1", nil, "reports").Return(nil, sql.ErrNoRows)
+	m.fsvc.EXPECT().Create("u1", model.CreateFolderRequest{Name: "reports"}).Return(created, nil)
+	m.folders.EXPECT().GetByName("u1", &created.ID, "q1.json").Return(nil, sql.ErrNoRows)
+	m.docs.EXPECT().GetByPath("u1", &created.ID, "q1.json").Return(nil, sql.ErrNoRows)
+	m.access.EXPECT().FolderPermission("u1", created).Return(model.PermissionManage, nil)
+	m.schemas.EXPECT().Validate(gomock.Any()).Return(nil)
+	m.docs.EXPECT().Create(gomock.Any()).Return(nil)
+
+	doc, isNew, err := fs.Put("u1", "alice", "/reports/q1.json", model.FSContent{Mime: "application/json", JSON: []byte(`{"a":1}`)}, nil)
+	if err != nil || !isNew || doc.Name != "q1.json" || *doc.FolderID != "f1" || doc.Owner != "u1" {
+		t.Fatalf("unexpected result %+v, %v, %v", doc, isNew, err)
+	}
+
+	existing := &model.Document{ID: "d1", Owner: "u1", Name: "q1.json", FolderID: &created.ID}
+	m.folders.EXPECT().GetByName("u1", nil, "reports").Return(created, nil)
+	m.folders.EXPECT().GetByName("u1", &created.ID, "q1.json").Return(nil, sql.ErrNoRows)
+	m.docs.EXPECT().GetByPath("u1", &created.ID, "q1.json").Return(existing, nil)
+	m.access.EXPECT().Permission("u1", existing).Return(model.PermissionManage, nil)
+	if _, _, err := fs.Put("u1", "alice", "/reports/q1.json", model.FSContent{File: true, Mime: "text/plain"}, nil); !errors.Is(err, ErrPathKind) {
+		t.Fatalf("expected ErrPathKind, got %v", err)
+	}
+
+	m.folders.EXPECT().GetByName("u1", nil, "reports").Return(created, nil)
+	m.folders.EXPECT().GetByName("u1", &created.ID, "q1.json").Return(nil, sql.ErrNoRows)
+	m.docs.EXPECT().GetByPath("u1", &created.ID, "q1.json").Return(existing, nil)
+	m.access.EXPECT().Permission("u1", existing).Return(model.PermissionManage, nil)
+	m.schemas.EXPECT().Validate(existing).Return(nil)
+	m.docs.EXPECT().Update(existing).Return(nil)
+	if _, isNew, err := fs.Put("u1", "alice", "/reports/q1.json", model.FSContent{JSON: []byte(`{"a":2}`)}, nil); err != nil || isNew || existing.ModifiedAt == nil {
+		t.Fatalf("expected overwrite with modified time, got %v, %v, %+v", isNew, err, existing)
+	}
+
+	m.folders.EXPECT().GetByName("u1", nil, "x").Return(nil, sql.ErrNoRows)
+	if _, _, err := fs.Put("u2", "alice", "/x/y.json", model.FSContent{JSON: []byte(`1`)}, nil); !errors.Is(err, ErrPathNotFound) {
+		t.Fatalf("expected ErrPathNotFound in foreign root, got %v", err)
+	}
+}
+
+func TestFSService_Put_OverwriteRescans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	fs, m := newTestFSService(t, ctrl)
+
+	existing := &model.Document{ID: "d1", Owner: "u1", Name: "notes.txt", File: true, Mime: "text/plain", ScanStatus: model.ScanClean}
+	m.folders.EXPECT().GetByName("u1", nil, "notes.txt").Return(nil, sql.ErrNoRows)
+	m.docs.EXPECT().GetByPath("u1", nil, "notes.txt").Return(existing, nil)
+	m.access.EXPECT().Permission("u1", existing).Return(model.PermissionManage, nil)
+	// Пометка «на проверке» сохраняется вместе с новым содержимым
+	m.docs.EXPECT().UpdateFile(gomock.Any()).DoAndReturn(func(doc *model.Document) (string, error) {
+		if doc.ScanStatus != model.ScanPending || doc.ScanQueuedAt == nil || *doc.Size != 5 || doc.StorageKey() == "d1" || doc.ModifiedAt == nil {
+			t.Fatalf("unexpected document %+v", doc)
+		}
+		return "d1", nil
+	})
+	if _, err := m.blobs.Put("d1", strings.NewReader("old")); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, isNew, err := fs.Put("u1", "alice", "/notes.txt", model.FSContent{File: true}, strings.NewReader("hello"))
+	if err != nil || isNew || doc.ScanStatus != model.ScanClean {
+		t.Fatalf("unexpected result %+v, %v, %v", doc, isNew, err)
+	}
+	// Новое содержимое лежит под новым ключом, прежний файл удалён после сохранения
+	if _, err := m.blobs.Open("d1"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected previous blob to be removed, got %v", err)
+	}
+	f, err := m.blobs.Open(doc.StorageKey())
+	if err != nil {
+		t.Fatalf("expected new blob, got %v", err)
+	}
+	f.Close()
+}
+
+func TestFSService_Put_OverwriteKeepsBlobOnUpdateError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	fs, m := newTestFSService(t, ctrl)
+
+	existing := &model.Document{ID: "d1", Owner: "u1", Name: "notes.txt", File: true, Mime: "text/plain", ScanStatus: model.ScanClean}
+	m.folders.EXPECT().GetByName("u1", nil, "notes.txt").Return(nil, sql.ErrNoRows)
+	m.docs.EXPECT().GetByPath("u1", nil, "notes.txt").Return(existing, nil)
+	m.access.EXPECT().Permission("u1", existing).Return(model.PermissionManage, nil)
+	var written string
+	m.docs.EXPECT().UpdateFile(gomock.Any()).DoAndReturn(func(doc *model.Document) (string, error) {
+		written = doc.StorageKey()
+		return "", errors.New("db down")
+	})
+	if _, err := m.blobs.Put("d1", strings.NewReader("old")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := fs.Put("u1", "alice", "/notes.txt", model.FSContent{File: true}, strings.NewReader("hello")); err == nil {
+		t.Fatal("expected update error")
+	}
+	f, err := m.blobs.Open("d1")
+	if err != nil {
+		t.Fatalf("expected previous blob to stay, got %v", err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != "old" {
+		t.Fatalf("previous blob changed: %q", data)
+	}
+	if _, err := m.blobs.Open(written); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected new blob to be removed, got %v", err)
+	}
+}
+
+func TestFSService_Move(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	fs, m := newTestFSService(t, ctrl)
+
+	a := &model.Folder{ID: "fa", Owner: "u1", Name: "a", Path: []string{"fa"}}
+	b := &model.Folder{ID: "fb", Owner: "u1", Name: "b", Path: []string{"fb"}}
+	doc := &model.Document{ID: "d1", Owner: "u1", Name: "x.json", FolderID: &a.ID}
+	m.folders.EXPECT().GetByName("u1", nil, "a").Return(a, nil).AnyTimes()
+	m.folders.EXPECT().GetByName("u1", nil, "b").Return(b, nil).AnyTimes()
+	m.folders.EXPECT().GetByName("u1", &a.ID, "x.json").Return(nil, sql.ErrNoRows).AnyTimes()
+	m.docs.EXPECT().GetByPath("u1", &a.ID, "x.json").Return(doc, nil).AnyTimes()
+	m.folders.EXPECT().GetByName("u1", &b.ID, gomock.Any()).Return(nil, sql.ErrNoRows).AnyTimes()
+	m.docs.EXPECT().GetByPath("u1", &b.ID, "taken.json").Return(&model.Document{ID: "d2"}, nil)
+	m.docs.EXPECT().GetByPath("u1", &b.ID, "y.json").Return(nil, sql.ErrNoRows)
+	m.access.EXPECT().Permission("u2", doc).Return(model.PermissionWrite, nil).AnyTimes()
+
+	if _, err := fs.Move("u2", "alice", "/a/x.json", "/b/taken.json"); !errors.Is(err, ErrPathExists) {
+		t.Fatalf("expected ErrPathExists, got %v", err)
+	}
+	if _, err := fs.Move("u2", "alice", "/a/x.json", "/b/y.json"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for move with write, got %v", err)
+	}
+
+	m.docs.EXPECT().GetByPath("u1", &a.ID, "z.json").Return(nil, sql.ErrNoRows)
+	m.folders.EXPECT().GetByName("u1", &a.ID, "z.json").Return(nil, sql.ErrNoRows)
+	m.tx.EXPECT().InTx(gomock.Any()).DoAndReturn(func(fn func(tx *sql.Tx) error) error { return fn(nil) })
+	m.docs.EXPECT().MoveTx(gomock.Any(), "d1", &a.ID, "z.json").Return(nil)
+	node, err := fs.Move("u2", "alice", "/a/x.json", "/a/z.json")
+	if err != nil || node.Path != "/a/z.json" {
+		t.Fatalf("expected rename, got %+v, %v", node, err)
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrGroupNotFound    = errors.New("group not found")
+	ErrGroupExists      = errors.New("group already exists")
+	ErrInvalidGroupName = errors.New("group name must be 2-64 latin letters, digits, '.', '_' or '-'")
+	ErrInvalidGroupRole = errors.New("role must be member or owner")
+	ErrNotGroupOwner    = errors.New("group owner role required")
+	ErrLastGroupOwner   = errors.New("group must keep at least one owner")
+	ErrUserNotFound     = errors.New("user not found")
+)
+
+var groupNameRe = regexp.MustCompile(`^[a-zA-Z0-9._-]{2,64}$`)
+
+// GroupService управляет группами. Менять состав и удалять группу могут только её владельцы,
+// видеть состав — любые участники; для остальных группа как будто не существует.
+type GroupService struct {
+	groupRepo repository.GroupRepositoryInterface
+	userRepo  repository.UserRepositoryInterface
+}
+
+func NewGroupService(groupRepo repository.GroupRepositoryInterface, userRepo repository.UserRepositoryInterface) *GroupService {
+	return &GroupService{groupRepo: groupRepo, userRepo: userRepo}
+}
+
+func (s *GroupService) Create(ownerID, name string) (*model.Group, error) {
+	if !groupNameRe.MatchString(name) {
+		return nil, ErrInvalidGroupName
+	}
+	if _, err := s.groupRepo.GetByName(name); err == nil {
+		return nil, ErrGroupExists
+	}
+	group := &model.Group{ID: uuid.New().String(), Name: name, CreatedBy: &ownerID, CreatedAt: time.Now()}
+	if err := s.groupRepo.Create(group, ownerID); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (s *GroupService) ListByUser(userID string) ([]model.Group, error) {
+	return s.groupRepo.ListByUser(userID)
+}
+
+// Get возвращает группу и её состав, если actorID в ней состоит
+func (s *GroupService) Get(actorID, groupID string) (*model.Group, []model.GroupMember, error) {
+	group, members, err := s.load(groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if memberRole(members, actorID) == "" {
+		return nil, nil, ErrGroupNotFound
+	}
+	return group, members, nil
+}
+
+func (s *GroupService) Delete(actorID, groupID string) error {
+	_, members, err := s.load(groupID)
+	if err != nil {
+		return err
+	}
+	if err := requireGroupOwner(members, actorID); err != nil {
+		return err
+	}
+	return s.groupRepo.Delete(groupID)
+}
+
+// SetMember добавляет пользователя в группу или меняет его роль
+func (s *GroupService) SetMember(actorID, groupID, login, role string) error {
+	if role == "" {
+		role = model.GroupRoleMember
+	}
+	if role != model.GroupRoleMember && role != model.GroupRoleOwner {
+		return ErrInvalidGroupRole
+	}
+	_, members, err := s.load(groupID)
+	if err != nil {
+		return err
+	}
+	if err := requireGroupOwner(members, actorID); err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByLogin(login)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if role != model.GroupRoleOwner && isLastOwner(members, user.ID) {
+		return ErrLastGroupOwner
+	}
+	return s.groupRepo.SetMember(groupID, user.ID, role)
+}
+
+// RemoveMember исключает пользователя; участник может выйти из группы сам
+func (s *GroupService) RemoveMember(actorID, groupID, login string) error {
+	_, members, err := s.load(groupID)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByLogin(login)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.ID != actorID {
+		if err := requireGroupOwner(members, actorID); err != nil {
+			return err
+		}
+	}
+	if isLastOwner(members, user.ID) {
+		return ErrLastGroupOwner
+	}
+	return s.groupRepo.RemoveMember(groupID, user.ID)
+}
+
+func (s *GroupService) load(groupID string) (*model.Group, []model.GroupMember, error) {
+	group, err := s.groupRepo.GetByID(groupID)
+	if err != nil {
+		return nil, nil, ErrGroupNotFound
+	}
+	members, err := s.groupRepo.ListMembers(groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return group, members, nil
+}
+
+func memberRole(members []model.GroupMember, userID string) string {
+	for _, m := range members {
+		if m.UserID == userID {
+			return m.Role
+		}
+	}
+	return ""
+}
+
+func requireGroupOwner(members []model.GroupMember, actorID string) error {
+	switch memberRole(members, actorID) {
+	case model.GroupRoleOwner:
+		return nil
+	case "":
+		return ErrGroupNotFound
+	default:
+		return ErrNotGroupOwner
+	}
+}
+
+func isLastOwner(members []model.GroupMember, userID string) bool {
+	if memberRole(members, userID) != model.GroupRoleOwner {
+		return false
+	}
+	owners := 0
+	for _, m := range members {
+		if m.Role == model.GroupRoleOwner {
+			owners++
+		}
+	}
+	return owners == 1
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestGroupService_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	groupRepo := mocksgen.NewMockGroupRepositoryInterface(ctrl)
+	groups := NewGroupService(groupRepo, mocksgen.NewMockUserRepositoryInterface(ctrl))
+
+	groupRepo.EXPECT().GetByName("accounting").Return(nil, errors.New("not found"))
+	groupRepo.EXPECT().Create(gomock.Any(), "u1").Return(nil)
+
+	group, err := groups.Create("u1", "accounting")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if group.ID == "" || group.Name != "accounting" {
+		t.Fatalf("unexpected group: %+v", group)
+	}
+
+	if _, err := groups.Create("u1", "a b"); !errors.Is(err, ErrInvalidGroupName) {
+		t.Fatalf("expected ErrInvalidGroupName, got %v", err)
+	}
+
+	groupRepo.EXPECT().GetByName("accounting").Return(group, nil)
+	if _, err := groups.Create("u1", "accounting"); !errors.Is(err, ErrGroupExists) {
+		t.Fatalf("expected ErrGroupExists, got %v", err)
+	}
+}
+
+func TestGroupService_SetMember_RequiresOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	groupRepo := mocksgen.NewMockGroupRepositoryInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	groups := NewGroupService(groupRepo, userRepo)
+
+	members := []model.GroupMember{
+		{UserID: "u1", Role: model.GroupRoleOwner},
+		{UserID: "u2", Role: model.GroupRoleMember},
+	}
+	groupRepo.EXPECT().GetByID("g1").Return(&model.Group{ID: "g1"}, nil).AnyTimes()
+	groupRepo.EXPECT().ListMembers("g1").Return(members, nil).AnyTimes()
+
+	if err := groups.SetMember("u2", "g1", "newuser", ""); !errors.Is(err, ErrNotGroupOwner) {
+		t.Fatalf("expected ErrNotGroupOwner, got %v", err)
+	}
+	if err := groups.SetMember("u3", "g1", "newuser", ""); !errors.Is(err, ErrGroupNotFound) {
+		t.Fatalf("expected ErrGroupNotFound for outsider, got %v", err)
+	}
+
+	userRepo.EXPECT().GetByLogin("newuser").Return(&model.User{ID: "u3"}, nil)
+	groupRepo.EXPECT().SetMember("g1", "u3", model.GroupRoleMember).Return(nil)
+	if err := groups.SetMember("u1", "g1", "newuser", ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestGroupService_RemoveMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	groupRepo := mocksgen.NewMockGroupRepositoryInterface(ctrl)
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	groups := NewGroupService(groupRepo, userRepo)
+
+	members := []model.GroupMember{
+		{UserID: "u1", Role: model.GroupRoleOwner},
+		{UserID: "u2", Role: model.GroupRoleMember},
+	}
+	groupRepo.EXPECT().GetByID("g1").Return(&model.Group{ID: "g1"}, nil).AnyTimes()
+	groupRepo.EXPECT().ListMembers("g1").Return(members, nil).AnyTimes()
+	userRepo.EXPECT().GetByLogin("owner").Return(&model.User{ID: "u1"}, nil).AnyTimes()
+	userRepo.EXPECT().GetByLogin("member").Return(&model.User{ID: "u2"}, nil).AnyTimes()
+
+	// Единственный владелец не может выйти, пока не назначит другого
+	if err := groups.RemoveMember("u1", "g1", "owner"); !errors.Is(err, ErrLastGroupOwner) {
+		t.Fatalf("expected ErrLastGroupOwner, got %v", err)
+	}
+	if err := groups.RemoveMember("u2", "g1", "owner"); !errors.Is(err, ErrNotGroupOwner) {
+		t.Fatalf("expected ErrNotGroupOwner, got %v", err)
+	}
+
+	groupRepo.EXPECT().RemoveMember("g1", "u2").Return(nil)
+	if err := groups.RemoveMember("u2", "g1", "member"); err != nil {
+		t.Fatalf("expected member to leave, got %v", err)
+	}
+}
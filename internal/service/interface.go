@@ -1,6 +1,11 @@
 package service
 
-import "astra-api/internal/model"
+import (
+	"astra-api/internal/compress"
+	"astra-api/internal/model"
+	"io"
+	"time"
+)
 
 // AuthServiceInterface описывает контракт сервиса аутентификации
 type AuthServiceInterface interface {
@@ -11,15 +16,152 @@ type AuthServiceInterface interface {
 // DocsServiceInterface описывает контракт сервиса документов
 type DocsServiceInterface interface {
 	Create(doc *model.Document) error
-	List(owner string, limit int) ([]model.Document, error)
+	List(owner string, q model.ListQuery) ([]model.Document, error)
+	ListVisible(owner string, principals []string, q model.ListQuery) ([]model.Document, error)
+	ListAccessible(userID string, principals []string, includeOwned bool, q model.ListQuery) ([]model.Document, error)
+	Search(userID string, principals []string, query string, limit int) ([]model.SearchResult, error)
+	CountTags(userID string, principals []string, owned, shared bool) ([]model.TagCount, error)
 	GetByID(id string) (*model.Document, error)
+	Update(doc *model.Document) error
 	Delete(id string) error
 }
 
+// TrashServiceInterface описывает контракт корзины документов
+type TrashServiceInterface interface {
+	List(userID string, limit int) ([]model.TrashEntry, error)
+	Restore(actorID, id string) (*model.Document, error)
+	Purge(actorID, id string) (*model.Document, error)
+	PurgeExpired(now time.Time) (int, error)
+}
+
+// ReaperServiceInterface описывает контракт очистки документов с истёкшим сроком жизни
+type ReaperServiceInterface interface {
+	Reap(now time.Time) (model.ReapResult, error)
+	Stats() model.ReaperStats
+}
+
+// QuotaServiceInterface описывает контракт квот: использование места и проверку лимитов
+type QuotaServiceInterface interface {
+	Usage(userID string) (*model.UsageReport, error)
+	SetOverride(userID string, o *model.QuotaOverride) (*model.UsageReport, error)
+	SaveDefaults() error
+	Check(ownerID string, newDocs int, size, replaced int64) error
+	Reader(ownerID string, newDocs int, replaced int64, r io.Reader) (io.Reader, error)
+	BackfillSizes(batch int) (int, error)
+}
+
+// MimeServiceInterface описывает определение и проверку типа загружаемого файла
+type MimeServiceInterface interface {
+	Inspect(doc *model.Document, body io.Reader) (io.Reader, error)
+}
+
+// CompressionServiceInterface описывает сжатие загружаемых файлов для хранения
+type CompressionServiceInterface interface {
+	Pack(doc *model.Document, body io.Reader) (*compress.Packed, error)
+}
+
+// ScanServiceInterface описывает проверку загруженных файлов антивирусом
+type ScanServiceInterface interface {
+	Queue(doc *model.Document)
+	Scan(doc *model.Document) error
+	ScanPending(before time.Time, batch int) (int, error)
+}
+
 // SessionServiceInterface описывает контракт сервиса сессий
 type SessionServiceInterface interface {
 	Create(userID, login string) string
+	CreateImpersonation(actor model.Session, target *model.User, ttl time.Duration) string
 	Get(token string) (*model.Session, bool)
 	Validate(token string) (model.Session, bool)
 	Delete(token string) bool
+	DeleteByUser(userID string)
+}
+
+// TokenServiceInterface описывает сессии на подписанных токенах с парой access/refresh
+type TokenServiceInterface interface {
+	SessionServiceInterface
+	CreatePair(userID, login string) (*model.TokenPair, error)
+	Refresh(refreshToken string) (*model.TokenPair, error)
+}
+
+// AccountServiceInterface описывает самообслуживание аккаунта: выгрузку данных и удаление
+type AccountServiceInterface interface {
+	RequestDeletion(userID, password string) (time.Time, error)
+	PurgeDue(now time.Time) (int, error)
+	Purge(userID string, now time.Time) (bool, error)
+	Export(userID string, w io.Writer) error
+}
+
+// GroupServiceInterface описывает управление группами пользователей
+type GroupServiceInterface interface {
+	Create(ownerID, name string) (*model.Group, error)
+	ListByUser(userID string) ([]model.Group, error)
+	Get(actorID, groupID string) (*model.Group, []model.GroupMember, error)
+	Delete(actorID, groupID string) error
+	SetMember(actorID, groupID, login, role string) error
+	RemoveMember(actorID, groupID, login string) error
+}
+
+// AccessServiceInterface описывает проверку доступа к документам и управление грантами
+type AccessServiceInterface interface {
+	Principals(userID string) ([]string, error)
+	Permission(userID string, doc *model.Document) (model.Permission, error)
+	PermissionExpiry(userID string, doc *model.Document, perm model.Permission) (*time.Time, error)
+	Explain(actorID string, doc *model.Document) ([]model.AccessEntry, error)
+	ListGrants(actorID string, doc *model.Document) ([]model.Grant, error)
+	SetGrant(actorID string, doc *model.Document, req model.GrantRequest) (*model.Grant, error)
+	RevokeGrant(actorID string, doc *model.Document, principal string) error
+	ResolveGrants(principals []string) ([]model.Grant, error)
+	FolderPermission(userID string, folder *model.Folder) (model.Permission, error)
+	ListFolderGrants(actorID string, folder *model.Folder) ([]model.Grant, error)
+	SetFolderGrant(actorID string, folder *model.Folder, req model.GrantRequest) (*model.Grant, error)
+	RevokeFolderGrant(actorID string, folder *model.Folder, principal string) error
+}
+
+// LinkServiceInterface описывает ссылки на документы для доступа без учётной записи
+type LinkServiceInterface interface {
+	Create(actorID string, doc *model.Document, req model.CreateLinkRequest) (*model.ShareLink, string, error)
+	List(actorID string, doc *model.Document) ([]model.ShareLink, error)
+	Revoke(actorID string, doc *model.Document, linkID string) error
+	Resolve(token, password string) (*model.ShareLink, *model.Document, error)
+	CountDownload(link *model.ShareLink) error
+}
+
+// UploadServiceInterface описывает загрузку файлов по подписанной ссылке
+type UploadServiceInterface interface {
+	Reserve(doc *model.Document, size *int64) (*model.UploadReservation, string, error)
+	Complete(token string, body io.Reader, contentType string) (*model.Document, error)
+	PurgeExpired(now time.Time) (int64, error)
+}
+
+// FolderServiceInterface описывает дерево папок, их содержимое и перенос документов
+type FolderServiceInterface interface {
+	Create(actorID string, req model.CreateFolderRequest) (*model.Folder, error)
+	List(userID string) ([]model.Folder, error)
+	Get(actorID, id string) (*model.FolderContents, error)
+	Update(actorID, id string, req model.UpdateFolderRequest) (*model.Folder, error)
+	Delete(actorID, id string) error
+	Place(actorID string, doc *model.Document, folderID string) error
+	MoveDocuments(actorID string, ids []string, folderID string) error
+	Lookup(actorID, id string) (*model.Folder, error)
+}
+
+// FSServiceInterface описывает адресацию документов путями /api/fs/{login}/{path}
+type FSServiceInterface interface {
+	Get(actorID, login, path string) (*model.FSNode, *model.FSListing, error)
+	Put(actorID, login, path string, content model.FSContent, body io.Reader) (*model.Document, bool, error)
+	Delete(actorID, login, path string) (*model.FSNode, error)
+	Move(actorID, login, from, to string) (*model.FSNode, error)
+}
+
+// SchemaServiceInterface описывает именованные JSON Schema и проверку документов по ним
+type SchemaServiceInterface interface {
+	Put(ownerID, name string, raw []byte) (*model.JSONSchema, bool, error)
+	List(ownerID string) ([]model.JSONSchema, error)
+	Get(ownerID, name string) (*model.JSONSchema, error)
+	Delete(ownerID, name string) error
+	Attach(doc *model.Document, name string) error
+	AttachFolder(folder *model.Folder, name string) error
+	AttachTag(ownerID, tag, name string) error
+	Validate(doc *model.Document) error
 }
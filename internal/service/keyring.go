@@ -0,0 +1,139 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrMalformedToken = errors.New("malformed token")
+	ErrUnknownKey     = errors.New("unknown signing key")
+	ErrBadSignature   = errors.New("invalid token signature")
+)
+
+// SigningKey — ключ Ed25519 с идентификатором, который попадает в заголовок kid
+type SigningKey struct {
+	ID         string
+	PrivateKey ed25519.PrivateKey
+}
+
+// KeyRing подписывает токены активным ключом и проверяет их любым из известных.
+// Ротация: новый ключ ставится первым, старые остаются в списке до истечения выданных ими токенов.
+type KeyRing struct {
+	active SigningKey
+	public map[string]ed25519.PublicKey
+}
+
+func NewKeyRing(keys ...SigningKey) (*KeyRing, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one signing key is required")
+	}
+	ring := &KeyRing{active: keys[0], public: make(map[string]ed25519.PublicKey, len(keys))}
+	for _, k := range keys {
+		if k.ID == "" {
+			return nil, errors.New("signing key id must not be empty")
+		}
+		if _, dup := ring.public[k.ID]; dup {
+			return nil, fmt.Errorf("duplicate signing key id %q", k.ID)
+		}
+		ring.public[k.ID] = k.PrivateKey.Public().(ed25519.PublicKey)
+	}
+	return ring, nil
+}
+
+// GenerateSigningKey создаёт случайный ключ, пригодный для разработки и тестов
+func GenerateSigningKey(id string) (SigningKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return SigningKey{}, err
+	}
+	return SigningKey{ID: id, PrivateKey: priv}, nil
+}
+
+// ParseSigningKeys разбирает строку вида "kid1:<base64 seed>,kid2:<base64 seed>".
+// Первый ключ в списке становится активным.
+func ParseSigningKeys(spec string) ([]SigningKey, error) {
+	var keys []SigningKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("signing key %q must look like kid:seed", part)
+		}
+		seed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %q: %w", id, err)
+		}
+		if len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("signing key %q: seed must be %d bytes", id, ed25519.SeedSize)
+		}
+		keys = append(keys, SigningKey{ID: id, PrivateKey: ed25519.NewKeyFromSeed(seed)})
+	}
+	return keys, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid"`
+}
+
+// Sign сериализует claims в компактный JWS (alg EdDSA)
+func (k *KeyRing) Sign(claims interface{}) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: "EdDSA", Typ: "JWT", Kid: k.active.ID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	sig := ed25519.Sign(k.active.PrivateKey, []byte(signingInput))
+	return signingInput + "." + b64.EncodeToString(sig), nil
+}
+
+// Verify проверяет подпись по kid из заголовка и раскладывает payload в claims
+func (k *KeyRing) Verify(token string, claims interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrMalformedToken
+	}
+	rawHeader, err := b64.DecodeString(parts[0])
+	if err != nil {
+		return ErrMalformedToken
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(rawHeader, &header); err != nil || header.Alg != "EdDSA" {
+		return ErrMalformedToken
+	}
+	pub, ok := k.public[header.Kid]
+	if !ok {
+		return ErrUnknownKey
+	}
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return ErrMalformedToken
+	}
+	if !ed25519.Verify(pub, []byte(parts[0]+"."+parts[1]), sig) {
+		return ErrBadSignature
+	}
+	payload, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return ErrMalformedToken
+	}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return ErrMalformedToken
+	}
+	return nil
+}
+
+var b64 = base64.RawURLEncoding
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"astra-api/internal/storage"
+	"errors"
+	"io"
+	"log"
+)
+
+// MigrateLegacyBlobs переносит файлы, которые до хранения по ID лежали в хранилище под
+// именем документа, под ключ-ID и записывает их размер. Если несколько документов
+// назывались одинаково, на диске осталась последняя загрузка: её получает самый новый
+// из документов без собственного файла. Повторный запуск ничего не меняет.
+// Возвращает число перенесённых файлов.
+func MigrateLegacyBlobs(docRepo repository.DocumentRepositoryInterface, files *storage.FileStore, batch int) (int, error) {
+	moved := 0
+	var after *model.Document
+	for {
+		docs, err := docRepo.ListLegacyFiles(after, batch)
+		if err != nil {
+			return moved, err
+		}
+		for i := range docs {
+			ok, err := adoptLegacyBlob(docRepo, files, &docs[i])
+			if err != nil {
+				return moved, err
+			}
+			if ok {
+				moved++
+			}
+		}
+		if len(docs) < batch {
+			return moved, nil
+		}
+		after = &docs[len(docs)-1]
+	}
+}
+
+func adoptLegacyBlob(docRepo repository.DocumentRepositoryInterface, files *storage.FileStore, doc *model.Document) (bool, error) {
+	f, err := files.Open(doc.ID)
+	if err == nil {
+		f.Close()
+		return false, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return false, err
+	}
+	if err := files.Rename(doc.Name, doc.ID); err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			// Имя, недопустимое как ключ, не могло быть записано и прежним кодом
+			log.Printf("cannot move file %q of document %s: %v", doc.Name, doc.ID, err)
+		}
+		return false, nil
+	}
+	f, err = files.Open(doc.ID)
+	if err != nil {
+		return true, err
+	}
+	defer f.Close()
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return true, err
+	}
+	return true, docRepo.SetSize(doc.ID, size)
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/storage"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestMigrateLegacyBlobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	files, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	_, _ = files.Put("report.pdf", strings.NewReader("latest upload"))
+	_, _ = files.Put("d-new", strings.NewReader("already moved"))
+
+	now := time.Now()
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	// Два документа с одним именем: файл на диске — загрузка более нового
+	page := []model.Document{
+		{ID: "d-new", Name: "notes.txt", File: true, CreatedAt: now},
+		{ID: "d2", Name: "report.pdf", File: true, CreatedAt: now.Add(-time.Hour)},
+	}
+	docRepo.EXPECT().ListLegacyFiles(nil, 2).Return(page, nil)
+	docRepo.EXPECT().ListLegacyFiles(&page[1], 2).Return([]model.Document{
+		{ID: "d1", Name: "report.pdf", File: true, CreatedAt: now.Add(-2 * time.Hour)},
+	}, nil)
+
+	docRepo.EXPECT().SetSize("d2", int64(13)).Return(nil)
+
+	n, err := MigrateLegacyBlobs(docRepo, files, 2)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 moved file, got %d, %v", n, err)
+	}
+	f, err := files.Open("d2")
+	if err != nil {
+		t.Fatalf("expected file under document ID, got %v", err)
+	}
+	defer f.Close()
+	if data, _ := io.ReadAll(f); string(data) != "latest upload" {
+		t.Fatalf("unexpected content %q", data)
+	}
+	if _, err := files.Open("report.pdf"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected old name to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidLink     = errors.New("invalid share link")
+	ErrLinkExpired     = errors.New("share link expired")
+	ErrLinkExhausted   = errors.New("share link download limit reached")
+	ErrLinkPassword    = errors.New("share link password required")
+	ErrInvalidLinkSpec = errors.New("invalid share link request")
+)
+
+// linkClaims — содержимое токена ссылки. Подпись HMAC защищает от подбора и
+// подмены; отзыв и счётчик скачиваний хранятся в БД по ID ссылки.
+type linkClaims struct {
+	ID         string           `json:"lid"`
+	Document   string           `json:"doc"`
+	Permission model.Permission `json:"perm"`
+	ExpiresAt  int64            `json:"exp"`
+}
+
+// LinkService выпускает и проверяет ссылки на документы для внешних пользователей
+type LinkService struct {
+	linkRepo   repository.ShareLinkRepositoryInterface
+	docRepo    repository.DocumentRepositoryInterface
+	access     AccessServiceInterface
+	signer     urlSigner
+	defaultTTL time.Duration
+	// maxTTL ограничивает срок жизни ссылки; 0 — без ограничения
+	maxTTL time.Duration
+	now    func() time.Time
+}
+
+func NewLinkService(linkRepo repository.ShareLinkRepositoryInterface, docRepo repository.DocumentRepositoryInterface, access AccessServiceInterface, secret []byte, defaultTTL, maxTTL time.Duration) *LinkService {
+	return &LinkService{linkRepo: linkRepo, docRepo: docRepo, access: access, signer: newURLSigner(secret, "share-link"), defaultTTL: defaultTTL, maxTTL: maxTTL, now: time.Now}
+}
+
+// Create выпускает ссылку. Нужен уровень share, а уровень ссылки не может быть выше собственного.
+// Срок ссылки урезается до maxTTL и до срока гранта, который даёт создателю уровень share.
+func (s *LinkService) Create(actorID string, doc *model.Document, req model.CreateLinkRequest) (*model.ShareLink, string, error) {
+	if req.Permission == "" {
+		req.Permission = model.PermissionRead
+	}
+	if !req.Permission.Valid() {
+		return nil, "", fmt.Errorf("%w: unknown permission %q", ErrInvalidLinkSpec, req.Permission)
+	}
+	if req.MaxDownloads != nil && *req.MaxDownloads < 1 {
+		return nil, "", fmt.Errorf("%w: max_downloads must be positive", ErrInvalidLinkSpec)
+	}
+	now := s.now()
+	expires := now.Add(s.defaultTTL)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) {
+			return nil, "", fmt.Errorf("%w: expires_at is in the past", ErrInvalidLinkSpec)
+		}
+		expires = *req.ExpiresAt
+	}
+	level, err := s.access.Permission(actorID, doc)
+	if err != nil {
+		return nil, "", err
+	}
+	if !level.Includes(model.PermissionShare) || !level.Includes(req.Permission) {
+		return nil, "", ErrForbidden
+	}
+	if s.maxTTL > 0 && expires.After(now.Add(s.maxTTL)) {
+		expires = now.Add(s.maxTTL)
+	}
+	until, err := s.access.PermissionExpiry(actorID, doc, model.PermissionShare)
+	if err != nil {
+		return nil, "", err
+	}
+	if until != nil && expires.After(*until) {
+		expires = *until
+	}
+	link := &model.ShareLink{
+		ID:           uuid.New().String(),
+		DocumentID:   doc.ID,
+		CreatedBy:    &actorID,
+		Permission:   req.Permission,
+		MaxDownloads: req.MaxDownloads,
+		ExpiresAt:    expires,
+		CreatedAt:    now,
+	}
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, "", err
+		}
+		link.PasswordHash = string(hash)
+	}
+	token, err := s.signer.sign(linkClaims{ID: link.ID, Document: doc.ID, Permission: link.Permission, ExpiresAt: expires.Unix()})
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.linkRepo.Create(link); err != nil {
+		return nil, "", err
+	}
+	return link, token, nil
+}
+
+func (s *LinkService) List(actorID string, doc *model.Document) ([]model.ShareLink, error) {
+	if err := s.requireShare(actorID, doc); err != nil {
+		return nil, err
+	}
+	return s.linkRepo.ListByDocument(doc.ID)
+}
+
+func (s *LinkService) Revoke(actorID string, doc *model.Document, linkID string) error {
+	if err := s.requireShare(actorID, doc); err != nil {
+		return err
+	}
+	ok, err := s.linkRepo.Revoke(linkID, doc.ID, s.now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidLink
+	}
+	return nil
+}
+
+// Resolve проверяет подпись, срок, отзыв и пароль ссылки и возвращает её вместе с
+// документом. Ссылка действует, пока у создателя остаётся уровень share и уровень
+// самой ссылки: с отзывом или истечением его гранта ссылка перестаёт работать.
+// Скачивание не учитывается.
+func (s *LinkService) Resolve(token, password string) (*model.ShareLink, *model.Document, error) {
+	var claims linkClaims
+	if err := s.signer.verify(token, &claims); err != nil {
+		return nil, nil, ErrInvalidLink
+	}
+	now := s.now()
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, nil, ErrLinkExpired
+	}
+	link, err := s.linkRepo.GetByID(claims.ID)
+	if err != nil || link.DocumentID != claims.Document || link.RevokedAt != nil || link.CreatedBy == nil {
+		return nil, nil, ErrInvalidLink
+	}
+	if !now.Before(link.ExpiresAt) {
+		return nil, nil, ErrLinkExpired
+	}
+	if link.MaxDownloads != nil && link.Downloads >= *link.MaxDownloads {
+		return nil, nil, ErrLinkExhausted
+	}
+	doc, err := s.docRepo.GetByID(link.DocumentID)
+	if err != nil {
+		return nil, nil, ErrInvalidLink
+	}
+	level, err := s.access.Permission(*link.CreatedBy, doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !level.Includes(model.PermissionShare) || !level.Includes(link.Permission) {
+		return nil, nil, ErrInvalidLink
+	}
+	if link.Protected() {
+		if password == "" || bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) != nil {
+			return nil, nil, ErrLinkPassword
+		}
+	}
+	return link, doc, nil
+}
+
+// CountDownload учитывает скачивание; при гонке за последнее скачивание выигрывает один
+func (s *LinkService) CountDownload(link *model.ShareLink) error {
+	ok, err := s.linkRepo.CountDownload(link.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLinkExhausted
+	}
+	return nil
+}
+
+func (s *LinkService) requireShare(actorID string, doc *model.Document) error {
+	level, err := s.access.Permission(actorID, doc)
+	if err != nil {
+		return err
+	}
+	if !level.Includes(model.PermissionShare) {
+		return ErrForbidden
+	}
+	return nil
+}
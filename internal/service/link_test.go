@@ -0,0 +1,168 @@
+package service
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestLinkService_CreateAndResolve(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	linkRepo := mocksgen.NewMockShareLinkRepositoryInterface(ctrl)
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	links := NewLinkService(linkRepo, docRepo, access, []byte("secret"), time.Hour, 0)
+
+	doc := &model.Document{ID: "d1", Owner: "u1"}
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionManage, nil).Times(3)
+	access.EXPECT().PermissionExpiry("u1", doc, model.PermissionShare).Return(nil, nil)
+	docRepo.EXPECT().GetByID("d1").Return(doc, nil).Times(2)
+	var stored *model.ShareLink
+	linkRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(link *model.ShareLink) error {
+		stored = link
+		return nil
+	})
+
+	link, token, err := links.Create("u1", doc, model.CreateLinkRequest{Password: "pw"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if link.Permission != model.PermissionRead || !link.Protected() || link.PasswordHash == "pw" {
+		t.Fatalf("unexpected link: %+v", link)
+	}
+	if d := time.Until(link.ExpiresAt); d <= 0 || d > time.Hour {
+		t.Fatalf("expected default TTL, got expiry in %v", d)
+	}
+
+	linkRepo.EXPECT().GetByID(stored.ID).Return(stored, nil).Times(2)
+	if _, _, err := links.Resolve(token, ""); !errors.Is(err, ErrLinkPassword) {
+		t.Fatalf("expected ErrLinkPassword, got %v", err)
+	}
+	got, gotDoc, err := links.Resolve(token, "pw")
+	if err != nil || got.DocumentID != "d1" || gotDoc != doc {
+		t.Fatalf("expected link to resolve, got %v, %v", got, err)
+	}
+
+	if _, _, err := links.Resolve(token+"x", "pw"); !errors.Is(err, ErrInvalidLink) {
+		t.Fatalf("expected ErrInvalidLink for tampered token, got %v", err)
+	}
+	other := NewLinkService(linkRepo, docRepo, access, []byte("other"), time.Hour, 0)
+	if _, _, err := other.Resolve(token, "pw"); !errors.Is(err, ErrInvalidLink) {
+		t.Fatalf("expected ErrInvalidLink for foreign secret, got %v", err)
+	}
+}
+
+func TestLinkService_Create_Forbidden(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	links := NewLinkService(mocksgen.NewMockShareLinkRepositoryInterface(ctrl), nil, access, []byte("secret"), time.Hour, 0)
+
+	doc := &model.Document{ID: "d1", Owner: "u1"}
+	access.EXPECT().Permission("u2", doc).Return(model.PermissionWrite, nil)
+	if _, _, err := links.Create("u2", doc, model.CreateLinkRequest{}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden without share level, got %v", err)
+	}
+
+	access.EXPECT().Permission("u3", doc).Return(model.PermissionShare, nil)
+	if _, _, err := links.Create("u3", doc, model.CreateLinkRequest{Permission: model.PermissionManage}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden above own level, got %v", err)
+	}
+
+	zero := 0
+	if _, _, err := links.Create("u3", doc, model.CreateLinkRequest{MaxDownloads: &zero}); !errors.Is(err, ErrInvalidLinkSpec) {
+		t.Fatalf("expected ErrInvalidLinkSpec, got %v", err)
+	}
+}
+
+func TestLinkService_Resolve_Rejects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	linkRepo := mocksgen.NewMockShareLinkRepositoryInterface(ctrl)
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	links := NewLinkService(linkRepo, docRepo, access, []byte("secret"), time.Hour, 0)
+
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+	max := 2
+	creator, former := "u1", "u2"
+	doc := &model.Document{ID: "d1", Owner: "u1"}
+	docRepo.EXPECT().GetByID("d1").Return(doc, nil).AnyTimes()
+	// Грант u2 истёк или отозван: уровня share у создателя ссылки больше нет
+	access.EXPECT().Permission("u2", doc).Return(model.PermissionRead, nil)
+	cases := []struct {
+		name string
+		link model.ShareLink
+		want error
+	}{
+		{"revoked", model.ShareLink{ID: "l1", DocumentID: "d1", CreatedBy: &creator, ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}, ErrInvalidLink},
+		{"exhausted", model.ShareLink{ID: "l2", DocumentID: "d1", CreatedBy: &creator, ExpiresAt: now.Add(time.Hour), MaxDownloads: &max, Downloads: 2}, ErrLinkExhausted},
+		{"other document", model.ShareLink{ID: "l3", DocumentID: "d2", CreatedBy: &creator, ExpiresAt: now.Add(time.Hour)}, ErrInvalidLink},
+		{"creator lost share", model.ShareLink{ID: "l5", DocumentID: "d1", CreatedBy: &former, Permission: model.PermissionRead, ExpiresAt: now.Add(time.Hour)}, ErrInvalidLink},
+		{"creator deleted", model.ShareLink{ID: "l6", DocumentID: "d1", Permission: model.PermissionRead, ExpiresAt: now.Add(time.Hour)}, ErrInvalidLink},
+	}
+	for _, tc := range cases {
+		link := tc.link
+		linkRepo.EXPECT().GetByID(link.ID).Return(&link, nil)
+		token, err := links.signer.sign(linkClaims{ID: link.ID, Document: "d1", Permission: model.PermissionRead, ExpiresAt: now.Add(time.Hour).Unix()})
+		if err != nil {
+			t.Fatalf("%s: sign: %v", tc.name, err)
+		}
+		if _, _, err := links.Resolve(token, ""); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	expired, _ := links.signer.sign(linkClaims{ID: "l4", Document: "d1", ExpiresAt: now.Add(-time.Second).Unix()})
+	if _, _, err := links.Resolve(expired, ""); !errors.Is(err, ErrLinkExpired) {
+		t.Fatalf("expected ErrLinkExpired, got %v", err)
+	}
+
+	linkRepo.EXPECT().CountDownload("l2").Return(false, nil)
+	if err := links.CountDownload(&model.ShareLink{ID: "l2"}); !errors.Is(err, ErrLinkExhausted) {
+		t.Fatalf("expected ErrLinkExhausted on lost race, got %v", err)
+	}
+}
+
+func TestLinkService_Create_ClampsExpiry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	linkRepo := mocksgen.NewMockShareLinkRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	links := NewLinkService(linkRepo, nil, access, []byte("secret"), time.Hour, 24*time.Hour)
+	linkRepo.EXPECT().Create(gomock.Any()).Return(nil).Times(2)
+
+	doc := &model.Document{ID: "d1", Owner: "u1"}
+	far := time.Now().Add(90 * 24 * time.Hour)
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionManage, nil)
+	access.EXPECT().PermissionExpiry("u1", doc, model.PermissionShare).Return(nil, nil)
+	link, _, err := links.Create("u1", doc, model.CreateLinkRequest{ExpiresAt: &far})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if d := time.Until(link.ExpiresAt); d > 24*time.Hour || d < 23*time.Hour {
+		t.Fatalf("expected expiry clamped to max TTL, got %v", d)
+	}
+
+	// Ссылка не переживает грант, который дал создателю уровень share
+	grantEnds := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	access.EXPECT().Permission("u2", doc).Return(model.PermissionShare, nil)
+	access.EXPECT().PermissionExpiry("u2", doc, model.PermissionShare).Return(&grantEnds, nil)
+	link, _, err = links.Create("u2", doc, model.CreateLinkRequest{ExpiresAt: &far})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !link.ExpiresAt.Equal(grantEnds) {
+		t.Fatalf("expected expiry of the grant %v, got %v", grantEnds, link.ExpiresAt)
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/sniff"
+	"errors"
+	"io"
+)
+
+var (
+	ErrMimeMismatch   = errors.New("file content does not match the declared type")
+	ErrMimeNotAllowed = errors.New("file type is not allowed")
+)
+
+// Режимы реакции на расхождение заявленного типа с содержимым
+const (
+	MimeMismatchReject = "reject"
+	MimeMismatchFlag   = "flag"
+)
+
+// MimeService определяет тип загружаемого файла по содержимому и проверяет его
+// по спискам разрешённых и запрещённых типов
+type MimeService struct {
+	allow    sniff.List
+	deny     sniff.List
+	mismatch string
+}
+
+// NewMimeService создаёт сервис; пустой allow разрешает всё, что не в deny.
+// mismatch — MimeMismatchReject или MimeMismatchFlag.
+func NewMimeService(allow, deny sniff.List, mismatch string) *MimeService {
+	return &MimeService{allow: allow, deny: deny, mismatch: mismatch}
+}
+
+// Inspect читает начало body и определяет тип файла. Пустой Mime документа
+// заполняется найденным типом. Если заявленный тип не совпадает с содержимым,
+// загрузка отклоняется ErrMimeMismatch, а в режиме flag документ получает найденный
+// тип и сохраняет заявленный в DeclaredMime. Возвращает читатель всего body.
+func (s *MimeService) Inspect(doc *model.Document, body io.Reader) (io.Reader, error) {
+	head, r, err := sniff.Peek(body)
+	if err != nil {
+		return nil, err
+	}
+	detected := sniff.Detect(head)
+	declared := sniff.Normalize(doc.Mime)
+	doc.DeclaredMime = nil
+	switch {
+	case declared == "" || declared == sniff.Unknown:
+		doc.Mime = detected
+	case !sniff.Compatible(declared, detected):
+		if s.mismatch != MimeMismatchFlag {
+			return nil, ErrMimeMismatch
+		}
+		original := doc.Mime
+		doc.DeclaredMime = &original
+		doc.Mime = detected
+	}
+	// Запрет проверяется и по найденному типу: совместимый заявленный тип
+	// не должен скрывать запрещённое содержимое
+	if s.deny.Match(doc.Mime) || s.deny.Match(detected) {
+		return nil, ErrMimeNotAllowed
+	}
+	if len(s.allow) > 0 && !s.allow.Match(doc.Mime) {
+		return nil, ErrMimeNotAllowed
+	}
+	return r, nil
+}
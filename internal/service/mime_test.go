@@ -0,0 +1,68 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/sniff"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+const pngHeader = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+func TestMimeService_Inspect(t *testing.T) {
+	mime := NewMimeService(nil, nil, MimeMismatchReject)
+
+	doc := &model.Document{}
+	r, err := mime.Inspect(doc, strings.NewReader(pngHeader))
+	if err != nil || doc.Mime != "image/png" {
+		t.Fatalf("expected detected image/png, got %q, %v", doc.Mime, err)
+	}
+	if body, _ := io.ReadAll(r); string(body) != pngHeader {
+		t.Fatalf("expected the whole body, got %q", body)
+	}
+
+	doc = &model.Document{Mime: "text/csv"}
+	if _, err := mime.Inspect(doc, strings.NewReader("a,b\n1,2\n")); err != nil || doc.Mime != "text/csv" {
+		t.Fatalf("expected declared text/csv to be kept, got %q, %v", doc.Mime, err)
+	}
+
+	doc = &model.Document{Mime: "image/png"}
+	if _, err := mime.Inspect(doc, strings.NewReader("<html><script>alert(1)</script>")); !errors.Is(err, ErrMimeMismatch) {
+		t.Fatalf("expected ErrMimeMismatch, got %v", err)
+	}
+}
+
+func TestMimeService_Inspect_Flag(t *testing.T) {
+	mime := NewMimeService(nil, nil, MimeMismatchFlag)
+	doc := &model.Document{Mime: "image/png"}
+	if _, err := mime.Inspect(doc, strings.NewReader("<html><body>hi")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc.Mime != "text/html" || doc.DeclaredMime == nil || *doc.DeclaredMime != "image/png" {
+		t.Fatalf("expected flagged mismatch, got %q declared %v", doc.Mime, doc.DeclaredMime)
+	}
+}
+
+func TestMimeService_Inspect_Lists(t *testing.T) {
+	mime := NewMimeService(sniff.ParseList("image/*,text/plain,application/xhtml+xml"), sniff.ParseList("image/svg+xml,text/html"), MimeMismatchReject)
+	cases := []struct {
+		name     string
+		declared string
+		body     string
+		want     error
+	}{
+		{"allowed", "", pngHeader, nil},
+		{"not in allow list", "application/pdf", "%PDF-1.7\n", ErrMimeNotAllowed},
+		{"denied", "", `<svg xmlns="http://www.w3.org/2000/svg"/>`, ErrMimeNotAllowed},
+		// Заявленный XHTML совместим с HTML, но запрет смотрит и на содержимое
+		{"denied content", "application/xhtml+xml", "<html><body>hi", ErrMimeNotAllowed},
+	}
+	for _, c := range cases {
+		_, err := mime.Inspect(&model.Document{Mime: c.declared}, strings.NewReader(c.body))
+		if !errors.Is(err, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, err)
+		}
+	}
+}
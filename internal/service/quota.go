@@ -0,0 +1,210 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"astra-api/internal/storage"
+	"database/sql"
+	"errors"
+	"io"
+	"time"
+)
+
+// Ошибки квоты те же, что у репозитория: запись, прошедшую проверку сервиса, может
+// отклонить триггер БД
+var (
+	ErrFileTooLarge  = repository.ErrFileTooLarge
+	ErrStorageQuota  = repository.ErrStorageQuota
+	ErrDocumentQuota = repository.ErrDocumentQuota
+)
+
+// QuotaService проверяет лимиты места, числа документов и размера файла. Использование
+// считает и лимиты окончательно проверяет БД в транзакции записи документа (триггер
+// documents_track_usage): одновременные загрузки одного владельца вместе лимит не превысят.
+// Check и Reader сравнивают с лимитами снимок использования до записи, чтобы отказать
+// раньше, не принимая файл целиком.
+type QuotaService struct {
+	quotaRepo repository.QuotaRepositoryInterface
+	docRepo   repository.DocumentRepositoryInterface
+	blobs     storage.BlobStore
+	defaults  model.QuotaLimits
+	now       func() time.Time
+}
+
+func NewQuotaService(quotaRepo repository.QuotaRepositoryInterface, docRepo repository.DocumentRepositoryInterface, blobs storage.BlobStore, defaults model.QuotaLimits) *QuotaService {
+	return &QuotaService{quotaRepo: quotaRepo, docRepo: docRepo, blobs: blobs, defaults: defaults, now: time.Now}
+}
+
+// Usage возвращает использование, действующие и личные лимиты пользователя
+func (s *QuotaService) Usage(userID string) (*model.UsageReport, error) {
+	usage, err := s.quotaRepo.GetUsage(userID)
+	if err != nil {
+		return nil, err
+	}
+	override, err := s.override(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.UsageReport{Usage: *usage, Limits: override.Apply(s.defaults), Override: override}, nil
+}
+
+// SaveDefaults записывает лимиты по умолчанию из конфигурации в БД, где по ним
+// проверяется запись документов
+func (s *QuotaService) SaveDefaults() error {
+	return s.quotaRepo.SetDefaults(s.defaults)
+}
+
+// SetOverride задаёт личные лимиты пользователя; без единого заданного лимита
+// пользователь возвращается к лимитам по умолчанию
+func (s *QuotaService) SetOverride(userID string, o *model.QuotaOverride) (*model.UsageReport, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	o.UserID = userID
+	o.UpdatedAt = s.now()
+	var err error
+	if o.Empty() {
+		err = s.quotaRepo.DeleteOverride(userID)
+	} else {
+		err = s.quotaRepo.SetOverride(o)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.Usage(userID)
+}
+
+// Check проверяет, что владелец может сохранить содержимое размером size, добавив
+// newDocs документов. replaced — размер заменяемого содержимого, оно освободит место.
+func (s *QuotaService) Check(ownerID string, newDocs int, size, replaced int64) error {
+	limits, usage, err := s.state(ownerID)
+	if err != nil {
+		return err
+	}
+	if limits.MaxFileSize > 0 && size > limits.MaxFileSize {
+		return ErrFileTooLarge
+	}
+	if err := checkDocuments(limits, usage, newDocs); err != nil {
+		return err
+	}
+	// Уменьшать содержимое можно и сверх лимита
+	if limits.MaxBytes > 0 && size > replaced && usage.Bytes+size-replaced > limits.MaxBytes {
+		return ErrStorageQuota
+	}
+	return nil
+}
+
+// Reader проверяет лимит документов и оборачивает r так, что чтение сверх
+// размера файла или свободного места владельца завершается ErrFileTooLarge или
+// ErrStorageQuota. Загрузка прерывается на первом лишнем байте, а не после записи файла.
+func (s *QuotaService) Reader(ownerID string, newDocs int, replaced int64, r io.Reader) (io.Reader, error) {
+	limits, usage, err := s.state(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDocuments(limits, usage, newDocs); err != nil {
+		return nil, err
+	}
+	qr := &quotaReader{r: r, n: -1}
+	if limits.MaxFileSize > 0 {
+		qr.n, qr.err = limits.MaxFileSize, ErrFileTooLarge
+	}
+	if limits.MaxBytes > 0 {
+		free := limits.MaxBytes - usage.Bytes + replaced
+		if free < 0 {
+			free = 0
+		}
+		if qr.n < 0 || free < qr.n {
+			qr.n, qr.err = free, ErrStorageQuota
+		}
+	}
+	if qr.n < 0 {
+		return r, nil
+	}
+	return qr, nil
+}
+
+// BackfillSizes считает размеры файлов, загруженных до появления квот, пачками по batch.
+// Файл, которого нет в хранилище, считается пустым.
+func (s *QuotaService) BackfillSizes(batch int) (int, error) {
+	done := 0
+	for {
+		docs, err := s.docRepo.ListUnsized(batch)
+		if err != nil {
+			return done, err
+		}
+		for _, doc := range docs {
+			size, err := s.blobSize(doc.ID)
+			if err != nil {
+				return done, err
+			}
+			if err := s.docRepo.SetSize(doc.ID, size); err != nil {
+				return done, err
+			}
+			done++
+		}
+		if len(docs) < batch {
+			return done, nil
+		}
+	}
+}
+
+func (s *QuotaService) blobSize(id string) (int64, error) {
+	f, err := s.blobs.Open(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return f.Seek(0, io.SeekEnd)
+}
+
+func (s *QuotaService) state(userID string) (model.QuotaLimits, *model.Usage, error) {
+	override, err := s.override(userID)
+	if err != nil {
+		return model.QuotaLimits{}, nil, err
+	}
+	usage, err := s.quotaRepo.GetUsage(userID)
+	if err != nil {
+		return model.QuotaLimits{}, nil, err
+	}
+	return override.Apply(s.defaults), usage, nil
+}
+
+func (s *QuotaService) override(userID string) (*model.QuotaOverride, error) {
+	o, err := s.quotaRepo.GetOverride(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return o, err
+}
+
+func checkDocuments(limits model.QuotaLimits, usage *model.Usage, newDocs int) error {
+	if newDocs > 0 && limits.MaxDocuments > 0 && usage.Documents+int64(newDocs) > limits.MaxDocuments {
+		return ErrDocumentQuota
+	}
+	return nil
+}
+
+// quotaReader отдаёт не больше n байт; попытка прочитать больше завершается err
+type quotaReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (q *quotaReader) Read(p []byte) (int, error) {
+	// Читаем на байт больше остатка, чтобы заметить превышение
+	if int64(len(p)) > q.n+1 {
+		p = p[:q.n+1]
+	}
+	n, err := q.r.Read(p)
+	if int64(n) > q.n {
+		q.n = 0
+		return 0, q.err
+	}
+	q.n -= int64(n)
+	return n, err
+}
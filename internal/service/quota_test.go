@@ -0,0 +1,198 @@
+package service
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/storage"
+	"database/sql"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+// newUnlimitedQuota — квоты без лимитов для тестов, которые их не проверяют
+func newUnlimitedQuota(ctrl *gomock.Controller) *QuotaService {
+	quotaRepo := mocksgen.NewMockQuotaRepositoryInterface(ctrl)
+	quotaRepo.EXPECT().GetUsage(gomock.Any()).Return(&model.Usage{}, nil).AnyTimes()
+	quotaRepo.EXPECT().GetOverride(gomock.Any()).Return(nil, sql.ErrNoRows).AnyTimes()
+	return NewQuotaService(quotaRepo, nil, nil, model.QuotaLimits{})
+}
+
+func int64p(v int64) *int64 { return &v }
+
+func TestQuotaService_Usage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	quotaRepo := mocksgen.NewMockQuotaRepositoryInterface(ctrl)
+	quota := NewQuotaService(quotaRepo, nil, nil, model.QuotaLimits{MaxBytes: 1000, MaxDocuments: 10, MaxFileSize: 100})
+
+	override := &model.QuotaOverride{UserID: "u1", MaxBytes: int64p(0), MaxFileSize: int64p(500)}
+	quotaRepo.EXPECT().GetUsage("u1").Return(&model.Usage{Bytes: 300, Documents: 2}, nil)
+	quotaRepo.EXPECT().GetOverride("u1").Return(override, nil)
+
+	report, err := quota.Usage("u1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := model.QuotaLimits{MaxBytes: 0, MaxDocuments: 10, MaxFileSize: 500}
+	if report.Limits != want || report.Usage.Bytes != 300 || report.Override != override {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestQuotaService_Check(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	quotaRepo := mocksgen.NewMockQuotaRepositoryInterface(ctrl)
+	quotaRepo.EXPECT().GetUsage("u1").Return(&model.Usage{Bytes: 900, Documents: 10}, nil).AnyTimes()
+	quotaRepo.EXPECT().GetOverride("u1").Return(nil, sql.ErrNoRows).AnyTimes()
+	quota := NewQuotaService(quotaRepo, nil, nil, model.QuotaLimits{MaxBytes: 1000, MaxDocuments: 10, MaxFileSize: 200})
+
+	cases := []struct {
+		name     string
+		newDocs  int
+		size     int64
+		replaced int64
+		want     error
+	}{
+		{"too large", 0, 201, 0, ErrFileTooLarge},
+		{"too many documents", 1, 10, 0, ErrDocumentQuota},
+		{"no space", 0, 150, 0, ErrStorageQuota},
+		{"fits after replace", 0, 150, 100, nil},
+		{"shrink over limit", 0, 50, 100, nil},
+	}
+	for _, c := range cases {
+		if err := quota.Check("u1", c.newDocs, c.size, c.replaced); !errors.Is(err, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, err)
+		}
+	}
+}
+
+func TestQuotaService_Reader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	quotaRepo := mocksgen.NewMockQuotaRepositoryInterface(ctrl)
+	quotaRepo.EXPECT().GetUsage("u1").Return(&model.Usage{Bytes: 90, Documents: 1}, nil).AnyTimes()
+	quotaRepo.EXPECT().GetOverride("u1").Return(nil, sql.ErrNoRows).AnyTimes()
+	quota := NewQuotaService(quotaRepo, nil, nil, model.QuotaLimits{MaxBytes: 100, MaxFileSize: 8})
+
+	read := func(replaced int64, body string) (string, error) {
+		r, err := quota.Reader("u1", 1, replaced, strings.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		b, err := io.ReadAll(r)
+		return string(b), err
+	}
+	if got, err := read(0, "12345678"); err != nil || got != "12345678" {
+		t.Fatalf("expected file within limits to pass, got %q, %v", got, err)
+	}
+	if _, err := read(100, "123456789"); !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("expected ErrFileTooLarge, got %v", err)
+	}
+	// Свободно 10 байт, лимит файла 8: срабатывает лимит файла
+	if _, err := read(0, "123456789"); !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("expected ErrFileTooLarge, got %v", err)
+	}
+
+	quotaRepo2 := mocksgen.NewMockQuotaRepositoryInterface(ctrl)
+	quotaRepo2.EXPECT().GetUsage("u2").Return(&model.Usage{Bytes: 95}, nil)
+	quotaRepo2.EXPECT().GetOverride("u2").Return(nil, sql.ErrNoRows)
+	r, err := NewQuotaService(quotaRepo2, nil, nil, model.QuotaLimits{MaxBytes: 100}).Reader("u2", 1, 0, strings.NewReader("123456"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := io.ReadAll(r); !errors.Is(err, ErrStorageQuota) {
+		t.Fatalf("expected ErrStorageQuota, got %v", err)
+	}
+}
+
+func TestQuotaService_Reader_DocumentLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	quotaRepo := mocksgen.NewMockQuotaRepositoryInterface(ctrl)
+	quotaRepo.EXPECT().GetUsage("u1").Return(&model.Usage{Documents: 3}, nil).AnyTimes()
+	quotaRepo.EXPECT().GetOverride("u1").Return(&model.QuotaOverride{MaxDocuments: int64p(3)}, nil).AnyTimes()
+	quota := NewQuotaService(quotaRepo, nil, nil, model.QuotaLimits{})
+
+	if _, err := quota.Reader("u1", 1, 0, strings.NewReader("x")); !errors.Is(err, ErrDocumentQuota) {
+		t.Fatalf("expected ErrDocumentQuota, got %v", err)
+	}
+	// Перезапись существующего документа число документов не меняет
+	if _, err := quota.Reader("u1", 0, 0, strings.NewReader("x")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestQuotaService_SaveDefaults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	quotaRepo := mocksgen.NewMockQuotaRepositoryInterface(ctrl)
+	defaults := model.QuotaLimits{MaxBytes: 1000, MaxDocuments: 10, MaxFileSize: 100}
+	quotaRepo.EXPECT().SetDefaults(defaults).Return(nil)
+
+	if err := NewQuotaService(quotaRepo, nil, nil, defaults).SaveDefaults(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestQuotaService_SetOverride(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	quotaRepo := mocksgen.NewMockQuotaRepositoryInterface(ctrl)
+	quota := NewQuotaService(quotaRepo, nil, nil, model.QuotaLimits{MaxBytes: 1000})
+
+	if _, err := quota.SetOverride("u1", &model.QuotaOverride{MaxBytes: int64p(-1)}); !errors.Is(err, model.ErrInvalidQuota) {
+		t.Fatalf("expected ErrInvalidQuota, got %v", err)
+	}
+
+	quotaRepo.EXPECT().SetOverride(gomock.Any()).DoAndReturn(func(o *model.QuotaOverride) error {
+		if o.UserID != "u1" || *o.MaxBytes != 5000 || o.UpdatedAt.IsZero() {
+			t.Fatalf("unexpected override %+v", o)
+		}
+		return nil
+	})
+	quotaRepo.EXPECT().GetUsage("u1").Return(&model.Usage{}, nil).Times(2)
+	quotaRepo.EXPECT().GetOverride("u1").Return(&model.QuotaOverride{MaxBytes: int64p(5000)}, nil)
+	report, err := quota.SetOverride("u1", &model.QuotaOverride{MaxBytes: int64p(5000)})
+	if err != nil || report.Limits.MaxBytes != 5000 {
+		t.Fatalf("unexpected result %+v, %v", report, err)
+	}
+
+	// Без заданных лимитов личная квота снимается
+	quotaRepo.EXPECT().DeleteOverride("u1").Return(nil)
+	quotaRepo.EXPECT().GetOverride("u1").Return(nil, sql.ErrNoRows)
+	report, err = quota.SetOverride("u1", &model.QuotaOverride{})
+	if err != nil || report.Limits.MaxBytes != 1000 || report.Override != nil {
+		t.Fatalf("unexpected result %+v, %v", report, err)
+	}
+}
+
+func TestQuotaService_BackfillSizes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	blobs, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("cannot create blob store: %v", err)
+	}
+	if _, err := blobs.Put("f1", strings.NewReader("hello")); err != nil {
+		t.Fatalf("cannot write blob: %v", err)
+	}
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	quota := NewQuotaService(nil, docRepo, blobs, model.QuotaLimits{})
+
+	gomock.InOrder(
+		docRepo.EXPECT().ListUnsized(2).Return([]model.Document{{ID: "f1", File: true}, {ID: "missing", File: true}}, nil),
+		docRepo.EXPECT().ListUnsized(2).Return([]model.Document{}, nil),
+	)
+	docRepo.EXPECT().SetSize("f1", int64(5)).Return(nil)
+	docRepo.EXPECT().SetSize("missing", int64(0)).Return(nil)
+
+	n, err := quota.BackfillSizes(2)
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 sizes, got %d, %v", n, err)
+	}
+}
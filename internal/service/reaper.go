@@ -0,0 +1,85 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"astra-api/internal/storage"
+	"sync"
+	"time"
+)
+
+// ReaperService окончательно удаляет документы с истёкшим сроком жизни вместе с файлами
+// и копит статистику проходов. Истёкшие документы не видны и до очистки: их отсекают запросы.
+type ReaperService struct {
+	docRepo repository.DocumentRepositoryInterface
+	blobs   storage.BlobStore
+	batch   int
+
+	mu    sync.Mutex
+	stats model.ReaperStats
+}
+
+func NewReaperService(docRepo repository.DocumentRepositoryInterface, blobs storage.BlobStore, batch int) *ReaperService {
+	return &ReaperService{docRepo: docRepo, blobs: blobs, batch: batch}
+}
+
+// Reap удаляет истёкшие к now документы пачками по batch, пока они не кончатся
+func (s *ReaperService) Reap(now time.Time) (model.ReapResult, error) {
+	started := time.Now()
+	res, err := s.reap(now)
+	s.record(started, res, err)
+	return res, err
+}
+
+func (s *ReaperService) reap(now time.Time) (model.ReapResult, error) {
+	var res model.ReapResult
+	for {
+		docs, err := s.docRepo.ListExpired(now, s.batch)
+		if err != nil {
+			return res, err
+		}
+		if len(docs) == 0 {
+			return res, nil
+		}
+		res.Batches++
+		for _, doc := range docs {
+			// Сначала файл: если удаление строки не удастся, следующий проход повторит всё заново
+			if doc.File {
+				if err := s.blobs.Remove(doc.StorageKey()); err != nil {
+					return res, err
+				}
+				res.Files++
+			}
+			if err := s.docRepo.Delete(doc.ID); err != nil {
+				return res, err
+			}
+			res.Documents++
+		}
+		if len(docs) < s.batch {
+			return res, nil
+		}
+	}
+}
+
+func (s *ReaperService) record(started time.Time, res model.ReapResult, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Runs++
+	s.stats.Documents += int64(res.Documents)
+	s.stats.Files += int64(res.Files)
+	s.stats.LastRunAt = &started
+	s.stats.LastDuration = time.Since(started).Milliseconds()
+	s.stats.LastResult = res
+	s.stats.LastError = ""
+	if err != nil {
+		s.stats.Failures++
+		s.stats.LastError = err.Error()
+	}
+}
+
+// Stats возвращает статистику очистки с момента запуска сервера
+func (s *ReaperService) Stats() model.ReaperStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
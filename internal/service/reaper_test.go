@@ -0,0 +1,74 @@
+package service
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/storage"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestReaperService_Reap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blobs, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("cannot create blob store: %v", err)
+	}
+	if _, err := blobs.Put("f1", strings.NewReader("artifact")); err != nil {
+		t.Fatalf("cannot write blob: %v", err)
+	}
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	reaper := NewReaperService(docRepo, blobs, 2)
+
+	now := time.Now()
+	gomock.InOrder(
+		docRepo.EXPECT().ListExpired(now, 2).Return([]model.Document{{ID: "f1", File: true}, {ID: "j1"}}, nil),
+		docRepo.EXPECT().ListExpired(now, 2).Return([]model.Document{{ID: "j2"}}, nil),
+	)
+	docRepo.EXPECT().Delete("f1").Return(nil)
+	docRepo.EXPECT().Delete("j1").Return(nil)
+	docRepo.EXPECT().Delete("j2").Return(nil)
+
+	res, err := reaper.Reap(now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res != (model.ReapResult{Documents: 3, Files: 1, Batches: 2}) {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if _, err := blobs.Open("f1"); err == nil {
+		t.Fatal("expected blob to be removed")
+	}
+	stats := reaper.Stats()
+	if stats.Runs != 1 || stats.Documents != 3 || stats.Files != 1 || stats.LastRunAt == nil || stats.LastError != "" {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestReaperService_Reap_Failure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	reaper := NewReaperService(docRepo, nil, 10)
+
+	now := time.Now()
+	docRepo.EXPECT().ListExpired(now, 10).Return([]model.Document{{ID: "j1"}, {ID: "j2"}}, nil)
+	docRepo.EXPECT().Delete("j1").Return(nil)
+	docRepo.EXPECT().Delete("j2").Return(errors.New("connection reset"))
+
+	res, err := reaper.Reap(now)
+	if err == nil || res.Documents != 1 {
+		t.Fatalf("expected partial result with error, got %+v, %v", res, err)
+	}
+	stats := reaper.Stats()
+	if stats.Failures != 1 || stats.Documents != 1 || stats.LastError != "connection reset" {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"astra-api/internal/scan"
+	"astra-api/internal/storage"
+	"context"
+	"errors"
+	"time"
+)
+
+// ScanService проверяет загруженные файлы антивирусом. Файл ставится на проверку
+// при загрузке (Queue) и проверяется сразу после неё (Scan); если сканер недоступен,
+// файл остаётся в pending_scan, и его доделывает фоновая задача (ScanPending).
+// Заражённый файл остаётся в хранилище, но не отдаётся (см. model.Document.Downloadable).
+type ScanService struct {
+	docRepo repository.DocumentRepositoryInterface
+	blobs   storage.BlobStore
+	scanner scan.ContentScanner
+	now     func() time.Time
+}
+
+func NewScanService(docRepo repository.DocumentRepositoryInterface, blobs storage.BlobStore, scanner scan.ContentScanner) *ScanService {
+	return &ScanService{docRepo: docRepo, blobs: blobs, scanner: scanner, now: time.Now}
+}
+
+// Queue помечает файл документа как ожидающий проверки; JSON-документы не проверяются.
+// Сохраняет пометку вызывающий — вместе с документом.
+func (s *ScanService) Queue(doc *model.Document) {
+	if !doc.File {
+		return
+	}
+	// БД хранит время с точностью до микросекунд, а результат сверяется по нему
+	at := s.now().Truncate(time.Microsecond)
+	doc.ScanStatus = model.ScanPending
+	doc.ScanQueuedAt = &at
+	doc.ScanSignature = nil
+	doc.ScannedAt = nil
+}
+
+// Scan проверяет файл документа, ожидающего проверки, и записывает результат.
+// Ошибка означает, что сканер недоступен: файл остаётся в pending_scan.
+func (s *ScanService) Scan(doc *model.Document) error {
+	if doc.ScanStatus != model.ScanPending || doc.ScanQueuedAt == nil {
+		return nil
+	}
+	status, signature, err := s.check(doc)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	ok, err := s.docRepo.SetScanResult(doc.ID, *doc.ScanQueuedAt, status, signature, now)
+	if err != nil || !ok {
+		// !ok — файл заменили во время проверки, новое содержимое проверит следующий Scan
+		return err
+	}
+	doc.ScanStatus, doc.ScanSignature, doc.ScannedAt = status, signature, &now
+	return nil
+}
+
+func (s *ScanService) check(doc *model.Document) (string, *string, error) {
+	// Проверяется исходное содержимое: в сжатом сканер сигнатур не найдёт
+	f, err := OpenContent(s.blobs, doc)
+	if errors.Is(err, storage.ErrNotFound) {
+		reason := "file not found"
+		return model.ScanFailed, &reason, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	verdict, err := s.scanner.Scan(context.Background(), f)
+	switch {
+	case errors.Is(err, scan.ErrUnscannable):
+		reason := err.Error()
+		return model.ScanFailed, &reason, nil
+	case err != nil:
+		return "", nil, err
+	case verdict.Infected:
+		return model.ScanInfected, &verdict.Signature, nil
+	}
+	return model.ScanClean, nil, nil
+}
+
+// ScanPending проверяет до batch файлов, поставленных на проверку раньше before:
+// те, что не удалось проверить при загрузке, и загруженные до появления проверки
+func (s *ScanService) ScanPending(before time.Time, batch int) (int, error) {
+	docs, err := s.docRepo.ListPendingScan(before, batch)
+	if err != nil {
+		return 0, err
+	}
+	for i := range docs {
+		if err := s.Scan(&docs[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(docs), nil
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"astra-api/internal/compress"
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/scan"
+	"astra-api/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+// newNopScans ставит файлы на проверку и сразу считает их чистыми
+func newNopScans(ctrl *gomock.Controller) *mocksgen.MockScanServiceInterface {
+	scans := mocksgen.NewMockScanServiceInterface(ctrl)
+	scans.EXPECT().Queue(gomock.Any()).Do(func(doc *model.Document) {
+		at := time.Now()
+		doc.ScanStatus, doc.ScanQueuedAt = model.ScanPending, &at
+	}).AnyTimes()
+	scans.EXPECT().Scan(gomock.Any()).Do(func(doc *model.Document) { doc.ScanStatus = model.ScanClean }).Return(nil).AnyTimes()
+	return scans
+}
+
+// newNopCompression записывает файлы как есть
+func newNopCompression() *CompressionService {
+	return NewCompressionService(compress.Policy{})
+}
+
+// fakeScanner находит в файле строку "virus" и отвечает заданной ошибкой
+type fakeScanner struct{ err error }
+
+func (f fakeScanner) Scan(ctx context.Context, r io.Reader) (scan.Verdict, error) {
+	if f.err != nil {
+		return scan.Verdict{}, f.err
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return scan.Verdict{}, err
+	}
+	if strings.Contains(string(b), "virus") {
+		return scan.Verdict{Infected: true, Signature: "Test-Virus"}, nil
+	}
+	return scan.Verdict{}, nil
+}
+
+func newTestScanBlobs(t *testing.T, files map[string]string) storage.BlobStore {
+	blobs, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("cannot create blob store: %v", err)
+	}
+	for id, content := range files {
+		if _, err := blobs.Put(id, strings.NewReader(content)); err != nil {
+			t.Fatalf("cannot write blob: %v", err)
+		}
+	}
+	return blobs
+}
+
+func TestScanService_Queue(t *testing.T) {
+	scans := NewScanService(nil, nil, scan.Nop{})
+	old := "Old-Signature"
+	file := &model.Document{File: true, ScanStatus: model.ScanInfected, ScanSignature: &old}
+	scans.Queue(file)
+	if file.ScanStatus != model.ScanPending || file.ScanQueuedAt == nil || file.ScanSignature != nil {
+		t.Fatalf("unexpected queued file %+v", file)
+	}
+	doc := &model.Document{}
+	scans.Queue(doc)
+	if doc.ScanStatus != "" || doc.ScanQueuedAt != nil {
+		t.Fatalf("JSON documents must not be scanned: %+v", doc)
+	}
+}
+
+func TestScanService_Scan(t *testing.T) {
+	blobs := newTestScanBlobs(t, map[string]string{"clean": "hello", "bad": "a virus inside"})
+	cases := []struct {
+		id        string
+		scanner   scan.ContentScanner
+		status    string
+		signature string
+	}{
+		{"clean", fakeScanner{}, model.ScanClean, ""},
+		{"bad", fakeScanner{}, model.ScanInfected, "Test-Virus"},
+		{"missing", fakeScanner{}, model.ScanFailed, "file not found"},
+		{"clean", fakeScanner{err: fmt.Errorf("%w: size limit exceeded", scan.ErrUnscannable)}, model.ScanFailed, "scanner could not check the file: size limit exceeded"},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+		docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+		scans := NewScanService(docRepo, blobs, c.scanner)
+		doc := &model.Document{ID: c.id, File: true}
+		scans.Queue(doc)
+
+		docRepo.EXPECT().SetScanResult(c.id, *doc.ScanQueuedAt, c.status, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ string, _ time.Time, _ string, signature *string, _ time.Time) (bool, error) {
+				got := ""
+				if signature != nil {
+					got = *signature
+				}
+				if got != c.signature {
+					t.Errorf("%s: unexpected signature %q", c.id, got)
+				}
+				return true, nil
+			})
+		if err := scans.Scan(doc); err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.id, err)
+		}
+		if doc.ScanStatus != c.status || doc.ScannedAt == nil {
+			t.Fatalf("%s: unexpected result %+v", c.id, doc)
+		}
+		ctrl.Finish()
+	}
+}
+
+func TestScanService_Scan_Unavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	blobs := newTestScanBlobs(t, map[string]string{"f1": "hello"})
+	unavailable := errors.New("connection refused")
+	scans := NewScanService(mocksgen.NewMockDocumentRepositoryInterface(ctrl), blobs, fakeScanner{err: unavailable})
+
+	doc := &model.Document{ID: "f1", File: true}
+	scans.Queue(doc)
+	if err := scans.Scan(doc); !errors.Is(err, unavailable) {
+		t.Fatalf("expected scanner error, got %v", err)
+	}
+	if doc.ScanStatus != model.ScanPending {
+		t.Fatalf("file must stay pending, got %s", doc.ScanStatus)
+	}
+}
+
+func TestScanService_Scan_Stale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	scans := NewScanService(docRepo, newTestScanBlobs(t, map[string]string{"f1": "hello"}), fakeScanner{})
+
+	doc := &model.Document{ID: "f1", File: true}
+	scans.Queue(doc)
+	// Файл заменили во время проверки: результат не записан, документ ждёт новой проверки
+	docRepo.EXPECT().SetScanResult("f1", gomock.Any(), model.ScanClean, nil, gomock.Any()).Return(false, nil)
+	if err := scans.Scan(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc.ScanStatus != model.ScanPending {
+		t.Fatalf("expected the document to stay pending, got %s", doc.ScanStatus)
+	}
+}
+
+func TestScanService_ScanPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	scans := NewScanService(docRepo, newTestScanBlobs(t, map[string]string{"f1": "hello", "f2": "virus"}), fakeScanner{})
+
+	before := time.Now()
+	queued := before.Add(-time.Hour)
+	docRepo.EXPECT().ListPendingScan(before, 10).Return([]model.Document{
+		{ID: "f1", File: true, ScanStatus: model.ScanPending, ScanQueuedAt: &queued},
+		{ID: "f2", File: true, ScanStatus: model.ScanPending, ScanQueuedAt: &queued},
+	}, nil)
+	docRepo.EXPECT().SetScanResult("f1", queued, model.ScanClean, nil, gomock.Any()).Return(true, nil)
+	docRepo.EXPECT().SetScanResult("f2", queued, model.ScanInfected, gomock.Any(), gomock.Any()).Return(true, nil)
+
+	n, err := scans.ScanPending(before, 10)
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 scanned files, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"astra-api/internal/jsonschema"
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSchemaNotFound     = errors.New("schema not found")
+	ErrInvalidSchemaName  = errors.New("schema name must be 2-64 latin letters, digits, '.', '_' or '-'")
+	ErrSchemaInUse        = errors.New("schema is attached to documents, folders or tags")
+	ErrSchemaFileDocument = errors.New("file document has no json to validate")
+	ErrIncompatibleSchema = errors.New("schema change breaks attached documents")
+)
+
+// maxSchemaConflicts ограничивает число документов в отчёте о несовместимой схеме
+const maxSchemaConflicts = 20
+
+// IncompatibleSchemaError перечисляет документы, которые не прошли бы проверку новой схемой
+type IncompatibleSchemaError struct {
+	Conflicts []model.SchemaConflict
+	// Truncated — конфликтующих документов больше, чем в Conflicts
+	Truncated bool
+}
+
+func (e *IncompatibleSchemaError) Error() string {
+	more := ""
+	if e.Truncated {
+		more = " or more"
+	}
+	return fmt.Sprintf("%v: %d%s documents do not match the new schema", ErrIncompatibleSchema, len(e.Conflicts), more)
+}
+
+func (e *IncompatibleSchemaError) Unwrap() error { return ErrIncompatibleSchema }
+
+var schemaNameRe = regexp.MustCompile(`^[a-zA-Z0-9._-]{2,64}$`)
+
+// SchemaService хранит именованные схемы пользователей и проверяет по ним документы.
+// Схема видна только владельцу; документ можно привязать лишь к схеме своего владельца.
+type SchemaService struct {
+	schemaRepo repository.SchemaRepositoryInterface
+}
+
+func NewSchemaService(schemaRepo repository.SchemaRepositoryInterface) *SchemaService {
+	return &SchemaService{schemaRepo: schemaRepo}
+}
+
+// Put создаёт схему или заменяет существующую. Замена отклоняется, если хотя бы
+// один привязанный документ или документ из папки или с тегом со схемой ей не соответствует.
+func (s *SchemaService) Put(ownerID, name string, raw []byte) (*model.JSONSchema, bool, error) {
+	if !schemaNameRe.MatchString(name) {
+		return nil, false, ErrInvalidSchemaName
+	}
+	compiled, err := jsonschema.Compile(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	now := time.Now()
+	existing, err := s.schemaRepo.GetByName(ownerID, name)
+	if err != nil {
+		schema := &model.JSONSchema{ID: uuid.New().String(), Owner: ownerID, Name: name, Schema: raw, CreatedAt: now, UpdatedAt: now}
+		if err := s.schemaRepo.Create(schema); err != nil {
+			return nil, false, err
+		}
+		return schema, true, nil
+	}
+	if err := s.checkDocuments(existing.ID, compiled); err != nil {
+		return nil, false, err
+	}
+	existing.Schema = raw
+	existing.UpdatedAt = now
+	if err := s.schemaRepo.Update(existing); err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+// checkDocuments проверяет привязанные документы новой версией схемы
+func (s *SchemaService) checkDocuments(schemaID string, compiled *jsonschema.Schema) error {
+	return collectConflicts(compiled, func(fn func(doc *model.Document) error) error {
+		return s.schemaRepo.ForEachDocument(schemaID, fn)
+	})
+}
+
+// collectConflicts проверяет схемой документы, которые перебирает forEach
+func collectConflicts(compiled *jsonschema.Schema, forEach func(fn func(doc *model.Document) error) error) error {
+	conflicts := &IncompatibleSchemaError{}
+	errStop := errors.New("stop")
+	err := forEach(func(doc *model.Document) error {
+		var verr *jsonschema.ValidationError
+		if err := validateData(compiled, doc.JsonData); errors.As(err, &verr) {
+			if len(conflicts.Conflicts) == maxSchemaConflicts {
+				conflicts.Truncated = true
+				return errStop
+			}
+			conflicts.Conflicts = append(conflicts.Conflicts, model.SchemaConflict{DocumentID: doc.ID, Name: doc.Name, Violations: verr.Violations})
+		}
+		return nil
+	})
+	if err != nil && err != errStop {
+		return err
+	}
+	if len(conflicts.Conflicts) > 0 {
+		return conflicts
+	}
+	return nil
+}
+
+func (s *SchemaService) List(ownerID string) ([]model.JSONSchema, error) {
+	return s.schemaRepo.ListByOwner(ownerID)
+}
+
+func (s *SchemaService) Get(ownerID, name string) (*model.JSONSchema, error) {
+	schema, err := s.schemaRepo.GetByName(ownerID, name)
+	if err != nil {
+		return nil, ErrSchemaNotFound
+	}
+	return schema, nil
+}
+
+// Delete удаляет схему, если к ней не привязаны ни документы, ни папки, ни теги
+func (s *SchemaService) Delete(ownerID, name string) error {
+	schema, err := s.Get(ownerID, name)
+	if err != nil {
+		return err
+	}
+	if schema.Documents > 0 || schema.Folders > 0 || schema.Tags > 0 {
+		return ErrSchemaInUse
+	}
+	return s.schemaRepo.Delete(schema.ID)
+}
+
+// Attach привязывает к документу схему его владельца по имени и проверяет json;
+// пустое имя отвязывает схему, после чего документ всё ещё проверяется схемами папки и тегов
+func (s *SchemaService) Attach(doc *model.Document, name string) error {
+	if name == "" {
+		doc.SchemaID = nil
+		return s.Validate(doc)
+	}
+	if doc.File {
+		return ErrSchemaFileDocument
+	}
+	schema, err := s.Get(doc.Owner, name)
+	if err != nil {
+		return err
+	}
+	compiled, err := jsonschema.Compile(schema.Schema)
+	if err != nil {
+		return err
+	}
+	if err := validateData(compiled, doc.JsonData); err != nil {
+		return err
+	}
+	if err := s.validateFolder(doc); err != nil {
+		return err
+	}
+	if err := s.validateTags(doc); err != nil {
+		return err
+	}
+	doc.SchemaID = &schema.ID
+	return nil
+}
+
+// AttachFolder привязывает к папке схему её владельца; все JSON-документы, лежащие
+// прямо в папке, должны ей соответствовать. Пустое имя отвязывает схему.
+func (s *SchemaService) AttachFolder(folder *model.Folder, name string) error {
+	if name == "" {
+		folder.SchemaID = nil
+		return nil
+	}
+	schema, err := s.Get(folder.Owner, name)
+	if err != nil {
+		return err
+	}
+	compiled, err := jsonschema.Compile(schema.Schema)
+	if err != nil {
+		return err
+	}
+	err = collectConflicts(compiled, func(fn func(doc *model.Document) error) error {
+		return s.schemaRepo.ForEachInFolder(folder.ID, fn)
+	})
+	if err != nil {
+		return err
+	}
+	folder.SchemaID = &schema.ID
+	return nil
+}
+
+// AttachTag привязывает к тегу схему его владельца; все JSON-документы владельца
+// с этим тегом должны ей соответствовать. Пустое имя отвязывает схему.
+func (s *SchemaService) AttachTag(ownerID, tag, name string) error {
+	tags, err := model.NormalizeTags([]string{tag})
+	if err != nil {
+		return err
+	}
+	tag = tags[0]
+	if name == "" {
+		return s.schemaRepo.DetachTag(ownerID, tag)
+	}
+	schema, err := s.Get(ownerID, name)
+	if err != nil {
+		return err
+	}
+	compiled, err := jsonschema.Compile(schema.Schema)
+	if err != nil {
+		return err
+	}
+	err = collectConflicts(compiled, func(fn func(doc *model.Document) error) error {
+		return s.schemaRepo.ForEachTagged(ownerID, tag, fn)
+	})
+	if err != nil {
+		return err
+	}
+	return s.schemaRepo.AttachTag(ownerID, tag, schema.ID)
+}
+
+// Validate проверяет json документа привязанной схемой и схемами его папки и тегов;
+// без схем документ корректен
+func (s *SchemaService) Validate(doc *model.Document) error {
+	if doc.SchemaID != nil {
+		schema, err := s.schemaRepo.GetByID(*doc.SchemaID)
+		if err != nil {
+			return ErrSchemaNotFound
+		}
+		if err := validateSchema(schema, doc.JsonData); err != nil {
+			return err
+		}
+	}
+	if err := s.validateFolder(doc); err != nil {
+		return err
+	}
+	return s.validateTags(doc)
+}
+
+// validateFolder проверяет JSON-документ схемой папки, в которой он лежит
+func (s *SchemaService) validateFolder(doc *model.Document) error {
+	if doc.FolderID == nil || doc.File {
+		return nil
+	}
+	schema, err := s.schemaRepo.GetByFolder(*doc.FolderID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return validateSchema(schema, doc.JsonData)
+}
+
+// validateTags проверяет JSON-документ схемами, привязанными к его тегам
+func (s *SchemaService) validateTags(doc *model.Document) error {
+	if len(doc.Tags) == 0 || doc.File {
+		return nil
+	}
+	schemas, err := s.schemaRepo.ListByTags(doc.Owner, doc.Tags)
+	if err != nil {
+		return err
+	}
+	for i := range schemas {
+		if err := validateSchema(&schemas[i], doc.JsonData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateSchema(schema *model.JSONSchema, data []byte) error {
+	compiled, err := jsonschema.Compile(schema.Schema)
+	if err != nil {
+		return err
+	}
+	return validateData(compiled, data)
+}
+
+// validateData проверяет содержимое json_data; отсутствующий json проверяется как null
+func validateData(compiled *jsonschema.Schema, data []byte) error {
+	if len(data) == 0 {
+		return compiled.Validate(nil)
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return compiled.Validate(v)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"astra-api/internal/jsonschema"
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+const replicasSchema = `{"type": "object", "required": ["replicas"], "properties": {"replicas": {"type": "integer", "minimum": 1}}}`
+
+func TestSchemaService_Put(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	schemaRepo := mocksgen.NewMockSchemaRepositoryInterface(ctrl)
+	schemas := NewSchemaService(schemaRepo)
+
+	schemaRepo.EXPECT().GetByName("u1", "service-config").Return(nil, sql.ErrNoRows)
+	schemaRepo.EXPECT().Create(gomock.Any()).Return(nil)
+	schema, created, err := schemas.Put("u1", "service-config", []byte(replicasSchema))
+	if err != nil || !created || schema.ID == "" || schema.Owner != "u1" {
+		t.Fatalf("unexpected result %+v, %v, %v", schema, created, err)
+	}
+
+	if _, _, err := schemas.Put("u1", "a b", []byte(replicasSchema)); !errors.Is(err, ErrInvalidSchemaName) {
+		t.Fatalf("expected ErrInvalidSchemaName, got %v", err)
+	}
+	if _, _, err := schemas.Put("u1", "service-config", []byte(`{"type": 1}`)); !errors.Is(err, jsonschema.ErrInvalidSchema) {
+		t.Fatalf("expected ErrInvalidSchema, got %v", err)
+	}
+}
+
+func TestSchemaService_Put_Incompatible(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	schemaRepo := mocksgen.NewMockSchemaRepositoryInterface(ctrl)
+	schemas := NewSchemaService(schemaRepo)
+
+	existing := &model.JSONSchema{ID: "s1", Owner: "u1", Name: "service-config", Schema: []byte(`{}`)}
+	schemaRepo.EXPECT().GetByName("u1", "service-config").Return(existing, nil).Times(2)
+	schemaRepo.EXPECT().ForEachDocument("s1", gomock.Any()).DoAndReturn(func(_ string, fn func(doc *model.Document) error) error {
+		for _, doc := range []*model.Document{
+			{ID: "d1", Name: "ok.json", JsonData: []byte(`{"replicas": 2}`)},
+			{ID: "d2", Name: "broken.json", JsonData: []byte(`{"replicas": 0}`)},
+		} {
+			if err := fn(doc); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Times(2)
+
+	_, _, err := schemas.Put("u1", "service-config", []byte(replicasSchema))
+	var incompatible *IncompatibleSchemaError
+	if !errors.As(err, &incompatible) || !errors.Is(err, ErrIncompatibleSchema) {
+		t.Fatalf("expected IncompatibleSchemaError, got %v", err)
+	}
+	if len(incompatible.Conflicts) != 1 || incompatible.Conflicts[0].DocumentID != "d2" || incompatible.Conflicts[0].Violations[0].Path != "/replicas" {
+		t.Fatalf("unexpected conflicts %+v", incompatible.Conflicts)
+	}
+
+	schemaRepo.EXPECT().Update(existing).Return(nil)
+	if _, created, err := schemas.Put("u1", "service-config", []byte(`{"type": "object"}`)); err != nil || created {
+		t.Fatalf("expected compatible update, got %v", err)
+	}
+}
+
+func TestSchemaService_AttachAndDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	schemaRepo := mocksgen.NewMockSchemaRepositoryInterface(ctrl)
+	schemas := NewSchemaService(schemaRepo)
+
+	schema := &model.JSONSchema{ID: "s1", Owner: "u1", Name: "service-config", Schema: []byte(replicasSchema), Documents: 1}
+	schemaRepo.EXPECT().GetByName("u1", "service-config").Return(schema, nil).AnyTimes()
+
+	doc := &model.Document{ID: "d1", Owner: "u1", JsonData: []byte(`{"replicas": "two"}`)}
+	var verr *jsonschema.ValidationError
+	if err := schemas.Attach(doc, "service-config"); !errors.As(err, &verr) || doc.SchemaID != nil {
+		t.Fatalf("expected ValidationError and no schema attached, got %v", err)
+	}
+	doc.JsonData = []byte(`{"replicas": 2}`)
+	if err := schemas.Attach(doc, "service-config"); err != nil || doc.SchemaID == nil || *doc.SchemaID != "s1" {
+		t.Fatalf("expected schema attached, got %v", err)
+	}
+	if err := schemas.Attach(&model.Document{Owner: "u1", File: true}, "service-config"); !errors.Is(err, ErrSchemaFileDocument) {
+		t.Fatalf("expected ErrSchemaFileDocument, got %v", err)
+	}
+
+	schemaRepo.EXPECT().GetByID("s1").Return(schema, nil)
+	doc.JsonData = []byte(`{}`)
+	if err := schemas.Validate(doc); !errors.As(err, &verr) || verr.Violations[0].Keyword != "#/required" {
+		t.Fatalf("expected required violation, got %v", err)
+	}
+
+	if err := schemas.Delete("u1", "service-config"); !errors.Is(err, ErrSchemaInUse) {
+		t.Fatalf("expected ErrSchemaInUse, got %v", err)
+	}
+}
+
+func TestSchemaService_FolderSchema(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	schemaRepo := mocksgen.NewMockSchemaRepositoryInterface(ctrl)
+	schemas := NewSchemaService(schemaRepo)
+
+	schema := &model.JSONSchema{ID: "s1", Owner: "u1", Name: "service-config", Schema: []byte(replicasSchema)}
+	schemaRepo.EXPECT().GetByName("u1", "service-config").Return(schema, nil).AnyTimes()
+	schemaRepo.EXPECT().ForEachInFolder("f1", gomock.Any()).DoAndReturn(func(_ string, fn func(doc *model.Document) error) error {
+		return fn(&model.Document{ID: "d1", Name: "broken.json", JsonData: []byte(`{}`)})
+	})
+	folder := &model.Folder{ID: "f1", Owner: "u1"}
+	var incompatible *IncompatibleSchemaError
+	if err := schemas.AttachFolder(folder, "service-config"); !errors.As(err, &incompatible) || folder.SchemaID != nil {
+		t.Fatalf("expected IncompatibleSchemaError, got %v", err)
+	}
+
+	schemaRepo.EXPECT().GetByFolder("f1").Return(schema, nil)
+	doc := &model.Document{ID: "d2", Owner: "u1", FolderID: &folder.ID, JsonData: []byte(`{"replicas": 0}`)}
+	var verr *jsonschema.ValidationError
+	if err := schemas.Validate(doc); !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError from folder schema, got %v", err)
+	}
+
+	schemaRepo.EXPECT().GetByFolder("f1").Return(nil, sql.ErrNoRows)
+	if err := schemas.Validate(doc); err != nil {
+		t.Fatalf("expected no error without folder schema, got %v", err)
+	}
+	doc.File = true
+	if err := schemas.Validate(doc); err != nil {
+		t.Fatalf("expected file documents to skip folder schema, got %v", err)
+	}
+}
+
+func TestSchemaService_TagSchema(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	schemaRepo := mocksgen.NewMockSchemaRepositoryInterface(ctrl)
+	schemas := NewSchemaService(schemaRepo)
+
+	schema := &model.JSONSchema{ID: "s1", Owner: "u1", Name: "service-config", Schema: []byte(replicasSchema)}
+	schemaRepo.EXPECT().GetByName("u1", "service-config").Return(schema, nil).AnyTimes()
+	tagged := []*model.Document{{ID: "d1", Name: "broken.json", Tags: []string{"prod"}, JsonData: []byte(`{}`)}}
+	schemaRepo.EXPECT().ForEachTagged("u1", "prod", gomock.Any()).DoAndReturn(func(_, _ string, fn func(doc *model.Document) error) error {
+		for _, doc := range tagged {
+			if err := fn(doc); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Times(2)
+
+	// Тег приводится к виду, в котором хранится у документов
+	var incompatible *IncompatibleSchemaError
+	if err := schemas.AttachTag("u1", " Prod ", "service-config"); !errors.As(err, &incompatible) {
+		t.Fatalf("expected IncompatibleSchemaError, got %v", err)
+	}
+	tagged[0].JsonData = []byte(`{"replicas": 2}`)
+	schemaRepo.EXPECT().AttachTag("u1", "prod", "s1").Return(nil)
+	if err := schemas.AttachTag("u1", "prod", "service-config"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	schemaRepo.EXPECT().ListByTags("u1", []string{"prod", "eu"}).Return([]model.JSONSchema{*schema}, nil)
+	doc := &model.Document{ID: "d2", Owner: "u1", Tags: []string{"prod", "eu"}, JsonData: []byte(`{"replicas": 0}`)}
+	var verr *jsonschema.ValidationError
+	if err := schemas.Validate(doc); !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError from tag schema, got %v", err)
+	}
+	doc.File = true
+	if err := schemas.Validate(doc); err != nil {
+		t.Fatalf("expected file documents to skip tag schemas, got %v", err)
+	}
+
+	schemaRepo.EXPECT().DetachTag("u1", "prod").Return(nil)
+	if err := schemas.AttachTag("u1", "prod", ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := schemas.AttachTag("u1", "", "service-config"); !errors.Is(err, model.ErrInvalidTag) {
+		t.Fatalf("expected ErrInvalidTag, got %v", err)
+	}
+
+	schemaRepo.EXPECT().GetByName("u1", "tagged").Return(&model.JSONSchema{ID: "s2", Tags: 1}, nil)
+	if err := schemas.Delete("u1", "tagged"); !errors.Is(err, ErrSchemaInUse) {
+		t.Fatalf("expected ErrSchemaInUse for schema attached to a tag, got %v", err)
+	}
+}
@@ -25,10 +25,27 @@ func (s *SessionService) Create(userID, login string) string {
 	return token
 }
 
+// CreateImpersonation создаёт ограниченную по времени сессию администратора actor от имени target
+func (s *SessionService) CreateImpersonation(actor model.Session, target *model.User, ttl time.Duration) string {
+	token := uuid.New().String()
+	now := time.Now()
+	s.mu.Lock()
+	s.sessions[token] = model.Session{
+		Token:             token,
+		UserID:            target.ID,
+		Login:             target.Login,
+		Roles:             withoutRole(target.Roles, model.RoleAdmin),
+		Created:           now,
+		ExpiresAt:         now.Add(ttl),
+		ImpersonatorID:    actor.UserID,
+		ImpersonatorLogin: actor.Login,
+	}
+	s.mu.Unlock()
+	return token
+}
+
 func (s *SessionService) Get(token string) (*model.Session, bool) {
-	s.mu.RLock()
-	sess, ok := s.sessions[token]
-	s.mu.RUnlock()
+	sess, ok := s.Validate(token)
 	if !ok {
 		return nil, false
 	}
@@ -39,6 +56,10 @@ func (s *SessionService) Validate(token string) (model.Session, bool) {
 	s.mu.RLock()
 	sess, ok := s.sessions[token]
 	s.mu.RUnlock()
+	if ok && sess.Expired(time.Now()) {
+		s.Delete(token)
+		return model.Session{}, false
+	}
 	return sess, ok
 }
 
@@ -51,3 +72,25 @@ func (s *SessionService) Delete(token string) bool {
 	s.mu.Unlock()
 	return ok
 }
+
+// DeleteByUser завершает все сессии пользователя, включая сессии имперсонации от его имени
+func (s *SessionService) DeleteByUser(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token, sess := range s.sessions {
+		if sess.UserID == userID || sess.ImpersonatorID == userID {
+			delete(s.sessions, token)
+		}
+	}
+}
+
+// withoutRole возвращает копию списка ролей без указанной
+func withoutRole(roles []string, role string) []string {
+	out := make([]string, 0, len(roles))
+	for _, r := range roles {
+		if r != role {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"astra-api/internal/model"
 	"testing"
 	"time"
 )
@@ -150,3 +151,57 @@ func TestSessionService_CreatedAt(t *testing.T) {
 		t.Fatal("expected CreatedAt to be within expected time range")
 	}
 }
+
+func TestSessionService_CreateImpersonation(t *testing.T) {
+	sessionService := NewSessionService()
+
+	actor := model.Session{UserID: "admin1", Login: "adminuser"}
+	target := &model.User{ID: "user1", Login: "testuser", Roles: []string{model.RoleAdmin, "support"}}
+
+	token := sessionService.CreateImpersonation(actor, target, time.Minute)
+
+	session, ok := sessionService.Validate(token)
+	if !ok {
+		t.Fatal("expected impersonation session to exist")
+	}
+	if session.UserID != "user1" || session.ImpersonatorID != "admin1" || session.ImpersonatorLogin != "adminuser" {
+		t.Fatalf("unexpected session: %+v", session)
+	}
+	if !session.Impersonated() {
+		t.Fatal("expected session to be marked as impersonated")
+	}
+	if len(session.Roles) != 1 || session.Roles[0] != "support" {
+		t.Fatalf("expected admin role to be stripped, got %v", session.Roles)
+	}
+}
+
+func TestSessionService_CreateImpersonation_Expires(t *testing.T) {
+	sessionService := NewSessionService()
+
+	token := sessionService.CreateImpersonation(model.Session{UserID: "admin1"}, &model.User{ID: "user1"}, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	if _, ok := sessionService.Get(token); ok {
+		t.Fatal("expected expired impersonation session to be rejected")
+	}
+}
+
+func TestSessionService_DeleteByUser(t *testing.T) {
+	sessionService := NewSessionService()
+
+	own := sessionService.Create("user1", "testuser")
+	other := sessionService.Create("user2", "otheruser")
+	imp := sessionService.CreateImpersonation(model.Session{UserID: "user1"}, &model.User{ID: "user3"}, time.Minute)
+
+	sessionService.DeleteByUser("user1")
+
+	if _, ok := sessionService.Validate(own); ok {
+		t.Fatal("expected user session to be deleted")
+	}
+	if _, ok := sessionService.Validate(imp); ok {
+		t.Fatal("expected impersonation session started by user to be deleted")
+	}
+	if _, ok := sessionService.Validate(other); !ok {
+		t.Fatal("expected other user's session to survive")
+	}
+}
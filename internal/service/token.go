@@ -0,0 +1,372 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+type accessClaims struct {
+	Subject   string       `json:"sub"`
+	Login     string       `json:"login"`
+	Roles     []string     `json:"roles,omitempty"`
+	Actor     *actorClaims `json:"act,omitempty"`
+	ID        string       `json:"jti"`
+	IssuedAt  int64        `json:"iat"`
+	ExpiresAt int64        `json:"exp"`
+}
+
+// actorClaims — claim "act" (RFC 8693): кто на самом деле действует от имени sub
+type actorClaims struct {
+	Subject string `json:"sub"`
+	Login   string `json:"login"`
+}
+
+// TokenService выдаёт короткоживущие подписанные access-токены и refresh-токены с ротацией.
+// Access-токен проверяется без обращения к хранилищу: подпись — ключами, отзыв — по копии
+// в памяти. Отзыв и refresh-токены хранятся в БД, так что их видят все экземпляры и после
+// перезапуска; копию перечитывает LoadRevocations, свои отзывы вносятся в неё сразу.
+type TokenService struct {
+	keys        *KeyRing
+	userRepo    repository.UserRepositoryInterface
+	refreshRepo repository.RefreshTokenRepositoryInterface
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+	revoked     *revocations
+	now         func() time.Time
+}
+
+func NewTokenService(keys *KeyRing, userRepo repository.UserRepositoryInterface, refreshRepo repository.RefreshTokenRepositoryInterface, accessTTL, refreshTTL time.Duration) *TokenService {
+	return &TokenService{
+		keys:        keys,
+		userRepo:    userRepo,
+		refreshRepo: refreshRepo,
+		accessTTL:   accessTTL,
+		refreshTTL:  refreshTTL,
+		revoked:     newRevocations(accessTTL),
+		now:         time.Now,
+	}
+}
+
+// Create выпускает только access-токен; роли берутся из профиля пользователя
+func (s *TokenService) Create(userID, login string) string {
+	token, _, err := s.issueAccess(userID, login, s.rolesOf(userID))
+	if err != nil {
+		log.Printf("cannot sign access token for user %s: %v", userID, err)
+		return ""
+	}
+	return token
+}
+
+// CreateImpersonation выпускает access-токен без refresh-токена, так что
+// сессия имперсонации заканчивается вместе с ttl
+func (s *TokenService) CreateImpersonation(actor model.Session, target *model.User, ttl time.Duration) string {
+	token, _, err := s.sign(accessClaims{
+		Subject: target.ID,
+		Login:   target.Login,
+		Roles:   withoutRole(target.Roles, model.RoleAdmin),
+		Actor:   &actorClaims{Subject: actor.UserID, Login: actor.Login},
+	}, ttl)
+	if err != nil {
+		log.Printf("cannot sign impersonation token for user %s: %v", target.ID, err)
+		return ""
+	}
+	return token
+}
+
+func (s *TokenService) CreatePair(userID, login string) (*model.TokenPair, error) {
+	roles := s.rolesOf(userID)
+	access, exp, err := s.issueAccess(userID, login, roles)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := s.issueRefresh(userID, uuid.New().String())
+	if err != nil {
+		return nil, err
+	}
+	return &model.TokenPair{AccessToken: access, RefreshToken: refresh, ExpiresAt: exp}, nil
+}
+
+// Refresh обменивает refresh-токен на новую пару. Повторное предъявление уже
+// использованного токена считается кражей: вся цепочка токенов отзывается.
+func (s *TokenService) Refresh(refreshToken string) (*model.TokenPair, error) {
+	stored, err := s.refreshRepo.GetByHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	now := s.now()
+	if stored.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if stored.UsedAt != nil {
+		_ = s.refreshRepo.RevokeFamily(stored.FamilyID, now)
+		return nil, ErrRefreshTokenReused
+	}
+	if now.After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	ok, err := s.refreshRepo.MarkUsed(stored.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		_ = s.refreshRepo.RevokeFamily(stored.FamilyID, now)
+		return nil, ErrRefreshTokenReused
+	}
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	access, exp, err := s.issueAccess(user.ID, user.Login, user.Roles)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := s.issueRefresh(user.ID, stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TokenPair{AccessToken: access, RefreshToken: refresh, ExpiresAt: exp}, nil
+}
+
+func (s *TokenService) Get(token string) (*model.Session, bool) {
+	sess, ok := s.Validate(token)
+	if !ok {
+		return nil, false
+	}
+	return &sess, true
+}
+
+func (s *TokenService) Validate(token string) (model.Session, bool) {
+	var claims accessClaims
+	if err := s.keys.Verify(token, &claims); err != nil {
+		return model.Session{}, false
+	}
+	if s.now().Unix() >= claims.ExpiresAt {
+		return model.Session{}, false
+	}
+	if s.isRevoked(claims) {
+		return model.Session{}, false
+	}
+	sess := model.Session{
+		Token:     token,
+		UserID:    claims.Subject,
+		Login:     claims.Login,
+		Roles:     claims.Roles,
+		Created:   time.Unix(claims.IssuedAt, 0),
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+		Stateless: true,
+	}
+	if claims.Actor != nil {
+		sess.ImpersonatorID = claims.Actor.Subject
+		sess.ImpersonatorLogin = claims.Actor.Login
+	}
+	return sess, true
+}
+
+// Delete отзывает access-токен до истечения его срока либо всю цепочку,
+// если передан refresh-токен
+func (s *TokenService) Delete(token string) bool {
+	var claims accessClaims
+	if err := s.keys.Verify(token, &claims); err == nil {
+		now := s.now()
+		if now.Unix() >= claims.ExpiresAt {
+			return false
+		}
+		ok, err := s.refreshRepo.RevokeAccess(claims.ID, time.Unix(claims.ExpiresAt, 0), now)
+		if err != nil {
+			log.Printf("cannot revoke access token %s: %v", claims.ID, err)
+			return false
+		}
+		s.revoked.addToken(claims.ID, time.Unix(claims.ExpiresAt, 0))
+		return ok
+	}
+	stored, err := s.refreshRepo.GetByHash(hashToken(token))
+	if err != nil || stored.RevokedAt != nil {
+		return false
+	}
+	return s.refreshRepo.RevokeFamily(stored.FamilyID, s.now()) == nil
+}
+
+func (s *TokenService) issueAccess(userID, login string, roles []string) (string, time.Time, error) {
+	return s.sign(accessClaims{Subject: userID, Login: login, Roles: roles}, s.accessTTL)
+}
+
+// sign дополняет claims идентификатором и сроками действия и подписывает их
+func (s *TokenService) sign(claims accessClaims, ttl time.Duration) (string, time.Time, error) {
+	now := s.now()
+	exp := now.Add(ttl)
+	claims.ID = uuid.New().String()
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = exp.Unix()
+	s.revoked.widen(ttl)
+	token, err := s.keys.Sign(claims)
+	return token, exp, err
+}
+
+func (s *TokenService) issueRefresh(userID, familyID string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := b64.EncodeToString(raw)
+	now := s.now()
+	err := s.refreshRepo.Create(&model.RefreshToken{
+		ID:        uuid.New().String(),
+		FamilyID:  familyID,
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.refreshTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *TokenService) rolesOf(userID string) []string {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil
+	}
+	return user.Roles
+}
+
+// DeleteByUser отзывает все refresh-токены пользователя и все уже выданные ему
+// access-токены, в том числе токены имперсонации, где он выступает администратором
+func (s *TokenService) DeleteByUser(userID string) {
+	now := s.now()
+	// iat хранится с точностью до секунды: отсекаем и токены, выданные в эту секунду
+	notBefore := time.Unix(now.Unix()+1, 0)
+	s.revoked.addCutoff(userID, notBefore)
+	if err := s.refreshRepo.RevokeAccessBefore(userID, notBefore); err != nil {
+		log.Printf("cannot revoke access tokens of user %s: %v", userID, err)
+	}
+	if err := s.refreshRepo.RevokeByUser(userID, now); err != nil {
+		log.Printf("cannot revoke refresh tokens of user %s: %v", userID, err)
+	}
+}
+
+// LoadRevocations перечитывает отзыв access-токенов из БД, чтобы увидеть отзывы других
+// экземпляров. При ошибке остаётся последнее известное состояние.
+func (s *TokenService) LoadRevocations() error {
+	now := s.now()
+	tokens, cutoffs, err := s.refreshRepo.ListAccessRevocations(now, now.Add(-s.revoked.lifetime()))
+	if err != nil {
+		return err
+	}
+	s.revoked.merge(now, tokens, cutoffs)
+	return nil
+}
+
+// isRevoked проверяет, что токен отозван сам или выдан раньше отсечки субъекта либо
+// администратора при имперсонации
+func (s *TokenService) isRevoked(claims accessClaims) bool {
+	// Токен, живущий дольше известного окна (например, имперсонация с другого экземпляра),
+	// расширяет окно: следующая загрузка захватит и более ранние отсечки
+	s.revoked.widen(time.Duration(claims.ExpiresAt-claims.IssuedAt) * time.Second)
+	issuedAt := time.Unix(claims.IssuedAt, 0)
+	if s.revoked.has(claims.ID, claims.Subject, issuedAt) {
+		return true
+	}
+	return claims.Actor != nil && s.revoked.has("", claims.Actor.Subject, issuedAt)
+}
+
+// revocations — копия отзыва access-токенов в памяти. Отзывы только добавляются:
+// отозванный токен забывается, когда истекает, отсечка — когда истекли все токены,
+// которые она могла отсечь (старше окна window — самого долгого срока жизни токена).
+type revocations struct {
+	mu      sync.RWMutex
+	tokens  map[string]time.Time
+	cutoffs map[string]time.Time
+	window  time.Duration
+}
+
+func newRevocations(window time.Duration) *revocations {
+	return &revocations{tokens: map[string]time.Time{}, cutoffs: map[string]time.Time{}, window: window}
+}
+
+func (r *revocations) has(tokenID, userID string, issuedAt time.Time) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, ok := r.tokens[tokenID]; ok {
+		return true
+	}
+	notBefore, ok := r.cutoffs[userID]
+	return ok && issuedAt.Before(notBefore)
+}
+
+func (r *revocations) addToken(tokenID string, expiresAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[tokenID] = expiresAt
+}
+
+func (r *revocations) addCutoff(userID string, notBefore time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if notBefore.After(r.cutoffs[userID]) {
+		r.cutoffs[userID] = notBefore
+	}
+}
+
+func (r *revocations) lifetime() time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.window
+}
+
+func (r *revocations) widen(ttl time.Duration) {
+	if ttl <= r.lifetime() {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ttl > r.window {
+		r.window = ttl
+	}
+}
+
+// merge добавляет прочитанное из БД и забывает то, что уже ничего не отсекает. Состояние
+// не заменяется целиком: отзыв, внесённый во время загрузки, не теряется.
+func (r *revocations) merge(now time.Time, tokens []model.RevokedAccessToken, cutoffs []model.AccessCutoff) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range tokens {
+		r.tokens[t.TokenID] = t.ExpiresAt
+	}
+	for _, c := range cutoffs {
+		if c.NotBefore.After(r.cutoffs[c.UserID]) {
+			r.cutoffs[c.UserID] = c.NotBefore
+		}
+	}
+	for id, exp := range r.tokens {
+		if !exp.After(now) {
+			delete(r.tokens, id)
+		}
+	}
+	since := now.Add(-r.window)
+	for id, notBefore := range r.cutoffs {
+		if !notBefore.After(since) {
+			delete(r.cutoffs, id)
+		}
+	}
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
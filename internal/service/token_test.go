@@ -0,0 +1,377 @@
+package service
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+// newTestTokenService собирает TokenService поверх моков, которые хранят refresh-токены в map
+func newTestTokenService(t *testing.T, ctrl *gomock.Controller) *TokenService {
+	key, err := GenerateSigningKey("k1")
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	ring, err := NewKeyRing(key)
+	if err != nil {
+		t.Fatalf("failed to create key ring: %v", err)
+	}
+
+	userRepo := mocksgen.NewMockUserRepositoryInterface(ctrl)
+	refreshRepo := mocksgen.NewMockRefreshTokenRepositoryInterface(ctrl)
+	store := map[string]*model.RefreshToken{}
+
+	userRepo.EXPECT().GetByID("u1").Return(&model.User{ID: "u1", Login: "testuser", Roles: []string{"admin"}}, nil).AnyTimes()
+	refreshRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(token *model.RefreshToken) error {
+		store[token.TokenHash] = token
+		return nil
+	}).AnyTimes()
+	refreshRepo.EXPECT().GetByHash(gomock.Any()).DoAndReturn(func(hash string) (*model.RefreshToken, error) {
+		token, ok := store[hash]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		copied := *token
+		return &copied, nil
+	}).AnyTimes()
+	refreshRepo.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).DoAndReturn(func(id string, at time.Time) (bool, error) {
+		for _, token := range store {
+			if token.ID == id && token.UsedAt == nil {
+				token.UsedAt = &at
+				return true, nil
+			}
+		}
+		return false, nil
+	}).AnyTimes()
+	refreshRepo.EXPECT().RevokeFamily(gomock.Any(), gomock.Any()).DoAndReturn(func(familyID string, at time.Time) error {
+		for _, token := range store {
+			if token.FamilyID == familyID {
+				token.RevokedAt = &at
+			}
+		}
+		return nil
+	}).AnyTimes()
+
+	// Отзыв access-токенов хранится в БД: общая map имитирует её для нескольких экземпляров
+	revoked := map[string]bool{}
+	cutoffs := map[string]time.Time{}
+	refreshRepo.EXPECT().RevokeByUser(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	refreshRepo.EXPECT().RevokeAccess(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(tokenID string, expiresAt, now time.Time) (bool, error) {
+		if revoked[tokenID] {
+			return false, nil
+		}
+		revoked[tokenID] = true
+		return true, nil
+	}).AnyTimes()
+	refreshRepo.EXPECT().RevokeAccessBefore(gomock.Any(), gomock.Any()).DoAndReturn(func(userID string, notBefore time.Time) error {
+		cutoffs[userID] = notBefore
+		return nil
+	}).AnyTimes()
+	refreshRepo.EXPECT().ListAccessRevocations(gomock.Any(), gomock.Any()).DoAndReturn(func(now, since time.Time) ([]model.RevokedAccessToken, []model.AccessCutoff, error) {
+		tokens := []model.RevokedAccessToken{}
+		for id := range revoked {
+			tokens = append(tokens, model.RevokedAccessToken{TokenID: id, ExpiresAt: now.Add(time.Minute)})
+		}
+		list := []model.AccessCutoff{}
+		for id, nb := range cutoffs {
+			if nb.After(since) {
+				list = append(list, model.AccessCutoff{UserID: id, NotBefore: nb})
+			}
+		}
+		return tokens, list, nil
+	}).AnyTimes()
+
+	return NewTokenService(ring, userRepo, refreshRepo, time.Minute, time.Hour)
+}
+
+func TestTokenService_CreateAndValidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := newTestTokenService(t, ctrl)
+
+	token := tokens.Create("u1", "testuser")
+	if token == "" {
+		t.Fatal("expected token to be issued")
+	}
+
+	sess, ok := tokens.Validate(token)
+	if !ok {
+		t.Fatal("expected token to be valid")
+	}
+	if sess.UserID != "u1" || sess.Login != "testuser" {
+		t.Fatalf("unexpected session identity: %+v", sess)
+	}
+	if len(sess.Roles) != 1 || sess.Roles[0] != "admin" {
+		t.Fatalf("expected roles [admin], got %v", sess.Roles)
+	}
+	if !sess.Stateless {
+		t.Fatal("expected session to be stateless")
+	}
+}
+
+func TestTokenService_Validate_Expired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := newTestTokenService(t, ctrl)
+	token := tokens.Create("u1", "testuser")
+
+	tokens.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+
+	if _, ok := tokens.Validate(token); ok {
+		t.Fatal("expected expired token to be rejected")
+	}
+}
+
+func TestTokenService_Validate_Tampered(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := newTestTokenService(t, ctrl)
+	token := tokens.Create("u1", "testuser")
+
+	// Первый символ подписи несёт 6 значащих бит, так что замена всегда меняет подпись
+	sig := strings.LastIndex(token, ".") + 1
+	swap := byte('A')
+	if token[sig] == swap {
+		swap = 'B'
+	}
+	tampered := token[:sig] + string(swap) + token[sig+1:]
+	if _, ok := tokens.Validate(tampered); ok {
+		t.Fatal("expected tampered token to be rejected")
+	}
+	if _, ok := tokens.Validate("not-a-token"); ok {
+		t.Fatal("expected garbage to be rejected")
+	}
+}
+
+func TestTokenService_KeyRotation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := newTestTokenService(t, ctrl)
+	oldToken := tokens.Create("u1", "testuser")
+
+	newKey, err := GenerateSigningKey("k2")
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	oldKey := tokens.keys.active
+
+	// Новый ключ подписывает, старый всё ещё проверяет
+	rotated, err := NewKeyRing(newKey, oldKey)
+	if err != nil {
+		t.Fatalf("failed to create key ring: %v", err)
+	}
+	tokens.keys = rotated
+	if _, ok := tokens.Validate(oldToken); !ok {
+		t.Fatal("expected token signed by retired key to remain valid")
+	}
+
+	// После удаления старого ключа его токены отвергаются
+	retired, _ := NewKeyRing(newKey)
+	tokens.keys = retired
+	if _, ok := tokens.Validate(oldToken); ok {
+		t.Fatal("expected token with unknown kid to be rejected")
+	}
+	if _, ok := tokens.Validate(tokens.Create("u1", "testuser")); !ok {
+		t.Fatal("expected token signed by new key to be valid")
+	}
+}
+
+func TestTokenService_Refresh_Rotates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := newTestTokenService(t, ctrl)
+
+	pair, err := tokens.CreatePair("u1", "testuser")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	next, err := tokens.Refresh(pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if next.RefreshToken == pair.RefreshToken {
+		t.Fatal("expected refresh token to be rotated")
+	}
+	if _, ok := tokens.Validate(next.AccessToken); !ok {
+		t.Fatal("expected refreshed access token to be valid")
+	}
+}
+
+func TestTokenService_Refresh_ReuseRevokesFamily(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := newTestTokenService(t, ctrl)
+
+	pair, _ := tokens.CreatePair("u1", "testuser")
+	next, err := tokens.Refresh(pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := tokens.Refresh(pair.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("expected ErrRefreshTokenReused, got %v", err)
+	}
+	// Украденная цепочка отозвана целиком, включая легитимный последний токен
+	if _, err := tokens.Refresh(next.RefreshToken); err == nil {
+		t.Fatal("expected rotated token to be revoked after reuse")
+	}
+}
+
+func TestTokenService_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := newTestTokenService(t, ctrl)
+	pair, _ := tokens.CreatePair("u1", "testuser")
+
+	if !tokens.Delete(pair.AccessToken) {
+		t.Fatal("expected access token to be revoked")
+	}
+	if _, ok := tokens.Validate(pair.AccessToken); ok {
+		t.Fatal("expected revoked access token to be rejected")
+	}
+	if tokens.Delete(pair.AccessToken) {
+		t.Fatal("expected second revoke to report false")
+	}
+
+	if !tokens.Delete(pair.RefreshToken) {
+		t.Fatal("expected refresh token family to be revoked")
+	}
+	if _, err := tokens.Refresh(pair.RefreshToken); err == nil {
+		t.Fatal("expected revoked refresh token to be rejected")
+	}
+}
+
+func TestParseSigningKeys(t *testing.T) {
+	keys, err := ParseSigningKeys("k1:AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=, k2:HxwdHh8AAQIDBAUGBwgJCgsMDQ4PEBESExQVFhcYGRo=")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != "k1" || keys[1].ID != "k2" {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+
+	if _, err := ParseSigningKeys("k1:c2hvcnQ="); err == nil {
+		t.Fatal("expected error for short seed")
+	}
+	if _, err := ParseSigningKeys("no-separator"); err == nil {
+		t.Fatal("expected error for malformed spec")
+	}
+}
+
+func TestTokenService_CreateImpersonation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := newTestTokenService(t, ctrl)
+
+	actor := model.Session{UserID: "admin1", Login: "adminuser"}
+	target := &model.User{ID: "u2", Login: "otheruser", Roles: []string{model.RoleAdmin}}
+
+	token := tokens.CreateImpersonation(actor, target, 30*time.Second)
+	sess, ok := tokens.Validate(token)
+	if !ok {
+		t.Fatal("expected impersonation token to be valid")
+	}
+	if sess.UserID != "u2" || sess.ImpersonatorID != "admin1" || sess.ImpersonatorLogin != "adminuser" {
+		t.Fatalf("unexpected session: %+v", sess)
+	}
+	if len(sess.Roles) != 0 {
+		t.Fatalf("expected admin role to be stripped, got %v", sess.Roles)
+	}
+
+	tokens.now = func() time.Time { return time.Now().Add(time.Minute) }
+	if _, ok := tokens.Validate(token); ok {
+		t.Fatal("expected impersonation token to expire with its ttl")
+	}
+}
+
+func TestTokenService_DeleteByUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := newTestTokenService(t, ctrl)
+	own := tokens.Create("u1", "testuser")
+	impersonation := tokens.CreateImpersonation(model.Session{UserID: "u1", Login: "testuser"}, &model.User{ID: "u2", Login: "otheruser"}, time.Minute)
+
+	tokens.DeleteByUser("u1")
+	if _, ok := tokens.Validate(own); ok {
+		t.Fatal("expected token of the user to be revoked")
+	}
+	if _, ok := tokens.Validate(impersonation); ok {
+		t.Fatal("expected impersonation by the user to be revoked")
+	}
+
+	// Токены, выданные после отзыва, действуют
+	tokens.now = func() time.Time { return time.Now().Add(2 * time.Second) }
+	if _, ok := tokens.Validate(tokens.Create("u1", "testuser")); !ok {
+		t.Fatal("expected a new token to be valid")
+	}
+}
+
+// Отзыв с другого экземпляра виден после перечитывания, а недоступная БД не сбрасывает
+// уже известный отзыв и не отвергает остальные токены
+func TestTokenService_LoadRevocations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := newTestTokenService(t, ctrl)
+	other := NewTokenService(tokens.keys, tokens.userRepo, tokens.refreshRepo, time.Minute, time.Hour)
+	pair, _ := tokens.CreatePair("u1", "testuser")
+	if !tokens.Delete(pair.AccessToken) {
+		t.Fatal("expected access token to be revoked")
+	}
+	if _, ok := other.Validate(pair.AccessToken); !ok {
+		t.Fatal("expected other instance to accept the token until it reloads revocations")
+	}
+	if err := other.LoadRevocations(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := other.Validate(pair.AccessToken); ok {
+		t.Fatal("expected other instance to reject the revoked token after reload")
+	}
+
+	refreshRepo := mocksgen.NewMockRefreshTokenRepositoryInterface(ctrl)
+	refreshRepo.EXPECT().ListAccessRevocations(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("db down"))
+	other.refreshRepo = refreshRepo
+	if err := other.LoadRevocations(); err == nil {
+		t.Fatal("expected load error")
+	}
+	if _, ok := other.Validate(pair.AccessToken); ok {
+		t.Fatal("expected revoked token to stay rejected")
+	}
+	if _, ok := other.Validate(tokens.Create("u1", "testuser")); !ok {
+		t.Fatal("expected other tokens to be accepted while revocations cannot be loaded")
+	}
+}
+
+// Отсечка действует, пока живы токены, которые она могла отсечь, в том числе
+// имперсонация дольше access-токена
+func TestTokenService_LoadRevocations_Window(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := newTestTokenService(t, ctrl)
+	impersonation := tokens.CreateImpersonation(model.Session{UserID: "u1", Login: "testuser"}, &model.User{ID: "u2", Login: "otheruser"}, time.Hour)
+	tokens.DeleteByUser("u1")
+
+	tokens.now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+	if err := tokens.LoadRevocations(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := tokens.Validate(impersonation); ok {
+		t.Fatal("expected cutoff to outlive the access token ttl while impersonation is alive")
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"astra-api/internal/storage"
+	"time"
+)
+
+// trashPurgeBatch — сколько документов окончательно удаляется за один запрос к БД
+const trashPurgeBatch = 100
+
+// TrashService обслуживает корзину: удалённые документы хранятся retention,
+// их можно восстановить или удалить окончательно вместе с файлами
+type TrashService struct {
+	docRepo   repository.DocumentRepositoryInterface
+	access    AccessServiceInterface
+	schemas   SchemaServiceInterface
+	blobs     storage.BlobStore
+	retention time.Duration
+}
+
+func NewTrashService(docRepo repository.DocumentRepositoryInterface, access AccessServiceInterface, schemas SchemaServiceInterface, blobs storage.BlobStore, retention time.Duration) *TrashService {
+	return &TrashService{docRepo: docRepo, access: access, schemas: schemas, blobs: blobs, retention: retention}
+}
+
+// List возвращает документы пользователя в корзине со временем окончательного удаления
+func (s *TrashService) List(userID string, limit int) ([]model.TrashEntry, error) {
+	docs, err := s.docRepo.ListTrash(userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]model.TrashEntry, len(docs))
+	for i := range docs {
+		entries[i] = model.TrashEntry{Document: docs[i], PurgeAt: docs[i].DeletedAt.Add(s.retention)}
+	}
+	return entries, nil
+}
+
+// Restore возвращает документ на прежнее место (уровень manage). JSON снова проверяется
+// схемами документа и папки: пока документ был в корзине, они могли измениться.
+// Если путь занят, возвращается ErrDocumentExists.
+func (s *TrashService) Restore(actorID, id string) (*model.Document, error) {
+	doc, err := s.load(actorID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.schemas.Validate(doc); err != nil {
+		return nil, err
+	}
+	ok, err := s.docRepo.Restore(id)
+	if err != nil {
+		return nil, documentExists(err)
+	}
+	if !ok {
+		return nil, ErrDocumentNotFound
+	}
+	doc.DeletedAt = nil
+	return doc, nil
+}
+
+// Purge окончательно удаляет документ из корзины (уровень manage)
+func (s *TrashService) Purge(actorID, id string) (*model.Document, error) {
+	doc, err := s.load(actorID, id)
+	if err != nil {
+		return nil, err
+	}
+	return doc, s.purge(doc)
+}
+
+// PurgeExpired окончательно удаляет документы, пролежавшие в корзине дольше retention
+func (s *TrashService) PurgeExpired(now time.Time) (int, error) {
+	purged := 0
+	for {
+		docs, err := s.docRepo.ListTrashedBefore(now.Add(-s.retention), trashPurgeBatch)
+		if err != nil {
+			return purged, err
+		}
+		for i := range docs {
+			if err := s.purge(&docs[i]); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+		if len(docs) < trashPurgeBatch {
+			return purged, nil
+		}
+	}
+}
+
+// purge удаляет файл документа, затем строку: если удаление строки не удастся,
+// следующий запуск повторит всё заново
+func (s *TrashService) purge(doc *model.Document) error {
+	if doc.File {
+		if err := s.blobs.Remove(doc.StorageKey()); err != nil {
+			return err
+		}
+	}
+	return s.docRepo.Delete(doc.ID)
+}
+
+// load читает документ из корзины и требует уровень manage; тем, кто не может
+// читать документ, он не виден
+func (s *TrashService) load(actorID, id string) (*model.Document, error) {
+	doc, err := s.docRepo.GetTrashed(id)
+	if err != nil {
+		return nil, ErrDocumentNotFound
+	}
+	level, err := s.access.Permission(actorID, doc)
+	if err != nil {
+		return nil, err
+	}
+	if level == "" {
+		return nil, ErrDocumentNotFound
+	}
+	if !level.Includes(model.PermissionManage) {
+		return nil, ErrForbidden
+	}
+	return doc, nil
+}
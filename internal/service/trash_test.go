@@ -0,0 +1,146 @@
+package service
+
+import (
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"astra-api/internal/storage"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func newTestTrashService(t *testing.T, ctrl *gomock.Controller) (*TrashService, *mocksgen.MockDocumentRepositoryInterface, *mocksgen.MockAccessServiceInterface, *mocksgen.MockSchemaServiceInterface, storage.BlobStore) {
+	blobs, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("cannot create blob store: %v", err)
+	}
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	access := mocksgen.NewMockAccessServiceInterface(ctrl)
+	schemas := mocksgen.NewMockSchemaServiceInterface(ctrl)
+	return NewTrashService(docRepo, access, schemas, blobs, 24*time.Hour), docRepo, access, schemas, blobs
+}
+
+func TestTrashService_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trash, docRepo, _, _, _ := newTestTrashService(t, ctrl)
+	deleted := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	docRepo.EXPECT().ListTrash("u1", 20).Return([]model.Document{{ID: "d1", DeletedAt: &deleted}}, nil)
+
+	entries, err := trash.List("u1", 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || !entries[0].PurgeAt.Equal(deleted.Add(24*time.Hour)) {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+}
+
+func TestTrashService_Restore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trash, docRepo, access, schemas, _ := newTestTrashService(t, ctrl)
+	deleted := time.Now()
+	doc := &model.Document{ID: "d1", Owner: "u1", DeletedAt: &deleted}
+	docRepo.EXPECT().GetTrashed("d1").Return(doc, nil).Times(3)
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionManage, nil).Times(3)
+	schemas.EXPECT().Validate(doc).Return(nil).Times(3)
+	docRepo.EXPECT().Restore("d1").Return(true, nil)
+	docRepo.EXPECT().Restore("d1").Return(false, repository.ErrDuplicate)
+	docRepo.EXPECT().Restore("d1").Return(false, nil)
+
+	restored, err := trash.Restore("u1", "d1")
+	if err != nil || restored.DeletedAt != nil {
+		t.Fatalf("expected restored document, got %+v, %v", restored, err)
+	}
+	doc.DeletedAt = &deleted
+	if _, err := trash.Restore("u1", "d1"); !errors.Is(err, ErrDocumentExists) {
+		t.Fatalf("expected ErrDocumentExists, got %v", err)
+	}
+	if _, err := trash.Restore("u1", "d1"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Fatalf("expected ErrDocumentNotFound for concurrent restore, got %v", err)
+	}
+}
+
+func TestTrashService_Restore_Rejects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trash, docRepo, access, schemas, _ := newTestTrashService(t, ctrl)
+	doc := &model.Document{ID: "d1", Owner: "u1"}
+	docRepo.EXPECT().GetTrashed("missing").Return(nil, sql.ErrNoRows)
+	docRepo.EXPECT().GetTrashed("d1").Return(doc, nil).Times(3)
+	access.EXPECT().Permission("u2", doc).Return(model.Permission(""), nil)
+	access.EXPECT().Permission("u3", doc).Return(model.PermissionWrite, nil)
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionManage, nil)
+	schemas.EXPECT().Validate(doc).Return(errors.New("document does not match schema"))
+
+	if _, err := trash.Restore("u1", "missing"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Fatalf("expected ErrDocumentNotFound, got %v", err)
+	}
+	if _, err := trash.Restore("u2", "d1"); !errors.Is(err, ErrDocumentNotFound) {
+		t.Fatalf("expected ErrDocumentNotFound for unreadable document, got %v", err)
+	}
+	if _, err := trash.Restore("u3", "d1"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if _, err := trash.Restore("u1", "d1"); err == nil || !strings.Contains(err.Error(), "schema") {
+		t.Fatalf("expected schema error, got %v", err)
+	}
+}
+
+func TestTrashService_Purge_RemovesBlob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trash, docRepo, access, _, blobs := newTestTrashService(t, ctrl)
+	if _, err := blobs.Put("d1", strings.NewReader("content")); err != nil {
+		t.Fatalf("cannot write blob: %v", err)
+	}
+	doc := &model.Document{ID: "d1", Owner: "u1", File: true}
+	docRepo.EXPECT().GetTrashed("d1").Return(doc, nil)
+	access.EXPECT().Permission("u1", doc).Return(model.PermissionManage, nil)
+	docRepo.EXPECT().Delete("d1").Return(nil)
+
+	if _, err := trash.Purge("u1", "d1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := blobs.Open("d1"); err == nil {
+		t.Fatal("expected blob to be removed")
+	}
+}
+
+func TestTrashService_PurgeExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trash, docRepo, _, _, blobs := newTestTrashService(t, ctrl)
+	now := time.Now()
+	batch := make([]model.Document, trashPurgeBatch)
+	for i := range batch {
+		batch[i] = model.Document{ID: "json" + strings.Repeat("x", i)}
+	}
+	if _, err := blobs.Put("f1", strings.NewReader("content")); err != nil {
+		t.Fatalf("cannot write blob: %v", err)
+	}
+	gomock.InOrder(
+		docRepo.EXPECT().ListTrashedBefore(now.Add(-24*time.Hour), trashPurgeBatch).Return(batch, nil),
+		docRepo.EXPECT().ListTrashedBefore(now.Add(-24*time.Hour), trashPurgeBatch).Return([]model.Document{{ID: "f1", File: true}}, nil),
+	)
+	docRepo.EXPECT().Delete(gomock.Any()).Return(nil).Times(trashPurgeBatch + 1)
+
+	n, err := trash.PurgeExpired(now)
+	if err != nil || n != trashPurgeBatch+1 {
+		t.Fatalf("expected %d purged, got %d, %v", trashPurgeBatch+1, n, err)
+	}
+	if _, err := blobs.Open("f1"); err == nil {
+		t.Fatal("expected blob to be removed")
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"astra-api/internal/storage"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	ErrInvalidUpload  = errors.New("invalid upload request")
+	ErrUploadNotFound = errors.New("upload url is invalid or already used")
+	ErrUploadExpired  = errors.New("upload url expired")
+	ErrUploadSize     = errors.New("upload size does not match reservation")
+)
+
+type uploadClaims struct {
+	ID        string `json:"rid"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// UploadService выдаёт подписанные ссылки на загрузку файла и принимает по ним файлы.
+// Документ создаётся вместе со ссылкой, но скрыт до загрузки: он сразу занимает путь
+// и заявленное место в квоте. Сама загрузка сессии не требует.
+type UploadService struct {
+	uploadRepo repository.UploadReservationRepositoryInterface
+	docRepo    repository.DocumentRepositoryInterface
+	blobs      storage.BlobStore
+	quota      QuotaServiceInterface
+	mime       MimeServiceInterface
+	scans      ScanServiceInterface
+	compress   CompressionServiceInterface
+	tx         repository.TxManagerInterface
+	signer     urlSigner
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+func NewUploadService(uploadRepo repository.UploadReservationRepositoryInterface, docRepo repository.DocumentRepositoryInterface, blobs storage.BlobStore, quota QuotaServiceInterface, mime MimeServiceInterface, scans ScanServiceInterface, compress CompressionServiceInterface, tx repository.TxManagerInterface, secret []byte, ttl time.Duration) *UploadService {
+	return &UploadService{uploadRepo: uploadRepo, docRepo: docRepo, blobs: blobs, quota: quota, mime: mime, scans: scans, compress: compress, tx: tx, signer: newURLSigner(secret, "upload"), ttl: ttl, now: time.Now}
+}
+
+// Reserve создаёт скрытый документ doc, ожидающий файла, и возвращает токен для его
+// загрузки. doc уже проверен и размещён вызывающим (папка, схема, гранты); size —
+// заявленный размер файла, он сразу учитывается в квоте.
+func (s *UploadService) Reserve(doc *model.Document, size *int64) (*model.UploadReservation, string, error) {
+	if doc.Name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", ErrInvalidUpload)
+	}
+	if !model.ValidName(doc.Name) {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidUpload, ErrInvalidDocumentName)
+	}
+	if size != nil && *size < 0 {
+		return nil, "", fmt.Errorf("%w: size must not be negative", ErrInvalidUpload)
+	}
+	// Заявленный размер проверяем сразу, чтобы не выдавать заведомо бесполезную ссылку
+	var reserved int64
+	if size != nil {
+		reserved = *size
+	}
+	if err := s.quota.Check(doc.Owner, 1, reserved, 0); err != nil {
+		return nil, "", err
+	}
+	now := s.now()
+	res := &model.UploadReservation{
+		ID:                doc.ID,
+		Owner:             doc.Owner,
+		Name:              doc.Name,
+		Mime:              doc.Mime,
+		Size:              size,
+		DocumentExpiresAt: doc.ExpiresAt,
+		ExpiresAt:         now.Add(s.ttl),
+		CreatedAt:         now,
+	}
+	token, err := s.signer.sign(uploadClaims{ID: res.ID, ExpiresAt: res.ExpiresAt.Unix()})
+	if err != nil {
+		return nil, "", err
+	}
+	// До загрузки срок жизни документа — срок ссылки с запасом на передачу файла:
+	// брошенный резерв удалит очистка истёкших документов
+	pendingUntil := res.ExpiresAt.Add(s.ttl)
+	pending := *doc
+	pending.File = true
+	pending.UploadPending = true
+	pending.CreatedAt = now
+	pending.ExpiresAt = &pendingUntil
+	pending.Size = &reserved
+	err = s.tx.InTx(func(tx *sql.Tx) error {
+		if err := s.docRepo.CreateTx(tx, &pending); err != nil {
+			return documentExists(err)
+		}
+		return s.uploadRepo.CreateTx(tx, res)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return res, token, nil
+}
+
+// Complete сохраняет файл по токену загрузки и открывает документ. Резерв одноразовый:
+// после неудачной загрузки документ удаляется и нужна новая ссылка.
+func (s *UploadService) Complete(token string, body io.Reader, contentType string) (*model.Document, error) {
+	var claims uploadClaims
+	if err := s.signer.verify(token, &claims); err != nil {
+		return nil, ErrUploadNotFound
+	}
+	now := s.now()
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrUploadExpired
+	}
+	res, err := s.uploadRepo.Take(claims.ID, now)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.complete(res, body, contentType, now)
+	if err != nil {
+		// Освобождаем путь и место в квоте, занятые резервом
+		_ = s.docRepo.Delete(res.ID)
+		_ = s.blobs.Remove(res.ID)
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (s *UploadService) complete(res *model.UploadReservation, body io.Reader, contentType string, now time.Time) (*model.Document, error) {
+	doc := &model.Document{
+		ID:        res.ID,
+		Name:      res.Name,
+		Mime:      res.Mime,
+		File:      true,
+		Owner:     res.Owner,
+		CreatedAt: now,
+		ExpiresAt: res.DocumentExpiresAt,
+	}
+	if doc.Mime == "" {
+		doc.Mime = contentType
+	}
+	// Заявленный размер уже учтён в квоте ожидающим документом
+	var reserved int64
+	if res.Size != nil {
+		reserved = *res.Size
+		// Читаем на байт больше заявленного, чтобы заметить превышение
+		body = io.LimitReader(body, *res.Size+1)
+	}
+	body, err := s.mime.Inspect(doc, body)
+	if err != nil {
+		return nil, err
+	}
+	body, err = s.quota.Reader(doc.Owner, 0, reserved, body)
+	if err != nil {
+		return nil, err
+	}
+	packed, err := s.compress.Pack(doc, body)
+	if err != nil {
+		return nil, err
+	}
+	n, err := s.blobs.Put(doc.ID, packed)
+	if err != nil {
+		return nil, err
+	}
+	size := packed.Size()
+	doc.Size, doc.StoredSize = &size, &n
+	if res.Size != nil && size != *res.Size {
+		return nil, ErrUploadSize
+	}
+	s.scans.Queue(doc)
+	ok, err := s.docRepo.CompleteUpload(doc)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Ожидающий документ уже удалён очисткой
+		return nil, ErrUploadExpired
+	}
+	// Недоступный сканер не мешает загрузке: файл проверит фоновая задача
+	_ = s.scans.Scan(doc)
+	return doc, nil
+}
+
+// PurgeExpired удаляет неиспользованные резервы с истёкшим сроком
+func (s *UploadService) PurgeExpired(now time.Time) (int64, error) {
+	return s.uploadRepo.DeleteExpired(now)
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"astra-api/internal/compress"
+	mocksgen "astra-api/internal/mocks/gomock"
+	"astra-api/internal/model"
+	"astra-api/internal/repository"
+	"astra-api/internal/storage"
+	"database/sql"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func newTestUploadService(t *testing.T, ctrl *gomock.Controller) (*UploadService, *mocksgen.MockUploadReservationRepositoryInterface, *mocksgen.MockDocumentRepositoryInterface, storage.BlobStore) {
+	blobs, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("cannot create blob store: %v", err)
+	}
+	uploadRepo := mocksgen.NewMockUploadReservationRepositoryInterface(ctrl)
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	return NewUploadService(uploadRepo, docRepo, blobs, newUnlimitedQuota(ctrl), NewMimeService(nil, nil, MimeMismatchReject), newNopScans(ctrl), newNopCompression(), newInlineTx(ctrl), []byte("secret"), time.Minute), uploadRepo, docRepo, blobs
+}
+
+// expectReserve ожидает создание скрытого документа и резерва и возвращает сохранённый резерв
+func expectReserve(uploadRepo *mocksgen.MockUploadReservationRepositoryInterface, docRepo *mocksgen.MockDocumentRepositoryInterface) **model.UploadReservation {
+	var stored *model.UploadReservation
+	docRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any()).Return(nil)
+	uploadRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ *sql.Tx, res *model.UploadReservation) error {
+		stored = res
+		return nil
+	})
+	return &stored
+}
+
+func TestUploadService_ReserveAndComplete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uploads, uploadRepo, docRepo, blobs := newTestUploadService(t, ctrl)
+
+	expires := time.Now().Add(24 * time.Hour)
+	folder := "f1"
+	size := int64(5)
+	var stored *model.UploadReservation
+	// Документ создаётся сразу со всеми метаданными, но скрытым: он занимает путь и место
+	docRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ *sql.Tx, doc *model.Document) error {
+		if !doc.UploadPending || !doc.File || doc.FolderID == nil || *doc.FolderID != "f1" || len(doc.Tags) != 1 || len(doc.Grants) != 1 {
+			t.Fatalf("unexpected pending document: %+v", doc)
+		}
+		if doc.Size == nil || *doc.Size != 5 || doc.ExpiresAt == nil || !doc.ExpiresAt.Before(expires) {
+			t.Fatalf("expected reserved size and reservation expiry, got %+v", doc)
+		}
+		return nil
+	})
+	uploadRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ *sql.Tx, res *model.UploadReservation) error {
+		stored = res
+		return nil
+	})
+	doc := &model.Document{
+		ID:        "d1",
+		Name:      "report.txt",
+		Owner:     "u1",
+		FolderID:  &folder,
+		Tags:      []string{"q3"},
+		ExpiresAt: &expires,
+		Grants:    []model.Grant{{Principal: "group:g1", Permission: model.PermissionRead}},
+	}
+	res, token, err := uploads.Reserve(doc, &size)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	uploadRepo.EXPECT().Take(res.ID, gomock.Any()).Return(stored, nil)
+	docRepo.EXPECT().CompleteUpload(gomock.Any()).DoAndReturn(func(doc *model.Document) (bool, error) {
+		if doc.ID != "d1" || doc.Mime != "text/plain" || doc.Size == nil || *doc.Size != 5 {
+			t.Fatalf("unexpected document: %+v", doc)
+		}
+		if doc.ExpiresAt == nil || !doc.ExpiresAt.Equal(expires) {
+			t.Fatalf("expected requested expiry after upload, got %v", doc.ExpiresAt)
+		}
+		return true, nil
+	})
+
+	if _, err := uploads.Complete(token, strings.NewReader("hello"), "text/plain"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	f, err := blobs.Open(res.ID)
+	if err != nil {
+		t.Fatalf("expected blob to be stored, got %v", err)
+	}
+	defer f.Close()
+	if data, _ := io.ReadAll(f); string(data) != "hello" {
+		t.Fatalf("unexpected blob content %q", data)
+	}
+}
+
+func TestUploadService_Reserve_NameTaken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uploads, _, docRepo, _ := newTestUploadService(t, ctrl)
+
+	docRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any()).Return(repository.ErrDuplicate)
+	if _, _, err := uploads.Reserve(&model.Document{ID: "d1", Name: "a.txt", Owner: "u1"}, nil); !errors.Is(err, ErrDocumentExists) {
+		t.Fatalf("expected ErrDocumentExists, got %v", err)
+	}
+}
+
+func TestUploadService_Complete_Rejects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uploads, uploadRepo, docRepo, blobs := newTestUploadService(t, ctrl)
+
+	if _, err := uploads.Complete("garbage", strings.NewReader("x"), ""); !errors.Is(err, ErrUploadNotFound) {
+		t.Fatalf("expected ErrUploadNotFound for bad token, got %v", err)
+	}
+
+	expired, _ := uploads.signer.sign(uploadClaims{ID: "r1", ExpiresAt: time.Now().Add(-time.Second).Unix()})
+	if _, err := uploads.Complete(expired, strings.NewReader("x"), ""); !errors.Is(err, ErrUploadExpired) {
+		t.Fatalf("expected ErrUploadExpired, got %v", err)
+	}
+
+	used, _ := uploads.signer.sign(uploadClaims{ID: "r2", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+	uploadRepo.EXPECT().Take("r2", gomock.Any()).Return(nil, sql.ErrNoRows)
+	if _, err := uploads.Complete(used, strings.NewReader("x"), ""); !errors.Is(err, ErrUploadNotFound) {
+		t.Fatalf("expected ErrUploadNotFound for used reservation, got %v", err)
+	}
+
+	size := int64(3)
+	sized, _ := uploads.signer.sign(uploadClaims{ID: "r3", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+	uploadRepo.EXPECT().Take("r3", gomock.Any()).Return(&model.UploadReservation{ID: "r3", Owner: "u1", Size: &size}, nil)
+	// Неудачная загрузка освобождает путь и место, занятые документом
+	docRepo.EXPECT().Delete("r3").Return(nil)
+	if _, err := uploads.Complete(sized, strings.NewReader("too long"), ""); !errors.Is(err, ErrUploadSize) {
+		t.Fatalf("expected ErrUploadSize, got %v", err)
+	}
+	if _, err := blobs.Open("r3"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected oversized blob to be removed, got %v", err)
+	}
+
+	// Документ удалён очисткой, пока шла загрузка
+	late, _ := uploads.signer.sign(uploadClaims{ID: "r4", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+	uploadRepo.EXPECT().Take("r4", gomock.Any()).Return(&model.UploadReservation{ID: "r4", Owner: "u1"}, nil)
+	docRepo.EXPECT().CompleteUpload(gomock.Any()).Return(false, nil)
+	docRepo.EXPECT().Delete("r4").Return(nil)
+	if _, err := uploads.Complete(late, strings.NewReader("x"), ""); !errors.Is(err, ErrUploadExpired) {
+		t.Fatalf("expected ErrUploadExpired, got %v", err)
+	}
+	if _, err := blobs.Open("r4"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected orphan blob to be removed, got %v", err)
+	}
+}
+
+func TestUploadService_Quota(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blobs, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("cannot create blob store: %v", err)
+	}
+	uploadRepo := mocksgen.NewMockUploadReservationRepositoryInterface(ctrl)
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	quotaRepo := mocksgen.NewMockQuotaRepositoryInterface(ctrl)
+	usage := &model.Usage{Bytes: 3}
+	quotaRepo.EXPECT().GetUsage("u1").DoAndReturn(func(string) (*model.Usage, error) { return usage, nil }).AnyTimes()
+	quotaRepo.EXPECT().GetOverride("u1").Return(nil, sql.ErrNoRows).AnyTimes()
+	quota := NewQuotaService(quotaRepo, nil, nil, model.QuotaLimits{MaxBytes: 10, MaxFileSize: 8})
+	uploads := NewUploadService(uploadRepo, docRepo, blobs, quota, NewMimeService(nil, nil, MimeMismatchReject), newNopScans(ctrl), newNopCompression(), newInlineTx(ctrl), []byte("secret"), time.Minute)
+
+	// Заявленный размер больше лимита файла: ссылка не выдаётся
+	size := int64(9)
+	if _, _, err := uploads.Reserve(&model.Document{ID: "d0", Name: "big.bin", Owner: "u1"}, &size); !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("expected ErrFileTooLarge, got %v", err)
+	}
+
+	size = 4
+	stored := expectReserve(uploadRepo, docRepo)
+	res, token, err := uploads.Reserve(&model.Document{ID: "d1", Name: "a.bin", Owner: "u1"}, &size)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// Резерв уже учтён в использовании: загрузка заявленного размера проходит
+	usage.Bytes = 7
+	uploadRepo.EXPECT().Take(res.ID, gomock.Any()).Return(*stored, nil)
+	docRepo.EXPECT().CompleteUpload(gomock.Any()).Return(true, nil)
+	if _, err := uploads.Complete(token, strings.NewReader("1234"), ""); err != nil {
+		t.Fatalf("expected reserved upload to fit, got %v", err)
+	}
+
+	// Без заявленного размера свободно 3 байта: загрузка обрывается, документ удаляется
+	stored = expectReserve(uploadRepo, docRepo)
+	res, token, err = uploads.Reserve(&model.Document{ID: "d2", Name: "b.bin", Owner: "u1"}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	uploadRepo.EXPECT().Take(res.ID, gomock.Any()).Return(*stored, nil)
+	docRepo.EXPECT().Delete("d2").Return(nil)
+	if _, err := uploads.Complete(token, strings.NewReader("hello"), ""); !errors.Is(err, ErrStorageQuota) {
+		t.Fatalf("expected ErrStorageQuota, got %v", err)
+	}
+	if _, err := blobs.Open(res.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected no blob, got %v", err)
+	}
+}
+
+func TestUploadService_Complete_Compressed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blobs, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("cannot create blob store: %v", err)
+	}
+	uploadRepo := mocksgen.NewMockUploadReservationRepositoryInterface(ctrl)
+	docRepo := mocksgen.NewMockDocumentRepositoryInterface(ctrl)
+	uploads := NewUploadService(uploadRepo, docRepo, blobs, newUnlimitedQuota(ctrl), NewMimeService(nil, nil, MimeMismatchReject), newNopScans(ctrl), NewCompressionService(compress.Policy{MaxRatio: 0.9}), newInlineTx(ctrl), []byte("secret"), time.Minute)
+
+	text := strings.Repeat("line of a log file\n", 1000)
+	stored := expectReserve(uploadRepo, docRepo)
+	size := int64(len(text))
+	res, token, err := uploads.Reserve(&model.Document{ID: "d1", Name: "app.log", Owner: "u1"}, &size)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	uploadRepo.EXPECT().Take(res.ID, gomock.Any()).Return(*stored, nil)
+	docRepo.EXPECT().CompleteUpload(gomock.Any()).Return(true, nil)
+
+	// Заявленный размер сверяется с исходным, а не со сжатым
+	doc, err := uploads.Complete(token, strings.NewReader(text), "text/plain")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if doc.Encoding != compress.Gzip || *doc.Size != size || *doc.StoredSize >= size/5 {
+		t.Fatalf("unexpected document: encoding %q, size %d, stored %d", doc.Encoding, *doc.Size, *doc.StoredSize)
+	}
+	f, err := OpenContent(blobs, doc)
+	if err != nil {
+		t.Fatalf("cannot open content: %v", err)
+	}
+	defer f.Close()
+	if data, _ := io.ReadAll(f); string(data) != text {
+		t.Fatal("stored file does not decompress to the upload")
+	}
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunEvery вызывает job раз в interval, пока не отменён ctx. Ошибки только
+// логируются: фоновые задачи идемпотентны и доделают работу на следующем шаге.
+func RunEvery(ctx context.Context, interval time.Duration, name string, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job(); err != nil {
+				log.Printf("%s: %v", name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package sniff определяет MIME-тип файла по первым байтам: http.DetectContentType
+// и сигнатуры форматов, которых он не знает. Здесь же — совместимость заявленного
+// типа с найденным, списки разрешённых типов и типы, которые нельзя показывать в браузере.
+package sniff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// HeaderSize — сколько первых байт нужно для определения типа
+const HeaderSize = 512
+
+// Unknown — тип содержимого, которое не удалось опознать
+const Unknown = "application/octet-stream"
+
+// Peek читает до HeaderSize первых байт r и возвращает их вместе с читателем,
+// который отдаёт поток целиком, начиная с этих байт
+func Peek(r io.Reader) ([]byte, io.Reader, error) {
+	head := make([]byte, HeaderSize)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, nil, err
+	}
+	head = head[:n]
+	return head, io.MultiReader(bytes.NewReader(head), r), nil
+}
+
+type signature struct {
+	offset int
+	magic  string
+	mime   string
+}
+
+// signatures — форматы, которые http.DetectContentType не различает
+var signatures = []signature{
+	{0, "\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1", "application/x-ole-storage"},
+	{0, "7z\xBC\xAF\x27\x1C", "application/x-7z-compressed"},
+	{0, "\xFD7zXZ\x00", "application/x-xz"},
+	{0, "\x28\xB5\x2F\xFD", "application/zstd"},
+	{0, "\x7FELF", "application/x-elf"},
+	{0, "MZ", "application/vnd.microsoft.portable-executable"},
+	{0, "\xFE\xED\xFA\xCE", "application/x-mach-binary"},
+	{0, "\xFE\xED\xFA\xCF", "application/x-mach-binary"},
+	{0, "\xCE\xFA\xED\xFE", "application/x-mach-binary"},
+	{0, "\xCF\xFA\xED\xFE", "application/x-mach-binary"},
+	{0, "SQLite format 3\x00", "application/vnd.sqlite3"},
+	{4, "ftypheic", "image/heic"},
+	{4, "ftypheix", "image/heic"},
+	{4, "ftypmif1", "image/heif"},
+	{4, "ftypavif", "image/avif"},
+}
+
+// Detect возвращает MIME-тип содержимого без параметров; Unknown, если тип не опознан
+func Detect(head []byte) string {
+	for _, s := range signatures {
+		if len(head) >= s.offset+len(s.magic) && string(head[s.offset:s.offset+len(s.magic)]) == s.magic {
+			return s.mime
+		}
+	}
+	// За BZh идёт размер блока 1-9: так текст, начинающийся с «BZh», не примется за архив
+	if len(head) >= 4 && string(head[:3]) == "BZh" && head[3] >= '1' && head[3] <= '9' {
+		return "application/x-bzip2"
+	}
+	detected := Normalize(http.DetectContentType(head))
+	switch detected {
+	case "application/zip":
+		if m := zipMimetype(head); m != "" {
+			return m
+		}
+	case "text/xml", "text/plain":
+		if bytes.Contains(bytes.ToLower(head), []byte("<svg")) {
+			return "image/svg+xml"
+		}
+	}
+	return detected
+}
+
+// zipMimetype читает тип из несжатого первого файла архива mimetype — так устроены
+// EPUB и OpenDocument
+func zipMimetype(head []byte) string {
+	const fixed = 30
+	if len(head) < fixed || binary.LittleEndian.Uint16(head[8:]) != 0 {
+		return ""
+	}
+	nameLen := int(binary.LittleEndian.Uint16(head[26:]))
+	start := fixed + nameLen + int(binary.LittleEndian.Uint16(head[28:]))
+	if start > len(head) || string(head[fixed:fixed+nameLen]) != "mimetype" {
+		return ""
+	}
+	data := head[start:]
+	if size := int(binary.LittleEndian.Uint32(head[18:])); size > 0 && size <= len(data) {
+		data = data[:size]
+	} else if end := bytes.Index(data, []byte("PK")); end >= 0 {
+		// Размер записан после данных: они заканчиваются следующей сигнатурой
+		data = data[:end]
+	}
+	m := Normalize(string(data))
+	if !strings.Contains(m, "/") || strings.ContainsAny(m, " \x00") {
+		return ""
+	}
+	return m
+}
+
+// Normalize приводит MIME-тип к нижнему регистру без параметров и заменяет синонимы
+func Normalize(s string) string {
+	s = strings.TrimSpace(s)
+	if t, _, err := mime.ParseMediaType(s); err == nil {
+		s = t
+	}
+	s = strings.ToLower(s)
+	if alias, ok := aliases[s]; ok {
+		return alias
+	}
+	return s
+}
+
+var aliases = map[string]string{
+	"image/jpg":                    "image/jpeg",
+	"image/pjpeg":                  "image/jpeg",
+	"image/x-png":                  "image/png",
+	"image/x-icon":                 "image/vnd.microsoft.icon",
+	"application/x-gzip":           "application/gzip",
+	"application/x-zip-compressed": "application/zip",
+	"application/x-pdf":            "application/pdf",
+	"audio/mp3":                    "audio/mpeg",
+	"audio/x-wav":                  "audio/wave",
+	"audio/wav":                    "audio/wave",
+	"application/x-javascript":     "text/javascript",
+	"application/javascript":       "text/javascript",
+	"application/x-msdownload":     "application/vnd.microsoft.portable-executable",
+	"application/x-dosexec":        "application/vnd.microsoft.portable-executable",
+	"application/x-executable":     "application/x-elf",
+}
+
+// containers — контейнерные форматы и типы, которые в них хранятся
+var containers = map[string][]string{
+	"application/zip": {
+		"application/vnd.openxmlformats-officedocument.", "application/vnd.oasis.opendocument.",
+		"application/epub+zip", "application/java-archive", "application/vnd.android.package-archive",
+		"application/x-xpinstall", "application/vnd.ms-xpsdocument", "+zip",
+	},
+	"application/x-ole-storage": {
+		"application/msword", "application/vnd.ms-excel", "application/vnd.ms-powerpoint",
+		"application/vnd.ms-outlook", "application/x-msi", "application/vnd.visio",
+	},
+	"application/gzip": {"application/x-tar", "application/x-gtar", "application/x-compressed-tar", "+gzip"},
+	"text/xml":         {"application/xml", "+xml"},
+	"video/mp4": {
+		"video/", "audio/mp4", "audio/x-m4a", "audio/aac",
+	},
+}
+
+// Compatible сообщает, что содержимое с типом detected (результат Detect) может быть
+// тем, что заявил клиент. Заявленный application/octet-stream и неопознанное
+// содержимое совместимы с любым типом.
+func Compatible(declared, detected string) bool {
+	declared, detected = Normalize(declared), Normalize(detected)
+	if declared == "" || declared == detected || declared == Unknown || detected == Unknown {
+		return true
+	}
+	switch {
+	case detected == "text/plain":
+		// Текст без явных признаков формата: CSV, JSON, YAML, исходники
+		return Textual(declared) && declared != "text/html" && declared != "application/xhtml+xml"
+	case detected == "text/html":
+		return declared == "application/xhtml+xml"
+	}
+	for _, p := range containers[detected] {
+		if strings.HasPrefix(declared, p) || strings.HasPrefix(p, "+") && strings.HasSuffix(declared, p) {
+			return true
+		}
+	}
+	return false
+}
+
+var textualApplications = map[string]bool{
+	"application/json": true, "application/xml": true, "application/yaml": true, "application/x-yaml": true,
+	"application/x-ndjson": true, "application/ndjson": true, "application/jsonl": true, "application/toml": true,
+	"application/sql": true, "application/x-sh": true, "application/x-httpd-php": true, "application/graphql": true,
+	"application/rtf": true, "application/x-tex": true, "application/xhtml+xml": true,
+}
+
+// Textual сообщает, что тип — текстовый: text/*, JSON, XML, YAML и производные от них
+func Textual(m string) bool {
+	m = Normalize(m)
+	return strings.HasPrefix(m, "text/") || textualApplications[m] ||
+		strings.HasSuffix(m, "+json") || strings.HasSuffix(m, "+xml") || strings.HasSuffix(m, "+yaml")
+}
+
+// Risky сообщает, что тип нельзя показывать в браузере: HTML, SVG, XML, скрипты и всё,
+// что браузер может исполнить. Безопасны только изображения (кроме SVG), аудио,
+// видео и простой текст — их можно отдавать inline.
+func Risky(m string) bool {
+	m = Normalize(m)
+	switch {
+	case m == "image/svg+xml":
+		return true
+	case strings.HasPrefix(m, "image/"), strings.HasPrefix(m, "audio/"), strings.HasPrefix(m, "video/"):
+		return false
+	case m == "text/plain", m == "text/csv", m == "application/json":
+		return false
+	}
+	return true
+}
+
+// List — список MIME-типов и шаблонов вида image/*
+type List []string
+
+// ParseList разбирает список через запятую; пустая строка — пустой список
+func ParseList(s string) List {
+	var l List
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			l = append(l, p)
+		}
+	}
+	return l
+}
+
+// Match сообщает, подходит ли тип под один из шаблонов списка
+func (l List) Match(m string) bool {
+	m = Normalize(m)
+	for _, p := range l {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok && strings.HasSuffix(prefix, "/") {
+			if prefix == "*/" || strings.HasPrefix(m, prefix) {
+				return true
+			}
+		} else if Normalize(p) == m {
+			return true
+		}
+	}
+	return false
+}
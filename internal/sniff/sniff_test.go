@@ -0,0 +1,126 @@
+package sniff
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	var epub bytes.Buffer
+	zw := zip.NewWriter(&epub)
+	w, _ := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	_, _ = w.Write([]byte("application/epub+zip"))
+	_ = zw.Close()
+
+	cases := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), "image/png"},
+		{"html", []byte("<!DOCTYPE html><html><body>hi"), "text/html"},
+		{"svg", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`), "image/svg+xml"},
+		{"bare svg", []byte(`<svg onload="alert(1)"/>`), "image/svg+xml"},
+		{"text", []byte("name,count\nalpha,1\n"), "text/plain"},
+		{"gzip", []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00"), "application/gzip"},
+		{"ole", []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1\x00\x00"), "application/x-ole-storage"},
+		{"elf", []byte("\x7FELF\x02\x01\x01"), "application/x-elf"},
+		{"pe", []byte("MZ\x90\x00\x03\x00"), "application/vnd.microsoft.portable-executable"},
+		{"bzip2", []byte("BZh91AY&SY"), "application/x-bzip2"},
+		{"not bzip2", []byte("BZhello world"), "text/plain"},
+		{"heic", []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00"), "image/heic"},
+		{"epub", epub.Bytes(), "application/epub+zip"},
+		{"binary", []byte{0x00, 0x01, 0x02, 0x03}, Unknown},
+	}
+	for _, c := range cases {
+		if got := Detect(c.head); got != c.want {
+			t.Errorf("%s: expected %s, got %s", c.name, c.want, got)
+		}
+	}
+}
+
+func TestPeek(t *testing.T) {
+	body := strings.Repeat("x", HeaderSize+100)
+	head, r, err := Peek(strings.NewReader(body))
+	if err != nil || len(head) != HeaderSize {
+		t.Fatalf("unexpected head of %d bytes, %v", len(head), err)
+	}
+	if all, _ := io.ReadAll(r); string(all) != body {
+		t.Fatalf("expected the whole body to be readable after Peek")
+	}
+	head, r, err = Peek(strings.NewReader("short"))
+	if err != nil || string(head) != "short" {
+		t.Fatalf("unexpected head %q, %v", head, err)
+	}
+	if all, _ := io.ReadAll(r); string(all) != "short" {
+		t.Fatalf("unexpected body %q", all)
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	cases := []struct {
+		declared, detected string
+		want               bool
+	}{
+		{"image/png", "image/png", true},
+		{"IMAGE/JPG", "image/jpeg", true},
+		{"", "text/html", true},
+		{"application/octet-stream", "text/html", true},
+		{"image/x-foo", Unknown, true},
+		{"text/csv; charset=utf-8", "text/plain", true},
+		{"application/json", "text/plain", true},
+		{"image/png", "text/plain", false},
+		{"image/png", "text/html", false},
+		{"text/plain", "text/html", false},
+		{"text/html", "text/plain", false},
+		{"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/zip", true},
+		{"application/msword", "application/x-ole-storage", true},
+		{"application/pdf", "application/zip", false},
+		{"application/rss+xml", "text/xml", true},
+		{"application/x-tar", "application/gzip", true},
+	}
+	for _, c := range cases {
+		if got := Compatible(c.declared, c.detected); got != c.want {
+			t.Errorf("Compatible(%q, %q) = %v, want %v", c.declared, c.detected, got, c.want)
+		}
+	}
+}
+
+func TestRisky(t *testing.T) {
+	for _, m := range []string{"text/html", "image/svg+xml", "application/xhtml+xml", "text/xml", "text/javascript", "application/pdf", "", Unknown} {
+		if !Risky(m) {
+			t.Errorf("expected %q to be risky", m)
+		}
+	}
+	for _, m := range []string{"image/png", "image/jpeg", "video/mp4", "audio/mpeg", "text/plain; charset=utf-8", "application/json"} {
+		if Risky(m) {
+			t.Errorf("expected %q to be safe", m)
+		}
+	}
+}
+
+func TestList_Match(t *testing.T) {
+	l := ParseList(" image/* , application/PDF,, application/x-zip-compressed")
+	if len(l) != 3 {
+		t.Fatalf("unexpected list %v", l)
+	}
+	for _, m := range []string{"image/png", "application/pdf", "application/zip", "Image/JPEG; q=1"} {
+		if !l.Match(m) {
+			t.Errorf("expected %q to match", m)
+		}
+	}
+	for _, m := range []string{"text/html", "imagex/png", "application/pdfx"} {
+		if l.Match(m) {
+			t.Errorf("expected %q not to match", m)
+		}
+	}
+	if !ParseList("*/*").Match("text/html") {
+		t.Error("expected */* to match anything")
+	}
+	if ParseList("").Match("text/html") {
+		t.Error("expected empty list to match nothing")
+	}
+}
@@ -0,0 +1,450 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	// ErrUnknownMasterKey is returned when a data key is wrapped by a master key that is not configured
+	ErrUnknownMasterKey = errors.New("unknown master key")
+	// ErrCorrupted is returned when an encrypted blob fails authentication
+	ErrCorrupted = errors.New("encrypted blob is corrupted")
+)
+
+// MasterKey encrypts (wraps) per-blob data keys. The ID is stored next to every
+// wrapped key so that old master keys can still unwrap after rotation.
+type MasterKey struct {
+	ID  string
+	Key []byte
+}
+
+// ParseMasterKeys parses "kid1:<base64 32 bytes>,kid2:..." (commas or newlines).
+// The first key wraps new data keys, the others are only used to unwrap.
+func ParseMasterKeys(spec string) ([]MasterKey, error) {
+	var keys []MasterKey
+	seen := map[string]bool{}
+	for _, part := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(part, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("master key %q must look like kid:key", part)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate master key id %q", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("master key %q: %w", id, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("master key %q must be 32 bytes", id)
+		}
+		seen[id] = true
+		keys = append(keys, MasterKey{ID: id, Key: key})
+	}
+	return keys, nil
+}
+
+// WrappedKey is a blob data key encrypted by a master key. A blob gets a new data key
+// (and version) on every Put; the version is written into the blob header.
+type WrappedKey struct {
+	BlobKey     string `db:"blob_key"`
+	Version     string `db:"version"`
+	MasterKeyID string `db:"master_key_id"`
+	Key         []byte `db:"wrapped_key"`
+}
+
+// KeyStore keeps wrapped data keys outside of the blobs, so that rotating the master
+// key rewrites only these small records
+type KeyStore interface {
+	PutKey(k *WrappedKey) error
+	// GetKey returns ErrNotFound when there is no such key
+	GetKey(blobKey, version string) (*WrappedKey, error)
+	// DeleteKey deletes one version of the key of blobKey
+	DeleteKey(blobKey, version string) error
+	// DeleteKeys deletes all versions of blobKey except keep ("" deletes all)
+	DeleteKeys(blobKey, keep string) error
+	// ListStale returns keys wrapped by any master key other than masterKeyID
+	ListStale(masterKeyID string, limit int) ([]WrappedKey, error)
+	// UpdateKey stores a re-wrapped key unless it was re-wrapped or deleted since it was read
+	UpdateKey(k *WrappedKey, oldMasterKeyID string) error
+}
+
+const (
+	encMagic   = "ASTRAENC"
+	encFormat  = 1
+	versionLen = 16
+	headerLen  = len(encMagic) + 1 + 4 + versionLen
+	// defaultSegment is the plaintext size of one GCM segment; Range reads decrypt whole segments
+	defaultSegment = 64 << 10
+)
+
+// EncryptedStore encrypts blobs of the inner store with AES-256-GCM (envelope encryption).
+//
+// Every Put generates a random data key, wraps it with the active master key and stores
+// it in the KeyStore. The blob is a header (magic, format, segment size, key version)
+// followed by segments sealed independently: the nonce is the segment number plus a
+// "last segment" flag, and the header and blob key are authenticated, so segments can't
+// be reordered, truncated or moved to another blob. Independent segments let Open seek
+// without decrypting the whole file. Blobs written before encryption was enabled
+// (without the header) are read as is.
+type EncryptedStore struct {
+	inner   BlobStore
+	keys    KeyStore
+	active  MasterKey
+	masters map[string]MasterKey
+	segment int
+}
+
+func NewEncryptedStore(inner BlobStore, keys KeyStore, masters []MasterKey) (*EncryptedStore, error) {
+	if len(masters) == 0 {
+		return nil, errors.New("at least one master key is required")
+	}
+	s := &EncryptedStore{inner: inner, keys: keys, active: masters[0], masters: make(map[string]MasterKey, len(masters)), segment: defaultSegment}
+	for _, m := range masters {
+		s.masters[m.ID] = m
+	}
+	return s, nil
+}
+
+// Put returns the number of plaintext bytes written
+func (s *EncryptedStore) Put(key string, r io.Reader) (int64, error) {
+	dataKey := make([]byte, 32)
+	versionRaw := make([]byte, versionLen)
+	if _, err := rand.Read(dataKey); err != nil {
+		return 0, err
+	}
+	if _, err := rand.Read(versionRaw); err != nil {
+		return 0, err
+	}
+	version := hex.EncodeToString(versionRaw)
+	wrapped, err := s.wrap(s.active, key, version, dataKey)
+	if err != nil {
+		return 0, err
+	}
+	// The key is saved before the blob: a blob must never exist without its key.
+	// If the write fails the previous blob and its key stay in place, and the new
+	// key, which no blob refers to, is deleted.
+	if err := s.keys.PutKey(&WrappedKey{BlobKey: key, Version: version, MasterKeyID: s.active.ID, Key: wrapped}); err != nil {
+		return 0, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return 0, err
+	}
+	header := make([]byte, 0, headerLen)
+	header = append(header, encMagic...)
+	header = append(header, encFormat)
+	header = binary.BigEndian.AppendUint32(header, uint32(s.segment))
+	header = append(header, versionRaw...)
+	enc := &encryptReader{src: r, aead: aead, aad: append(header, key...), segment: s.segment, out: header}
+	if _, err := s.inner.Put(key, enc); err != nil {
+		if derr := s.keys.DeleteKey(key, version); derr != nil {
+			return 0, errors.Join(err, fmt.Errorf("delete unused data key: %w", derr))
+		}
+		return 0, err
+	}
+	// Keys of the overwritten content are no longer needed
+	if err := s.keys.DeleteKeys(key, version); err != nil {
+		return 0, err
+	}
+	return enc.plain, nil
+}
+
+func (s *EncryptedStore) Open(key string) (io.ReadSeekCloser, error) {
+	f, err := s.inner.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, headerLen)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		f.Close()
+		return nil, err
+	}
+	if n < headerLen || string(header[:len(encMagic)]) != encMagic {
+		// Written before encryption was enabled
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return f, nil
+	}
+	dec, err := s.openEncrypted(key, header, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return dec, nil
+}
+
+func (s *EncryptedStore) openEncrypted(key string, header []byte, f io.ReadSeekCloser) (*decryptReader, error) {
+	if header[len(encMagic)] != encFormat {
+		return nil, fmt.Errorf("unsupported encrypted blob format %d", header[len(encMagic)])
+	}
+	segment := int(binary.BigEndian.Uint32(header[len(encMagic)+1:]))
+	if segment <= 0 {
+		return nil, ErrCorrupted
+	}
+	version := hex.EncodeToString(header[headerLen-versionLen:])
+	wk, err := s.keys.GetKey(key, version)
+	if err != nil {
+		return nil, fmt.Errorf("data key of blob %q: %w", key, err)
+	}
+	dataKey, err := s.unwrap(wk)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	// Every blob has at least one (possibly empty) sealed segment
+	body := end - int64(headerLen)
+	sealed := int64(segment + aead.Overhead())
+	if body < int64(aead.Overhead()) {
+		return nil, ErrCorrupted
+	}
+	count := (body + sealed - 1) / sealed
+	if body-(count-1)*sealed < int64(aead.Overhead()) {
+		return nil, ErrCorrupted
+	}
+	size := body - count*int64(aead.Overhead())
+	return &decryptReader{f: f, aead: aead, aad: append(header, key...), segment: segment, count: count, size: size, cached: -1}, nil
+}
+
+// Remove deletes the blob and its data keys
+func (s *EncryptedStore) Remove(key string) error {
+	if err := s.inner.Remove(key); err != nil {
+		return err
+	}
+	return s.keys.DeleteKeys(key, "")
+}
+
+// Rotate re-wraps data keys wrapped by old master keys with the active one, batch keys
+// at a time. Blobs are not touched. Returns the number of re-wrapped keys.
+func (s *EncryptedStore) Rotate(batch int) (int, error) {
+	done := 0
+	for {
+		stale, err := s.keys.ListStale(s.active.ID, batch)
+		if err != nil {
+			return done, err
+		}
+		for i := range stale {
+			k := &stale[i]
+			dataKey, err := s.unwrap(k)
+			if err != nil {
+				return done, err
+			}
+			old := k.MasterKeyID
+			if k.Key, err = s.wrap(s.active, k.BlobKey, k.Version, dataKey); err != nil {
+				return done, err
+			}
+			k.MasterKeyID = s.active.ID
+			if err := s.keys.UpdateKey(k, old); err != nil {
+				return done, err
+			}
+			done++
+		}
+		if len(stale) < batch {
+			return done, nil
+		}
+	}
+}
+
+// wrap seals the data key with a master key; the blob key and version are authenticated
+// so a wrapped key can't be reused for another blob
+func (s *EncryptedStore) wrap(m MasterKey, blobKey, version string, dataKey []byte) ([]byte, error) {
+	aead, err := newGCM(m.Key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, dataKey, wrapAAD(m.ID, blobKey, version)), nil
+}
+
+func (s *EncryptedStore) unwrap(k *WrappedKey) ([]byte, error) {
+	m, ok := s.masters[k.MasterKeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownMasterKey, k.MasterKeyID)
+	}
+	aead, err := newGCM(m.Key)
+	if err != nil {
+		return nil, err
+	}
+	if len(k.Key) < aead.NonceSize() {
+		return nil, ErrCorrupted
+	}
+	nonce, sealed := k.Key[:aead.NonceSize()], k.Key[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, wrapAAD(m.ID, k.BlobKey, k.Version))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key of blob %q: %w", k.BlobKey, ErrCorrupted)
+	}
+	return dataKey, nil
+}
+
+func wrapAAD(masterID, blobKey, version string) []byte {
+	return []byte(masterID + "\x00" + blobKey + "\x00" + version)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// segmentNonce is the segment number followed by a flag marking the last segment,
+// which makes truncation at a segment boundary detectable
+func segmentNonce(size int, n int64, last bool) []byte {
+	nonce := make([]byte, size)
+	binary.BigEndian.PutUint64(nonce[size-9:], uint64(n))
+	if last {
+		nonce[size-1] = 1
+	}
+	return nonce
+}
+
+// encryptReader reads plaintext from src and yields the header followed by sealed segments
+type encryptReader struct {
+	src     io.Reader
+	aead    cipher.AEAD
+	aad     []byte
+	segment int
+	out     []byte
+	pending []byte
+	n       int64
+	plain   int64
+	done    bool
+	err     error
+}
+
+func (e *encryptReader) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.err != nil {
+			return 0, e.err
+		}
+		if e.done {
+			return 0, io.EOF
+		}
+		e.err = e.fill()
+	}
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+// fill seals the next segment. One byte more than a segment is read ahead to know
+// whether the segment is the last one.
+func (e *encryptReader) fill() error {
+	for len(e.pending) <= e.segment {
+		buf := make([]byte, e.segment+1-len(e.pending))
+		n, err := io.ReadFull(e.src, buf)
+		e.pending = append(e.pending, buf[:n]...)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			e.out = e.aead.Seal(nil, segmentNonce(e.aead.NonceSize(), e.n, true), e.pending, e.aad)
+			e.plain += int64(len(e.pending))
+			e.pending, e.done = nil, true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	e.out = e.aead.Seal(nil, segmentNonce(e.aead.NonceSize(), e.n, false), e.pending[:e.segment], e.aad)
+	e.plain += int64(e.segment)
+	e.pending = bytes.Clone(e.pending[e.segment:])
+	e.n++
+	return nil
+}
+
+// decryptReader decrypts the segment under the current offset on demand
+type decryptReader struct {
+	f       io.ReadSeekCloser
+	aead    cipher.AEAD
+	aad     []byte
+	segment int
+	count   int64
+	size    int64
+	off     int64
+	cached  int64
+	plain   []byte
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	if d.off >= d.size {
+		return 0, io.EOF
+	}
+	idx := d.off / int64(d.segment)
+	if idx != d.cached {
+		if err := d.load(idx); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.plain[d.off-idx*int64(d.segment):])
+	d.off += int64(n)
+	return n, nil
+}
+
+func (d *decryptReader) load(idx int64) error {
+	sealed := int64(d.segment + d.aead.Overhead())
+	if _, err := d.f.Seek(int64(headerLen)+idx*sealed, io.SeekStart); err != nil {
+		return err
+	}
+	length := sealed
+	if idx == d.count-1 {
+		length = d.size - idx*int64(d.segment) + int64(d.aead.Overhead())
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(d.f, buf); err != nil {
+		return err
+	}
+	plain, err := d.aead.Open(buf[:0], segmentNonce(d.aead.NonceSize(), idx, idx == d.count-1), buf, d.aad)
+	if err != nil {
+		return ErrCorrupted
+	}
+	d.plain, d.cached = plain, idx
+	return nil
+}
+
+func (d *decryptReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += d.off
+	case io.SeekEnd:
+		offset += d.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	d.off = offset
+	return offset, nil
+}
+
+func (d *decryptReader) Close() error {
+	return d.f.Close()
+}
@@ -0,0 +1,292 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// memKeys is an in-memory KeyStore
+type memKeys map[string]WrappedKey
+
+func (m memKeys) PutKey(k *WrappedKey) error {
+	m[k.BlobKey+"/"+k.Version] = *k
+	return nil
+}
+
+func (m memKeys) GetKey(blobKey, version string) (*WrappedKey, error) {
+	k, ok := m[blobKey+"/"+version]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &k, nil
+}
+
+func (m memKeys) DeleteKey(blobKey, version string) error {
+	delete(m, blobKey+"/"+version)
+	return nil
+}
+
+func (m memKeys) DeleteKeys(blobKey, keep string) error {
+	for id, k := range m {
+		if k.BlobKey == blobKey && k.Version != keep {
+			delete(m, id)
+		}
+	}
+	return nil
+}
+
+func (m memKeys) ListStale(masterKeyID string, limit int) ([]WrappedKey, error) {
+	var stale []WrappedKey
+	for _, k := range m {
+		if k.MasterKeyID != masterKeyID && len(stale) < limit {
+			stale = append(stale, k)
+		}
+	}
+	return stale, nil
+}
+
+func (m memKeys) UpdateKey(k *WrappedKey, oldMasterKeyID string) error {
+	if cur, ok := m[k.BlobKey+"/"+k.Version]; ok && cur.MasterKeyID == oldMasterKeyID {
+		m[k.BlobKey+"/"+k.Version] = *k
+	}
+	return nil
+}
+
+func newMasterKey(t *testing.T, id string) MasterKey {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("cannot generate key: %v", err)
+	}
+	return MasterKey{ID: id, Key: key}
+}
+
+func newTestEncryptedStore(t *testing.T, segment int) (*EncryptedStore, *FileStore, memKeys) {
+	dir := t.TempDir()
+	inner, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	keys := memKeys{}
+	store, err := NewEncryptedStore(inner, keys, []MasterKey{newMasterKey(t, "k1")})
+	if err != nil {
+		t.Fatalf("failed to create encrypted store: %v", err)
+	}
+	store.segment = segment
+	return store, inner, keys
+}
+
+func readBlob(t *testing.T, store BlobStore, key string) []byte {
+	f, err := store.Open(key)
+	if err != nil {
+		t.Fatalf("cannot open %s: %v", key, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("cannot read %s: %v", key, err)
+	}
+	return data
+}
+
+func TestEncryptedStore_RoundTrip(t *testing.T) {
+	store, inner, _ := newTestEncryptedStore(t, 16)
+	for _, size := range []int{0, 1, 15, 16, 17, 32, 100} {
+		plain := bytes.Repeat([]byte("secret!"), size/7+1)[:size]
+		n, err := store.Put("doc1", bytes.NewReader(plain))
+		if err != nil || n != int64(size) {
+			t.Fatalf("size %d: unexpected result %d, %v", size, n, err)
+		}
+		if got := readBlob(t, store, "doc1"); !bytes.Equal(got, plain) {
+			t.Fatalf("size %d: expected %q, got %q", size, plain, got)
+		}
+		if raw := readBlob(t, inner, "doc1"); size > 0 && bytes.Contains(raw, []byte("secret!")) {
+			t.Fatalf("size %d: plaintext is stored on disk", size)
+		}
+	}
+}
+
+func TestEncryptedStore_Seek(t *testing.T) {
+	store, _, _ := newTestEncryptedStore(t, 16)
+	plain := make([]byte, 100)
+	for i := range plain {
+		plain[i] = byte(i)
+	}
+	if _, err := store.Put("doc1", bytes.NewReader(plain)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, err := store.Open("doc1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	if end, err := f.Seek(0, io.SeekEnd); err != nil || end != 100 {
+		t.Fatalf("expected plaintext size 100, got %d, %v", end, err)
+	}
+	// Ranges across segment boundaries, as http.ServeContent reads them
+	for _, r := range [][2]int{{0, 5}, {14, 20}, {31, 33}, {90, 100}, {50, 51}, {3, 100}} {
+		if _, err := f.Seek(int64(r[0]), io.SeekStart); err != nil {
+			t.Fatalf("seek: %v", err)
+		}
+		got := make([]byte, r[1]-r[0])
+		if _, err := io.ReadFull(f, got); err != nil || !bytes.Equal(got, plain[r[0]:r[1]]) {
+			t.Fatalf("range %v: unexpected %v, %v", r, got, err)
+		}
+	}
+}
+
+func TestEncryptedStore_Tampering(t *testing.T) {
+	store, inner, _ := newTestEncryptedStore(t, 16)
+	plain := strings.Repeat("x", 40)
+	_, _ = store.Put("doc1", strings.NewReader(plain))
+	_, _ = store.Put("doc2", strings.NewReader(plain))
+	raw := readBlob(t, inner, "doc1")
+
+	flipped := bytes.Clone(raw)
+	flipped[len(flipped)-1] ^= 1
+	_, _ = inner.Put("doc1", bytes.NewReader(flipped))
+	if _, err := io.ReadAll(mustOpen(t, store, "doc1")); !errors.Is(err, ErrCorrupted) {
+		t.Fatalf("expected ErrCorrupted for a modified blob, got %v", err)
+	}
+
+	// Dropping the last segment leaves a segment that is not marked as last
+	_, _ = inner.Put("doc1", bytes.NewReader(raw[:headerLen+2*(16+16)]))
+	if _, err := io.ReadAll(mustOpen(t, store, "doc1")); !errors.Is(err, ErrCorrupted) {
+		t.Fatalf("expected ErrCorrupted for a truncated blob, got %v", err)
+	}
+
+	// A blob copied from another key does not decrypt
+	_, _ = inner.Put("doc1", bytes.NewReader(readBlob(t, inner, "doc2")))
+	if _, err := store.Open("doc1"); err == nil {
+		t.Fatal("expected a blob moved from another key to be rejected")
+	}
+}
+
+func mustOpen(t *testing.T, store BlobStore, key string) io.ReadSeekCloser {
+	t.Helper()
+	f, err := store.Open(key)
+	if err != nil {
+		t.Fatalf("cannot open %s: %v", key, err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestEncryptedStore_LegacyPlaintext(t *testing.T) {
+	store, inner, _ := newTestEncryptedStore(t, 16)
+	_, _ = inner.Put("old", strings.NewReader("written before encryption"))
+	if got := readBlob(t, store, "old"); string(got) != "written before encryption" {
+		t.Fatalf("unexpected legacy content %q", got)
+	}
+}
+
+func TestEncryptedStore_Keys(t *testing.T) {
+	store, inner, keys := newTestEncryptedStore(t, 16)
+	_, _ = store.Put("doc1", strings.NewReader("v1"))
+	_, _ = store.Put("doc1", strings.NewReader("v2"))
+	if len(keys) != 1 {
+		t.Fatalf("expected only the current data key to remain, got %d", len(keys))
+	}
+
+	// A failed overwrite keeps the previous content readable
+	if _, err := store.Put("doc1", io.MultiReader(strings.NewReader("v3"), errReader{})); err == nil {
+		t.Fatal("expected error from failing reader")
+	}
+	if got := readBlob(t, store, "doc1"); string(got) != "v2" {
+		t.Fatalf("expected v2, got %q", got)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("expected the key of the failed write to be deleted, got %d keys", len(keys))
+	}
+
+	if err := store.Remove("doc1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("expected keys to be removed, got %d", len(keys))
+	}
+	if _, err := inner.Open("doc1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the blob to be removed, got %v", err)
+	}
+}
+
+func TestEncryptedStore_Rotate(t *testing.T) {
+	old, inner, keys := newTestEncryptedStore(t, 16)
+	for _, id := range []string{"a", "b", "c"} {
+		_, _ = old.Put(id, strings.NewReader("content of "+id))
+	}
+	before := map[string][]byte{}
+	for _, id := range []string{"a", "b", "c"} {
+		before[id] = readBlob(t, inner, id)
+	}
+
+	k2 := newMasterKey(t, "k2")
+	rotated, err := NewEncryptedStore(inner, keys, []MasterKey{k2, old.active})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n, err := rotated.Rotate(2)
+	if err != nil || n != 3 {
+		t.Fatalf("expected 3 re-wrapped keys, got %d, %v", n, err)
+	}
+	for _, k := range keys {
+		if k.MasterKeyID != "k2" {
+			t.Fatalf("key %s is still wrapped by %s", k.BlobKey, k.MasterKeyID)
+		}
+	}
+
+	// Blobs are not rewritten and are readable without the old master key
+	onlyNew, _ := NewEncryptedStore(inner, keys, []MasterKey{k2})
+	for _, id := range []string{"a", "b", "c"} {
+		if !bytes.Equal(readBlob(t, inner, id), before[id]) {
+			t.Fatalf("blob %s was rewritten", id)
+		}
+		if got := readBlob(t, onlyNew, id); string(got) != "content of "+id {
+			t.Fatalf("unexpected content %q", got)
+		}
+	}
+	if _, err := old.Open("a"); !errors.Is(err, ErrUnknownMasterKey) {
+		t.Fatalf("expected ErrUnknownMasterKey without the new key, got %v", err)
+	}
+}
+
+func TestEncryptedStore_LargeBlob(t *testing.T) {
+	dir := t.TempDir()
+	inner, _ := NewFileStore(dir)
+	store, _ := NewEncryptedStore(inner, memKeys{}, []MasterKey{newMasterKey(t, "k1")})
+	plain := bytes.Repeat([]byte{1, 2, 3}, 100000)
+	if _, err := store.Put("big", bytes.NewReader(plain)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "big"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The header plus one tag per 64 KiB
+	if overhead := info.Size() - int64(len(plain)); overhead > int64(headerLen+16*(len(plain)/defaultSegment+1)) {
+		t.Fatalf("unexpected overhead %d", overhead)
+	}
+	if got := readBlob(t, store, "big"); !bytes.Equal(got, plain) {
+		t.Fatal("large blob does not round-trip")
+	}
+}
+
+func TestParseMasterKeys(t *testing.T) {
+	k := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	keys, err := ParseMasterKeys("k2:" + k + ",\n k1:" + k + "\n")
+	if err != nil || len(keys) != 2 || keys[0].ID != "k2" || len(keys[1].Key) != 32 {
+		t.Fatalf("unexpected keys %+v, %v", keys, err)
+	}
+	for _, spec := range []string{"k1", ":" + k, "k1:short", "k1:" + k + ",k1:" + k, "k1:!!"} {
+		if _, err := ParseMasterKeys(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when a blob does not exist
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps document contents. Keys are document IDs.
+type BlobStore interface {
+	// Put stores the whole reader under key and returns the number of bytes written
+	Put(key string, r io.Reader) (int64, error)
+	// Open returns a seekable reader so that Range requests can be served
+	Open(key string) (io.ReadSeekCloser, error)
+	// Remove deletes the blob; removing a missing blob is not an error
+	Remove(key string) error
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFileStore_PutOpenRemove(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	n, err := store.Put("doc1", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n != 5 {
+		t.Fatalf("expected 5 bytes written, got %d", n)
+	}
+
+	f, err := store.Open("doc1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != "hello" {
+		t.Fatalf("expected 'hello', got %q", data)
+	}
+
+	if err := store.Remove("doc1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := store.Open("doc1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := store.Remove("doc1"); err != nil {
+		t.Fatalf("expected removing a missing blob to succeed, got %v", err)
+	}
+}
+
+func TestFileStore_InvalidKey(t *testing.T) {
+	store, _ := NewFileStore(t.TempDir())
+
+	for _, key := range []string{"", "..", "../etc/passwd", `a\b`} {
+		if _, err := store.Put(key, strings.NewReader("x")); err == nil {
+			t.Fatalf("expected error for key %q", key)
+		}
+	}
+}
+
+func TestFileStore_FailedPutKeepsOldBlob(t *testing.T) {
+	store, _ := NewFileStore(t.TempDir())
+	_, _ = store.Put("doc1", strings.NewReader("old"))
+
+	if _, err := store.Put("doc1", io.MultiReader(strings.NewReader("new"), errReader{})); err == nil {
+		t.Fatal("expected error from failing reader")
+	}
+
+	f, err := store.Open("doc1")
+	if err != nil {
+		t.Fatalf("expected old blob to remain, got %v", err)
+	}
+	defer f.Close()
+	data, _ := io.ReadAll(f)
+	if string(data) != "old" {
+		t.Fatalf("expected 'old', got %q", data)
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("boom") }
+
+func TestFileStore_Rename(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	_, _ = store.Put("old", strings.NewReader("content"))
+	_, _ = store.Put("taken", strings.NewReader("other"))
+
+	if err := store.Rename("old", "taken"); err == nil {
+		t.Fatal("expected existing blob not to be replaced")
+	}
+	if err := store.Rename("old", "new"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	f, err := store.Open("new")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != "content" {
+		t.Fatalf("expected 'content', got %q", data)
+	}
+	if err := store.Rename("old", "other"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN roles TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE refresh_tokens (
+    id UUID PRIMARY KEY,
+    family_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX refresh_tokens_family_idx ON refresh_tokens (family_id);
+
+-- Отозванные access-токены (logout) до истечения их срока
+CREATE TABLE revoked_access_tokens (
+    token_id UUID PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+CREATE INDEX revoked_access_tokens_expires_idx ON revoked_access_tokens (expires_at);
+-- +goose Down
+DROP TABLE IF EXISTS revoked_access_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+ALTER TABLE users DROP COLUMN IF EXISTS roles;
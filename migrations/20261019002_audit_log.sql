@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY,
+    actor_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    method VARCHAR(16) NOT NULL DEFAULT '',
+    path TEXT NOT NULL DEFAULT '',
+    status INT NOT NULL DEFAULT 0,
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_log_actor_idx ON audit_log (actor_id, created_at DESC);
+CREATE INDEX audit_log_user_idx ON audit_log (user_id, created_at DESC);
+-- +goose Down
+DROP TABLE IF EXISTS audit_log;
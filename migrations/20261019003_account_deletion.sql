@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN delete_after TIMESTAMP;
+
+CREATE INDEX users_delete_after_idx ON users (delete_after) WHERE delete_after IS NOT NULL;
+
+-- Токены пользователя, выданные раньше not_before, недействительны. Без внешнего ключа:
+-- запись должна пережить удаление пользователя, пока не истекут его токены
+CREATE TABLE access_token_cutoffs (
+    user_id UUID PRIMARY KEY,
+    not_before TIMESTAMP NOT NULL
+);
+-- +goose Down
+DROP TABLE IF EXISTS access_token_cutoffs;
+DROP INDEX IF EXISTS users_delete_after_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS delete_after;
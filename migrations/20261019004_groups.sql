@@ -0,0 +1,42 @@
+-- +goose Up
+CREATE TABLE groups (
+    id UUID PRIMARY KEY,
+    name VARCHAR(64) UNIQUE NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE group_members (
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL DEFAULT 'member',
+    added_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX group_members_user_idx ON group_members (user_id);
+
+-- Гранты ссылаются на субъектов: логины пользователей переводим в user:<id>
+UPDATE documents d SET grants = ARRAY(
+    SELECT COALESCE('user:' || u.id::text, g.value)
+    FROM unnest(d.grants) WITH ORDINALITY AS g(value, pos)
+    LEFT JOIN users u ON u.login = g.value
+    ORDER BY g.pos
+)
+WHERE cardinality(d.grants) > 0;
+
+CREATE INDEX documents_grants_idx ON documents USING GIN (grants);
+-- +goose Down
+DROP INDEX IF EXISTS documents_grants_idx;
+
+UPDATE documents d SET grants = ARRAY(
+    SELECT COALESCE(u.login, g.value)
+    FROM unnest(d.grants) WITH ORDINALITY AS g(value, pos)
+    LEFT JOIN users u ON 'user:' || u.id::text = g.value
+    WHERE g.value NOT LIKE 'group:%'
+    ORDER BY g.pos
+)
+WHERE cardinality(d.grants) > 0;
+
+DROP TABLE IF EXISTS group_members;
+DROP TABLE IF EXISTS groups;
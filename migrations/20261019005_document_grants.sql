@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE document_grants (
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    principal VARCHAR(64) NOT NULL,
+    permission VARCHAR(16) NOT NULL DEFAULT 'read',
+    expires_at TIMESTAMP,
+    granted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (document_id, principal)
+);
+
+CREATE INDEX document_grants_principal_idx ON document_grants (principal);
+
+-- Прежние гранты давали только чтение
+INSERT INTO document_grants (document_id, principal, permission, granted_by)
+SELECT DISTINCT d.id, g.value, 'read', d.owner
+FROM documents d, unnest(d.grants) AS g(value)
+WHERE g.value LIKE 'user:%' OR g.value LIKE 'group:%';
+
+DROP INDEX IF EXISTS documents_grants_idx;
+ALTER TABLE documents DROP COLUMN grants;
+-- +goose Down
+ALTER TABLE documents ADD COLUMN grants TEXT[];
+
+UPDATE documents d SET grants = ARRAY(
+    SELECT g.principal FROM document_grants g WHERE g.document_id = d.id ORDER BY g.created_at
+);
+
+CREATE INDEX documents_grants_idx ON documents USING GIN (grants);
+
+DROP TABLE IF EXISTS document_grants;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE share_links (
+    id UUID PRIMARY KEY,
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    permission VARCHAR(16) NOT NULL DEFAULT 'read',
+    password_hash VARCHAR(128) NOT NULL DEFAULT '',
+    max_downloads INT,
+    downloads INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX share_links_document_idx ON share_links (document_id, created_at DESC);
+-- +goose Down
+DROP TABLE IF EXISTS share_links;
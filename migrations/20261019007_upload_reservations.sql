@@ -0,0 +1,20 @@
+-- +goose Up
+-- Документ загрузки по ссылке создаётся при выдаче ссылки и скрыт до загрузки файла;
+-- резерв — одноразовый токен и заявленный размер
+ALTER TABLE documents ADD COLUMN upload_pending BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE upload_reservations (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    mime VARCHAR(255) NOT NULL DEFAULT '',
+    size BIGINT,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX upload_reservations_expires_idx ON upload_reservations (expires_at);
+-- +goose Down
+DROP TABLE IF EXISTS upload_reservations;
+DELETE FROM documents WHERE upload_pending;
+ALTER TABLE documents DROP COLUMN IF EXISTS upload_pending;
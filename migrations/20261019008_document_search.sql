@@ -0,0 +1,14 @@
+-- +goose Up
+-- Имя весит больше содержимого; в JSON индексируются только строковые значения.
+-- Конфигурация russian стеммит и латиницу, english добавлена для английских стоп-слов и словоформ.
+ALTER TABLE documents ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(jsonb_to_tsvector('russian', coalesce(json_data, '{}'::jsonb), '["string"]'), 'B') ||
+    setweight(jsonb_to_tsvector('english', coalesce(json_data, '{}'::jsonb), '["string"]'), 'B')
+) STORED;
+
+CREATE INDEX documents_search_idx ON documents USING GIN (search_vector);
+-- +goose Down
+DROP INDEX IF EXISTS documents_search_idx;
+ALTER TABLE documents DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,5 @@
+-- +goose Up
+-- Фильтры where на равенство и вхождение по ключам объектов выражаются через json_data @> ...
+CREATE INDEX documents_json_data_idx ON documents USING GIN (json_data);
+-- +goose Down
+DROP INDEX IF EXISTS documents_json_data_idx;
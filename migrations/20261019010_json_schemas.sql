@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE json_schemas (
+    id UUID PRIMARY KEY,
+    owner UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    schema JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (owner, name)
+);
+
+-- NO ACTION, а не RESTRICT: при удалении пользователя его документы и схемы
+-- удаляются одним каскадом, и ссылка проверяется уже в конце выражения
+ALTER TABLE documents ADD COLUMN schema_id UUID REFERENCES json_schemas(id);
+CREATE INDEX documents_schema_idx ON documents (schema_id) WHERE schema_id IS NOT NULL;
+-- +goose Down
+DROP INDEX IF EXISTS documents_schema_idx;
+ALTER TABLE documents DROP COLUMN IF EXISTS schema_id;
+DROP TABLE IF EXISTS json_schemas;
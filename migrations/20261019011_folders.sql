@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE folders (
+    id UUID PRIMARY KEY,
+    owner UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES folders(id),
+    name VARCHAR(255) NOT NULL,
+    -- id предков от корня и самой папки: по нему наследуются гранты и переносится поддерево
+    path UUID[] NOT NULL,
+    schema_id UUID REFERENCES json_schemas(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX folders_name_idx ON folders (owner, COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'), name);
+CREATE INDEX folders_parent_idx ON folders (parent_id);
+CREATE INDEX folders_path_idx ON folders USING GIN (path);
+
+CREATE TABLE folder_grants (
+    folder_id UUID NOT NULL REFERENCES folders(id) ON DELETE CASCADE,
+    principal VARCHAR(64) NOT NULL,
+    permission VARCHAR(16) NOT NULL DEFAULT 'read',
+    expires_at TIMESTAMP,
+    granted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (folder_id, principal)
+);
+
+CREATE INDEX folder_grants_principal_idx ON folder_grants (principal);
+
+ALTER TABLE documents ADD COLUMN folder_id UUID REFERENCES folders(id);
+CREATE INDEX documents_folder_idx ON documents (folder_id) WHERE folder_id IS NOT NULL;
+-- +goose Down
+DROP INDEX IF EXISTS documents_folder_idx;
+ALTER TABLE documents DROP COLUMN IF EXISTS folder_id;
+DROP TABLE IF EXISTS folder_grants;
+DROP TABLE IF EXISTS folders;
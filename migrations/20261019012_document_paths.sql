@@ -0,0 +1,20 @@
+-- +goose Up
+-- Путь документа — имена папок и имя документа, поэтому '/' в имени недопустим,
+-- а имя уникально в папке владельца. Существующие имена приводятся к этим правилам.
+UPDATE documents SET name = replace(name, '/', '_') WHERE name LIKE '%/%';
+UPDATE documents SET name = id::text WHERE name = '';
+UPDATE documents d SET name = d.name || ' (' || left(d.id::text, 8) || ')'
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY owner, folder_id, name ORDER BY created_at, id) AS n
+    FROM documents
+) dup
+WHERE dup.id = d.id AND dup.n > 1;
+
+ALTER TABLE documents ADD CONSTRAINT documents_path_key UNIQUE NULLS NOT DISTINCT (owner, folder_id, name);
+
+-- Время замены содержимого по пути: от него зависят Last-Modified и условные запросы
+ALTER TABLE documents ADD COLUMN modified_at TIMESTAMP;
+-- +goose Down
+-- Переименования при Up не откатываются
+ALTER TABLE documents DROP COLUMN IF EXISTS modified_at;
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS documents_path_key;
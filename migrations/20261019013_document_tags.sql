@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE documents ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE documents ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+-- Фильтры ?tag= и ?meta.key= выражаются через tags @> ... и metadata @> ...
+CREATE INDEX documents_tags_idx ON documents USING GIN (tags);
+CREATE INDEX documents_metadata_idx ON documents USING GIN (metadata);
+
+-- Схема, привязанная к тегу, проверяет все JSON-документы владельца с этим тегом.
+-- Ссылка на схему без каскада: схему с привязанными тегами удалить нельзя.
+CREATE TABLE tag_schemas (
+    owner UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tag VARCHAR(64) NOT NULL,
+    schema_id UUID NOT NULL REFERENCES json_schemas(id),
+    PRIMARY KEY (owner, tag)
+);
+CREATE INDEX tag_schemas_schema_idx ON tag_schemas (schema_id);
+-- +goose Down
+DROP TABLE IF EXISTS tag_schemas;
+DROP INDEX IF EXISTS documents_metadata_idx;
+DROP INDEX IF EXISTS documents_tags_idx;
+ALTER TABLE documents DROP COLUMN IF EXISTS metadata;
+ALTER TABLE documents DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE documents ADD COLUMN deleted_at TIMESTAMP;
+CREATE INDEX documents_deleted_at_idx ON documents (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Документы в корзине не занимают путь: на их место можно положить новый
+ALTER TABLE documents DROP CONSTRAINT documents_path_key;
+CREATE UNIQUE INDEX documents_path_key ON documents (owner, folder_id, name) NULLS NOT DISTINCT WHERE deleted_at IS NULL;
+
+-- Живые документы не дают удалить папку или схему на уровне сервисов; удаление
+-- отвязывает только документы из корзины, и восстанавливаются они в корень без схемы
+ALTER TABLE documents DROP CONSTRAINT documents_folder_id_fkey,
+    ADD CONSTRAINT documents_folder_id_fkey FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE SET NULL;
+ALTER TABLE documents DROP CONSTRAINT documents_schema_id_fkey,
+    ADD CONSTRAINT documents_schema_id_fkey FOREIGN KEY (schema_id) REFERENCES json_schemas(id) ON DELETE SET NULL;
+-- +goose Down
+DELETE FROM documents WHERE deleted_at IS NOT NULL;
+ALTER TABLE documents DROP CONSTRAINT documents_schema_id_fkey,
+    ADD CONSTRAINT documents_schema_id_fkey FOREIGN KEY (schema_id) REFERENCES json_schemas(id);
+ALTER TABLE documents DROP CONSTRAINT documents_folder_id_fkey,
+    ADD CONSTRAINT documents_folder_id_fkey FOREIGN KEY (folder_id) REFERENCES folders(id);
+DROP INDEX IF EXISTS documents_path_key;
+ALTER TABLE documents ADD CONSTRAINT documents_path_key UNIQUE NULLS NOT DISTINCT (owner, folder_id, name);
+DROP INDEX IF EXISTS documents_deleted_at_idx;
+ALTER TABLE documents DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE documents ADD COLUMN expires_at TIMESTAMP;
+-- По нему очистка выбирает истёкшие документы пачками
+CREATE INDEX documents_expires_at_idx ON documents (expires_at) WHERE expires_at IS NOT NULL;
+
+-- Документ по ссылке до загрузки живёт по сроку ссылки, а заказанный срок получает после неё
+ALTER TABLE upload_reservations ADD COLUMN document_expires_at TIMESTAMP;
+-- +goose Down
+ALTER TABLE upload_reservations DROP COLUMN IF EXISTS document_expires_at;
+DROP INDEX IF EXISTS documents_expires_at_idx;
+ALTER TABLE documents DROP COLUMN IF EXISTS expires_at;
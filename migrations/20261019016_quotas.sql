@@ -0,0 +1,98 @@
+-- +goose Up
+-- size — байты файла или JSON; NULL — размер файла ещё не посчитан (файлы, загруженные до квот)
+ALTER TABLE documents ADD COLUMN size BIGINT;
+UPDATE documents SET size = octet_length(json_data::text) WHERE NOT file AND json_data IS NOT NULL;
+UPDATE documents SET size = 0 WHERE NOT file AND json_data IS NULL;
+CREATE INDEX documents_unsized_idx ON documents (id) WHERE size IS NULL;
+
+-- Занятое место и число документов владельца, включая корзину и ещё не удалённые истёкшие
+CREATE TABLE user_usage (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    bytes BIGINT NOT NULL DEFAULT 0,
+    documents BIGINT NOT NULL DEFAULT 0
+);
+INSERT INTO user_usage (user_id, bytes, documents)
+SELECT owner, COALESCE(SUM(size), 0), COUNT(*) FROM documents WHERE owner IS NOT NULL GROUP BY owner;
+
+-- Личные лимиты; NULL — лимит по умолчанию из конфигурации, 0 — без ограничения
+CREATE TABLE user_quotas (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    max_bytes BIGINT CHECK (max_bytes >= 0),
+    max_documents BIGINT CHECK (max_documents >= 0),
+    max_file_size BIGINT CHECK (max_file_size >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Лимиты по умолчанию из конфигурации; приложение записывает их при старте. 0 — без ограничения
+CREATE TABLE quota_defaults (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    max_bytes BIGINT NOT NULL DEFAULT 0 CHECK (max_bytes >= 0),
+    max_documents BIGINT NOT NULL DEFAULT 0 CHECK (max_documents >= 0),
+    max_file_size BIGINT NOT NULL DEFAULT 0 CHECK (max_file_size >= 0)
+);
+INSERT INTO quota_defaults DEFAULT VALUES;
+
+-- Счётчики меняет и лимиты проверяет триггер в той же транзакции, что и сам документ:
+-- так их не обойдёт ни один путь записи, включая каскадное удаление. Лимиты сверяются
+-- с уже обновлёнными счётчиками: строка user_usage блокируется до конца транзакции, так
+-- что одновременные записи одного владельца видят итоги друг друга и вместе лимит не
+-- превысят. Проверяется только рост: удалять и уменьшать содержимое можно и сверх лимита.
+-- Размер, посчитанный у уже сохранённого файла (astra.quota_exempt), не проверяется.
+-- Коды ошибок: AQ001 — место, AQ002 — число документов, AQ003 — размер файла.
+-- +goose StatementBegin
+CREATE FUNCTION documents_track_usage() RETURNS trigger AS $$
+DECLARE
+    added BOOLEAN := FALSE;
+    grown BIGINT := 0;
+    total_bytes BIGINT;
+    total_documents BIGINT;
+    limit_bytes BIGINT;
+    limit_documents BIGINT;
+    limit_file_size BIGINT;
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.owner IS NOT NULL THEN
+        UPDATE user_usage SET bytes = bytes - COALESCE(OLD.size, 0), documents = documents - 1
+        WHERE user_id = OLD.owner;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.owner IS NOT NULL THEN
+        INSERT INTO user_usage (user_id, bytes, documents) VALUES (NEW.owner, COALESCE(NEW.size, 0), 1)
+        ON CONFLICT (user_id) DO UPDATE
+        SET bytes = user_usage.bytes + EXCLUDED.bytes, documents = user_usage.documents + 1
+        RETURNING bytes, documents INTO total_bytes, total_documents;
+
+        added := TG_OP = 'INSERT' OR OLD.owner IS DISTINCT FROM NEW.owner;
+        IF added THEN
+            grown := COALESCE(NEW.size, 0);
+        ELSE
+            grown := COALESCE(NEW.size, 0) - COALESCE(OLD.size, 0);
+        END IF;
+        IF (added OR grown > 0) AND current_setting('astra.quota_exempt', TRUE) IS DISTINCT FROM 'on' THEN
+            SELECT COALESCE(q.max_bytes, d.max_bytes), COALESCE(q.max_documents, d.max_documents), COALESCE(q.max_file_size, d.max_file_size)
+            INTO limit_bytes, limit_documents, limit_file_size
+            FROM quota_defaults d LEFT JOIN user_quotas q ON q.user_id = NEW.owner;
+            IF grown > 0 AND limit_file_size > 0 AND NEW.size > limit_file_size THEN
+                RAISE EXCEPTION 'file exceeds the maximum file size' USING ERRCODE = 'AQ003';
+            END IF;
+            IF added AND limit_documents > 0 AND total_documents > limit_documents THEN
+                RAISE EXCEPTION 'document count quota exceeded' USING ERRCODE = 'AQ002';
+            END IF;
+            IF grown > 0 AND limit_bytes > 0 AND total_bytes > limit_bytes THEN
+                RAISE EXCEPTION 'storage quota exceeded' USING ERRCODE = 'AQ001';
+            END IF;
+        END IF;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER documents_track_usage AFTER INSERT OR DELETE OR UPDATE OF owner, size ON documents
+    FOR EACH ROW EXECUTE FUNCTION documents_track_usage();
+-- +goose Down
+DROP TRIGGER IF EXISTS documents_track_usage ON documents;
+DROP FUNCTION IF EXISTS documents_track_usage();
+DROP TABLE IF EXISTS quota_defaults;
+DROP TABLE IF EXISTS user_quotas;
+DROP TABLE IF EXISTS user_usage;
+DROP INDEX IF EXISTS documents_unsized_idx;
+ALTER TABLE documents DROP COLUMN IF EXISTS size;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Тип, заявленный при загрузке, если он не совпал с содержимым и файл принят
+-- с найденным типом (MIME_MISMATCH=flag); NULL — расхождения не было
+ALTER TABLE documents ADD COLUMN declared_mime VARCHAR(255);
+-- +goose Down
+ALTER TABLE documents DROP COLUMN IF EXISTS declared_mime;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- scan_status: pending_scan, clean, infected, scan_failed; пусто у JSON-документов
+ALTER TABLE documents
+    ADD COLUMN scan_status VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN scan_signature TEXT,
+    ADD COLUMN scanned_at TIMESTAMP,
+    ADD COLUMN scan_queued_at TIMESTAMP;
+-- Файлы, загруженные до появления проверки, проверит фоновая задача
+UPDATE documents SET scan_status = 'pending_scan', scan_queued_at = now() WHERE file;
+CREATE INDEX documents_pending_scan_idx ON documents (scan_queued_at) WHERE scan_status = 'pending_scan';
+-- +goose Down
+DROP INDEX IF EXISTS documents_pending_scan_idx;
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS scan_queued_at,
+    DROP COLUMN IF EXISTS scanned_at,
+    DROP COLUMN IF EXISTS scan_signature,
+    DROP COLUMN IF EXISTS scan_status;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Ключи данных зашифрованных файлов, зашифрованные мастер-ключом. version — из
+-- заголовка файла: при перезаписи новый ключ добавляется, старый удаляется после записи
+CREATE TABLE blob_keys (
+    blob_key TEXT NOT NULL,
+    version TEXT NOT NULL,
+    master_key_id TEXT NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (blob_key, version)
+);
+CREATE INDEX blob_keys_master_key_idx ON blob_keys (master_key_id);
+-- +goose Down
+DROP TABLE IF EXISTS blob_keys;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- encoding — как файл записан в хранилище: '' — как есть, 'gzip' — сжатым;
+-- stored_size — сколько файл занимает в хранилище. size остаётся исходным размером,
+-- по нему считаются квоты.
+-- blob_key — ключ файла в хранилище; NULL — файл хранится под id документа. Перезапись
+-- пишет файл под новым ключом и переключает документ на него вместе с encoding и
+-- размером, так что строка и файл всегда соответствуют друг другу.
+ALTER TABLE documents
+    ADD COLUMN encoding VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN stored_size BIGINT,
+    ADD COLUMN blob_key TEXT;
+-- +goose Down
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS blob_key,
+    DROP COLUMN IF EXISTS stored_size,
+    DROP COLUMN IF EXISTS encoding;